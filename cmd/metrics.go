@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"near-swap/pkg/plan"
+)
+
+// metricsShutdownTimeout bounds how long the daemon waits for in-flight scrapes on shutdown
+const metricsShutdownTimeout = 5 * time.Second
+
+// startMetricsServer serves the executor's /healthz and /metrics on addr in the background. It
+// fails right away if addr can't be listened on.
+func startMetricsServer(addr string, executor *plan.Executor) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &http.Server{
+		Handler:           executor.MetricsHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			printError(err)
+		}
+	}()
+	return server, nil
+}
+
+// stopMetricsServer shuts the metrics server down, if one was started
+func stopMetricsServer(server *http.Server) {
+	if server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+	defer cancel()
+	_ = server.Shutdown(ctx)
+}
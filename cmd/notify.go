@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"near-swap/config"
+	"near-swap/pkg/plan"
+)
+
+var notifyTestEvent string
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage execution notifications",
+	Long:  `Commands for the notifications the plan daemon sends on execution events.`,
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a sample notification to every configured sink",
+	Long: `Send a sample notification through every configured notification sink and report
+whether each one accepted it. Use this to check your webhook URL and signing secret
+before relying on them in the daemon. Samples are marked with "test": true.
+
+Event types:
+  trade     - a plan's trigger fired (trigger_met)
+  complete  - a swap settled (swap_completed)
+  error     - a swap failed or was refunded (swap_failed)
+
+Examples:
+  near-swap notify test
+  near-swap notify test --event complete`,
+	Args: cobra.NoArgs,
+	Run:  runNotifyTest,
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+
+	notifyTestCmd.Flags().StringVar(&notifyTestEvent, "event", "trade", "Sample event to send: trade, complete or error")
+}
+
+func runNotifyTest(cmd *cobra.Command, args []string) {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	cfg, err := config.Load()
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	results, err := plan.SendTestNotification(cfg.Notifications, notifyTestEvent)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		jsonData, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(jsonData))
+	} else {
+		fmt.Printf("\nSent a sample %s notification (%s):\n\n", notifyTestEvent, plan.TestNotificationKinds[notifyTestEvent])
+		for _, result := range results {
+			if result.Error != "" {
+				fmt.Printf("  %s %s: %s\n", color.RedString("✗"), result.Sink, result.Error)
+			} else {
+				fmt.Printf("  %s %s\n", color.GreenString("✓"), result.Sink)
+			}
+		}
+		fmt.Println()
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
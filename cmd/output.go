@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"near-swap/config"
+	"near-swap/pkg/output"
+)
+
+// addFormatFlag adds --format to a command whose output honors output_format. csvOK is
+// whether the command's result is a list of records that CSV can hold.
+func addFormatFlag(cmd *cobra.Command, csvOK bool) {
+	formats := "table, json or yaml"
+	if csvOK {
+		formats = "table, json, yaml or csv"
+	}
+	cmd.Flags().String("format", "", fmt.Sprintf("Output format: %s (default: output_format from config)", formats))
+}
+
+// outputFormat resolves how a command renders its result: --format, then --json, then the
+// output_format config setting. A csv output_format falls back to table for commands whose
+// result isn't a list; asking for it with --format is an error.
+func outputFormat(cmd *cobra.Command, cfg *config.Config, csvOK bool) (string, error) {
+	if flag := cmd.Flags().Lookup("format"); flag != nil && flag.Changed {
+		format, err := output.ParseFormat(flag.Value.String())
+		if err != nil {
+			return "", err
+		}
+		if format == output.CSV && !csvOK {
+			return "", fmt.Errorf("%s has no csv output; use table, json or yaml", cmd.CommandPath())
+		}
+		return format, nil
+	}
+
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		return output.JSON, nil
+	}
+
+	format, err := output.ParseFormat(cfg.OutputFormat)
+	if err != nil {
+		return "", fmt.Errorf("output_format: %w", err)
+	}
+	if format == output.CSV && !csvOK {
+		return output.Table, nil
+	}
+	return format, nil
+}
+
+// mustOutputFormat resolves the output format, exiting on an invalid one
+func mustOutputFormat(cmd *cobra.Command, cfg *config.Config, csvOK bool) string {
+	format, err := outputFormat(cmd, cfg, csvOK)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+	return format
+}
+
+// printOutput writes a result in a machine-readable format, exiting on failure
+func printOutput(format string, v interface{}) {
+	if err := output.Write(os.Stdout, format, v); err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+}
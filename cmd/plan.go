@@ -1,45 +1,97 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
 	"text/tabwriter"
+	"time"
 
+	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"near-swap/config"
 	"near-swap/pkg/client"
+	"near-swap/pkg/deposit"
+	"near-swap/pkg/logging"
+	"near-swap/pkg/output"
+	"near-swap/pkg/parser"
 	"near-swap/pkg/plan"
 )
 
 var (
 	// Plan creation flags
-	planFromToken      string
-	planToToken        string
-	planFromChain      string
-	planToChain        string
-	planTotalAmount    string
-	planAmountPerTrade string
-	planAmountPerDay   string
-	planTriggerPrice   string
-	planRecipient      string
-	planRefundTo       string
-	planDescription    string
+	planFromToken       string
+	planToToken         string
+	planFromChain       string
+	planToChain         string
+	planTotalAmount     string
+	planAmountPerTrade  string
+	planAmountPerDest   string
+	planAmountPerDay    string
+	planNumTrades       int
+	planDays            int
+	planTriggerPrice    string
+	planRecipient       string
+	planRefundTo        string
+	planDescription     string
+	planForce           bool
+	planCancelBelow     string
+	planCancelAbove     string
+	planLadder          string
+	planJitter          float64
+	planWithdrawTo      string
+	planSmoothing       int
+	planSlippage        int
+	planDeadline        time.Duration
+	planNormalizeName   bool
+	planDustThreshold   string
+	planAPITokenEnv     string
+	planMaxDivergence   float64
+	planMaxImpact       float64
+	planVerifyFirst     bool
+	planVerifyAmount    string
+	planSkipMarketCheck bool
+	resumeVerification  bool
+	daemonObserve       bool
+	daemonMetricsAddr   string
+	exportFormat        string
+	exportBasis         string
+	exportOutput        string
+	backupNames         []string
+	backupStatus        string
+	backupOutput        string
+	importOverwrite     bool
 
 	// Plan list flags
 	planStatusFilter string
+	planListPrices   bool
+
+	// Plan history flags
+	historyFollow bool
 
 	// Plan stats flags
-	statsPage     int
-	statsPageSize int
+	statsPage      int
+	statsPageSize  int
+	statsCostBasis string
+
+	// Plan cancel-execution flags
+	cancelExecutionReason string
 )
 
+// marketCheckTimeout bounds the 1Click API calls made while validating a plan
+const marketCheckTimeout = 30 * time.Second
+
 var planCmd = &cobra.Command{
 	Use:   "plan",
 	Short: "Manage automated trading plans",
@@ -76,7 +128,47 @@ Examples:
     --from-chain near --to-chain eth \
     --total 5000 --per-trade 500 --per-day 1000 \
     --when-price below 3000 \
-    --recipient 0x123...`,
+    --recipient 0x123...
+
+  # Acquire 0.1 ETH per trade, spending up to 5000 USDC in total
+  near-swap plan create stack-eth \
+    --from USDC --to ETH \
+    --from-chain near --to-chain eth \
+    --total 5000 --per-trade-dest 0.1 --per-day 1000 \
+    --when-price below 3000 \
+    --recipient 0x123...
+
+  # Sell 5 SOL in 10 equal trades spread over 5 days (0.5 SOL per trade, 1 SOL per day)
+  near-swap plan create sell-sol-slowly \
+    --from SOL --to USDC \
+    --from-chain sol --to-chain near \
+    --total 5 --num-trades 10 --days 5 \
+    --when-price above 250 \
+    --recipient your.near
+
+  # Sell BTC above $150k, but give up entirely if it crashes below $80k
+  near-swap plan create sell-btc-guarded \
+    --from BTC --to USDC \
+    --from-chain btc --to-chain near \
+    --total 10 --per-trade 1 --per-day 2 \
+    --when-price above 150000 --cancel-below 80000 \
+    --recipient your.near
+
+  # Trailing stop: sell once BTC falls 5% below its highest price since the plan started
+  near-swap plan create btc-trailing-stop \
+    --from BTC --to USDC \
+    --from-chain btc --to-chain near \
+    --total 1 --per-trade 1 --per-day 1 \
+    --when-price "trailing 5%" \
+    --recipient your.near
+
+  # Sell 25% at 150k, 25% at 160k and 50% at 175k (each tranche fills once)
+  near-swap plan create sell-btc-ladder \
+    --from BTC --to USDC \
+    --from-chain btc --to-chain near \
+    --total 4 --per-day 4 \
+    --ladder "above 150000:25%,160000:25%,175000:50%" \
+    --recipient your.near`,
 	Args: cobra.ExactArgs(1),
 	Run:  runPlanCreate,
 }
@@ -93,6 +185,9 @@ Examples:
   # List only active plans
   near-swap plan list --status active
 
+  # Show current prices, nearest to triggering first
+  near-swap plan list --prices
+
   # List in JSON format
   near-swap plan list --json`,
 	Run: runPlanList,
@@ -118,8 +213,17 @@ var planStartCmd = &cobra.Command{
 The plan will run in the background and automatically execute trades when
 price conditions are met.
 
+If another active plan trades the same pair with an overlapping trigger, the
+plan is not started unless --force is given, to avoid double-trading.
+
+With --resume-verification, the plan's pending and deposited executions are
+reconciled against the API first, so its accounting is current before it can
+trade again.
+
 Examples:
-  near-swap plan start sell-btc-high`,
+  near-swap plan start sell-btc-high
+  near-swap plan start sell-btc-high --force
+  near-swap plan start sell-btc-high --resume-verification`,
 	Args: cobra.ExactArgs(1),
 	Run:  runPlanStart,
 }
@@ -137,6 +241,35 @@ Examples:
 	Run:  runPlanStop,
 }
 
+var planPauseAllCmd = &cobra.Command{
+	Use:   "pause-all",
+	Short: "Pause every active trading plan",
+	Long: `Pause all active plans at once, e.g. to stop trading during a market event.
+
+Plans paused this way are marked so 'plan resume-all' can reactivate exactly
+them; plans you stopped yourself or that were paused automatically stay paused.
+A running daemon stops and restarts the plans on its next check.
+
+Examples:
+  near-swap plan pause-all
+  near-swap plan resume-all`,
+	Args: cobra.NoArgs,
+	Run:  runPlanPauseAll,
+}
+
+var planResumeAllCmd = &cobra.Command{
+	Use:   "resume-all",
+	Short: "Resume the plans paused by pause-all",
+	Long: `Reactivate every plan paused by 'plan pause-all'. Plans stopped with 'plan stop'
+or paused automatically (e.g. for a low balance) are listed but left paused.
+
+Examples:
+  near-swap plan resume-all
+  near-swap plan resume-all --json`,
+	Args: cobra.NoArgs,
+	Run:  runPlanResumeAll,
+}
+
 var planDeleteCmd = &cobra.Command{
 	Use:   "delete <name>",
 	Short: "Delete a trading plan",
@@ -155,9 +288,14 @@ var planHistoryCmd = &cobra.Command{
 	Short: "View execution history for a plan",
 	Long: `Display the execution history of a trading plan showing all past trades.
 
+Use --follow to keep watching the plan and print new executions as they are
+recorded by the daemon, like tail -f. Following stops when the plan completes,
+is cancelled or deleted, or on Ctrl+C.
+
 Examples:
   near-swap plan history sell-btc-high
-  near-swap plan history sell-btc-high --json`,
+  near-swap plan history sell-btc-high --json
+  near-swap plan history sell-btc-high --follow`,
 	Args: cobra.ExactArgs(1),
 	Run:  runPlanHistory,
 }
@@ -169,16 +307,182 @@ var planStatsCmd = &cobra.Command{
 - Total number of swaps
 - Total amount deposited
 - Total amount received
+- Average executed price and value versus the trigger prices
+- Realized P&L against a cost basis (--cost-basis)
 - Recent transaction history with pagination
 
+The cost basis is in dest tokens per source token, like the plan's prices: either a
+single price or FIFO lots of amount@price, as in 'plan export-history --basis'.
+
 Examples:
   near-swap plan stats sell-btc-high
   near-swap plan stats sell-btc-high --page 2
+  near-swap plan stats sell-btc-high --cost-basis 42000
+  near-swap plan stats sell-btc-high --cost-basis 0.5@30000,1.5@42000
   near-swap plan stats sell-btc-high --json`,
 	Args: cobra.ExactArgs(1),
 	Run:  runPlanStats,
 }
 
+var planRecomputeCmd = &cobra.Command{
+	Use:     "recompute <name>",
+	Aliases: []string{"resume-from"},
+	Short:   "Recompute a plan's progress from its execution history",
+	Long: `Recalculate a plan's executed, remaining and daily amounts from the
+deposited/completed executions in its history, and re-derive its status.
+
+Use this if a plan's progress looks wrong (for example a remaining amount
+that never reaches zero). A plan that was marked completed but still has
+an amount remaining is left paused so you can review it before restarting.
+
+Examples:
+  near-swap plan recompute sell-btc-high
+  near-swap plan recompute sell-btc-high --json`,
+	Args: cobra.ExactArgs(1),
+	Run:  runPlanRecompute,
+}
+
+var planCancelExecutionCmd = &cobra.Command{
+	Use:   "cancel-execution <name> <execution-id>",
+	Short: "Cancel a pending or deposited execution of a plan",
+	Long: `Cancel an execution that is still in flight, for example a trade that
+triggered by mistake. The daemon stops verifying a cancelled execution and no
+longer updates its status or the plan's accounting for it.
+
+A pending execution whose deposit hasn't been sent yet is not deposited, and
+its amount is returned to the plan's daily and total limits. A deposited
+execution keeps its amount counted, since the funds have already left the
+wallet: the 1Click API has no way to cancel a swap or request a refund, so it
+may still complete, and if it fails or its quote expires the deposit is
+refunded to the plan's refund address.
+
+Examples:
+  near-swap plan cancel-execution sell-btc-high 3f2a9c1e-...
+  near-swap plan cancel-execution sell-btc-high 3f2a9c1e-... --reason "wrong trigger price"`,
+	Args: cobra.ExactArgs(2),
+	Run:  runPlanCancelExecution,
+}
+
+var planAuditCmd = &cobra.Command{
+	Use:   "audit <name>",
+	Short: "Check a plan's recorded deposits against the source chain",
+	Long: `For every deposited or completed execution of a plan, look up its recorded
+deposit transaction with the source chain's auto-deposit wallet and check that it
+exists, succeeded and sent the recorded amount.
+
+Discrepancies point at recording bugs or manual edits to the plan file. The command
+exits with status 1 when any are found. Requires auto-deposit to be configured for
+the plan's source chain.
+
+Examples:
+  near-swap plan audit sell-btc-high
+  near-swap plan audit sell-btc-high --json`,
+	Args: cobra.ExactArgs(1),
+	Run:  runPlanAudit,
+}
+
+var planDiffCmd = &cobra.Command{
+	Use:   "diff <name> <file|name>",
+	Short: "Compare a plan's trading parameters with a definition file or another plan",
+	Long: `Show the trading parameters that differ between a plan and a plan definition
+file, or between two plans. Identity and runtime state (name, status, progress,
+execution history, ladder fills) are ignored, so the diff shows what an edit would
+actually change.
+
+A definition file holds one plan in the format printed by 'plan view --json'. The
+second argument is read as a plan name if such a plan exists, and as a file
+otherwise. The command exits with status 1 when the plans differ.
+
+Examples:
+  near-swap plan view sell-btc-high --json > sell-btc-high.json
+  near-swap plan diff sell-btc-high sell-btc-high.json
+  near-swap plan diff sell-btc-high sell-btc-higher --json`,
+	Args: cobra.ExactArgs(2),
+	Run:  runPlanDiff,
+}
+
+var planValidateCmd = &cobra.Command{
+	Use:   "validate <name|file>",
+	Short: "Check a plan or plan definition file for problems",
+	Long: `Run every check on a stored plan or a plan definition file in the format
+printed by 'plan view --json', and list all problems found at once.
+
+Structural checks cover required fields, amounts and their ordering, the trigger
+and kill switch prices, and option ranges. Market checks then ask the 1Click API
+whether both tokens exist on their chains, whether one trade of the plan's size can
+be quoted (and clears the source chain's minimum send), and whether the trigger and
+kill switch prices are plausible next to the current price.
+
+Errors mean the plan can't trade as defined; warnings point at likely mistakes.
+The command exits with status 1 when there are errors.
+
+Examples:
+  near-swap plan validate sell-btc-high
+  near-swap plan validate sell-btc-high.json --skip-market-check
+  near-swap plan validate sell-btc-high --json`,
+	Args: cobra.ExactArgs(1),
+	Run:  runPlanValidate,
+}
+
+var planExportHistoryCmd = &cobra.Command{
+	Use:   "export-history <name>",
+	Short: "Export a plan's completed trades as a ledger for tax reporting",
+	Long: `Export a plan's completed executions as a normalized ledger: date, disposed
+asset and amount, acquired asset and amount, price and fees. Acquired amounts are
+net of swap fees, which are taken out of the quoted rate.
+
+Pass --basis to compute cost basis and gain per disposal, in units of the
+acquired token: a single price ("30000") or first-in first-out lots of
+amount@price ("0.5@30000,1.5@42000").
+
+The ledger comes with a manifest holding its totals and a SHA-256 hash of the
+CSV ledger, so the file can be checked for changes later. With --output the
+manifest is written next to the CSV as <output>.manifest.json; otherwise the hash
+is printed to stderr. The json format embeds the manifest instead.
+
+Examples:
+  near-swap plan export-history sell-btc-high --output btc-2026.csv
+  near-swap plan export-history sell-btc-high --basis 30000 --output btc-2026.csv
+  near-swap plan export-history sell-btc-high --basis "0.5@30000,1.5@42000" --format json`,
+	Args: cobra.ExactArgs(1),
+	Run:  runPlanExportHistory,
+}
+
+var planExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export plans with their execution history for backup or migration",
+	Long: `Write plans, including their progress and execution history, to a portable
+JSON document that 'plan import' can load on this or another machine.
+
+The export contains each plan's recipient, refund and withdrawal addresses, so
+keep it private. With --output the file is created readable by you only.
+
+Examples:
+  near-swap plan export --output plans-backup.json
+  near-swap plan export --status active --output active.json
+  near-swap plan export --name sell-btc-high --name buy-eth-dip > two-plans.json`,
+	Args: cobra.NoArgs,
+	Run:  runPlanExport,
+}
+
+var planImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import plans from a 'plan export' document",
+	Long: `Load the plans in a document written by 'plan export'. Each plan is validated
+before it is stored, and plans whose name is already taken are skipped unless
+--overwrite is given. An active plan is never overwritten.
+
+Plans that were active when exported are imported paused, so restoring a backup
+never starts trading by itself; review them and run 'plan start'. The command
+exits with status 1 when any plan was skipped.
+
+Examples:
+  near-swap plan import plans-backup.json
+  near-swap plan import plans-backup.json --overwrite`,
+	Args: cobra.ExactArgs(1),
+	Run:  runPlanImport,
+}
+
 var planDaemonCmd = &cobra.Command{
 	Use:   "daemon",
 	Short: "Run daemon to monitor and execute all active plans",
@@ -211,7 +515,13 @@ Examples:
   # In another terminal, manage plans while daemon runs:
   near-swap plan create new-plan ...
   near-swap plan start new-plan      # Daemon auto-detects in <60s
-  near-swap plan stop old-plan       # Daemon auto-stops in <60s`,
+  near-swap plan stop old-plan       # Daemon auto-stops in <60s
+
+  # Dry-run every active plan without trading
+  near-swap plan daemon --observe
+
+  # Expose /healthz and /metrics for monitoring
+  near-swap plan daemon --metrics-addr :9090`,
 	Run: runPlanDaemon,
 }
 
@@ -224,9 +534,19 @@ func init() {
 	planCmd.AddCommand(planViewCmd)
 	planCmd.AddCommand(planStartCmd)
 	planCmd.AddCommand(planStopCmd)
+	planCmd.AddCommand(planPauseAllCmd)
+	planCmd.AddCommand(planResumeAllCmd)
 	planCmd.AddCommand(planDeleteCmd)
 	planCmd.AddCommand(planHistoryCmd)
 	planCmd.AddCommand(planStatsCmd)
+	planCmd.AddCommand(planRecomputeCmd)
+	planCmd.AddCommand(planCancelExecutionCmd)
+	planCmd.AddCommand(planAuditCmd)
+	planCmd.AddCommand(planDiffCmd)
+	planCmd.AddCommand(planValidateCmd)
+	planCmd.AddCommand(planExportHistoryCmd)
+	planCmd.AddCommand(planExportCmd)
+	planCmd.AddCommand(planImportCmd)
 	planCmd.AddCommand(planDaemonCmd)
 
 	// Create command flags
@@ -236,44 +556,126 @@ func init() {
 	planCreateCmd.Flags().StringVar(&planToChain, "to-chain", "", "Destination blockchain")
 	planCreateCmd.Flags().StringVar(&planTotalAmount, "total", "", "Total amount to trade")
 	planCreateCmd.Flags().StringVar(&planAmountPerTrade, "per-trade", "", "Amount per trade execution")
+	planCreateCmd.Flags().StringVar(&planAmountPerDest, "per-trade-dest", "", "Destination amount to acquire per trade (instead of --per-trade)")
 	planCreateCmd.Flags().StringVar(&planAmountPerDay, "per-day", "", "Maximum amount to trade per day")
-	planCreateCmd.Flags().StringVar(&planTriggerPrice, "when-price", "", "Price trigger condition (e.g., 'above 150000', 'below 3000')")
-	planCreateCmd.Flags().StringVar(&planRecipient, "recipient", "", "Recipient address for swapped tokens")
-	planCreateCmd.Flags().StringVar(&planRefundTo, "refund-to", "", "Refund address (optional, defaults to recipient)")
+	planCreateCmd.Flags().IntVar(&planNumTrades, "num-trades", 0, "Split the total into this many equal trades (instead of --per-trade)")
+	planCreateCmd.Flags().IntVar(&planDays, "days", 0, "Spread the total evenly over this many days (instead of --per-day)")
+	planCreateCmd.Flags().StringVar(&planTriggerPrice, "when-price", "", "Price trigger condition (e.g., 'above 150000', 'below 3000', 'trailing 5%')")
+	planCreateCmd.Flags().StringVar(&planRecipient, "recipient", "", "Recipient address for swapped tokens (defaults to default_recipient)")
+	planCreateCmd.Flags().StringVar(&planRefundTo, "refund-to", "", "Refund address (optional, defaults to default_refund_to, then the recipient)")
 	planCreateCmd.Flags().StringVar(&planDescription, "description", "", "Plan description (optional)")
+	planCreateCmd.Flags().BoolVar(&planForce, "force", false, "Create even if an active plan trades the same pair with an overlapping trigger")
+	planCreateCmd.Flags().StringVar(&planCancelBelow, "cancel-below", "", "Kill switch: cancel the plan if the price falls to or below this (optional)")
+	planCreateCmd.Flags().StringVar(&planCancelAbove, "cancel-above", "", "Kill switch: cancel the plan if the price rises to or above this (optional)")
+	planCreateCmd.Flags().StringVar(&planLadder, "ladder", "", "Trade tranches at several levels (e.g., 'above 150000:25%,160000:25%,175000:50%')")
+	planCreateCmd.Flags().StringVar(&planWithdrawTo, "withdraw-to", "", "Forward each trade's output from the recipient to this cold address (requires auto_withdraw)")
+	planCreateCmd.Flags().BoolVar(&addressOverride, "i-know-what-im-doing", false, "Create even if the recipient and refund addresses look swapped")
+	planCreateCmd.Flags().Float64Var(&planJitter, "jitter", 0, "Randomize each trade by up to ±this percent of --per-trade (optional, e.g. 2)")
+	planCreateCmd.Flags().BoolVar(&planNormalizeName, "normalize-name", false, "Replace characters not allowed in plan names (spaces, slashes, ...) with '-' instead of failing")
+	planCreateCmd.Flags().IntVar(&planSlippage, "slippage", 0, "Quote slippage tolerance in basis points, 1-5000 (optional, defaults to default_slippage)")
+	planCreateCmd.Flags().DurationVar(&planDeadline, "deadline", 0, "How long each trade's quote accepts the deposit, 1m-168h (optional, defaults to default_deadline)")
+	planCreateCmd.Flags().Float64Var(&planMaxDivergence, "max-quote-divergence", 0, "Abort a trade when the deposit quote is more than this percent worse than the trigger price (optional, defaults to max_quote_divergence)")
+	planCreateCmd.Flags().StringVar(&planAPITokenEnv, "api-token-env", "", "Environment variable holding a 1Click JWT for this plan only (optional, defaults to the global token)")
+	planCreateCmd.Flags().StringVar(&planDustThreshold, "dust-threshold", "", "Complete the plan instead of trading once the remaining amount is worth less than this many destination tokens (optional)")
+	planCreateCmd.Flags().Float64Var(&planMaxImpact, "max-price-impact", 0, "Skip a triggered trade when a full-size quote is more than this percent worse than the small probe quote (optional, defaults to max_price_impact)")
+	planCreateCmd.Flags().BoolVar(&planVerifyFirst, "verify-first", false, "Run a tiny verification swap end-to-end before the plan's first full trade")
+	planCreateCmd.Flags().StringVar(&planVerifyAmount, "verify-amount", "", "Source amount of the verification swap (defaults to 1% of --per-trade, at least the chain's minimum send; required with --per-trade-dest or --ladder)")
+	planCreateCmd.Flags().BoolVar(&planSkipMarketCheck, "skip-market-check", false, "Don't check the pair, trade size and trigger against the 1Click API before creating the plan")
+	planCreateCmd.Flags().IntVar(&planSmoothing, "price-smoothing", 0, "Trigger on the average of the last N price checks instead of the spot price (optional)")
 
 	planCreateCmd.MarkFlagRequired("from")
 	planCreateCmd.MarkFlagRequired("to")
 	planCreateCmd.MarkFlagRequired("from-chain")
 	planCreateCmd.MarkFlagRequired("to-chain")
 	planCreateCmd.MarkFlagRequired("total")
-	planCreateCmd.MarkFlagRequired("per-trade")
-	planCreateCmd.MarkFlagRequired("per-day")
-	planCreateCmd.MarkFlagRequired("when-price")
-	planCreateCmd.MarkFlagRequired("recipient")
+	planCreateCmd.MarkFlagsOneRequired("per-trade", "per-trade-dest", "num-trades", "ladder")
+	planCreateCmd.MarkFlagsMutuallyExclusive("per-trade", "per-trade-dest", "num-trades", "ladder")
+	planCreateCmd.MarkFlagsOneRequired("per-day", "days")
+	planCreateCmd.MarkFlagsMutuallyExclusive("per-day", "days")
+	planCreateCmd.MarkFlagsOneRequired("when-price", "ladder")
+	planCreateCmd.MarkFlagsMutuallyExclusive("when-price", "ladder")
 
 	// List command flags
 	planListCmd.Flags().StringVar(&planStatusFilter, "status", "", "Filter by status (active, paused, completed, cancelled)")
+	planListCmd.Flags().BoolVar(&planListPrices, "prices", false, "Fetch current prices and sort plans by how close they are to triggering")
+	addFormatFlag(planListCmd, true)
+
+	// View command flags
+	addFormatFlag(planViewCmd, false)
+
+	// Start command flags
+	planStartCmd.Flags().BoolVar(&planForce, "force", false, "Start even if an active plan trades the same pair with an overlapping trigger")
+	planStartCmd.Flags().BoolVar(&resumeVerification, "resume-verification", false, "Refresh the status of the plan's pending executions before activating it")
+
+	// Daemon command flags
+	planDaemonCmd.Flags().BoolVar(&daemonObserve, "observe", false, "Evaluate triggers and record would-be trades without sending any deposits")
+	planDaemonCmd.Flags().StringVar(&daemonMetricsAddr, "metrics-addr", "", "Serve /healthz and Prometheus /metrics on this address (e.g. :9090)")
+
+	// History command flags
+	planHistoryCmd.Flags().BoolVarP(&historyFollow, "follow", "f", false, "Keep running and print new executions as they arrive")
+	addFormatFlag(planHistoryCmd, true)
 
 	// Stats command flags
 	planStatsCmd.Flags().IntVar(&statsPage, "page", 1, "Page number for transaction history")
 	planStatsCmd.Flags().IntVar(&statsPageSize, "page-size", 10, "Number of transactions per page")
+	planStatsCmd.Flags().StringVar(&statsCostBasis, "cost-basis", "", "Cost basis for realized P&L: a price, or FIFO lots like '0.5@30000,1.5@42000' (optional)")
+	addFormatFlag(planStatsCmd, true)
+
+	// Validate command flags
+	planValidateCmd.Flags().BoolVar(&planSkipMarketCheck, "skip-market-check", false, "Only run the structural checks, without calling the 1Click API")
+
+	// Cancel execution command flags
+	planCancelExecutionCmd.Flags().StringVar(&cancelExecutionReason, "reason", "", "Why the execution is cancelled (recorded on the execution)")
+
+	// Export history command flags
+	planExportHistoryCmd.Flags().StringVar(&exportFormat, "format", "tax", "Export format: tax (CSV ledger) or json (ledger with manifest)")
+	planExportHistoryCmd.Flags().StringVar(&exportBasis, "basis", "", "Cost basis: a price, or FIFO lots like '0.5@30000,1.5@42000' (optional)")
+	planExportHistoryCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write the ledger to this file instead of stdout")
+
+	// Export and import command flags
+	planExportCmd.Flags().StringSliceVar(&backupNames, "name", nil, "Only export these plans (repeatable)")
+	planExportCmd.Flags().StringVar(&backupStatus, "status", "", "Only export plans with this status (active, paused, completed, cancelled)")
+	planExportCmd.Flags().StringVarP(&backupOutput, "output", "o", "", "Write the export to this file instead of stdout")
+	planImportCmd.Flags().BoolVar(&importOverwrite, "overwrite", false, "Replace existing plans with the same name (active plans are never replaced)")
 }
 
 func runPlanCreate(cmd *cobra.Command, args []string) {
 	planName := args[0]
 	jsonOutput, _ := cmd.Flags().GetBool("json")
 
-	// Parse price condition
-	condition, price, err := parsePriceCondition(planTriggerPrice)
-	if err != nil {
-		printError(fmt.Errorf("invalid price condition: %w", err))
-		os.Exit(1)
+	// Parse price condition (a ladder carries its own direction and levels)
+	var condition plan.PriceCondition
+	var price string
+	var ladder []plan.LadderLevel
+	var err error
+	if planLadder != "" {
+		condition, ladder, err = plan.ParseLadder(planLadder)
+		if err != nil {
+			printError(fmt.Errorf("invalid ladder: %w", err))
+			os.Exit(1)
+		}
+	} else {
+		condition, price, err = parsePriceCondition(planTriggerPrice)
+		if err != nil {
+			printError(fmt.Errorf("invalid price condition: %w", err))
+			os.Exit(1)
+		}
 	}
 
-	// Set refund address to recipient if not provided
-	if planRefundTo == "" {
-		planRefundTo = planRecipient
+	// Derive the absolute limits from trade and day counts
+	if cmd.Flags().Changed("num-trades") {
+		planAmountPerTrade, err = plan.SplitAmount(planTotalAmount, planNumTrades)
+		if err != nil {
+			printError(fmt.Errorf("invalid --num-trades: %w (use --per-trade instead)", err))
+			os.Exit(1)
+		}
+	}
+	if cmd.Flags().Changed("days") {
+		planAmountPerDay, err = plan.SplitAmount(planTotalAmount, planDays)
+		if err != nil {
+			printError(fmt.Errorf("invalid --days: %w (use --per-day instead)", err))
+			os.Exit(1)
+		}
 	}
 
 	// Load config to get storage path
@@ -284,23 +686,75 @@ func runPlanCreate(cmd *cobra.Command, args []string) {
 	}
 
 	// Create plan manager
-	manager, err := plan.NewManager(cfg.PlanStoragePath)
+	manager, err := newPlanManager(cfg)
 	if err != nil {
 		printError(err)
 		os.Exit(1)
 	}
 
-	// Create the plan
-	newPlan, err := manager.CreatePlan(
-		planName,
-		planFromToken, planToToken,
-		planFromChain, planToChain,
-		planTotalAmount, planAmountPerTrade, planAmountPerDay,
-		price, condition,
-		planRecipient, planRefundTo,
-		planDescription,
-	)
-	if err != nil {
+	spec := plan.PlanSpec{
+		Name:           planName,
+		Description:    planDescription,
+		SourceToken:    planFromToken,
+		DestToken:      planToToken,
+		SourceChain:    planFromChain,
+		DestChain:      planToChain,
+		TotalAmount:    planTotalAmount,
+		AmountPerTrade: planAmountPerTrade,
+		AmountPerDay:   planAmountPerDay,
+		TriggerPrice:   price,
+		PriceCondition: condition,
+		RecipientAddr:  planRecipient,
+		RefundAddr:     planRefundTo,
+	}
+	spec.Options = plan.CreatePlanOptions{
+		Force:              planForce,
+		AmountPerTradeDest: planAmountPerDest,
+		CancelBelow:        planCancelBelow,
+		CancelAbove:        planCancelAbove,
+		Ladder:             ladder,
+		AmountJitter:       planJitter,
+		WithdrawTo:         planWithdrawTo,
+		PriceSmoothing:     planSmoothing,
+		SlippageBps:        planSlippage,
+		QuoteDeadline:      planDeadline,
+		NormalizeName:      planNormalizeName,
+		MaxQuoteDivergence: planMaxDivergence,
+		MaxPriceImpact:     planMaxImpact,
+		DustThreshold:      planDustThreshold,
+		APITokenEnv:        planAPITokenEnv,
+		VerifyFirst:        planVerifyFirst,
+		VerifyAmount:       planVerifyAmount,
+	}
+	checks := plan.CreateChecks{
+		DefaultRecipient:      cfg.DefaultRecipient,
+		DefaultRefundTo:       cfg.DefaultRefundTo,
+		AllowSwappedAddresses: addressOverride,
+	}
+	if !planSkipMarketCheck {
+		checks.MarketClient = planMarketClient(cfg)
+	}
+
+	// Check the plan, against the market too, before saving it
+	ctx, cancel := context.WithTimeout(context.Background(), marketCheckTimeout)
+	defer cancel()
+	newPlan, findings, err := manager.CreateValidatedPlan(ctx, spec, checks)
+	var validationErr *plan.PlanValidationError
+	if errors.As(err, &validationErr) {
+		findings = validationErr.Findings
+	}
+	if !jsonOutput {
+		printFindings(findings)
+	}
+	var roleErr *deposit.AddressRoleError
+	switch {
+	case validationErr != nil:
+		printError(fmt.Errorf("plan failed validation; fix the errors above (market checks can be skipped with --skip-market-check)"))
+		os.Exit(1)
+	case errors.As(err, &roleErr) && roleErr.Swapped:
+		printError(fmt.Errorf("%w. Pass --i-know-what-im-doing to continue anyway", roleErr))
+		os.Exit(1)
+	case err != nil:
 		printError(err)
 		os.Exit(1)
 	}
@@ -314,24 +768,42 @@ func runPlanCreate(cmd *cobra.Command, args []string) {
 		fmt.Println(strings.Repeat("=", 60))
 		fmt.Printf("\n  Name:             %s\n", color.CyanString(newPlan.Name))
 		fmt.Printf("  Strategy:         Swap %s %s -> %s\n", newPlan.TotalAmount, newPlan.SourceToken, newPlan.DestToken)
-		fmt.Printf("  Per Trade:        %s %s\n", newPlan.AmountPerTrade, newPlan.SourceToken)
+		fmt.Printf("  Per Trade:        %s\n", perTradeDisplay(newPlan))
 		fmt.Printf("  Per Day:          %s %s\n", newPlan.AmountPerDay, newPlan.SourceToken)
-		fmt.Printf("  Trigger:          When price is %s %s %s/%s\n",
-			condition, price, newPlan.DestToken, newPlan.SourceToken)
+		if newPlan.HasLadder() {
+			fmt.Printf("  Ladder:           %s/%s\n", newPlan.DestToken, newPlan.SourceToken)
+			for _, level := range newPlan.Ladder {
+				fmt.Printf("                      %s\n", newPlan.FormatLadderLevel(level))
+			}
+		} else if newPlan.IsTrailingStop() {
+			fmt.Printf("  Trigger:          %s\n", trailingStopDisplay(newPlan))
+		} else {
+			fmt.Printf("  Trigger:          When price is %s %s %s/%s\n",
+				condition, price, newPlan.DestToken, newPlan.SourceToken)
+		}
+		if ks := killSwitchDisplay(newPlan); ks != "" {
+			fmt.Printf("  Kill Switch:      %s\n", ks)
+		}
+		if newPlan.VerifyFirst {
+			fmt.Printf("  Verify First:     %s %s before the first full trade\n", newPlan.VerifyAmount, newPlan.SourceToken)
+		}
 		fmt.Printf("  Status:           %s\n", color.YellowString(string(newPlan.Status)))
 		fmt.Printf("  Auto-deposit:     %s\n", color.GreenString("enabled (required)"))
 		if newPlan.Description != "" {
 			fmt.Printf("  Description:      %s\n", newPlan.Description)
 		}
 		fmt.Println("\n" + strings.Repeat("=", 60))
+		if newPlan.HasOwnAPIToken() && os.Getenv(newPlan.APITokenEnv) == "" {
+			color.Yellow("\nWARNING: %s is not set; export it where the daemon runs or the plan cannot trade\n", newPlan.APITokenEnv)
+		}
 		color.Yellow("\nIMPORTANT: Ensure auto-deposit is configured for %s in your .near-swap.yaml\n", newPlan.SourceChain)
 		fmt.Println("\nTo start the plan, run:")
-		color.Cyan("  near-swap plan start %s\n", planName)
+		color.Cyan("  near-swap plan start %s\n", newPlan.Name)
 	}
 }
 
 func runPlanList(cmd *cobra.Command, args []string) {
-	jsonOutput, _ := cmd.Flags().GetBool("json")
+	verbose, _ := cmd.Flags().GetBool("verbose")
 
 	// Load config
 	cfg, err := config.Load()
@@ -340,8 +812,10 @@ func runPlanList(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	format := mustOutputFormat(cmd, cfg, true)
+
 	// Create plan manager
-	manager, err := plan.NewManager(cfg.PlanStoragePath)
+	manager, err := newPlanManager(cfg)
 	if err != nil {
 		printError(err)
 		os.Exit(1)
@@ -356,13 +830,24 @@ func runPlanList(cmd *cobra.Command, args []string) {
 		plans = manager.ListPlans()
 	}
 
-	if jsonOutput {
+	var prices map[string]plan.PriceResult
+	if planListPrices {
+		prices = fetchPlanPrices(cfg, plans, format != output.Table)
+		sortByTriggerDistance(plans, prices)
+	}
+
+	if format != output.Table {
 		summaries := make([]*plan.PlanSummary, len(plans))
 		for i, p := range plans {
 			summaries[i] = p.ToSummary()
+			if result, ok := prices[p.Name]; ok && result.Price != nil {
+				summaries[i].CurrentPrice = result.Price.Price
+				if distance, ok := p.TriggerDistance(result.Price.PriceFloat); ok {
+					summaries[i].TriggerDistance = &distance
+				}
+			}
 		}
-		output, _ := json.MarshalIndent(summaries, "", "  ")
-		fmt.Println(string(output))
+		printOutput(format, summaries)
 		return
 	}
 
@@ -378,27 +863,112 @@ func runPlanList(cmd *cobra.Command, args []string) {
 	fmt.Println(strings.Repeat("=", 120))
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "\nNAME\tSTRATEGY\tPROGRESS\tTRIGGER\tSTATUS\tEXECUTIONS")
+	if planListPrices {
+		fmt.Fprintln(w, "\nNAME\tSTRATEGY\tPROGRESS\tTRIGGER\tPRICE\tTO TRIGGER\tSTATUS\tEXECUTIONS\tUPDATED")
+	} else {
+		fmt.Fprintln(w, "\nNAME\tSTRATEGY\tPROGRESS\tTRIGGER\tSTATUS\tEXECUTIONS\tUPDATED")
+	}
 	fmt.Fprintln(w, strings.Repeat("-", 120))
 
 	for _, p := range plans {
 		strategy := fmt.Sprintf("%s -> %s", p.SourceToken, p.DestToken)
 		progress := fmt.Sprintf("%s / %s", p.TotalExecuted, p.TotalAmount)
 		trigger := fmt.Sprintf("%s %s", p.PriceCondition, p.TriggerPrice)
+		if p.IsTrailingStop() {
+			trigger = fmt.Sprintf("%s %s%%", p.PriceCondition, strconv.FormatFloat(p.TrailPercent, 'f', -1, 64))
+		}
 
 		statusColor := getStatusColor(p.Status)
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n",
-			p.Name, strategy, progress, trigger, statusColor, p.ExecutionCount)
+		if planListPrices {
+			price, distance := priceColumns(p, prices)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+				p.Name, strategy, progress, trigger, price, distance, statusColor, p.ExecutionCount, formatTimestamp(p.LastUpdated, verbose))
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			p.Name, strategy, progress, trigger, statusColor, p.ExecutionCount, formatTimestamp(p.LastUpdated, verbose))
 	}
 
 	w.Flush()
 	fmt.Println("\n" + strings.Repeat("=", 120) + "\n")
 }
 
+// fetchPlanPrices fetches current prices for the plans that can still trade. Ctrl+C stops
+// the fetch early; plans not priced by then are listed without a price.
+func fetchPlanPrices(cfg *config.Config, plans []*plan.TradingPlan, quiet bool) map[string]plan.PriceResult {
+	var tradable []*plan.TradingPlan
+	for _, p := range plans {
+		if p.Status == plan.StatusActive || p.Status == plan.StatusPaused {
+			tradable = append(tradable, p)
+		}
+	}
+	if len(tradable) == 0 {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	if !quiet {
+		s.Suffix = fmt.Sprintf(" Fetching prices for %d plans (Ctrl+C to stop)...", len(tradable))
+		s.Start()
+	}
+	prices := plan.NewPricer(newAPIClient(cfg)).FetchPrices(ctx, tradable, plan.DefaultPriceFetchConcurrency)
+	if !quiet {
+		s.Stop()
+		if ctx.Err() != nil {
+			color.Yellow("Price fetch interrupted; showing the prices fetched so far.")
+		}
+	}
+	return prices
+}
+
+// sortByTriggerDistance orders plans nearest to triggering first; plans without a price
+// keep their order after the priced ones
+func sortByTriggerDistance(plans []*plan.TradingPlan, prices map[string]plan.PriceResult) {
+	distance := func(p *plan.TradingPlan) (float64, bool) {
+		result, ok := prices[p.Name]
+		if !ok || result.Price == nil {
+			return 0, false
+		}
+		return p.TriggerDistance(result.Price.PriceFloat)
+	}
+	sort.SliceStable(plans, func(i, j int) bool {
+		di, oki := distance(plans[i])
+		dj, okj := distance(plans[j])
+		if oki != okj {
+			return oki
+		}
+		return oki && di < dj
+	})
+}
+
+// priceColumns formats a plan's current price and distance to its trigger for plan list
+func priceColumns(p *plan.TradingPlan, prices map[string]plan.PriceResult) (string, string) {
+	result, ok := prices[p.Name]
+	if !ok {
+		return "-", "-"
+	}
+	if result.Err != nil || result.Price == nil {
+		return color.RedString("error"), "-"
+	}
+
+	price := strings.TrimRight(strings.TrimRight(result.Price.Price, "0"), ".")
+	distance, ok := p.TriggerDistance(result.Price.PriceFloat)
+	switch {
+	case !ok:
+		return price, "-"
+	case distance == 0:
+		return price, color.GreenString("met")
+	default:
+		return price, fmt.Sprintf("%.2f%%", distance)
+	}
+}
+
 func runPlanView(cmd *cobra.Command, args []string) {
 	planName := args[0]
-	jsonOutput, _ := cmd.Flags().GetBool("json")
 
 	// Load config
 	cfg, err := config.Load()
@@ -407,8 +977,10 @@ func runPlanView(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	format := mustOutputFormat(cmd, cfg, false)
+
 	// Create plan manager
-	manager, err := plan.NewManager(cfg.PlanStoragePath)
+	manager, err := newPlanManager(cfg)
 	if err != nil {
 		printError(err)
 		os.Exit(1)
@@ -421,9 +993,8 @@ func runPlanView(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	if jsonOutput {
-		output, _ := json.MarshalIndent(p, "", "  ")
-		fmt.Println(string(output))
+	if format != output.Table {
+		printOutput(format, p)
 		return
 	}
 
@@ -437,20 +1008,76 @@ func runPlanView(cmd *cobra.Command, args []string) {
 		fmt.Printf("  Description:       %s\n", p.Description)
 	}
 	fmt.Printf("  Status:            %s\n", getStatusColor(p.Status))
-	fmt.Printf("  Created:           %s\n", p.Created.Format("2006-01-02 15:04:05"))
-	fmt.Printf("  Last Updated:      %s\n", p.LastUpdated.Format("2006-01-02 15:04:05"))
+	if p.PauseReason != "" {
+		fmt.Printf("  Paused Because:    %s\n", color.YellowString(p.PauseReason))
+	}
+	if p.CancelReason != "" {
+		fmt.Printf("  Cancelled Because: %s\n", color.RedString(p.CancelReason))
+	}
+	if p.CompletionReason != "" {
+		fmt.Printf("  Completed Because: %s\n", color.GreenString(p.CompletionReason))
+	}
+	if p.DestinationFailures > 0 {
+		fmt.Printf("  Failed Swaps:      %s\n", color.YellowString("%d in a row after deposit", p.DestinationFailures))
+	}
+	fmt.Printf("  Created:           %s\n", formatTimestampFull(p.Created))
+	fmt.Printf("  Last Updated:      %s\n", formatTimestampFull(p.LastUpdated))
 
 	fmt.Printf("\n  Trading Strategy:\n")
 	fmt.Printf("    From:            %s %s (on %s)\n", p.TotalAmount, p.SourceToken, p.SourceChain)
 	fmt.Printf("    To:              %s (on %s)\n", p.DestToken, p.DestChain)
-	fmt.Printf("    Per Trade:       %s %s\n", p.AmountPerTrade, p.SourceToken)
+	fmt.Printf("    Per Trade:       %s\n", perTradeDisplay(p))
 	fmt.Printf("    Per Day:         %s %s\n", p.AmountPerDay, p.SourceToken)
-	fmt.Printf("    Trigger:         When price %s %s %s/%s\n",
-		p.PriceCondition, p.TriggerPrice, p.DestToken, p.SourceToken)
+	if p.HasLadder() {
+		fmt.Printf("    Ladder:          %s/%s\n", p.DestToken, p.SourceToken)
+		for _, level := range p.Ladder {
+			fmt.Printf("                       %s\n", p.FormatLadderLevel(level))
+		}
+	} else if p.IsTrailingStop() {
+		fmt.Printf("    Trigger:         %s\n", trailingStopDisplay(p))
+	} else {
+		fmt.Printf("    Trigger:         When price %s %s %s/%s\n",
+			p.PriceCondition, p.TriggerPrice, p.DestToken, p.SourceToken)
+	}
+	if p.HasPriceSmoothing() {
+		fmt.Printf("    Smoothing:       Average of the last %d price checks (%d recorded)\n",
+			p.PriceSmoothing, len(p.PriceSamples))
+	}
+	if ks := killSwitchDisplay(p); ks != "" {
+		fmt.Printf("    Kill Switch:     %s\n", ks)
+	}
+	if p.SlippageBps > 0 {
+		fmt.Printf("    Slippage:        %d bps (%.2f%%)\n", p.SlippageBps, float64(p.SlippageBps)/100)
+	}
+	if p.QuoteDeadline != "" {
+		fmt.Printf("    Quote Deadline:  %s\n", p.QuoteDeadline)
+	}
+	if p.MaxQuoteDivergence > 0 {
+		fmt.Printf("    Max Divergence:  Abort when the quote is more than %.2f%% worse than the trigger price\n", p.MaxQuoteDivergence)
+	}
+	if p.MaxPriceImpact > 0 {
+		fmt.Printf("    Max Impact:      Skip when a full trade prices more than %.2f%% worse than the probe quote\n", p.MaxPriceImpact)
+	}
+	if p.DustThreshold != "" {
+		fmt.Printf("    Dust Threshold:  Complete when the remainder is worth less than %s %s\n", p.DustThreshold, p.DestToken)
+	}
+	if p.VerifyFirst {
+		fmt.Printf("    Verify First:    %s\n", verificationDisplay(p))
+	}
 
 	fmt.Printf("\n  Addresses:\n")
 	fmt.Printf("    Recipient:       %s\n", p.RecipientAddr)
 	fmt.Printf("    Refund:          %s\n", p.RefundAddr)
+	if p.WithdrawTo != "" {
+		fmt.Printf("    Withdraw To:     %s\n", p.WithdrawTo)
+	}
+	if p.HasOwnAPIToken() {
+		tokenState := color.GreenString("set")
+		if os.Getenv(p.APITokenEnv) == "" {
+			tokenState = color.RedString("not set")
+		}
+		fmt.Printf("    API Token:       $%s (%s)\n", p.APITokenEnv, tokenState)
+	}
 
 	fmt.Printf("\n  Execution Progress:\n")
 	fmt.Printf("    Total Amount:    %s %s\n", p.TotalAmount, p.SourceToken)
@@ -476,7 +1103,8 @@ func runPlanView(cmd *cobra.Command, args []string) {
 
 		for i := len(p.ExecutionHistory) - 1; i >= start; i-- {
 			exec := p.ExecutionHistory[i]
-			fmt.Printf("\n  [%s] %s\n", exec.Timestamp.Format("2006-01-02 15:04:05"), getExecutionStatusColor(exec.Status))
+			fmt.Printf("\n  [%s] %s\n", formatTimestampFull(exec.Timestamp), getExecutionStatusColor(exec.Status))
+			fmt.Printf("    Execution ID:    %s\n", exec.ID)
 			fmt.Printf("    Amount In:       %s %s\n", exec.Amount, p.SourceToken)
 			fmt.Printf("    Price:           %s %s/%s\n", exec.ActualPrice, p.DestToken, p.SourceToken)
 
@@ -500,11 +1128,27 @@ func runPlanView(cmd *cobra.Command, args []string) {
 				fmt.Printf("    Swap Status:     %s\n", exec.SwapStatus)
 			}
 			if exec.CompletionTime != nil {
-				fmt.Printf("    Completed At:    %s\n", exec.CompletionTime.Format("2006-01-02 15:04:05"))
+				fmt.Printf("    Completed At:    %s\n", formatTimestampFull(*exec.CompletionTime))
+			}
+			if exec.RefundTxHash != "" {
+				refund := exec.RefundTxHash
+				if !exec.RefundConfirmed {
+					refund += " (unverified)"
+				}
+				fmt.Printf("    Refund TX:       %s\n", color.MagentaString(refund))
+			}
+			if exec.QuoteDivergence != "" {
+				fmt.Printf("    Quote Diverge:   %s%%\n", exec.QuoteDivergence)
+			}
+			if exec.Aborts > 1 {
+				fmt.Printf("    Aborted:         %d times in a row\n", exec.Aborts)
 			}
 			if exec.ErrorMessage != "" {
 				fmt.Printf("    Error:           %s\n", color.RedString(exec.ErrorMessage))
 			}
+			if exec.CancelReason != "" {
+				fmt.Printf("    Cancelled:       %s\n", exec.CancelReason)
+			}
 		}
 
 		fmt.Println("\n" + strings.Repeat("=", 70) + "\n")
@@ -522,14 +1166,31 @@ func runPlanStart(cmd *cobra.Command, args []string) {
 	}
 
 	// Create plan manager
-	manager, err := plan.NewManager(cfg.PlanStoragePath)
+	manager, err := newPlanManager(cfg)
 	if err != nil {
 		printError(err)
 		os.Exit(1)
 	}
 
+	// Reconcile unsettled executions while the plan is still inactive, so the daemon
+	// can't pick it up and trade on stale accounting
+	if resumeVerification {
+		executor, err := newExecutor(manager, newAPIClient(cfg), cfg)
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+		result, err := executor.ReconcilePlan(planName)
+		if err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+		fmt.Printf("\nReconciled %d pending execution(s): %d settled, %d still in progress\n",
+			result.Checked, result.Settled, result.Checked-result.Settled)
+	}
+
 	// Start the plan
-	if err := manager.StartPlan(planName); err != nil {
+	if err := manager.StartPlan(planName, planForce); err != nil {
 		printError(err)
 		os.Exit(1)
 	}
@@ -551,7 +1212,7 @@ func runPlanStop(cmd *cobra.Command, args []string) {
 	}
 
 	// Create plan manager
-	manager, err := plan.NewManager(cfg.PlanStoragePath)
+	manager, err := newPlanManager(cfg)
 	if err != nil {
 		printError(err)
 		os.Exit(1)
@@ -568,6 +1229,124 @@ func runPlanStop(cmd *cobra.Command, args []string) {
 	color.Cyan("  near-swap plan start %s\n", planName)
 }
 
+func runPlanCancelExecution(cmd *cobra.Command, args []string) {
+	planName, executionID := args[0], args[1]
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	// Load config
+	cfg, err := config.Load()
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Create plan manager
+	manager, err := newPlanManager(cfg)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	exec, err := manager.CancelExecution(planName, executionID, cancelExecutionReason)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		output, _ := json.MarshalIndent(exec, "", "  ")
+		fmt.Println(string(output))
+		return
+	}
+
+	p, err := manager.GetPlan(planName)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	color.Green("\n✓ Execution %s of plan '%s' has been cancelled.\n", exec.ID, planName)
+	fmt.Printf("  Reason: %s\n", exec.CancelReason)
+	if exec.TxHash == "" {
+		fmt.Printf("\nNo deposit was sent; its %s %s was returned to the plan's limits.\n", exec.Amount, p.SourceToken)
+		return
+	}
+
+	color.Yellow("\nIts deposit was already sent (TX: %s).", exec.TxHash)
+	fmt.Println("The 1Click API can't cancel a swap or refund it on request, so the swap may still complete.")
+	fmt.Printf("If it fails or its quote expires, the deposit is refunded to %s.\n", p.RefundAddr)
+	fmt.Println("Check on it with:")
+	color.Cyan("  near-swap status %s\n", exec.DepositAddress)
+}
+
+func runPlanPauseAll(cmd *cobra.Command, args []string) {
+	runBulkStatusChange(cmd, "paused", func(manager *plan.Manager) []plan.BulkResult {
+		return manager.PauseAll()
+	})
+}
+
+func runPlanResumeAll(cmd *cobra.Command, args []string) {
+	runBulkStatusChange(cmd, "resumed", func(manager *plan.Manager) []plan.BulkResult {
+		return manager.ResumeAll()
+	})
+}
+
+// runBulkStatusChange applies pause-all or resume-all and prints a summary of each plan
+func runBulkStatusChange(cmd *cobra.Command, verb string, apply func(*plan.Manager) []plan.BulkResult) {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	// Load config
+	cfg, err := config.Load()
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Create plan manager
+	manager, err := newPlanManager(cfg)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	results := apply(manager)
+	changed, failed := 0, 0
+	for _, r := range results {
+		if r.Changed {
+			changed++
+		}
+		if r.Error != "" {
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		output, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(output))
+	} else if len(results) == 0 {
+		color.Yellow("\nNo plans to change.\n")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\nPLAN\tSTATUS\tRESULT")
+		fmt.Fprintln(w, strings.Repeat("-", 70))
+		for _, r := range results {
+			result := color.GreenString(verb)
+			if r.Error != "" {
+				result = color.RedString("failed: %s", r.Error)
+			} else if !r.Changed {
+				result = color.YellowString("left alone (%s)", r.Detail)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", r.Plan, getStatusColor(r.Status), result)
+		}
+		w.Flush()
+		fmt.Printf("\n%d plan(s) %s.\n", changed, verb)
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
 func runPlanDelete(cmd *cobra.Command, args []string) {
 	planName := args[0]
 
@@ -579,7 +1358,7 @@ func runPlanDelete(cmd *cobra.Command, args []string) {
 	}
 
 	// Create plan manager
-	manager, err := plan.NewManager(cfg.PlanStoragePath)
+	manager, err := newPlanManager(cfg)
 	if err != nil {
 		printError(err)
 		os.Exit(1)
@@ -596,7 +1375,7 @@ func runPlanDelete(cmd *cobra.Command, args []string) {
 
 func runPlanHistory(cmd *cobra.Command, args []string) {
 	planName := args[0]
-	jsonOutput, _ := cmd.Flags().GetBool("json")
+	verbose, _ := cmd.Flags().GetBool("verbose")
 
 	// Load config
 	cfg, err := config.Load()
@@ -605,8 +1384,14 @@ func runPlanHistory(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	format := mustOutputFormat(cmd, cfg, true)
+	if historyFollow && format != output.Table && format != output.JSON {
+		printError(fmt.Errorf("--follow supports table or json output, not %s", format))
+		os.Exit(1)
+	}
+
 	// Create plan manager
-	manager, err := plan.NewManager(cfg.PlanStoragePath)
+	manager, err := newPlanManager(cfg)
 	if err != nil {
 		printError(err)
 		os.Exit(1)
@@ -619,20 +1404,34 @@ func runPlanHistory(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	if jsonOutput {
-		output, _ := json.MarshalIndent(history, "", "  ")
-		fmt.Println(string(output))
+	// Get plan details for token symbols
+	p, _ := manager.GetPlan(planName)
+
+	if format != output.Table {
+		if historyFollow {
+			// One execution per line so the stream can be consumed incrementally
+			for _, exec := range history {
+				output, _ := json.Marshal(exec)
+				fmt.Println(string(output))
+			}
+			followPlanHistory(manager, planName, func(exec plan.Execution) {
+				output, _ := json.Marshal(exec)
+				fmt.Println(string(output))
+			})
+			return
+		}
+		printOutput(format, history)
 		return
 	}
 
 	if len(history) == 0 {
 		color.Yellow("\nNo execution history found for plan '%s'.\n", planName)
+		if historyFollow {
+			followHistoryTable(manager, p, verbose)
+		}
 		return
 	}
 
-	// Get plan details for token symbols
-	p, _ := manager.GetPlan(planName)
-
 	// Calculate totals
 	var totalSold, totalReceived float64
 	completedCount := 0
@@ -645,7 +1444,7 @@ func runPlanHistory(cmd *cobra.Command, args []string) {
 
 		// Sum amounts received (only for completed with actual output)
 		if exec.Status == plan.ExecutionCompleted && exec.ActualOutput != "" {
-			if amount, err := strconv.ParseFloat(exec.ActualOutput, 64); err == nil {
+			if amount, err := parser.ParseFormattedAmount(exec.ActualOutput); err == nil {
 				totalReceived += amount
 				completedCount++
 			}
@@ -673,28 +1472,614 @@ func runPlanHistory(cmd *cobra.Command, args []string) {
 	fmt.Fprintln(w, strings.Repeat("-", 120))
 
 	for _, exec := range history {
-		timestamp := exec.Timestamp.Format("2006-01-02 15:04")
-		amountIn := fmt.Sprintf("%s %s", exec.Amount, p.SourceToken)
+		fmt.Fprintln(w, historyRow(exec, p, verbose))
+	}
 
-		// Show actual output if available, otherwise estimated
-		amountOut := ""
-		if exec.ActualOutput != "" {
-			amountOut = fmt.Sprintf("%s %s", exec.ActualOutput, p.DestToken)
-		} else if exec.EstimatedOutput != "" {
-			amountOut = fmt.Sprintf("~%s %s", exec.EstimatedOutput, p.DestToken)
+	w.Flush()
+
+	if historyFollow {
+		followHistoryTable(manager, p, verbose)
+		return
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 120) + "\n")
+}
+
+// trailingStopDisplay describes a trailing stop plan's trigger and, once it has seen a price,
+// its current peak and stop price
+func trailingStopDisplay(p *plan.TradingPlan) string {
+	trigger := fmt.Sprintf("When price falls %s%% below its peak", strconv.FormatFloat(p.TrailPercent, 'f', -1, 64))
+	if p.PeakPrice <= 0 {
+		return trigger + " (no price seen yet)"
+	}
+	return fmt.Sprintf("%s (peak %.8g, stop at %.8g %s/%s)", trigger,
+		p.PeakPrice, p.TrailingStopPrice(p.PeakPrice), p.DestToken, p.SourceToken)
+}
+
+// killSwitchDisplay describes the prices that cancel a plan, or "" when none are set
+func killSwitchDisplay(p *plan.TradingPlan) string {
+	var parts []string
+	if p.CancelBelow != "" {
+		parts = append(parts, fmt.Sprintf("cancel at or below %s", p.CancelBelow))
+	}
+	if p.CancelAbove != "" {
+		parts = append(parts, fmt.Sprintf("cancel at or above %s", p.CancelAbove))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s %s/%s", strings.Join(parts, ", "), p.DestToken, p.SourceToken)
+}
+
+// verificationDisplay describes a verify-first plan's verification swap and whether it has passed
+func verificationDisplay(p *plan.TradingPlan) string {
+	if p.VerifiedAt != nil {
+		return fmt.Sprintf("%s %s swap %s %s", p.VerifyAmount, p.SourceToken,
+			color.GreenString("passed"), formatTimestampFull(*p.VerifiedAt))
+	}
+	return fmt.Sprintf("%s %s swap %s before the first full trade", p.VerifyAmount, p.SourceToken,
+		color.YellowString("pending"))
+}
+
+// historyRow formats an execution as a tab-separated row of the history table
+func historyRow(exec plan.Execution, p *plan.TradingPlan, verbose bool) string {
+	timestamp := formatTimestamp(exec.Timestamp, verbose)
+	amountIn := fmt.Sprintf("%s %s", exec.Amount, p.SourceToken)
+
+	// Show actual output if available, otherwise estimated
+	amountOut := ""
+	if exec.ActualOutput != "" {
+		amountOut = fmt.Sprintf("%s %s", exec.ActualOutput, p.DestToken)
+	} else if exec.EstimatedOutput != "" {
+		amountOut = fmt.Sprintf("~%s %s", exec.EstimatedOutput, p.DestToken)
+	}
+
+	price := exec.ActualPrice
+	status := getExecutionStatusColor(exec.Status)
+	if exec.Verification {
+		status += " (verify)"
+	}
+	depositTx := truncateString(exec.TxHash, 12)
+	destTx := truncateString(exec.DestinationTxHash, 12)
+
+	return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s",
+		timestamp, amountIn, amountOut, price, status, depositTx, destTx)
+}
+
+// followHistoryTable prints new executions as table rows until the plan finishes or Ctrl+C
+func followHistoryTable(manager *plan.Manager, p *plan.TradingPlan, verbose bool) {
+	color.Yellow("\nFollowing new executions (Ctrl+C to stop)...\n")
+
+	// Rows are flushed one at a time, so use a fixed minimum width to keep columns aligned
+	w := tabwriter.NewWriter(os.Stdout, 16, 0, 2, ' ', 0)
+	final := followPlanHistory(manager, p.Name, func(exec plan.Execution) {
+		fmt.Fprintln(w, historyRow(exec, p, verbose))
+		w.Flush()
+	})
+
+	if final != nil && (final.Status == plan.StatusCompleted || final.Status == plan.StatusCancelled) {
+		color.Green("\n✓ Plan '%s' is %s. No further executions will be recorded.\n", p.Name, final.Status)
+	}
+}
+
+// followPlanHistory calls emit for each new execution of a plan until it finishes, is
+// deleted, or the user interrupts. It returns the last state of the plan, if any.
+func followPlanHistory(manager *plan.Manager, planName string, emit func(plan.Execution)) *plan.TradingPlan {
+	stop := make(chan struct{})
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	go func() {
+		<-sigChan
+		close(stop)
+	}()
+
+	final, err := manager.FollowExecutions(planName, plan.DefaultFollowInterval, stop, emit)
+	if err != nil {
+		if errors.Is(err, plan.ErrPlanNotFound) {
+			color.Yellow("\nPlan '%s' was deleted. Stopped following.\n", planName)
+			return nil
+		}
+		printError(err)
+		os.Exit(1)
+	}
+	return final
+}
+
+func runPlanExportHistory(cmd *cobra.Command, args []string) {
+	planName := args[0]
+
+	if exportFormat != "tax" && exportFormat != "json" {
+		printError(fmt.Errorf("invalid format '%s', must be 'tax' or 'json'", exportFormat))
+		os.Exit(1)
+	}
+
+	lots, err := plan.ParseBasisLots(exportBasis)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Load config
+	cfg, err := config.Load()
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Create plan manager
+	manager, err := newPlanManager(cfg)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	p, err := manager.GetPlan(planName)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	ledger, err := plan.BuildLedger(p, lots)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	var out bytes.Buffer
+	if exportFormat == "json" {
+		data, _ := json.MarshalIndent(ledger, "", "  ")
+		out.Write(data)
+		out.WriteString("\n")
+	} else if err := ledger.WriteCSV(&out); err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	if exportOutput == "" {
+		os.Stdout.Write(out.Bytes())
+		fmt.Fprintf(os.Stderr, "sha256: %s (%d rows)\n", ledger.Manifest.SHA256, ledger.Manifest.Rows)
+		return
+	}
+
+	if err := os.WriteFile(exportOutput, out.Bytes(), 0600); err != nil {
+		printError(fmt.Errorf("failed to write ledger: %w", err))
+		os.Exit(1)
+	}
+	if exportFormat == "json" {
+		printSuccess(fmt.Sprintf("Exported %d trade(s) of plan '%s' to %s", ledger.Manifest.Rows, p.Name, exportOutput))
+		return
+	}
+
+	manifestPath := exportOutput + ".manifest.json"
+	manifest, _ := json.MarshalIndent(ledger.Manifest, "", "  ")
+	if err := os.WriteFile(manifestPath, append(manifest, '\n'), 0600); err != nil {
+		printError(fmt.Errorf("failed to write manifest: %w", err))
+		os.Exit(1)
+	}
+
+	printSuccess(fmt.Sprintf("Exported %d trade(s) of plan '%s' to %s\nManifest: %s (sha256 %s)",
+		ledger.Manifest.Rows, p.Name, exportOutput, manifestPath, ledger.Manifest.SHA256))
+}
+
+func runPlanExport(cmd *cobra.Command, args []string) {
+	status := plan.PlanStatus(backupStatus)
+	switch status {
+	case "", plan.StatusActive, plan.StatusPaused, plan.StatusCompleted, plan.StatusCancelled:
+	default:
+		printError(fmt.Errorf("invalid status '%s', must be active, paused, completed or cancelled", backupStatus))
+		os.Exit(1)
+	}
+
+	// Load config
+	cfg, err := config.Load()
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Create plan manager
+	manager, err := newPlanManager(cfg)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	export, err := manager.ExportPlans(backupNames, status)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	data, _ := json.MarshalIndent(export, "", "  ")
+	data = append(data, '\n')
+
+	if backupOutput == "" {
+		os.Stdout.Write(data)
+		fmt.Fprintf(os.Stderr, "Exported %d plan(s). WARNING: the export %s.\n", len(export.Plans), plan.ExportNotice)
+		return
+	}
+
+	if err := os.WriteFile(backupOutput, data, 0600); err != nil {
+		printError(fmt.Errorf("failed to write export: %w", err))
+		os.Exit(1)
+	}
+	printSuccess(fmt.Sprintf("Exported %d plan(s) to %s", len(export.Plans), backupOutput))
+	color.Yellow("WARNING: the export %s.\n", plan.ExportNotice)
+}
+
+func runPlanImport(cmd *cobra.Command, args []string) {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	export, err := plan.LoadPlanExport(args[0])
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Load config
+	cfg, err := config.Load()
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Create plan manager
+	manager, err := newPlanManager(cfg)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	results := manager.ImportPlans(export, importOverwrite)
+	imported := 0
+	for _, r := range results {
+		if r.Imported {
+			imported++
 		}
+	}
+	skipped := len(results) - imported
+
+	if jsonOutput {
+		output, _ := json.MarshalIndent(results, "", "  ")
+		fmt.Println(string(output))
+	} else if len(results) == 0 {
+		color.Yellow("\nNo plans in %s.\n", args[0])
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\nPLAN\tRESULT")
+		fmt.Fprintln(w, strings.Repeat("-", 70))
+		for _, r := range results {
+			var result string
+			switch {
+			case !r.Imported:
+				result = color.RedString("skipped: %s", r.Detail)
+			case r.Overwrote:
+				result = color.YellowString("overwritten")
+			default:
+				result = color.GreenString("imported")
+			}
+			if r.Imported && r.Detail != "" {
+				result += fmt.Sprintf(" (%s)", r.Detail)
+			}
+			fmt.Fprintf(w, "%s\t%s\n", r.Plan, result)
+		}
+		w.Flush()
+		fmt.Printf("\n%d plan(s) imported, %d skipped.\n", imported, skipped)
+	}
+
+	if skipped > 0 {
+		os.Exit(1)
+	}
+}
 
-		price := exec.ActualPrice
-		status := getExecutionStatusColor(exec.Status)
-		depositTx := truncateString(exec.TxHash, 12)
-		destTx := truncateString(exec.DestinationTxHash, 12)
+func runPlanRecompute(cmd *cobra.Command, args []string) {
+	planName := args[0]
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	// Load config
+	cfg, err := config.Load()
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-			timestamp, amountIn, amountOut, price, status, depositTx, destTx)
+	// Create plan manager
+	manager, err := newPlanManager(cfg)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Capture the current values for comparison
+	before, err := manager.GetPlan(planName)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+	oldExecuted := before.TotalExecuted
+	oldRemaining := before.RemainingAmount
+	oldToday := before.TodayExecuted
+	oldStatus := before.Status
+
+	p, err := manager.RecomputeProgress(planName)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		output, _ := json.MarshalIndent(p.ToSummary(), "", "  ")
+		fmt.Println(string(output))
+		return
 	}
 
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	color.Green("                    RECOMPUTED PLAN PROGRESS")
+	fmt.Println(strings.Repeat("=", 70))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nFIELD\tBEFORE\tAFTER")
+	fmt.Fprintln(w, strings.Repeat("-", 70))
+	fmt.Fprintf(w, "Executed\t%s\t%s\n", oldExecuted, p.TotalExecuted)
+	fmt.Fprintf(w, "Remaining\t%s\t%s\n", oldRemaining, p.RemainingAmount)
+	fmt.Fprintf(w, "Today Executed\t%s\t%s\n", oldToday, p.TodayExecuted)
+	fmt.Fprintf(w, "Status\t%s\t%s\n", oldStatus, p.Status)
 	w.Flush()
-	fmt.Println("\n" + strings.Repeat("=", 120) + "\n")
+
+	fmt.Println("\n" + strings.Repeat("=", 70) + "\n")
+}
+
+func runPlanAudit(cmd *cobra.Command, args []string) {
+	planName := args[0]
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	// Load config
+	cfg, err := config.Load()
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Create plan manager
+	manager, err := newPlanManager(cfg)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	p, err := manager.GetPlan(planName)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	depositMgr := deposit.NewManager(cfg.AutoDeposit)
+	if err := depositMgr.CheckChain(p.SourceChain); err != nil {
+		printError(fmt.Errorf("cannot look up %s transactions: %w", p.SourceChain, err))
+		os.Exit(1)
+	}
+
+	findings := plan.AuditDeposits(p, depositMgr)
+	discrepancies := 0
+	for _, f := range findings {
+		if f.Discrepancy() {
+			discrepancies++
+		}
+	}
+
+	if jsonOutput {
+		output, _ := json.MarshalIndent(map[string]interface{}{
+			"plan":          p.Name,
+			"chain":         p.SourceChain,
+			"checked":       len(findings),
+			"discrepancies": discrepancies,
+			"executions":    findings,
+		}, "", "  ")
+		fmt.Println(string(output))
+	} else {
+		fmt.Println("\n" + strings.Repeat("=", 70))
+		color.Green("                 DEPOSIT AUDIT: %s", p.Name)
+		fmt.Println(strings.Repeat("=", 70))
+
+		if len(findings) == 0 {
+			color.Yellow("\nNo deposited or completed executions to audit.\n")
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "\nTIME\tAMOUNT\tTX\tRESULT\tDETAIL")
+			fmt.Fprintln(w, strings.Repeat("-", 70))
+			for _, f := range findings {
+				result := color.GreenString(f.Result)
+				if f.Discrepancy() {
+					result = color.RedString(f.Result)
+				} else if f.Result != plan.AuditOK {
+					result = color.YellowString(f.Result)
+				}
+				fmt.Fprintf(w, "%s\t%s %s\t%s\t%s\t%s\n",
+					f.Timestamp.Format("2006-01-02 15:04"), f.Amount, p.SourceToken,
+					truncateString(f.TxHash, 20), result, truncateString(f.Detail, 60))
+			}
+			w.Flush()
+		}
+
+		fmt.Printf("\nChecked %d execution(s) on %s: ", len(findings), p.SourceChain)
+		if discrepancies == 0 {
+			color.Green("no discrepancies")
+		} else {
+			color.Red("%d discrepancy(ies)", discrepancies)
+		}
+		fmt.Println("\n" + strings.Repeat("=", 70) + "\n")
+	}
+
+	if discrepancies > 0 {
+		os.Exit(1)
+	}
+}
+
+func runPlanDiff(cmd *cobra.Command, args []string) {
+	planName, other := args[0], args[1]
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	// Load config
+	cfg, err := config.Load()
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Create plan manager
+	manager, err := newPlanManager(cfg)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	p, err := manager.GetPlan(planName)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// The second argument names a plan if one exists, otherwise a definition file
+	var target *plan.TradingPlan
+	if manager.GetStorage().Exists(other) {
+		target, err = manager.GetPlan(other)
+	} else {
+		target, err = plan.LoadPlanDefinition(other)
+	}
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	changes := plan.DiffPlans(p, target)
+
+	if jsonOutput {
+		output, _ := json.MarshalIndent(map[string]interface{}{
+			"plan":    p.Name,
+			"against": other,
+			"changes": changes,
+		}, "", "  ")
+		fmt.Println(string(output))
+	} else if len(changes) == 0 {
+		color.Green("No parameter differences between '%s' and %s", p.Name, other)
+	} else {
+		fmt.Printf("Parameters of '%s' that differ in %s:\n\n", p.Name, other)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, change := range changes {
+			fmt.Fprintf(w, "  %s\t%s\t-> %s\n", change.Field,
+				color.RedString(diffValue(change.From)), color.GreenString(diffValue(change.To)))
+		}
+		w.Flush()
+	}
+
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+}
+
+func runPlanValidate(cmd *cobra.Command, args []string) {
+	target := args[0]
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	// Load config
+	cfg, err := config.Load()
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Create plan manager
+	manager, err := newPlanManager(cfg)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// The argument names a plan if one exists, otherwise a definition file
+	var p *plan.TradingPlan
+	if manager.GetStorage().Exists(target) {
+		p, err = manager.GetPlan(target)
+	} else {
+		p, err = plan.LoadPlanDefinition(target)
+	}
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	findings := validatePlan(cfg, p, !planSkipMarketCheck)
+
+	if jsonOutput {
+		output, _ := json.MarshalIndent(map[string]interface{}{
+			"plan":     p.Name,
+			"valid":    !plan.HasErrors(findings),
+			"findings": findings,
+		}, "", "  ")
+		fmt.Println(string(output))
+	} else if len(findings) == 0 {
+		color.Green("\n✓ No problems found with '%s'\n", p.Name)
+	} else {
+		printFindings(findings)
+	}
+
+	if plan.HasErrors(findings) {
+		os.Exit(1)
+	}
+}
+
+// validatePlan runs the structural checks on a plan and, when market is set, the market checks
+// using the plan's own API token if it has one
+func validatePlan(cfg *config.Config, p *plan.TradingPlan, market bool) []plan.Finding {
+	if !market {
+		return plan.ValidatePlan(p)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), marketCheckTimeout)
+	defer cancel()
+	return plan.ValidatePlanWith(ctx, p, planMarketClient(cfg))
+}
+
+// planMarketClient returns the client plan market checks quote with: one using the plan's own
+// API token when its variable is set, else the configured JWT token, or nil when there is none
+func planMarketClient(cfg *config.Config) func(apiTokenEnv string) *client.OneClickClient {
+	return func(apiTokenEnv string) *client.OneClickClient {
+		token := cfg.JWTToken
+		if apiTokenEnv != "" && os.Getenv(apiTokenEnv) != "" {
+			token = os.Getenv(apiTokenEnv)
+		}
+		if token == "" {
+			return nil
+		}
+		return newAPIClientWithToken(cfg, token)
+	}
+}
+
+// printFindings lists validation findings, errors in red and warnings in yellow
+func printFindings(findings []plan.Finding) {
+	for _, f := range findings {
+		field := ""
+		if f.Field != "" {
+			field = f.Field + ": "
+		}
+		if f.Severity == plan.SeverityError {
+			color.Red("\nERROR: %s%s", field, f.Message)
+		} else {
+			color.Yellow("\nWARNING: %s%s", field, f.Message)
+		}
+	}
+	if len(findings) > 0 {
+		fmt.Println()
+	}
+}
+
+// diffValue displays an unset parameter in a diff
+func diffValue(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
 }
 
 // Helper functions
@@ -702,7 +2087,7 @@ func runPlanHistory(cmd *cobra.Command, args []string) {
 func parsePriceCondition(input string) (plan.PriceCondition, string, error) {
 	parts := strings.Fields(input)
 	if len(parts) != 2 {
-		return "", "", fmt.Errorf("price condition must be in format '<condition> <price>' (e.g., 'above 150000')")
+		return "", "", fmt.Errorf("price condition must be in format '<condition> <price>' (e.g., 'above 150000' or 'trailing 5%%')")
 	}
 
 	conditionStr := strings.ToLower(parts[0])
@@ -716,8 +2101,10 @@ func parsePriceCondition(input string) (plan.PriceCondition, string, error) {
 		condition = plan.PriceBelow
 	case "at", "=", "==":
 		condition = plan.PriceAt
+	case "trailing":
+		condition = plan.PriceTrailingStop
 	default:
-		return "", "", fmt.Errorf("invalid condition '%s', must be 'above', 'below', or 'at'", conditionStr)
+		return "", "", fmt.Errorf("invalid condition '%s', must be 'above', 'below', 'at', or 'trailing'", conditionStr)
 	}
 
 	return condition, price, nil
@@ -748,11 +2135,29 @@ func getExecutionStatusColor(status plan.ExecutionStatus) string {
 		return color.YellowString(string(status))
 	case plan.ExecutionFailed:
 		return color.RedString(string(status))
+	case plan.ExecutionObserved:
+		return color.MagentaString(string(status))
+	case plan.ExecutionCancelled:
+		return color.HiBlackString(string(status))
 	default:
 		return string(status)
 	}
 }
 
+// perTradeDisplay describes how each trade of a plan is sized
+func perTradeDisplay(p *plan.TradingPlan) string {
+	if p.IsDestSized() {
+		return fmt.Sprintf("acquire %s %s", p.AmountPerTradeDest, p.DestToken)
+	}
+	if p.HasLadder() {
+		return fmt.Sprintf("laddered over %d level(s)", len(p.Ladder))
+	}
+	if p.HasJitter() {
+		return fmt.Sprintf("%s %s (±%s%%)", p.AmountPerTrade, p.SourceToken, strconv.FormatFloat(p.AmountJitter, 'f', -1, 64))
+	}
+	return fmt.Sprintf("%s %s", p.AmountPerTrade, p.SourceToken)
+}
+
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -765,7 +2170,13 @@ func truncateString(s string, maxLen int) string {
 
 func runPlanStats(cmd *cobra.Command, args []string) {
 	planName := args[0]
-	jsonOutput, _ := cmd.Flags().GetBool("json")
+	verbose, _ := cmd.Flags().GetBool("verbose")
+
+	lots, err := plan.ParseBasisLots(statsCostBasis)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
 
 	// Load config
 	cfg, err := config.Load()
@@ -775,7 +2186,7 @@ func runPlanStats(cmd *cobra.Command, args []string) {
 	}
 
 	// Create plan manager
-	manager, err := plan.NewManager(cfg.PlanStoragePath)
+	manager, err := newPlanManager(cfg)
 	if err != nil {
 		printError(err)
 		os.Exit(1)
@@ -790,10 +2201,20 @@ func runPlanStats(cmd *cobra.Command, args []string) {
 
 	history := p.ExecutionHistory
 
-	if jsonOutput {
-		statsData := calculateStats(p, history)
-		output, _ := json.MarshalIndent(statsData, "", "  ")
-		fmt.Println(string(output))
+	perf, err := plan.CalculatePerformance(p, lots)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	if format := mustOutputFormat(cmd, cfg, true); format != output.Table {
+		statsData := calculateStats(p, history, perf)
+		// A CSV has room for the transactions only
+		if format == output.CSV {
+			printOutput(format, statsData["transactions"])
+			return
+		}
+		printOutput(format, statsData)
 		return
 	}
 
@@ -814,7 +2235,7 @@ func runPlanStats(cmd *cobra.Command, args []string) {
 
 		// Sum received amounts (only for completed swaps with actual output)
 		if exec.ActualOutput != "" {
-			if amount, err := strconv.ParseFloat(exec.ActualOutput, 64); err == nil {
+			if amount, err := parser.ParseFormattedAmount(exec.ActualOutput); err == nil {
 				totalReceived += amount
 			}
 		}
@@ -837,6 +2258,7 @@ func runPlanStats(cmd *cobra.Command, args []string) {
 		fmt.Printf("  Total Received:     %s %s\n", color.GreenString("%.8f", totalReceived), p.DestToken)
 	}
 	fmt.Printf("  Remaining:          %s %s\n", p.RemainingAmount, p.SourceToken)
+	displayPerformance(p, perf)
 
 	if totalSwaps == 0 {
 		fmt.Println("\n" + strings.Repeat("=", 100))
@@ -845,6 +2267,9 @@ func runPlanStats(cmd *cobra.Command, args []string) {
 	}
 
 	// Pagination
+	if statsPageSize < 1 {
+		statsPageSize = 10
+	}
 	totalPages := (totalSwaps + statsPageSize - 1) / statsPageSize
 	if statsPage < 1 {
 		statsPage = 1
@@ -854,10 +2279,12 @@ func runPlanStats(cmd *cobra.Command, args []string) {
 	}
 
 	startIdx := (statsPage - 1) * statsPageSize
-	endIdx := startIdx + statsPageSize
-	if endIdx > totalSwaps {
-		endIdx = totalSwaps
+	page, _, err := manager.GetExecutionsPage(planName, startIdx, statsPageSize)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
 	}
+	endIdx := startIdx + len(page)
 
 	// Display transactions table
 	fmt.Println("\n" + strings.Repeat("=", 100))
@@ -868,27 +2295,9 @@ func runPlanStats(cmd *cobra.Command, args []string) {
 	fmt.Fprintln(w, "\nTIMESTAMP\tAMOUNT IN\tAMOUNT OUT\tPRICE\tSTATUS\tTX HASH\tDEST TX")
 	fmt.Fprintln(w, strings.Repeat("-", 100))
 
-	// Show transactions in reverse order (most recent first)
-	for i := len(history) - 1 - startIdx; i >= 0 && i >= len(history)-endIdx; i-- {
-		exec := history[i]
-		timestamp := exec.Timestamp.Format("2006-01-02 15:04")
-		amountIn := fmt.Sprintf("%s %s", exec.Amount, p.SourceToken)
-
-		// Show actual output if available, otherwise estimated
-		amountOut := ""
-		if exec.ActualOutput != "" {
-			amountOut = fmt.Sprintf("%s %s", exec.ActualOutput, p.DestToken)
-		} else if exec.EstimatedOutput != "" {
-			amountOut = fmt.Sprintf("~%s %s", exec.EstimatedOutput, p.DestToken)
-		}
-
-		price := exec.ActualPrice
-		status := getExecutionStatusColor(exec.Status)
-		txHash := truncateString(exec.TxHash, 12)
-		destTx := truncateString(exec.DestinationTxHash, 12)
-
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
-			timestamp, amountIn, amountOut, price, status, txHash, destTx)
+	// Pages come back most recent first
+	for _, exec := range page {
+		fmt.Fprintln(w, historyRow(exec, p, verbose))
 	}
 
 	w.Flush()
@@ -907,7 +2316,7 @@ func runPlanStats(cmd *cobra.Command, args []string) {
 	fmt.Println()
 }
 
-func calculateStats(p *plan.TradingPlan, history []plan.Execution) map[string]interface{} {
+func calculateStats(p *plan.TradingPlan, history []plan.Execution, perf *plan.Performance) map[string]interface{} {
 	totalSwaps := len(history)
 	completedSwaps := 0
 	var totalDeposited, totalReceived float64
@@ -924,7 +2333,7 @@ func calculateStats(p *plan.TradingPlan, history []plan.Execution) map[string]in
 		}
 
 		if exec.ActualOutput != "" {
-			if amount, err := strconv.ParseFloat(exec.ActualOutput, 64); err == nil {
+			if amount, err := parser.ParseFormattedAmount(exec.ActualOutput); err == nil {
 				totalReceived += amount
 			}
 		}
@@ -941,6 +2350,9 @@ func calculateStats(p *plan.TradingPlan, history []plan.Execution) map[string]in
 			"tx_hash":             exec.TxHash,
 			"destination_tx_hash": exec.DestinationTxHash,
 			"swap_status":         exec.SwapStatus,
+			"refund_tx_hash":      exec.RefundTxHash,
+			"refunded_amount":     exec.RefundedAmount,
+			"quote_divergence":    exec.QuoteDivergence,
 		}
 		transactions = append(transactions, txData)
 	}
@@ -956,10 +2368,49 @@ func calculateStats(p *plan.TradingPlan, history []plan.Execution) map[string]in
 		"total_deposited":  fmt.Sprintf("%.8f", totalDeposited),
 		"total_received":   fmt.Sprintf("%.8f", totalReceived),
 		"remaining_amount": p.RemainingAmount,
+		"performance":      perf,
 		"transactions":     transactions,
 	}
 }
 
+// displayPerformance prints the average price and realized P&L of a plan's completed executions
+func displayPerformance(p *plan.TradingPlan, perf *plan.Performance) {
+	if perf.Completed == 0 {
+		return
+	}
+
+	pair := fmt.Sprintf("%s/%s", p.DestToken, p.SourceToken)
+	fmt.Println()
+	fmt.Printf("  Sold (completed):   %s %s\n", color.CyanString(perf.Sold), p.SourceToken)
+	fmt.Printf("  Value Received:     %s %s\n", color.GreenString(perf.Received), p.DestToken)
+	if perf.AveragePrice != "" {
+		fmt.Printf("  Avg Executed Price: %s %s\n", color.CyanString(perf.AveragePrice), pair)
+	}
+	if perf.TriggerValue != "" {
+		fmt.Printf("  Value at Triggers:  %s %s (%s vs. trigger)\n", perf.TriggerValue, p.DestToken, signedAmount(perf.VersusTrigger))
+	}
+	if perf.CostBasis != "" {
+		fmt.Printf("  Cost Basis:         %s %s\n", perf.CostBasis, p.DestToken)
+		pnl := fmt.Sprintf("%s %s", signedAmount(perf.RealizedPnL), p.DestToken)
+		if perf.RealizedReturn != "" {
+			pnl += fmt.Sprintf(" (%s%%)", signedAmount(perf.RealizedReturn))
+		}
+		fmt.Printf("  Realized P&L:       %s\n", pnl)
+	}
+}
+
+// signedAmount colors a decimal string green when it is positive and red when negative
+func signedAmount(amount string) string {
+	switch {
+	case strings.HasPrefix(amount, "-"):
+		return color.RedString(amount)
+	case strings.Trim(amount, "0.") == "":
+		return amount
+	default:
+		return color.GreenString("+" + amount)
+	}
+}
+
 func runPlanDaemon(cmd *cobra.Command, args []string) {
 	// Load config
 	cfg, err := config.Load()
@@ -967,9 +2418,12 @@ func runPlanDaemon(cmd *cobra.Command, args []string) {
 		printError(err)
 		os.Exit(1)
 	}
+	if daemonObserve {
+		cfg.ObserverMode = true
+	}
 
 	// Create plan manager
-	manager, err := plan.NewManager(cfg.PlanStoragePath)
+	manager, err := newPlanManager(cfg)
 	if err != nil {
 		printError(err)
 		os.Exit(1)
@@ -978,6 +2432,12 @@ func runPlanDaemon(cmd *cobra.Command, args []string) {
 	// Get all active plans
 	activePlans := manager.GetActivePlans()
 
+	// With JSON logs every line on stdout is a log record, so the banners become records too
+	if strings.EqualFold(strings.TrimSpace(cfg.LogFormat), logging.FormatJSON) {
+		runJSONDaemon(cfg, manager, activePlans)
+		return
+	}
+
 	if len(activePlans) == 0 {
 		color.Yellow("\nNo active plans found.\n")
 		fmt.Println("\nTo create and start a plan:")
@@ -990,6 +2450,10 @@ func runPlanDaemon(cmd *cobra.Command, args []string) {
 	fmt.Println("\n" + strings.Repeat("=", 70))
 	color.Green("              NEAR-SWAP TRADING PLAN DAEMON")
 	fmt.Println(strings.Repeat("=", 70))
+	if cfg.ObserverMode {
+		color.Magenta("\n  OBSERVER MODE - triggers are recorded as 'observed' executions;")
+		color.Magenta("  no quotes are executed and no deposits will be sent")
+	}
 	fmt.Printf("\nLoading %d active plan(s)...\n\n", len(activePlans))
 
 	// Display loaded plans with their current state
@@ -998,7 +2462,11 @@ func runPlanDaemon(cmd *cobra.Command, args []string) {
 		fmt.Printf("      Strategy:  %s %s -> %s\n", p.TotalAmount, p.SourceToken, p.DestToken)
 		fmt.Printf("      Progress:  %s / %s executed\n", p.TotalExecuted, p.TotalAmount)
 		fmt.Printf("      Today:     %s / %s (daily limit)\n", p.TodayExecuted, p.AmountPerDay)
-		fmt.Printf("      Trigger:   Price %s %s %s/%s\n", p.PriceCondition, p.TriggerPrice, p.DestToken, p.SourceToken)
+		if p.IsTrailingStop() {
+			fmt.Printf("      Trigger:   %s\n", trailingStopDisplay(p))
+		} else {
+			fmt.Printf("      Trigger:   Price %s %s %s/%s\n", p.PriceCondition, p.TriggerPrice, p.DestToken, p.SourceToken)
+		}
 		if p.ExecutionCount > 0 {
 			fmt.Printf("      History:   %d execution(s)\n", p.ExecutionCount)
 		}
@@ -1006,25 +2474,57 @@ func runPlanDaemon(cmd *cobra.Command, args []string) {
 	}
 
 	// Check auto-deposit configuration
-	if !cfg.AutoDeposit.Enabled {
+	if cfg.ObserverMode {
+		// Nothing is deposited while observing
+	} else if !cfg.AutoDeposit.Enabled {
 		color.Red("\n⚠ WARNING: Auto-deposit is not enabled in your configuration!")
 		color.Yellow("Plans will not be able to execute trades automatically.")
 		color.Yellow("Please configure auto-deposit in your .near-swap.yaml file.\n")
+	} else {
+		depositMgr := deposit.NewManager(cfg.AutoDeposit)
+		for _, p := range activePlans {
+			if err := depositMgr.CheckChain(p.SourceChain); err != nil {
+				color.Red("\n⚠ WARNING: Plan '%s' cannot auto-deposit: %v", p.Name, err)
+			}
+		}
+	}
+
+	// Create API client
+	apiClient := newAPIClient(cfg)
+
+	// Daily limits and quote deadlines depend on the local clock
+	if skew, err := apiClient.ClockSkew(); err == nil {
+		if warning := client.ClockSkewWarning(skew); warning != "" {
+			color.Red("\n⚠ WARNING: %s", warning)
+		}
 	}
 
 	fmt.Println(strings.Repeat("=", 70))
 	color.Green("\nStarting executor...")
 	color.Cyan("• Monitoring prices every 30 seconds")
 	color.Cyan("• Checking for plan changes every 60 seconds")
+	if daemonMetricsAddr != "" {
+		color.Cyan("• Serving /healthz and /metrics on %s", daemonMetricsAddr)
+	}
 	color.Magenta("• You can create/start/stop plans in another terminal")
 	color.Yellow("• Press Ctrl+C to stop gracefully\n")
 	fmt.Println(strings.Repeat("=", 70) + "\n")
 
-	// Create API client
-	apiClient := client.NewOneClickClient(cfg.JWTToken)
-
 	// Create executor
-	executor := plan.NewExecutor(manager, apiClient, cfg)
+	executor, err := newExecutor(manager, apiClient, cfg)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Start the metrics server before trading so a bad address fails fast
+	var metricsServer *http.Server
+	if daemonMetricsAddr != "" {
+		if metricsServer, err = startMetricsServer(daemonMetricsAddr, executor); err != nil {
+			printError(fmt.Errorf("failed to serve metrics on %s: %w", daemonMetricsAddr, err))
+			os.Exit(1)
+		}
+	}
 
 	// Start executor
 	if err := executor.Start(); err != nil {
@@ -1044,6 +2544,7 @@ func runPlanDaemon(cmd *cobra.Command, args []string) {
 
 	// Stop executor
 	executor.Stop()
+	stopMetricsServer(metricsServer)
 
 	// Save final state
 	fmt.Println("Saving plan states...")
@@ -1053,3 +2554,74 @@ func runPlanDaemon(cmd *cobra.Command, args []string) {
 	color.Cyan("  near-swap plan daemon\n")
 	fmt.Println(strings.Repeat("=", 70) + "\n")
 }
+
+// runJSONDaemon runs the daemon like runPlanDaemon, reporting startup and shutdown as JSON log
+// records instead of banners
+func runJSONDaemon(cfg *config.Config, manager *plan.Manager, activePlans []*plan.TradingPlan) {
+	logger, err := logging.New(os.Stdout, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+	log := logging.Component(logger, "daemon")
+
+	if len(activePlans) == 0 {
+		log.Warn("No active plans found", "event", "daemon_idle")
+		return
+	}
+
+	names := make([]string, 0, len(activePlans))
+	for _, p := range activePlans {
+		names = append(names, p.Name)
+	}
+	log.Info("Loading active plans",
+		"event", "daemon_starting", "plans", names, "observer_mode", cfg.ObserverMode)
+
+	if !cfg.ObserverMode && !cfg.AutoDeposit.Enabled {
+		log.Warn("Auto-deposit is not enabled; plans cannot execute trades automatically")
+	} else if !cfg.ObserverMode {
+		depositMgr := deposit.NewManager(cfg.AutoDeposit)
+		for _, p := range activePlans {
+			if err := depositMgr.CheckChain(p.SourceChain); err != nil {
+				log.Warn("Plan cannot auto-deposit", "plan", p.Name, "error", err)
+			}
+		}
+	}
+
+	apiClient := newAPIClient(cfg)
+	if skew, err := apiClient.ClockSkew(); err == nil {
+		if warning := client.ClockSkewWarning(skew); warning != "" {
+			log.Warn("Local clock is skewed", "clock_skew", skew.String(), "detail", warning)
+		}
+	}
+
+	executor, err := newExecutor(manager, apiClient, cfg)
+	if err != nil {
+		log.Error("Failed to create executor", "error", err)
+		os.Exit(1)
+	}
+	var metricsServer *http.Server
+	if daemonMetricsAddr != "" {
+		if metricsServer, err = startMetricsServer(daemonMetricsAddr, executor); err != nil {
+			log.Error("Failed to serve metrics",
+				"metrics_addr", daemonMetricsAddr, "error", err)
+			os.Exit(1)
+		}
+		log.Info("Serving /healthz and /metrics", "metrics_addr", daemonMetricsAddr)
+	}
+	if err := executor.Start(); err != nil {
+		log.Error("Failed to start executor", "error", err)
+		os.Exit(1)
+	}
+	log.Info("Executor started", "event", "daemon_started")
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+
+	log.Info("Received shutdown signal, stopping executor", "event", "daemon_stopping")
+	executor.Stop()
+	stopMetricsServer(metricsServer)
+
+	log.Info("Daemon stopped", "event", "daemon_stopped")
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// qrText renders content as a QR code of Unicode half blocks, two module rows per line, so it
+// stays plain text (works over SSH). Light modules are drawn as blocks, which suits the usual
+// dark terminal background; the quiet zone is included.
+func qrText(content string) (string, error) {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	bitmap := code.Bitmap() // true is a dark module
+
+	var sb strings.Builder
+	for y := 0; y < len(bitmap); y += 2 {
+		for x := range bitmap[y] {
+			topLight := !bitmap[y][x]
+			bottomLight := y+1 >= len(bitmap) || !bitmap[y+1][x]
+			switch {
+			case topLight && bottomLight:
+				sb.WriteString("█")
+			case topLight:
+				sb.WriteString("▀")
+			case bottomLight:
+				sb.WriteString("▄")
+			default:
+				sb.WriteString(" ")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// encodeQR renders the QR codes printed by printQR
+var encodeQR = qrText
+
+// printQR prints a labelled QR code for content, or a warning if it can't be encoded
+func printQR(label, content string) {
+	qr, err := encodeQR(content)
+	if err != nil {
+		fmt.Printf("\n%s: %v\n", label, err)
+		return
+	}
+	fmt.Printf("\n%s:\n\n%s", label, qr)
+}
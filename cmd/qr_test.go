@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"near-swap/pkg/types"
+
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
+)
+
+func TestQRText(t *testing.T) {
+	qr, err := qrText("bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(qr, "\n"), "\n")
+	width := len([]rune(lines[0]))
+	// Two module rows per line, quiet zone included
+	if want := (width + 1) / 2; len(lines) != want {
+		t.Errorf("%d lines for a %d module wide code, want %d", len(lines), width, want)
+	}
+	for i, line := range lines {
+		if n := len([]rune(line)); n != width {
+			t.Fatalf("line %d is %d wide, want %d", i, n, width)
+		}
+	}
+}
+
+func TestDepositInstructionsQR(t *testing.T) {
+	var encoded []string
+	encodeQR = func(content string) (string, error) {
+		encoded = append(encoded, content)
+		return "", nil
+	}
+	t.Cleanup(func() { encodeQR, showQR = qrText, false })
+
+	quote := oneclick.NewQuote("100000", "0.001", "60", "100000", "60000000", "60", "60", "59000000", 10)
+	quote.SetDepositAddress("bc1qdeposit")
+	swapReq := &types.SwapRequest{SourceToken: "BTC", DestToken: "USDC"}
+
+	tests := []struct {
+		name string
+		qr   bool
+		memo string
+		want []string
+	}{
+		{name: "without --qr", memo: "42"},
+		{name: "deposit address", qr: true, want: []string{"bc1qdeposit"}},
+		{name: "deposit address and memo", qr: true, memo: "42", want: []string{"bc1qdeposit", "42"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, showQR = nil, tt.qr
+			q := *quote
+			if tt.memo != "" {
+				q.SetDepositMemo(tt.memo)
+			}
+
+			displayDepositInstructions(&q, swapReq)
+			if strings.Join(encoded, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("QR codes encoded for %q, want %q", encoded, tt.want)
+			}
+		})
+	}
+}
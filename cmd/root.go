@@ -2,8 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/spf13/cobra"
+
+	"near-swap/config"
+	"near-swap/pkg/client"
+	"near-swap/pkg/plan"
 )
 
 var rootCmd = &cobra.Command{
@@ -32,6 +37,60 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("json", "j", false, "Output in JSON format")
 }
 
+// apiLimiter paces every 1Click client the process creates, so they share one request budget
+var (
+	apiLimiterOnce sync.Once
+	apiLimiter     *client.RateLimiter
+)
+
+// sharedRateLimiter returns the process-wide API rate limiter configured by api_rate_limit
+func sharedRateLimiter(cfg *config.Config) *client.RateLimiter {
+	apiLimiterOnce.Do(func() {
+		apiLimiter = client.NewRateLimiter(cfg.APIRateLimit)
+	})
+	return apiLimiter
+}
+
+// newAPIClient creates a 1Click client with the quote defaults from config
+func newAPIClient(cfg *config.Config) *client.OneClickClient {
+	return newAPIClientWithToken(cfg, cfg.JWTToken)
+}
+
+// newAPIClientWithToken creates a 1Click client with the configured settings for a specific JWT
+func newAPIClientWithToken(cfg *config.Config, jwtToken string) *client.OneClickClient {
+	apiClient := client.NewOneClickClient(jwtToken, client.WithRetry(cfg.MaxRetries), client.WithTokenCacheTTL(cfg.TokenCacheTTL),
+		client.WithRateLimit(sharedRateLimiter(cfg)))
+	apiClient.SetQuoteDefaults(cfg.DefaultSlippage, cfg.DefaultDeadline)
+	for _, route := range cfg.UnsupportedRoutes {
+		if source, dest, err := client.ParseRoute(route); err == nil {
+			apiClient.MarkRouteUnsupported(source, dest)
+		}
+	}
+	return apiClient
+}
+
+// newExecutor creates a plan executor whose plan-specific API clients share the configured settings
+func newExecutor(manager *plan.Manager, apiClient *client.OneClickClient, cfg *config.Config) (*plan.Executor, error) {
+	executor, err := plan.NewExecutor(manager, apiClient, cfg)
+	if err != nil {
+		return nil, err
+	}
+	executor.SetClientFactory(func(jwtToken string) *client.OneClickClient {
+		return newAPIClientWithToken(cfg, jwtToken)
+	})
+	return executor, nil
+}
+
+// newPlanManager opens plan storage with the plan count and size guardrails from config
+func newPlanManager(cfg *config.Config) (*plan.Manager, error) {
+	manager, err := plan.NewManagerWithBackend(cfg.PlanStorageBackend, cfg.PlanStoragePath)
+	if err != nil {
+		return nil, err
+	}
+	manager.SetLimits(cfg.MaxPlans, cfg.PlanStorageWarnMB)
+	return manager, nil
+}
+
 func printError(err error) {
 	fmt.Printf("\nError: %v\n\n", err)
 }
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"near-swap/config"
+	"near-swap/pkg/api"
+	"near-swap/pkg/plan"
+)
+
+var (
+	serveAddr       string
+	serveWithDaemon bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run an HTTP API for managing trading plans",
+	Long: `Start a small REST API so other applications can manage trading plans.
+
+All requests require an "Authorization: Bearer <token>" header. The token is
+read from the environment variable named by api_server.token_env in your
+config (default: NEAR_SWAP_API_TOKEN).
+
+Endpoints:
+  GET    /api/health
+  GET    /api/plans[?status=active]
+  POST   /api/plans
+  GET    /api/plans/{name}
+  DELETE /api/plans/{name}
+  POST   /api/plans/{name}/start[?force=true]
+  POST   /api/plans/{name}/stop
+  GET    /api/plans/{name}/history
+
+Examples:
+  export NEAR_SWAP_API_TOKEN=$(openssl rand -hex 32)
+  near-swap serve
+  near-swap serve --addr 0.0.0.0:9090 --daemon`,
+	Run: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "", "Listen address (overrides api_server.addr)")
+	serveCmd.Flags().BoolVar(&serveWithDaemon, "daemon", false, "Also run the plan executor in this process")
+}
+
+func runServe(cmd *cobra.Command, args []string) {
+	// Load config
+	cfg, err := config.Load()
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	addr := cfg.APIServer.Addr
+	if serveAddr != "" {
+		addr = serveAddr
+	}
+
+	if cfg.APIServer.Token == "" {
+		printError(fmt.Errorf("API token not set. Export %s with a secret bearer token", cfg.APIServer.TokenEnv))
+		os.Exit(1)
+	}
+
+	// Create plan manager
+	manager, err := newPlanManager(cfg)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	apiServer, err := api.NewServer(manager, cfg.APIServer.Token, plan.CreateChecks{
+		DefaultRecipient: cfg.DefaultRecipient,
+		DefaultRefundTo:  cfg.DefaultRefundTo,
+		MarketClient:     planMarketClient(cfg),
+	})
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Optionally run the executor alongside the API
+	var executor *plan.Executor
+	if serveWithDaemon {
+		apiClient := newAPIClient(cfg)
+		if executor, err = newExecutor(manager, apiClient, cfg); err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+		if err := executor.Start(); err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+	}
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           apiServer,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 70))
+	color.Green("                    NEAR-SWAP API SERVER")
+	fmt.Println(strings.Repeat("=", 70))
+	fmt.Printf("\n  Listening on:  %s\n", color.CyanString("http://%s", addr))
+	if serveWithDaemon {
+		fmt.Printf("  Executor:      %s\n", color.GreenString("running"))
+	}
+	color.Yellow("\n  Press Ctrl+C to stop\n")
+	fmt.Println(strings.Repeat("=", 70) + "\n")
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- httpServer.ListenAndServe()
+	}()
+
+	// Setup signal handling for graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case err := <-errChan:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			printError(err)
+			os.Exit(1)
+		}
+	case <-sigChan:
+		color.Yellow("\nReceived shutdown signal. Stopping API server...")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = httpServer.Shutdown(ctx)
+
+	if executor != nil {
+		executor.Stop()
+	}
+
+	color.Green("\n✓ API server stopped.\n")
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -14,6 +13,7 @@ import (
 
 	"near-swap/config"
 	"near-swap/pkg/client"
+	"near-swap/pkg/output"
 )
 
 var (
@@ -29,7 +29,8 @@ var statusCmd = &cobra.Command{
 Examples:
   near-swap status 0x1234...abcd
   near-swap status 0x1234...abcd --watch
-  near-swap status 0x1234...abcd --watch --interval 10`,
+  near-swap status 0x1234...abcd --watch --interval 10
+  near-swap status 0x1234...abcd --qr`,
 	Args: cobra.ExactArgs(1),
 	Run:  runStatus,
 }
@@ -39,11 +40,12 @@ func init() {
 
 	statusCmd.Flags().BoolVarP(&watchStatus, "watch", "w", false, "Watch status updates continuously")
 	statusCmd.Flags().IntVar(&watchInterval, "interval", 5, "Polling interval in seconds (when watching)")
+	statusCmd.Flags().BoolVar(&showQR, "qr", false, "Show the deposit address as a QR code")
+	addFormatFlag(statusCmd, false)
 }
 
 func runStatus(cmd *cobra.Command, args []string) {
 	depositAddress := args[0]
-	jsonOutput, _ := cmd.Flags().GetBool("json")
 
 	// Load configuration
 	cfg, err := config.Load()
@@ -52,25 +54,31 @@ func runStatus(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	format := mustOutputFormat(cmd, cfg, false)
+
 	// Create client
-	apiClient := client.NewOneClickClient(cfg.JWTToken)
+	apiClient := newAPIClient(cfg)
+
+	if showQR && format == output.Table {
+		printQR("Deposit address", depositAddress)
+	}
 
 	if watchStatus {
-		watchSwapStatus(apiClient, depositAddress, jsonOutput)
+		watchSwapStatus(apiClient, depositAddress, format)
 	} else {
-		checkSwapStatus(apiClient, depositAddress, jsonOutput)
+		checkSwapStatus(apiClient, depositAddress, format)
 	}
 }
 
-func checkSwapStatus(apiClient *client.OneClickClient, depositAddress string, jsonOutput bool) {
+func checkSwapStatus(apiClient *client.OneClickClient, depositAddress string, format string) {
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-	if !jsonOutput {
+	if format == output.Table {
 		s.Suffix = " Checking swap status..."
 		s.Start()
 	}
 
 	status, err := apiClient.GetSwapStatus(depositAddress)
-	if !jsonOutput {
+	if format == output.Table {
 		s.Stop()
 	}
 
@@ -79,19 +87,22 @@ func checkSwapStatus(apiClient *client.OneClickClient, depositAddress string, js
 		os.Exit(1)
 	}
 
-	if jsonOutput {
-		jsonData, _ := json.MarshalIndent(status, "", "  ")
-		fmt.Println(string(jsonData))
+	if format != output.Table {
+		printOutput(format, status)
 	} else {
 		displayStatus(status, depositAddress)
 	}
 }
 
-func watchSwapStatus(apiClient *client.OneClickClient, depositAddress string, jsonOutput bool) {
-	if jsonOutput {
+func watchSwapStatus(apiClient *client.OneClickClient, depositAddress string, format string) {
+	if format == output.JSON {
 		fmt.Println(`{"error": "watch mode not supported with JSON output"}`)
 		os.Exit(1)
 	}
+	if format != output.Table {
+		printError(fmt.Errorf("watch mode not supported with %s output", format))
+		os.Exit(1)
+	}
 
 	fmt.Printf("\nWatching swap status (Deposit Address: %s)\n", color.CyanString(depositAddress))
 	fmt.Printf("Checking every %d seconds. Press Ctrl+C to stop.\n\n", watchInterval)
@@ -3,6 +3,7 @@ package cmd
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -17,6 +18,7 @@ import (
 	"near-swap/pkg/client"
 	"near-swap/pkg/deposit"
 	"near-swap/pkg/parser"
+	"near-swap/pkg/swap"
 	"near-swap/pkg/types"
 )
 
@@ -27,30 +29,56 @@ var (
 	refundAddr    string
 	noConfirm     bool
 	autoDeposit   bool
+	viaToken      string
+	viaChain      string
+	viaRecipient  string
+	viaLegTimeout time.Duration
+
+	addressOverride bool          // Proceed even if recipient and refund addresses look swapped
+	showQR          bool          // Print deposit details as terminal QR codes
+	forceDeposit    bool          // Auto-deposit even if a deposit to the same address was already sent
+	dryRun          bool          // Preview the quote without generating a deposit address
+	swapSlippage    int           // Quote slippage tolerance in basis points (0 uses default_slippage)
+	swapDeadline    time.Duration // How long the quote accepts deposits (0 uses default_deadline)
+
+	swapWait         bool // Block until the swap reaches a terminal status
+	swapWaitInterval int  // Seconds between status checks with --wait
 )
 
 var swapCmd = &cobra.Command{
-	Use:   "swap <amount> <source-token> to <dest-token>",
+	Use:   "swap <amount> <source-token>[@chain] to <dest-token>[@chain]",
 	Short: "Perform a cross-chain token swap",
 	Long: `Swap tokens across different blockchains using NEAR Intents 1Click API.
 
 IMPORTANT:
-  - You MUST specify --recipient (where you'll receive tokens)
-  - You SHOULD specify --refund-to for cross-chain swaps (where refunds go if swap fails)
+  - You MUST specify --recipient (where you'll receive tokens), or set default_recipient in your config
+  - You SHOULD specify --refund-to for cross-chain swaps (where refunds go if swap fails), or set default_refund_to
   - Both addresses must be valid for their respective blockchains
 
 Examples:
   # Cross-chain swap
   near-swap swap 1 SOL to USDC --from-chain sol --to-chain near --recipient your.near --refund-to <solana-addr>
 
+  # Chains given inline instead of with --from-chain/--to-chain (the flags win if both are set)
+  near-swap swap 1 USDC@sol to ETH@arb --recipient 0x123... --refund-to <solana-addr>
+
   # Same-chain swap
   near-swap swap 0.5 ETH to USDC --from-chain eth --to-chain eth --recipient 0x123... --refund-to 0x123...
 
   # With auto-deposit (Bitcoin example)
   near-swap swap 0.01 BTC to USDC --from-chain btc --to-chain near --recipient your.near --refund-to <btc-addr> --auto-deposit
 
+  # Auto-deposit and block until the swap completes (exits non-zero if it fails)
+  near-swap swap 0.01 BTC to USDC --from-chain btc --to-chain near --recipient your.near --auto-deposit --yes --wait
+
+  # Preview pricing without reserving a deposit address
+  near-swap swap 1 SOL to USDC --from-chain sol --to-chain near --recipient your.near --dry-run
+
   # Skip all confirmations
-  near-swap swap 1 SOL to USDC --from-chain sol --to-chain near --recipient your.near --refund-to <sol-addr> --yes`,
+  near-swap swap 1 SOL to USDC --from-chain sol --to-chain near --recipient your.near --refund-to <sol-addr> --yes
+
+  # Two legs through USDC on Ethereum when there is no direct route
+  near-swap swap 1 ZEC to XMR --from-chain zec --to-chain xmr --via USDC --via-chain eth --via-recipient 0xYourWallet --recipient <xmr-addr> --refund-to <zec-addr>`,
 	Args: cobra.MinimumNArgs(1),
 	Run:  runSwap,
 }
@@ -60,10 +88,22 @@ func init() {
 
 	swapCmd.Flags().StringVar(&fromChain, "from-chain", "", "Source blockchain (optional)")
 	swapCmd.Flags().StringVar(&toChain, "to-chain", "", "Destination blockchain (optional)")
-	swapCmd.Flags().StringVar(&recipientAddr, "recipient", "", "Recipient address (REQUIRED - where you'll receive tokens)")
-	swapCmd.Flags().StringVar(&refundAddr, "refund-to", "", "Refund address on source chain (optional - where refunds go if swap fails)")
+	swapCmd.Flags().StringVar(&recipientAddr, "recipient", "", "Recipient address (REQUIRED unless default_recipient is configured - where you'll receive tokens)")
+	swapCmd.Flags().StringVar(&refundAddr, "refund-to", "", "Refund address on source chain (optional, defaults to default_refund_to - where refunds go if swap fails)")
 	swapCmd.Flags().BoolVarP(&noConfirm, "yes", "y", false, "Skip confirmation prompt")
 	swapCmd.Flags().BoolVar(&autoDeposit, "auto-deposit", false, "Automatically send deposit (requires configuration)")
+	swapCmd.Flags().BoolVar(&forceDeposit, "force", false, "Auto-deposit even if the audit log shows a recent deposit to the same address")
+	swapCmd.Flags().IntVar(&swapSlippage, "slippage", 0, "Quote slippage tolerance in basis points, 1-5000 (defaults to default_slippage)")
+	swapCmd.Flags().DurationVar(&swapDeadline, "deadline", 0, "How long the quote accepts deposits, 1m-168h (defaults to default_deadline)")
+	swapCmd.Flags().BoolVar(&swapWait, "wait", false, "Wait until the swap completes, fails or is refunded, and exit non-zero unless it succeeded")
+	swapCmd.Flags().IntVar(&swapWaitInterval, "interval", 10, "Polling interval in seconds (with --wait)")
+	swapCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the quote without generating a deposit address")
+	swapCmd.Flags().BoolVar(&showQR, "qr", false, "Show the deposit address (and memo) as a QR code for manual deposits")
+	swapCmd.Flags().BoolVar(&addressOverride, "i-know-what-im-doing", false, "Swap even if the recipient and refund addresses look swapped")
+	swapCmd.Flags().StringVar(&viaToken, "via", "", "Swap through this intermediate token in two legs (optional)")
+	swapCmd.Flags().StringVar(&viaChain, "via-chain", "", "Blockchain of the intermediate token (required with --via)")
+	swapCmd.Flags().StringVar(&viaRecipient, "via-recipient", "", "Your auto-deposit wallet address that receives the intermediate token (required with --via)")
+	swapCmd.Flags().DurationVar(&viaLegTimeout, "leg-timeout", swap.DefaultLegTimeout, "How long to wait for each leg of a --via swap to settle")
 }
 
 func runSwap(cmd *cobra.Command, args []string) {
@@ -75,7 +115,7 @@ func runSwap(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	// Set chain, recipient, and refund address if provided via flags
+	// Set chain, recipient, and refund address if provided via flags; flags override inline @chain
 	if fromChain != "" {
 		swapReq.SourceChain = fromChain
 	}
@@ -88,6 +128,21 @@ func runSwap(cmd *cobra.Command, args []string) {
 	if refundAddr != "" {
 		swapReq.RefundAddr = refundAddr
 	}
+	swapReq.Dry = dryRun
+	if cmd.Flags().Changed("slippage") {
+		if err := client.ValidateSlippage(swapSlippage); err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+		swapReq.SlippageBps = swapSlippage
+	}
+	if cmd.Flags().Changed("deadline") {
+		if err := client.ValidateQuoteDeadline(swapDeadline); err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+		swapReq.Deadline = swapDeadline
+	}
 
 	verbose, _ := cmd.Flags().GetBool("verbose")
 	jsonOutput, _ := cmd.Flags().GetBool("json")
@@ -99,8 +154,52 @@ func runSwap(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	// Fall back to the configured default addresses for any not given as flags
+	swapReq.RecipientAddr, swapReq.RefundAddr, err = applyAddressDefaults(cfg, swapReq.RecipientAddr, swapReq.RefundAddr)
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	if err := checkAddressRoles(swapReq.SourceChain, swapReq.DestChain, swapReq.RecipientAddr, swapReq.RefundAddr, jsonOutput); err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	// Tag the quote with the configured referral and app fee
+	swapReq.Referral = cfg.Referral
+	swapReq.AppFeeRecipient = cfg.AppFee.Recipient
+	swapReq.AppFeeBps = cfg.AppFee.FeeBps
+
+	// A dry run never has an address to deposit to
+	if dryRun && viaToken != "" {
+		printError(fmt.Errorf("--dry-run cannot be combined with --via"))
+		os.Exit(1)
+	}
+	if dryRun && autoDeposit {
+		printError(fmt.Errorf("--dry-run cannot be combined with --auto-deposit"))
+		os.Exit(1)
+	}
+	if swapWait && dryRun {
+		printError(fmt.Errorf("--wait cannot be combined with --dry-run"))
+		os.Exit(1)
+	}
+	if swapWait && viaToken != "" {
+		printError(fmt.Errorf("--wait cannot be combined with --via, which already waits for each leg"))
+		os.Exit(1)
+	}
+	if swapWait && swapWaitInterval < 1 {
+		printError(fmt.Errorf("--interval must be at least 1 second, got %d", swapWaitInterval))
+		os.Exit(1)
+	}
+
 	// Create client
-	apiClient := client.NewOneClickClient(cfg.JWTToken)
+	apiClient := newAPIClient(cfg)
+
+	if viaToken != "" {
+		runViaSwap(cfg, apiClient, swapReq, verbose, jsonOutput)
+		return
+	}
 
 	// Get quote with spinner
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
@@ -139,6 +238,11 @@ func runSwap(cmd *cobra.Command, args []string) {
 	// Get the quote details
 	quoteDetails := quote.GetQuote()
 
+	if dryRun {
+		displayDryRun(&quoteDetails, swapReq, jsonOutput)
+		return
+	}
+
 	// Display quote
 	if jsonOutput {
 		output := map[string]interface{}{
@@ -171,12 +275,25 @@ func runSwap(cmd *cobra.Command, args []string) {
 
 	// Handle auto-deposit if enabled
 	if autoDeposit || cfg.AutoDeposit.Enabled {
-		if err := handleAutoDeposit(cfg, swapReq, &quoteDetails, verbose, noConfirm); err != nil {
+		if _, err := handleAutoDeposit(cfg, apiClient, swapReq, &quoteDetails, verbose, noConfirm); err != nil {
 			color.Red("\nAuto-deposit failed: %v", err)
-			color.Yellow("Please send the deposit manually to: %s\n", quoteDetails.GetDepositAddress())
+			var selfDeposit *deposit.SelfDepositError
+			var duplicate *deposit.DuplicateDepositError
+			if !errors.As(err, &selfDeposit) && !errors.As(err, &duplicate) {
+				color.Yellow("Please send the deposit manually to: %s\n", quoteDetails.GetDepositAddress())
+			}
+			// Waiting would block on a deposit that was never sent
+			if swapWait {
+				os.Exit(1)
+			}
 		}
 	}
 
+	if swapWait {
+		runSwapWait(apiClient, quoteDetails.GetDepositAddress(), jsonOutput)
+		return
+	}
+
 	// Monitor swap status (optional, in background)
 	if !jsonOutput {
 		fmt.Println("\nYou can monitor the swap status using:")
@@ -184,26 +301,60 @@ func runSwap(cmd *cobra.Command, args []string) {
 	}
 }
 
-func handleAutoDeposit(cfg *config.Config, swapReq *types.SwapRequest, quoteDetails *oneclick.Quote, verbose bool, skipConfirm bool) error {
+// handleAutoDeposit sends the swap's deposit from the configured wallet and returns what was sent
+func handleAutoDeposit(cfg *config.Config, apiClient *client.OneClickClient, swapReq *types.SwapRequest, quoteDetails *oneclick.Quote, verbose bool, skipConfirm bool) (*deposit.DepositResult, error) {
 	depositMgr := deposit.NewManager(cfg.AutoDeposit)
 
 	// Check if auto-deposit is supported for the source chain
-	if !depositMgr.IsEnabledForChain(swapReq.SourceChain) {
-		return fmt.Errorf("auto-deposit not enabled for chain: %s", swapReq.SourceChain)
+	if err := depositMgr.CheckChain(swapReq.SourceChain); err != nil {
+		return nil, err
 	}
 
 	depositAddress := quoteDetails.GetDepositAddress()
 	amount := swapReq.Amount
 
+	// Never send funds back to one of our own addresses
+	if err := deposit.CheckDepositAddress(swapReq.SourceChain, swapReq.DestChain, depositAddress, swapReq.RecipientAddr, swapReq.RefundAddr); err != nil {
+		return nil, err
+	}
+
+	// Refuse to fund the same quote twice, e.g. when the same swap is re-run
+	if !forceDeposit {
+		swapPending := func(address string) (bool, error) {
+			status, err := apiClient.GetSwapStatus(address)
+			if err != nil {
+				return false, err
+			}
+			return !isTerminalSwapStatus(status.GetStatus()), nil
+		}
+		if err := depositMgr.CheckDuplicateDeposit(depositAddress, swapPending); err != nil {
+			return nil, err
+		}
+	}
+
+	// Where the chain supports it, build and sign the transaction first so the confirmation
+	// shows exactly what will be broadcast
+	prepared, err := depositMgr.PrepareDeposit(swapReq.SourceChain, depositAddress, amount)
+	if err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		return nil, err
+	}
+	if prepared != nil {
+		defer prepared.Close()
+	}
+
 	color.Yellow("\n🔄 Initiating auto-deposit...\n")
-	fmt.Printf("  Chain:   %s\n", swapReq.SourceChain)
-	fmt.Printf("  Amount:  %s %s\n", amount, swapReq.SourceToken)
-	fmt.Printf("  To:      %s\n", depositAddress)
+	if prepared != nil {
+		displayTxPreview(prepared.Preview, swapReq.SourceToken)
+	} else {
+		fmt.Printf("  Chain:   %s\n", swapReq.SourceChain)
+		fmt.Printf("  Amount:  %s %s\n", amount, swapReq.SourceToken)
+		fmt.Printf("  To:      %s\n", depositAddress)
+	}
 
 	// Confirm auto-deposit (skip if --yes flag is set or auto_confirm is enabled in config)
 	if !skipConfirm && !cfg.AutoConfirm {
 		if !confirmAutoDeposit() {
-			return fmt.Errorf("auto-deposit cancelled by user")
+			return nil, fmt.Errorf("auto-deposit cancelled by user")
 		}
 	}
 
@@ -212,27 +363,110 @@ func handleAutoDeposit(cfg *config.Config, swapReq *types.SwapRequest, quoteDeta
 	s.Suffix = " Sending deposit..."
 	s.Start()
 
-	txid, err := depositMgr.SendDeposit(swapReq.SourceChain, depositAddress, amount)
+	var txid string
+	if prepared != nil {
+		txid, err = prepared.Broadcast()
+	} else {
+		txid, err = depositMgr.SendDeposit(swapReq.SourceChain, depositAddress, amount)
+	}
 	s.Stop()
 
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	result := deposit.NewDepositResult(swapReq.SourceChain, swapReq.SourceToken, amount, depositAddress, txid)
+	if prepared != nil && prepared.Preview.Fee != "" {
+		result.Fee = prepared.Preview.Fee + " " + prepared.Preview.FeeUnit
 	}
 
 	color.Green("\n✓ Deposit sent successfully!")
-	fmt.Printf("  Transaction ID: %s\n", color.CyanString(txid))
+	fmt.Printf("  Transaction ID: %s\n", color.CyanString(result.TxID))
+
+	if err := depositMgr.RecordAudit(result.AuditRecord("swap")); err != nil {
+		color.Yellow("Warning: %v\n", err)
+	}
 
 	if verbose {
 		fmt.Printf("\nDeposit transaction details:\n")
-		fmt.Printf("  Chain:      %s\n", swapReq.SourceChain)
-		fmt.Printf("  Amount:     %s %s\n", amount, swapReq.SourceToken)
-		fmt.Printf("  To:         %s\n", depositAddress)
-		fmt.Printf("  Tx Hash:    %s\n", txid)
+		fmt.Printf("  Chain:      %s\n", result.Chain)
+		fmt.Printf("  Amount:     %s %s\n", result.Amount, result.Token)
+		fmt.Printf("  To:         %s\n", result.ToAddress)
+		fmt.Printf("  Tx Hash:    %s\n", result.TxID)
+		if result.Fee != "" {
+			fmt.Printf("  Fee:        %s\n", result.Fee)
+		}
 	}
 
+	return result, nil
+}
+
+// applyAddressDefaults fills in an empty recipient or refund address from the config's
+// default_recipient and default_refund_to. A recipient is required one way or the other;
+// an empty refund address is left for the caller to default.
+func applyAddressDefaults(cfg *config.Config, recipient, refund string) (string, string, error) {
+	if recipient == "" {
+		recipient = cfg.DefaultRecipient
+	}
+	if refund == "" {
+		refund = cfg.DefaultRefundTo
+	}
+	if recipient == "" {
+		return "", "", fmt.Errorf("recipient address is required: pass --recipient or set default_recipient in your config")
+	}
+	return recipient, refund, nil
+}
+
+// checkAddressRoles refuses recipient and refund addresses that look swapped, unless
+// --i-know-what-im-doing is set, and warns when one doesn't look like an address of its chain
+func checkAddressRoles(sourceChain, destChain, recipient, refund string, jsonOutput bool) error {
+	var roleErr *deposit.AddressRoleError
+	if err := deposit.CheckAddressRoles(sourceChain, destChain, recipient, refund); !errors.As(err, &roleErr) {
+		return nil
+	}
+
+	if roleErr.Swapped && !addressOverride {
+		return fmt.Errorf("%w. Pass --i-know-what-im-doing to continue anyway", roleErr)
+	}
+	if !jsonOutput {
+		color.Yellow("\nWARNING: %v\n", roleErr)
+	}
 	return nil
 }
 
+// displayTxPreview prints a signed deposit transaction decoded before it is broadcast
+func displayTxPreview(preview *deposit.TxPreview, symbol string) {
+	fmt.Printf("  Chain:      %s\n", preview.Chain)
+	fmt.Printf("  From:       %s\n", preview.From)
+	fmt.Printf("  To:         %s\n", preview.To)
+	if preview.Token != "" {
+		fmt.Printf("  Token:      %s\n", preview.Token)
+	}
+	fmt.Printf("  Amount:     %s %s (%s base units, %d decimals)\n", preview.Amount(), symbol, preview.AmountUnits, preview.Decimals)
+	if preview.Fee != "" {
+		fmt.Printf("  Max Fee:    %s %s\n", preview.Fee, preview.FeeUnit)
+	}
+	if preview.Nonce != nil {
+		fmt.Printf("  Nonce:      %d\n", *preview.Nonce)
+	}
+	if preview.Blockhash != "" {
+		fmt.Printf("  Blockhash:  %s\n", preview.Blockhash)
+	}
+	fmt.Printf("  Tx ID:      %s\n", preview.TxID)
+	for _, note := range preview.Notes {
+		fmt.Printf("  Note:       %s\n", note)
+	}
+	color.Yellow("  The transaction is signed; confirm promptly or its %s may go stale.", staleField(preview))
+}
+
+// staleField names what ages out of a signed transaction while it waits for confirmation
+func staleField(preview *deposit.TxPreview) string {
+	if preview.Blockhash != "" {
+		return "blockhash"
+	}
+	return "nonce and fees"
+}
+
 func confirmAutoDeposit() bool {
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Print("\nProceed with auto-deposit? (y/N): ")
@@ -266,6 +500,41 @@ func displayQuote(quote *oneclick.Quote, swapReq *types.SwapRequest) {
 	fmt.Println("\n" + strings.Repeat("=", 60) + "\n")
 }
 
+// displayDryRun prints a dry-run quote, which has no deposit address or instructions
+func displayDryRun(quote *oneclick.Quote, swapReq *types.SwapRequest, jsonOutput bool) {
+	if jsonOutput {
+		output := map[string]interface{}{
+			"source_amount":     swapReq.Amount,
+			"source_token":      swapReq.SourceToken,
+			"dest_amount":       quote.GetAmountOutFormatted(),
+			"dest_token":        swapReq.DestToken,
+			"time_estimate_sec": quote.GetTimeEstimate(),
+			"status":            "dry_run",
+		}
+		jsonData, _ := json.MarshalIndent(output, "", "  ")
+		fmt.Println(string(jsonData))
+		return
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	color.Green("                  SWAP QUOTE (DRY RUN)")
+	fmt.Println(strings.Repeat("=", 60))
+
+	fmt.Printf("\n  From:              %s %s\n", quote.GetAmountInFormatted(), color.YellowString(swapReq.SourceToken))
+	fmt.Printf("  To:                ~%s %s\n", quote.GetAmountOutFormatted(), color.YellowString(swapReq.DestToken))
+	fmt.Printf("  Estimated Time:    %.0f seconds\n", quote.GetTimeEstimate())
+
+	if swapReq.SourceChain != "" {
+		fmt.Printf("  Source Chain:      %s\n", swapReq.SourceChain)
+	}
+	if swapReq.DestChain != "" {
+		fmt.Printf("  Destination Chain: %s\n", swapReq.DestChain)
+	}
+
+	color.Yellow("\n  DRY RUN – no deposit address generated")
+	fmt.Println("\n" + strings.Repeat("=", 60) + "\n")
+}
+
 func displayDepositInstructions(quote *oneclick.Quote, swapReq *types.SwapRequest) {
 	fmt.Println("\n" + strings.Repeat("=", 60))
 	color.Yellow("                 DEPOSIT INSTRUCTIONS")
@@ -277,6 +546,13 @@ func displayDepositInstructions(quote *oneclick.Quote, swapReq *types.SwapReques
 		fmt.Printf("\nMemo (REQUIRED): %s\n", color.MagentaString(quote.GetDepositMemo()))
 	}
 
+	if showQR {
+		printQR("Deposit address", quote.GetDepositAddress())
+		if quote.HasDepositMemo() {
+			printQR("Memo", quote.GetDepositMemo())
+		}
+	}
+
 	fmt.Println("\n" + strings.Repeat("=", 60) + "\n")
 }
 
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"near-swap/config"
+	"near-swap/pkg/deposit"
+	"near-swap/pkg/mockserver"
+	"near-swap/pkg/types"
+
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
+)
+
+func TestApplyAddressDefaults(t *testing.T) {
+	defaults := &config.Config{DefaultRecipient: "me.near", DefaultRefundTo: "bc1qrefund"}
+
+	tests := []struct {
+		name              string
+		cfg               *config.Config
+		recipient, refund string
+		wantRecipient     string
+		wantRefund        string
+		wantErr           bool
+	}{
+		{name: "flags omitted", cfg: defaults, wantRecipient: "me.near", wantRefund: "bc1qrefund"},
+		{name: "flags win", cfg: defaults, recipient: "you.near", refund: "bc1qmine", wantRecipient: "you.near", wantRefund: "bc1qmine"},
+		{name: "only the refund defaulted", cfg: defaults, recipient: "you.near", wantRecipient: "you.near", wantRefund: "bc1qrefund"},
+		{name: "no refund default is left empty", cfg: &config.Config{DefaultRecipient: "me.near"}, wantRecipient: "me.near"},
+		{name: "no recipient anywhere", cfg: &config.Config{DefaultRefundTo: "bc1qrefund"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recipient, refund, err := applyAddressDefaults(tt.cfg, tt.recipient, tt.refund)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error: %v", err, tt.wantErr)
+			}
+			if recipient != tt.wantRecipient || refund != tt.wantRefund {
+				t.Errorf("addresses = %q, %q; want %q, %q", recipient, refund, tt.wantRecipient, tt.wantRefund)
+			}
+		})
+	}
+}
+
+func TestCheckAddressRolesOverride(t *testing.T) {
+	const btcAddr, evmAddr = "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq", "0x742d35Cc6634C0532925a3b844Bc454e4438f44e"
+	t.Cleanup(func() { addressOverride = false })
+
+	addressOverride = false
+	err := checkAddressRoles("btc", "eth", btcAddr, evmAddr, true)
+	if err == nil || !strings.Contains(err.Error(), "--i-know-what-im-doing") {
+		t.Fatalf("swapped addresses: error = %v, want a refusal naming the override", err)
+	}
+
+	addressOverride = true
+	if err := checkAddressRoles("btc", "eth", btcAddr, evmAddr, true); err != nil {
+		t.Errorf("swapped addresses with the override: error = %v, want none", err)
+	}
+}
+
+func TestHandleAutoDepositResult(t *testing.T) {
+	// A bitcoin-cli stand-in with a funded wallet
+	dir := t.TempDir()
+	cli := filepath.Join(dir, "bitcoin-cli")
+	script := `#!/bin/sh
+for arg in "$@"; do
+	case "$arg" in
+	getblockchaininfo) echo '{}'; exit 0 ;;
+	getbalance) echo 10; exit 0 ;;
+	sendtoaddress) echo deadbeef; exit 0 ;;
+	esac
+done
+exit 1
+`
+	if err := os.WriteFile(cli, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	auditLog := filepath.Join(dir, "deposits.log")
+	cfg := &config.Config{AutoDeposit: config.AutoDepositConfig{Enabled: true, AuditLogPath: auditLog,
+		Bitcoin: config.BitcoinConfig{Enabled: true, CLIPath: cli}, Solana: config.SolanaConfig{PrivateKey: "solana-secret-key"}}}
+
+	server := mockserver.New()
+	t.Cleanup(server.Close)
+	quote := oneclick.NewQuote("1000000", "0.01", "600", "1000000", "600000000", "600", "600", "590000000", 10)
+	quote.SetDepositAddress("bc1qdepositaddressxxxxxxxxxxxxxxxxxxxxxxx")
+	swapReq := &types.SwapRequest{Amount: "0.01", SourceToken: "BTC", DestToken: "USDC", SourceChain: "btc",
+		DestChain: "near", RecipientAddr: "me.near", RefundAddr: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"}
+
+	start := time.Now().UTC()
+	result, err := handleAutoDeposit(cfg, server.Client("t"), swapReq, quote, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Chain != "btc" || result.Token != "BTC" || result.Amount != "0.01" ||
+		result.ToAddress != quote.GetDepositAddress() || result.TxID != "deadbeef" {
+		t.Errorf("result = %+v, want 0.01 BTC on btc to the deposit address in deadbeef", result)
+	}
+	if result.Timestamp.Before(start) || result.Timestamp.Location() != time.UTC {
+		t.Errorf("timestamp = %v, want the UTC time of the deposit", result.Timestamp)
+	}
+	if result.Fee != "" {
+		t.Errorf("fee = %q, want none for a depositor that doesn't report it", result.Fee)
+	}
+
+	// The audit log records the same deposit, once, without key material
+	audit, err := os.ReadFile(auditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(audit), "solana-secret-key") {
+		t.Errorf("audit log leaks a private key:\n%s", audit)
+	}
+	lines := strings.Split(strings.TrimSpace(string(audit)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("audit log has %d records, want 1:\n%s", len(lines), audit)
+	}
+	var record deposit.AuditRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	want := result.AuditRecord("swap")
+	if !record.Timestamp.Equal(want.Timestamp) {
+		t.Errorf("audit timestamp = %v, want %v", record.Timestamp, want.Timestamp)
+	}
+	if record.Timestamp = want.Timestamp; record != want {
+		t.Errorf("audit record = %+v, want %+v", record, want)
+	}
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
+	"github.com/fatih/color"
+
+	"near-swap/config"
+	"near-swap/pkg/client"
+	"near-swap/pkg/deposit"
+	"near-swap/pkg/swap"
+	"near-swap/pkg/types"
+)
+
+// runViaSwap swaps through an intermediate token in two legs: source -> via, then via -> dest
+// once the first leg has settled. The first leg pays out to --via-recipient, and the second
+// leg is auto-deposited from that wallet.
+func runViaSwap(cfg *config.Config, apiClient *client.OneClickClient, swapReq *types.SwapRequest, verbose, jsonOutput bool) {
+	if viaChain == "" {
+		printError(fmt.Errorf("--via-chain is required with --via"))
+		os.Exit(1)
+	}
+	if viaRecipient == "" {
+		printError(fmt.Errorf("--via-recipient is required with --via: the %s address of your auto-deposit wallet on %s", viaToken, viaChain))
+		os.Exit(1)
+	}
+	if swapReq.SourceChain == "" || swapReq.DestChain == "" {
+		printError(fmt.Errorf("--from-chain and --to-chain are required with --via"))
+		os.Exit(1)
+	}
+
+	// The second leg is always funded from the via wallet
+	depositMgr := deposit.NewManager(cfg.AutoDeposit)
+	if err := depositMgr.CheckChain(viaChain); err != nil {
+		printError(fmt.Errorf("auto-deposit must be configured for %s to fund the second leg: %w", viaChain, err))
+		os.Exit(1)
+	}
+
+	manualFirstLeg := !autoDeposit && !cfg.AutoDeposit.Enabled
+	if !manualFirstLeg {
+		if err := depositMgr.CheckChain(swapReq.SourceChain); err != nil {
+			printError(err)
+			os.Exit(1)
+		}
+	}
+
+	first := *swapReq
+	first.DestToken = viaToken
+	first.DestChain = viaChain
+	first.RecipientAddr = viaRecipient
+
+	second := *swapReq
+	second.Amount = ""
+	second.SourceToken = viaToken
+	second.SourceChain = viaChain
+	second.RefundAddr = viaRecipient
+
+	via := &swap.ViaSwap{
+		Client:         apiClient,
+		Depositor:      depositMgr,
+		First:          first,
+		Second:         second,
+		ManualFirstLeg: manualFirstLeg,
+		LegTimeout:     viaLegTimeout,
+		OnQuote: func(leg int, quote *oneclick.Quote) error {
+			if leg == 1 {
+				if !jsonOutput {
+					displayQuote(quote, &first)
+					fmt.Printf("  Then:              ~%s %s -> %s (quoted once the first leg settles)\n\n",
+						quote.GetAmountOutFormatted(), viaToken, swapReq.DestToken)
+				}
+				if !noConfirm && !jsonOutput && !confirmSwap() {
+					return fmt.Errorf("swap cancelled by user")
+				}
+				if manualFirstLeg && !jsonOutput {
+					displayDepositInstructions(quote, &first)
+				}
+				return nil
+			}
+			if !jsonOutput {
+				fmt.Printf("\n  Leg 2 quoted: %s %s -> ~%s %s (deposit %s)\n",
+					quote.GetAmountInFormatted(), viaToken, quote.GetAmountOutFormatted(), swapReq.DestToken,
+					color.CyanString(quote.GetDepositAddress()))
+			}
+			return nil
+		},
+		OnStatus: func(leg int, status string) {
+			if !jsonOutput {
+				fmt.Printf("  Leg %d status:  %s\n", leg, getColoredStatus(status))
+			}
+		},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if !jsonOutput {
+		fmt.Printf("\nSwapping %s %s -> %s via %s\n", swapReq.Amount, swapReq.SourceToken, swapReq.DestToken, viaToken)
+	}
+	result, runErr := via.Run(ctx)
+
+	// Record every deposit we sent, including those of a swap that stopped part way
+	legChains := []string{first.SourceChain, second.SourceChain}
+	for i, leg := range result.Legs {
+		if leg.DepositTx == "" {
+			continue
+		}
+		if err := depositMgr.RecordAudit(deposit.AuditRecord{
+			Source:    "swap",
+			Chain:     legChains[i],
+			Token:     leg.SourceToken,
+			Amount:    leg.AmountIn,
+			ToAddress: leg.DepositAddress,
+			TxID:      leg.DepositTx,
+		}); err != nil && !jsonOutput {
+			color.Yellow("Warning: %v\n", err)
+		}
+	}
+
+	if jsonOutput {
+		output := struct {
+			*swap.Result
+			Error string `json:"error,omitempty"`
+		}{Result: result}
+		if runErr != nil {
+			output.Error = runErr.Error()
+		}
+		jsonData, _ := json.MarshalIndent(output, "", "  ")
+		fmt.Println(string(jsonData))
+	} else {
+		displayViaResult(result, verbose)
+	}
+
+	if runErr != nil {
+		printError(runErr)
+		os.Exit(1)
+	}
+}
+
+// displayViaResult prints the rolled-up outcome of a two-leg swap
+func displayViaResult(result *swap.Result, verbose bool) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	switch result.Status {
+	case swap.StatusCompleted:
+		color.Green("                 TWO-LEG SWAP COMPLETED")
+	case swap.StatusPartial:
+		color.Yellow("            TWO-LEG SWAP PARTIALLY COMPLETED")
+	default:
+		color.Red("                  TWO-LEG SWAP FAILED")
+	}
+	fmt.Println(strings.Repeat("=", 60))
+
+	for i, leg := range result.Legs {
+		fmt.Printf("\n  Leg %d:             %s -> %s\n", i+1, leg.SourceToken, leg.DestToken)
+		if leg.AmountIn != "" {
+			fmt.Printf("    Amount:          %s %s -> %s %s\n", leg.AmountIn, leg.SourceToken, leg.AmountOut, leg.DestToken)
+		}
+		if leg.Status != "" {
+			fmt.Printf("    Status:          %s\n", getColoredStatus(leg.Status))
+		}
+		if leg.DepositAddress != "" {
+			fmt.Printf("    Deposit Address: %s\n", leg.DepositAddress)
+		}
+		if leg.DepositTx != "" && verbose {
+			fmt.Printf("    Deposit Tx:      %s\n", leg.DepositTx)
+		}
+		if leg.Error != "" {
+			fmt.Printf("    Error:           %s\n", color.RedString(leg.Error))
+		}
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 60) + "\n")
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
+	"github.com/fatih/color"
+
+	"near-swap/pkg/client"
+)
+
+// exitDetached is the exit code of `swap --wait` when Ctrl+C stops waiting on a swap that is
+// still in flight, following the shell convention for SIGINT
+const exitDetached = 130
+
+// isTerminalSwapStatus reports whether a swap status is final
+func isTerminalSwapStatus(status string) bool {
+	switch strings.ToUpper(status) {
+	case "SUCCESS", "COMPLETED", "FAILED", "REFUNDED":
+		return true
+	}
+	return false
+}
+
+// waitForSwap polls a swap's status every interval until it reaches a terminal state or ctx
+// is cancelled, printing each status change. Polling errors are reported and retried.
+func waitForSwap(ctx context.Context, apiClient *client.OneClickClient, depositAddress string, interval time.Duration, jsonOutput bool) (*oneclick.GetExecutionStatusResponse, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		status, err := apiClient.GetSwapStatus(depositAddress)
+		if err != nil {
+			if !jsonOutput {
+				color.Red("  [%s] Error checking status: %v", time.Now().Format("15:04:05"), err)
+			}
+		} else {
+			current := strings.ToUpper(status.GetStatus())
+			if current != last && !jsonOutput {
+				fmt.Printf("  [%s] Status: %s\n", time.Now().Format("15:04:05"), getColoredStatus(current))
+			}
+			last = current
+			if isTerminalSwapStatus(current) {
+				return status, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// runSwapWait blocks until the swap at depositAddress settles, prints the outcome and exits
+// non-zero unless it succeeded. Ctrl+C stops waiting without affecting the swap.
+func runSwapWait(apiClient *client.OneClickClient, depositAddress string, jsonOutput bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if !jsonOutput {
+		fmt.Printf("\nWaiting for the swap to complete (checking every %d seconds). Press Ctrl+C to stop waiting.\n", swapWaitInterval)
+	}
+
+	status, err := waitForSwap(ctx, apiClient, depositAddress, time.Duration(swapWaitInterval)*time.Second, jsonOutput)
+	if errors.Is(err, context.Canceled) {
+		if jsonOutput {
+			fmt.Printf("{\"deposit_address\": %q, \"status\": \"detached\"}\n", depositAddress)
+		} else {
+			color.Yellow("\nStopped waiting; the swap continues. Check it later with:")
+			color.Cyan("  near-swap status %s\n", depositAddress)
+		}
+		os.Exit(exitDetached)
+	}
+
+	if jsonOutput {
+		jsonData, _ := json.MarshalIndent(status, "", "  ")
+		fmt.Println(string(jsonData))
+	} else {
+		displayStatus(status, depositAddress)
+	}
+
+	switch strings.ToUpper(status.GetStatus()) {
+	case "SUCCESS", "COMPLETED":
+		if !jsonOutput {
+			color.Green("✓ Swap completed")
+		}
+	default:
+		if !jsonOutput {
+			color.Red("✗ Swap %s", strings.ToLower(status.GetStatus()))
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+)
+
+// absoluteTimeLayout is the layout used for absolute timestamps in text output
+const absoluteTimeLayout = "2006-01-02 15:04:05"
+
+// relativeTime renders t relative to now, e.g. "just now", "5m ago", "2h ago", "yesterday"
+func relativeTime(t, now time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	var text string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		text = fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		text = fmt.Sprintf("%dh", int(d/time.Hour))
+	case d < 48*time.Hour:
+		if future {
+			return "tomorrow"
+		}
+		return "yesterday"
+	case d < 7*24*time.Hour:
+		text = fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d < 30*24*time.Hour:
+		text = fmt.Sprintf("%dw", int(d/(7*24*time.Hour)))
+	case d < 365*24*time.Hour:
+		text = fmt.Sprintf("%dmo", int(d/(30*24*time.Hour)))
+	default:
+		text = fmt.Sprintf("%dy", int(d/(365*24*time.Hour)))
+	}
+
+	if future {
+		return "in " + text
+	}
+	return text + " ago"
+}
+
+// formatTimestamp renders a timestamp for tables: relative by default, with the
+// absolute time appended in verbose mode
+func formatTimestamp(t time.Time, verbose bool) string {
+	relative := relativeTime(t, time.Now())
+	if verbose && !t.IsZero() {
+		return fmt.Sprintf("%s (%s)", relative, t.Format(absoluteTimeLayout))
+	}
+	return relative
+}
+
+// formatTimestampFull renders the absolute time followed by the relative time
+func formatTimestampFull(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return fmt.Sprintf("%s (%s)", t.Format(absoluteTimeLayout), relativeTime(t, time.Now()))
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{ago: 0, want: "just now"},
+		{ago: 59 * time.Second, want: "just now"},
+		{ago: 5 * time.Minute, want: "5m ago"},
+		{ago: 2*time.Hour + 59*time.Minute, want: "2h ago"},
+		{ago: 30 * time.Hour, want: "yesterday"},
+		{ago: 3 * 24 * time.Hour, want: "3d ago"},
+		{ago: 15 * 24 * time.Hour, want: "2w ago"},
+		{ago: 90 * 24 * time.Hour, want: "3mo ago"},
+		{ago: 800 * 24 * time.Hour, want: "2y ago"},
+		{ago: -10 * time.Minute, want: "in 10m"},
+		{ago: -30 * time.Hour, want: "tomorrow"},
+		{ago: -3 * 24 * time.Hour, want: "in 3d"},
+	}
+
+	for _, tt := range tests {
+		if got := relativeTime(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("relativeTime(%s ago) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+	if got := relativeTime(time.Time{}, now); got != "never" {
+		t.Errorf("relativeTime(zero) = %q, want never", got)
+	}
+}
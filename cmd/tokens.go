@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
@@ -15,11 +14,23 @@ import (
 
 	"near-swap/config"
 	"near-swap/pkg/client"
+	"near-swap/pkg/deposit"
+	"near-swap/pkg/output"
 )
 
 var (
 	filterChain  string
 	filterSymbol string
+	tokenSearch  string
+	showAssetID  bool
+	tokenSort    string
+	tokensFlat   bool
+	depositable  bool
+
+	// Route probing flags
+	pairsToken   string
+	pairsTargets []string
+	pairsAmount  string
 )
 
 var tokensCmd = &cobra.Command{
@@ -28,12 +39,32 @@ var tokensCmd = &cobra.Command{
 	Short:   "List all supported tokens",
 	Long: `List all tokens supported by the NEAR Intents 1Click API.
 
-You can filter tokens by blockchain or symbol.
+You can filter tokens by blockchain or symbol. --search matches loosely instead:
+case-insensitive, against the symbol (letters in order, e.g. "wbt" finds WBTC), the
+contract address and the asset ID, with the best matches listed first. Add
+--show-asset-id to print each token's 1Click asset ID, which helps when a symbol
+exists on several chains and a quote picks the wrong one (JSON output always
+includes it).
+
+Tokens are grouped by chain. --sort orders them by symbol or decimals within each
+chain, and --flat lists them in one table sorted across all chains. --depositable
+keeps only tokens on chains your auto-deposit config can send from.
+
+Use --pairs to list which destinations are reachable from a token. Each
+candidate destination is checked with a dry quote (no deposit address is
+created); by default common targets such as USDC, USDT, ETH, BTC and SOL on
+every chain are probed. --chain then selects the chain of the source token.
 
 Examples:
   near-swap list-tokens
   near-swap list-tokens --chain solana
-  near-swap list-tokens --symbol USDC`,
+  near-swap list-tokens --symbol USDC
+  near-swap list-tokens --search usdc --show-asset-id
+  near-swap list-tokens --search 0xa0b86991
+  near-swap list-tokens --sort symbol --flat
+  near-swap list-tokens --depositable
+  near-swap list-tokens --pairs ZEC
+  near-swap list-tokens --pairs USDC --chain ethereum --targets BTC,SOL --amount 100`,
 	Run: runListTokens,
 }
 
@@ -42,11 +73,18 @@ func init() {
 
 	tokensCmd.Flags().StringVar(&filterChain, "chain", "", "Filter by blockchain")
 	tokensCmd.Flags().StringVar(&filterSymbol, "symbol", "", "Filter by token symbol")
+	tokensCmd.Flags().StringVar(&tokenSearch, "search", "", "Fuzzy search symbols, contract addresses and asset IDs (best matches first)")
+	tokensCmd.Flags().BoolVar(&showAssetID, "show-asset-id", false, "Show each token's 1Click asset ID")
+	tokensCmd.Flags().StringVar(&tokenSort, "sort", "", "Sort tokens by symbol or decimals (within each chain unless --flat)")
+	tokensCmd.Flags().BoolVar(&tokensFlat, "flat", false, "List tokens in one table instead of grouping them by chain")
+	tokensCmd.Flags().BoolVar(&depositable, "depositable", false, "Only show tokens on chains configured for auto-deposit")
+	tokensCmd.Flags().StringVar(&pairsToken, "pairs", "", "List destinations reachable from this token")
+	tokensCmd.Flags().StringSliceVar(&pairsTargets, "targets", nil, "Destination symbols to probe with --pairs (default: common tokens)")
+	tokensCmd.Flags().StringVar(&pairsAmount, "amount", "1", "Source amount used for --pairs probe quotes")
+	addFormatFlag(tokensCmd, true)
 }
 
 func runListTokens(cmd *cobra.Command, args []string) {
-	jsonOutput, _ := cmd.Flags().GetBool("json")
-
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -54,18 +92,32 @@ func runListTokens(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	format := mustOutputFormat(cmd, cfg, true)
+	quiet := format != output.Table
+
+	if tokenSort != "" && tokenSort != tokenSortSymbol && tokenSort != tokenSortDecimals {
+		printError(fmt.Errorf("invalid --sort %q: must be %s or %s", tokenSort, tokenSortSymbol, tokenSortDecimals))
+		os.Exit(1)
+	}
+
 	// Create client
-	apiClient := client.NewOneClickClient(cfg.JWTToken)
+	apiClient := newAPIClient(cfg)
+
+	if pairsToken != "" {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		runTokenPairs(apiClient, format, verbose)
+		return
+	}
 
 	// Get tokens with spinner
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-	if !jsonOutput {
+	if !quiet {
 		s.Suffix = " Fetching supported tokens..."
 		s.Start()
 	}
 
 	tokens, err := apiClient.GetSupportedTokens()
-	if !jsonOutput {
+	if !quiet {
 		s.Stop()
 	}
 
@@ -96,15 +148,117 @@ func runListTokens(cmd *cobra.Command, args []string) {
 		filtered = temp
 	}
 
+	if depositable {
+		filtered = depositableTokens(filtered, deposit.NewManager(cfg.AutoDeposit))
+	}
+
+	if tokenSearch != "" {
+		filtered = searchTokens(filtered, tokenSearch)
+	}
+
+	// An explicit --sort replaces the API's (or the search's) order; --flat alone sorts by chain
+	switch {
+	case tokenSort != "":
+		sortTokens(filtered, tokenSort, tokensFlat)
+	case tokensFlat && tokenSearch == "":
+		sortTokens(filtered, "", true)
+	}
+
 	// Output
-	if jsonOutput {
-		jsonData, _ := json.MarshalIndent(filtered, "", "  ")
-		fmt.Println(string(jsonData))
+	if quiet {
+		printOutput(format, filtered)
+	} else if tokensFlat {
+		displayTokensFlat(filtered)
 	} else {
 		displayTokens(filtered)
 	}
 }
 
+// Orders accepted by list-tokens --sort
+const (
+	tokenSortSymbol   = "symbol"
+	tokenSortDecimals = "decimals"
+)
+
+// sortTokens sorts tokens in place by symbol or decimals, ties broken by symbol then chain.
+// Unless flat, tokens are ordered by chain first, matching how displayTokens groups them;
+// an empty by then just orders the chains.
+func sortTokens(tokens []oneclick.TokenResponse, by string, flat bool) {
+	sort.SliceStable(tokens, func(i, j int) bool {
+		a, b := tokens[i], tokens[j]
+		chainA, chainB := strings.ToLower(a.GetBlockchain()), strings.ToLower(b.GetBlockchain())
+		if !flat && chainA != chainB {
+			return chainA < chainB
+		}
+		symbolA, symbolB := strings.ToUpper(a.GetSymbol()), strings.ToUpper(b.GetSymbol())
+		switch by {
+		case tokenSortDecimals:
+			if a.GetDecimals() != b.GetDecimals() {
+				return a.GetDecimals() < b.GetDecimals()
+			}
+			fallthrough
+		case tokenSortSymbol:
+			if symbolA != symbolB {
+				return symbolA < symbolB
+			}
+		}
+		return chainA < chainB
+	})
+}
+
+// depositableTokens returns the tokens on chains that auto-deposit can send from
+func depositableTokens(tokens []oneclick.TokenResponse, depositMgr *deposit.Manager) []oneclick.TokenResponse {
+	ready := make(map[string]bool)
+	var result []oneclick.TokenResponse
+	for _, token := range tokens {
+		chain := token.GetBlockchain()
+		ok, seen := ready[chain]
+		if !seen {
+			ok = depositMgr.IsEnabledForChain(chain)
+			ready[chain] = ok
+		}
+		if ok {
+			result = append(result, token)
+		}
+	}
+	return result
+}
+
+// displayTokensFlat prints tokens as one table, in the order given
+func displayTokensFlat(tokens []oneclick.TokenResponse) {
+	if len(tokens) == 0 {
+		fmt.Println("\nNo tokens found matching the criteria.")
+		return
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 90))
+	color.Green("                            SUPPORTED TOKENS")
+	fmt.Println(strings.Repeat("=", 90) + "\n")
+
+	chains := make(map[string]bool)
+	for _, token := range tokens {
+		chains[token.GetBlockchain()] = true
+
+		address := token.GetContractAddress()
+		if len(address) > 40 {
+			address = address[:37] + "..."
+		}
+
+		fmt.Printf("  %-10s  %-10s  %2.0f decimals  %s",
+			color.YellowString(token.GetSymbol()),
+			color.CyanString(token.GetBlockchain()),
+			token.GetDecimals(),
+			color.HiBlackString(address))
+		if showAssetID {
+			fmt.Printf("  %s", token.GetAssetId())
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 90))
+	fmt.Printf("\nTotal: %d tokens across %d blockchains\n\n", len(tokens), len(chains))
+}
+
 func displayTokens(tokens []oneclick.TokenResponse) {
 	if len(tokens) == 0 {
 		fmt.Println("\nNo tokens found matching the criteria.")
@@ -145,13 +299,157 @@ func displayTokens(tokens []oneclick.TokenResponse) {
 				address = address[:37] + "..."
 			}
 
-			fmt.Printf("  %-10s  %2.0f decimals  %s\n",
+			fmt.Printf("  %-10s  %2.0f decimals  %s",
 				color.YellowString(symbol),
 				decimals,
 				color.HiBlackString(address))
+			if showAssetID {
+				fmt.Printf("  %s", token.GetAssetId())
+			}
+			fmt.Println()
 		}
 	}
 
 	fmt.Println("\n" + strings.Repeat("=", 90))
 	fmt.Printf("\nTotal: %d tokens across %d blockchains\n\n", len(tokens), len(chains))
 }
+
+// searchTokens returns the tokens matching query, best matches first
+func searchTokens(tokens []oneclick.TokenResponse, query string) []oneclick.TokenResponse {
+	type match struct {
+		token oneclick.TokenResponse
+		score int
+	}
+
+	var matches []match
+	for _, token := range tokens {
+		if score := tokenMatchScore(token, query); score > 0 {
+			matches = append(matches, match{token, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	result := make([]oneclick.TokenResponse, len(matches))
+	for i, m := range matches {
+		result[i] = m.token
+	}
+	return result
+}
+
+// tokenMatchScore rates how well a token matches a search query, case-insensitively: an exact
+// symbol beats a symbol prefix, which beats a substring of the symbol, contract address or
+// asset ID, which beats the query's letters appearing in order in the symbol. 0 is no match.
+func tokenMatchScore(token oneclick.TokenResponse, query string) int {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return 0
+	}
+	symbol := strings.ToLower(token.GetSymbol())
+
+	switch {
+	case symbol == query:
+		return 4
+	case strings.HasPrefix(symbol, query):
+		return 3
+	case strings.Contains(symbol, query),
+		strings.Contains(strings.ToLower(token.GetContractAddress()), query),
+		strings.Contains(strings.ToLower(token.GetAssetId()), query):
+		return 2
+	case isSubsequence(query, symbol):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// isSubsequence reports whether the characters of query appear in s in order
+func isSubsequence(query, s string) bool {
+	for _, r := range s {
+		if len(query) == 0 {
+			break
+		}
+		if strings.HasPrefix(query, string(r)) {
+			query = query[len(string(r)):]
+		}
+	}
+	return len(query) == 0
+}
+
+// runTokenPairs probes and prints the destinations reachable from --pairs
+func runTokenPairs(apiClient *client.OneClickClient, format string, verbose bool) {
+	quiet := format != output.Table
+
+	var source *oneclick.TokenResponse
+	var err error
+	if filterChain != "" {
+		source, err = apiClient.FindTokenOnChain(pairsToken, filterChain)
+	} else {
+		source, err = apiClient.FindToken(pairsToken)
+	}
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	if !quiet {
+		s.Suffix = fmt.Sprintf(" Probing routes from %s on %s...", source.GetSymbol(), source.GetBlockchain())
+		s.Start()
+	}
+
+	results, err := apiClient.FindRoutes(*source, pairsTargets, pairsAmount)
+	if !quiet {
+		s.Stop()
+	}
+
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	if quiet {
+		printOutput(format, results)
+		return
+	}
+
+	displayRoutes(source, results, verbose)
+}
+
+func displayRoutes(source *oneclick.TokenResponse, results []client.RouteResult, verbose bool) {
+	fmt.Println("\n" + strings.Repeat("=", 90))
+	color.Green("                    ROUTES FROM %s (%s)", source.GetSymbol(), strings.ToUpper(source.GetBlockchain()))
+	fmt.Println(strings.Repeat("=", 90))
+
+	viable := 0
+	fmt.Println()
+	for _, r := range results {
+		if r.Viable() {
+			viable++
+			fmt.Printf("  %s  %-10s  %-12s  %s\n",
+				color.GreenString("✓"),
+				color.YellowString(r.Symbol),
+				r.Chain,
+				color.HiBlackString("%s %s for %s %s", r.AmountOut, r.Symbol, pairsAmount, source.GetSymbol()))
+		} else if verbose {
+			fmt.Printf("  %s  %-10s  %-12s  %s\n",
+				color.RedString("✗"),
+				r.Symbol,
+				r.Chain,
+				color.HiBlackString(r.Error))
+		}
+	}
+
+	if viable == 0 {
+		color.Yellow("  No reachable destinations found among the probed tokens.")
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 90))
+	fmt.Printf("\n%d of %d probed destinations reachable", viable, len(results))
+	if !verbose && viable < len(results) {
+		fmt.Print(" (use --verbose to see failures)")
+	}
+	fmt.Println()
+	fmt.Println()
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"near-swap/config"
+	"near-swap/pkg/deposit"
+
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
+)
+
+// tokenKeys reduces tokens to "SYMBOL@chain" for comparison
+func tokenKeys(tokens []oneclick.TokenResponse) []string {
+	keys := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		keys = append(keys, fmt.Sprintf("%s@%s", token.GetSymbol(), token.GetBlockchain()))
+	}
+	return keys
+}
+
+func TestListTokensOrdering(t *testing.T) {
+	token := func(symbol, chain string, decimals float32) oneclick.TokenResponse {
+		return *oneclick.NewTokenResponse("nep141:"+chain+"-"+symbol, decimals, chain, symbol, 1, time.Now())
+	}
+	// In the API's order, which is neither by chain nor by symbol
+	tokens := []oneclick.TokenResponse{
+		token("wBTC", "eth", 8),
+		token("USDC", "sol", 6),
+		token("ETH", "eth", 18),
+		token("BTC", "btc", 8),
+		token("USDC", "eth", 6),
+		token("SOL", "sol", 9),
+	}
+
+	tests := []struct {
+		name string
+		by   string
+		flat bool
+		want []string
+	}{
+		{name: "symbol within each chain", by: tokenSortSymbol,
+			want: []string{"BTC@btc", "ETH@eth", "USDC@eth", "wBTC@eth", "SOL@sol", "USDC@sol"}},
+		{name: "decimals within each chain", by: tokenSortDecimals,
+			want: []string{"BTC@btc", "USDC@eth", "wBTC@eth", "ETH@eth", "USDC@sol", "SOL@sol"}},
+		{name: "flat by symbol", by: tokenSortSymbol, flat: true,
+			want: []string{"BTC@btc", "ETH@eth", "SOL@sol", "USDC@eth", "USDC@sol", "wBTC@eth"}},
+		{name: "flat by decimals", by: tokenSortDecimals, flat: true,
+			want: []string{"USDC@eth", "USDC@sol", "BTC@btc", "wBTC@eth", "SOL@sol", "ETH@eth"}},
+		{name: "flat alone orders chains and keeps the API's order within them", flat: true,
+			want: []string{"BTC@btc", "wBTC@eth", "ETH@eth", "USDC@eth", "USDC@sol", "SOL@sol"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := append([]oneclick.TokenResponse(nil), tokens...)
+			sortTokens(sorted, tt.by, tt.flat)
+			if got := tokenKeys(sorted); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Only bitcoin is fully configured; solana is enabled without a key
+	depositMgr := deposit.NewManager(config.AutoDepositConfig{
+		Enabled: true,
+		Bitcoin: config.BitcoinConfig{Enabled: true, CLIPath: "bitcoin-cli"},
+		Solana:  config.SolanaConfig{Enabled: true},
+	})
+	if got, want := tokenKeys(depositableTokens(tokens, depositMgr)), []string{"BTC@btc"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("depositable tokens = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"near-swap/config"
+	"near-swap/pkg/client"
+	"near-swap/pkg/deposit"
+)
+
+var validateConfigCmd = &cobra.Command{
+	Use:   "validate-config",
+	Short: "Check configuration, auto-deposit setup and system clock",
+	Long: `Load and validate your configuration, then report:
+  - auto-deposit status for each configured chain
+  - clock skew between this machine and the 1Click API
+
+Examples:
+  near-swap validate-config
+  near-swap validate-config --json`,
+	Run: runValidateConfig,
+}
+
+func init() {
+	rootCmd.AddCommand(validateConfigCmd)
+}
+
+func runValidateConfig(cmd *cobra.Command, args []string) {
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+
+	// Load config (performs field validation)
+	cfg, err := config.Load()
+	if err != nil {
+		printError(err)
+		os.Exit(1)
+	}
+
+	warnings := make([]string, 0)
+
+	// Auto-deposit chains
+	depositMgr := deposit.NewManager(cfg.AutoDeposit)
+	supported := depositMgr.GetSupportedChains()
+	sort.Strings(supported)
+
+	chains := make(map[string]string)
+	if cfg.AutoDeposit.Enabled {
+		for _, chain := range supported {
+			status, reason := depositMgr.IsConfiguredForChain(chain)
+			if status == deposit.ChainReady {
+				chains[chain] = "ready"
+				continue
+			}
+			chains[chain] = reason
+			if status == deposit.ChainMisconfigured {
+				warnings = append(warnings, reason)
+			}
+		}
+	}
+
+	// Clock skew
+	skewText := ""
+	skew, err := newAPIClient(cfg).ClockSkew()
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("could not check clock skew: %v", err))
+	} else {
+		skewText = skew.String()
+		if warning := client.ClockSkewWarning(skew); warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	if jsonOutput {
+		output := map[string]interface{}{
+			"valid":        len(warnings) == 0,
+			"auto_deposit": chains,
+			"clock_skew":   skewText,
+			"warnings":     warnings,
+		}
+		jsonData, _ := json.MarshalIndent(output, "", "  ")
+		fmt.Println(string(jsonData))
+		return
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	color.Green("                 CONFIGURATION CHECK")
+	fmt.Println(strings.Repeat("=", 60))
+
+	fmt.Printf("\n  Config:            %s\n", color.GreenString("loaded"))
+	if !cfg.AutoDeposit.Enabled {
+		fmt.Printf("  Auto-deposit:      %s\n", color.YellowString("disabled"))
+	} else {
+		fmt.Printf("  Auto-deposit:\n")
+		for _, chain := range supported {
+			state := chains[chain]
+			if state == "ready" {
+				fmt.Printf("    %-16s %s\n", chain, color.GreenString("ready"))
+			} else {
+				fmt.Printf("    %-16s %s\n", chain, color.YellowString(state))
+			}
+		}
+	}
+	if skewText != "" {
+		fmt.Printf("  Clock skew:        %s\n", skewText)
+	}
+
+	if len(warnings) > 0 {
+		fmt.Println()
+		for _, warning := range warnings {
+			color.Yellow("  ⚠ %s", warning)
+		}
+	} else {
+		color.Green("\n  ✓ No problems found")
+	}
+	fmt.Println("\n" + strings.Repeat("=", 60) + "\n")
+}
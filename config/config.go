@@ -2,20 +2,31 @@ package config
 
 import (
 	"fmt"
+	"math"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // BitcoinConfig holds Bitcoin-specific configuration
 type BitcoinConfig struct {
-	Enabled  bool     `mapstructure:"enabled"`
-	CLIPath  string   `mapstructure:"cli_path"`
-	CLIArgs  []string `mapstructure:"cli_args"`
-	Wallet   string   `mapstructure:"wallet"`
-	FeeRate  float64  `mapstructure:"fee_rate"`
+	Enabled       bool     `mapstructure:"enabled"`
+	CLIPath       string   `mapstructure:"cli_path"`
+	CLIArgs       []string `mapstructure:"cli_args"`
+	Wallet        string   `mapstructure:"wallet"`
+	FeeRate       string   `mapstructure:"fee_rate"`        // sat/vB; BitcoinFeeRateAuto estimates it, empty or "0" leaves it to the wallet
+	FeeConfTarget int      `mapstructure:"fee_conf_target"` // Blocks an estimated fee rate aims to confirm within
+	MaxFeeRate    float64  `mapstructure:"max_fee_rate"`    // Cap in sat/vB on an estimated fee rate
 }
 
+// BitcoinFeeRateAuto estimates the Bitcoin fee rate with estimatesmartfee before each send
+const BitcoinFeeRateAuto = "auto"
+
 // MoneroConfig holds Monero-specific configuration for auto-deposit
 type MoneroConfig struct {
 	Enabled      bool   `mapstructure:"enabled"`
@@ -35,6 +46,14 @@ type ZcashConfig struct {
 	CLIArgs  []string `mapstructure:"cli_args"`
 }
 
+// LitecoinConfig holds Litecoin-specific configuration
+type LitecoinConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`
+	CLIPath  string   `mapstructure:"cli_path"`
+	CLIArgs  []string `mapstructure:"cli_args"`
+	Wallet   string   `mapstructure:"wallet"`
+}
+
 // EVMConfig holds EVM-specific configuration for auto-deposit
 type EVMConfig struct {
 	Enabled    bool              `mapstructure:"enabled"`
@@ -48,9 +67,61 @@ type EVMNetwork struct {
 	PrivateKeyEnv string  `mapstructure:"private_key_env"` // Environment variable name containing the private key
 	PrivateKey    string  // Resolved private key value (populated after loading config)
 	GasPrice      *int64  `mapstructure:"gas_price"`   // Optional: wei per gas unit
+	GasPriceGwei  *float64 `mapstructure:"gas_price_gwei"` // Optional: gas_price in gwei (normalized into GasPrice)
 	GasLimit      *uint64 `mapstructure:"gas_limit"`   // Optional: max gas for transaction
+
+	InfiniteApproval bool `mapstructure:"infinite_approval"` // Approve max uint256 instead of the exact amount when an ERC20 allowance is needed
+	Confirmations    uint64 `mapstructure:"confirmations"`   // Blocks a deposit must be buried under before it is final (0 returns on broadcast)
+
+	FeeMode        string `mapstructure:"fee_mode"`         // FeeModeLegacy (default) or FeeModeEIP1559
+	MaxPriorityFee *int64 `mapstructure:"max_priority_fee"` // Optional (eip1559): tip in wei per gas (if not set, uses network estimate)
+	MaxFee         *int64 `mapstructure:"max_fee"`          // Optional (eip1559): cap on the total fee in wei per gas
+
+	MaxPriorityFeeGwei *float64 `mapstructure:"max_priority_fee_gwei"` // Optional: max_priority_fee in gwei (normalized into MaxPriorityFee)
+	MaxFeeGwei         *float64 `mapstructure:"max_fee_gwei"`          // Optional: max_fee in gwei (normalized into MaxFee)
+
+	L2 string `mapstructure:"l2"` // Optional: L2FeeOptimism or L2FeeArbitrum for L2-aware fee estimation
 }
 
+// weiPerGwei converts the gwei fee fields to wei
+const weiPerGwei = 1e9
+
+// normalizeGwei converts a fee given in gwei into its wei field, refusing a fee set in both
+// units and gwei values that are negative or overflow wei
+func normalizeGwei(networkName, field string, wei **int64, gwei *float64) error {
+	if gwei == nil {
+		return nil
+	}
+	if *wei != nil {
+		return fmt.Errorf("auto_deposit.evm.networks.%s sets both %s (wei) and %s_gwei; set only one", networkName, field, field)
+	}
+	value := math.Round(*gwei * weiPerGwei)
+	if math.IsNaN(value) || value < 0 || value > math.MaxInt64 {
+		return fmt.Errorf("auto_deposit.evm.networks.%s.%s_gwei is out of range, got %v", networkName, field, *gwei)
+	}
+	converted := int64(value)
+	*wei = &converted
+	return nil
+}
+
+// EVM transaction fee modes
+const (
+	FeeModeLegacy  = "legacy"  // Gas price transactions
+	FeeModeEIP1559 = "eip1559" // Dynamic fee transactions with a priority tip and fee cap
+)
+
+// L2 fee models for EVM networks whose fees don't follow the L1 gas model
+const (
+	L2FeeOptimism = "optimism" // OP stack chains (Optimism, Base): L2 gas plus an L1 data fee from the gas price oracle
+	L2FeeArbitrum = "arbitrum" // Arbitrum: the L1 data cost is charged as extra L2 gas, so limits must be estimated
+)
+
+// Price sources plans can be priced from, tried in the order listed in price_sources
+const (
+	PriceSourceQuote     = "quote"      // A small dry-run 1Click quote for the pair
+	PriceSourceTokenList = "token_list" // The ratio of the pair's USD prices in the 1Click token list
+)
+
 // SolanaConfig holds Solana-specific configuration for auto-deposit
 type SolanaConfig struct {
 	Enabled       bool   `mapstructure:"enabled"`
@@ -60,16 +131,50 @@ type SolanaConfig struct {
 	PrivateKey    string                                     // Resolved private key value (populated after loading config)
 	Commitment    string `mapstructure:"commitment"`         // Commitment level: finalized, confirmed, processed
 	SkipPreflight bool   `mapstructure:"skip_preflight"`     // Skip preflight transaction checks
+	DroppedAfter  time.Duration `mapstructure:"dropped_after"` // Plan deposits the cluster still doesn't know this long after sending are marked dropped (0 disables)
 }
 
 // AutoDepositConfig holds auto-deposit configuration
 type AutoDepositConfig struct {
-	Enabled bool          `mapstructure:"enabled"`
-	Bitcoin BitcoinConfig `mapstructure:"bitcoin"`
-	Monero  MoneroConfig  `mapstructure:"monero"`
-	Zcash   ZcashConfig   `mapstructure:"zcash"`
-	EVM     EVMConfig     `mapstructure:"evm"`
-	Solana  SolanaConfig  `mapstructure:"solana"`
+	Enabled  bool           `mapstructure:"enabled"`
+	Bitcoin  BitcoinConfig  `mapstructure:"bitcoin"`
+	Monero   MoneroConfig   `mapstructure:"monero"`
+	Zcash    ZcashConfig    `mapstructure:"zcash"`
+	Litecoin LitecoinConfig `mapstructure:"litecoin"`
+	EVM      EVMConfig      `mapstructure:"evm"`
+	Solana   SolanaConfig   `mapstructure:"solana"`
+
+	AuditLogPath string `mapstructure:"audit_log_path"` // Append-only JSON-lines log of sent deposits (empty uses ~/.near-swap-deposits.log)
+
+	LowBalance map[string]float64 `mapstructure:"low_balance"` // Per-chain native balance below which plans from that chain are paused
+}
+
+// AutoWithdrawConfig gates forwarding swap output from the recipient wallet to a plan's cold
+// address. The dest chain's auto_deposit wallet must be the plan's recipient.
+type AutoWithdrawConfig struct {
+	Enabled    bool               `mapstructure:"enabled"`
+	FeeReserve map[string]float64 `mapstructure:"fee_reserve"` // Per dest chain amount of the received token kept back to pay the withdrawal fee
+}
+
+// AppFeeConfig holds an optional integrator fee charged on each swap
+type AppFeeConfig struct {
+	Recipient string  `mapstructure:"recipient"` // Account ID within NEAR Intents receiving the fee
+	FeeBps    float32 `mapstructure:"fee_bps"`   // Fee in basis points of amountIn (100 = 1%)
+}
+
+// APIServerConfig holds configuration for the `serve` HTTP API
+type APIServerConfig struct {
+	Addr     string `mapstructure:"addr"`      // Listen address (e.g. 127.0.0.1:8080)
+	TokenEnv string `mapstructure:"token_env"` // Environment variable name containing the bearer token
+	Token    string                            // Resolved bearer token (populated after loading config)
+}
+
+// NotificationsConfig holds the optional webhook the daemon posts execution events to
+type NotificationsConfig struct {
+	WebhookURL string `mapstructure:"webhook_url"` // Endpoint receiving a JSON POST per event (empty disables)
+	SecretEnv  string `mapstructure:"secret_env"`  // Environment variable name containing the HMAC signing secret (optional)
+	Secret     string                              // Resolved signing secret (populated after loading config)
+	Timeout    int    `mapstructure:"timeout"`     // Seconds to wait for the webhook before giving up on an event
 }
 
 // Config holds the application configuration
@@ -79,16 +184,80 @@ type Config struct {
 	DefaultRecipient string           `mapstructure:"default_recipient"`
 	DefaultRefundTo  string           `mapstructure:"default_refund_to"`
 	AutoDeposit     AutoDepositConfig `mapstructure:"auto_deposit"`
+	AutoWithdraw    AutoWithdrawConfig `mapstructure:"auto_withdraw"`
 	OutputFormat    string            `mapstructure:"output_format"`
 	Verbose         bool              `mapstructure:"verbose"`
+	LogFormat       string            `mapstructure:"log_format"` // Daemon log lines: "text" (default) or "json"
+	LogLevel        string            `mapstructure:"log_level"`  // Least severe daemon log level shown: debug, info (default), warn or error
 	AutoConfirm     bool              `mapstructure:"auto_confirm"`
 	Timeout         int               `mapstructure:"timeout"`
 	MaxRetries      int               `mapstructure:"max_retries"`
+	APIRateLimit    float64           `mapstructure:"api_rate_limit"` // 1Click API requests per second across all clients (0 disables pacing)
+	TokenCacheTTL   time.Duration     `mapstructure:"token_cache_ttl"` // How long the supported token list is reused (0 disables caching)
 	PlanStoragePath string            `mapstructure:"plan_storage_path"`
+	PlanStorageBackend string         `mapstructure:"plan_storage_backend"` // "json" (default) or "sqlite"
+	WarmupConcurrency int             `mapstructure:"warmup_concurrency"` // Plans primed at once when the daemon starts
+	MaxQuoteDivergence float64        `mapstructure:"max_quote_divergence"` // Max % the deposit quote may be worse than the trigger price (0 disables)
+	MaxPriceImpact     float64        `mapstructure:"max_price_impact"`     // Max % a full trade may price worse than the small probe quote (0 disables)
+	PriceSources       []string       `mapstructure:"price_sources"`        // Price sources tried in order until one returns a price
+	Referral        string            `mapstructure:"referral"` // Optional referral identifier attached to quotes
+	AppFee          AppFeeConfig      `mapstructure:"app_fee"`  // Optional integrator fee attached to quotes
+	APIServer       APIServerConfig   `mapstructure:"api_server"`
+	Notifications   NotificationsConfig `mapstructure:"notifications"` // Optional webhook for execution events
+	DefaultSlippage int               `mapstructure:"default_slippage"` // Quote slippage tolerance in basis points
+	DefaultDeadline time.Duration     `mapstructure:"default_deadline"` // How long quotes stay valid for deposits
+	StatsSnapshotPath     string `mapstructure:"stats_snapshot_path"`     // Daemon writes aggregate stats JSON here (empty disables)
+	StatsSnapshotInterval int    `mapstructure:"stats_snapshot_interval"` // Seconds between snapshots
+	MaxUnverifiedExecutions int  `mapstructure:"max_unverified_executions"` // Deposited-but-unverified executions a plan may have before new trades are held (0 disables)
+	MaxDestinationFailures  int  `mapstructure:"max_destination_failures"`  // Consecutive failed/refunded swaps after which a plan is paused (0 disables)
+	UnsupportedRoutes []string   `mapstructure:"unsupported_routes"` // "source:dest" chain pairs that quotes fail fast on
+	ObserverMode      bool       `mapstructure:"observer_mode"`      // Daemon records the trades plans would make but never deposits
+	MaxPlans          int        `mapstructure:"max_plans"`          // Plans that may be stored before creation is refused (0 is unlimited)
+	PlanStorageWarnMB int        `mapstructure:"plan_storage_warn_mb"` // Plan storage size that triggers a warning on save (0 disables)
 }
 
 var globalConfig *Config
 
+// maxAppFeeBps caps the integrator fee at 10% of the swap
+const maxAppFeeBps = 1000
+
+// referralPattern restricts referral identifiers to a safe charset
+var referralPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// validateIntegratorSettings checks the optional referral and app fee settings
+func validateIntegratorSettings(cfg *Config) error {
+	if cfg.Referral != "" && !referralPattern.MatchString(cfg.Referral) {
+		return fmt.Errorf("invalid referral '%s': must be 1-64 characters of letters, digits, '.', '_' or '-'", cfg.Referral)
+	}
+
+	if cfg.AppFee.Recipient == "" && cfg.AppFee.FeeBps != 0 {
+		return fmt.Errorf("app_fee.recipient is required when app_fee.fee_bps is set")
+	}
+	if cfg.AppFee.Recipient != "" && (cfg.AppFee.FeeBps <= 0 || cfg.AppFee.FeeBps > maxAppFeeBps) {
+		return fmt.Errorf("app_fee.fee_bps must be between 1 and %d, got %v", maxAppFeeBps, cfg.AppFee.FeeBps)
+	}
+
+	return nil
+}
+
+// validateNotifications checks the optional execution webhook settings
+func validateNotifications(n NotificationsConfig) error {
+	if n.WebhookURL == "" {
+		if n.SecretEnv != "" {
+			return fmt.Errorf("notifications.webhook_url is required when notifications.secret_env is set")
+		}
+		return nil
+	}
+	u, err := url.Parse(n.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid notifications.webhook_url '%s': must be an http or https URL", n.WebhookURL)
+	}
+	if n.Timeout < 1 || n.Timeout > 60 {
+		return fmt.Errorf("notifications.timeout must be between 1 and 60 seconds, got %d", n.Timeout)
+	}
+	return nil
+}
+
 // resolvePrivateKeys resolves environment variable references to actual private key values
 func resolvePrivateKeys(cfg *Config) error {
 	// Resolve EVM network private keys
@@ -103,6 +272,20 @@ func resolvePrivateKeys(cfg *Config) error {
 		}
 	}
 
+	// Resolve API server bearer token (only required when running `serve`)
+	if cfg.APIServer.TokenEnv != "" {
+		cfg.APIServer.Token = os.Getenv(cfg.APIServer.TokenEnv)
+	}
+
+	// Resolve the webhook signing secret
+	if cfg.Notifications.SecretEnv != "" {
+		secret := os.Getenv(cfg.Notifications.SecretEnv)
+		if secret == "" {
+			return fmt.Errorf("environment variable '%s' for notifications is not set or empty", cfg.Notifications.SecretEnv)
+		}
+		cfg.Notifications.Secret = secret
+	}
+
 	// Resolve Solana private key
 	if cfg.AutoDeposit.Solana.PrivateKeyEnv != "" {
 		privateKey := os.Getenv(cfg.AutoDeposit.Solana.PrivateKeyEnv)
@@ -127,13 +310,36 @@ func Load() (*Config, error) {
 	viper.SetDefault("base_url", "https://1click.chaindefuser.com")
 	viper.SetDefault("output_format", "text")
 	viper.SetDefault("verbose", false)
+	viper.SetDefault("log_format", "text")
+	viper.SetDefault("log_level", "info")
 	viper.SetDefault("auto_confirm", false)
 	viper.SetDefault("timeout", 30)
 	viper.SetDefault("max_retries", 3)
+	viper.SetDefault("api_rate_limit", 5.0)
+	viper.SetDefault("token_cache_ttl", "5m")
 	viper.SetDefault("plan_storage_path", "") // Empty means use default (~/.near-swap-plans.json)
+	viper.SetDefault("plan_storage_backend", "json")
+	viper.SetDefault("warmup_concurrency", 1)
+	viper.SetDefault("max_quote_divergence", 0.0)
+	viper.SetDefault("max_price_impact", 0.0)
+	viper.SetDefault("price_sources", []string{PriceSourceQuote})
+	viper.SetDefault("default_slippage", 100)
+	viper.SetDefault("default_deadline", "24h")
+	viper.SetDefault("stats_snapshot_interval", 60)
+	viper.SetDefault("max_unverified_executions", 0)
+	viper.SetDefault("max_destination_failures", 0)
+	viper.SetDefault("api_server.addr", "127.0.0.1:8080")
+	viper.SetDefault("api_server.token_env", "NEAR_SWAP_API_TOKEN")
+	viper.SetDefault("notifications.timeout", 5)
+	viper.SetDefault("auto_withdraw.enabled", false)
+	viper.SetDefault("observer_mode", false)
+	viper.SetDefault("max_plans", 0)
+	viper.SetDefault("plan_storage_warn_mb", 10)
 	viper.SetDefault("auto_deposit.enabled", false)
 	viper.SetDefault("auto_deposit.bitcoin.enabled", false)
 	viper.SetDefault("auto_deposit.bitcoin.cli_path", "bitcoin-cli")
+	viper.SetDefault("auto_deposit.bitcoin.fee_conf_target", 6)
+	viper.SetDefault("auto_deposit.bitcoin.max_fee_rate", 100.0)
 	viper.SetDefault("auto_deposit.monero.enabled", false)
 	viper.SetDefault("auto_deposit.monero.host", "127.0.0.1")
 	viper.SetDefault("auto_deposit.monero.port", 18082)
@@ -141,12 +347,15 @@ func Load() (*Config, error) {
 	viper.SetDefault("auto_deposit.monero.priority", 0)
 	viper.SetDefault("auto_deposit.zcash.enabled", false)
 	viper.SetDefault("auto_deposit.zcash.cli_path", "zcash-cli")
+	viper.SetDefault("auto_deposit.litecoin.enabled", false)
+	viper.SetDefault("auto_deposit.litecoin.cli_path", "litecoin-cli")
 	viper.SetDefault("auto_deposit.evm.enabled", false)
 	viper.SetDefault("auto_deposit.evm.networks", map[string]interface{}{})
 	viper.SetDefault("auto_deposit.solana.enabled", false)
 	viper.SetDefault("auto_deposit.solana.rpc_url", "https://api.mainnet-beta.solana.com")
 	viper.SetDefault("auto_deposit.solana.commitment", "confirmed")
 	viper.SetDefault("auto_deposit.solana.skip_preflight", false)
+	viper.SetDefault("auto_deposit.solana.dropped_after", "0")
 
 	// Read from environment variables
 	viper.SetEnvPrefix("NEAR_SWAP")
@@ -166,6 +375,169 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to resolve private keys: %w", err)
 	}
 
+	// Validate referral and app fee settings
+	if err := validateIntegratorSettings(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.WarmupConcurrency < 0 {
+		return nil, fmt.Errorf("warmup_concurrency must not be negative, got %d", cfg.WarmupConcurrency)
+	}
+
+	if len(cfg.PriceSources) == 0 {
+		return nil, fmt.Errorf("price_sources must list at least one of '%s' or '%s'", PriceSourceQuote, PriceSourceTokenList)
+	}
+	seenSources := make(map[string]bool)
+	for i, source := range cfg.PriceSources {
+		source = strings.ToLower(strings.TrimSpace(source))
+		if source != PriceSourceQuote && source != PriceSourceTokenList {
+			return nil, fmt.Errorf("price_sources: unknown source '%s' (use '%s' or '%s')", cfg.PriceSources[i], PriceSourceQuote, PriceSourceTokenList)
+		}
+		if seenSources[source] {
+			return nil, fmt.Errorf("price_sources: '%s' is listed more than once", source)
+		}
+		seenSources[source] = true
+		cfg.PriceSources[i] = source
+	}
+
+	if cfg.APIRateLimit < 0 || math.IsInf(cfg.APIRateLimit, 0) || math.IsNaN(cfg.APIRateLimit) {
+		return nil, fmt.Errorf("api_rate_limit must be 0 (disabled) or a positive number of requests per second, got %v", cfg.APIRateLimit)
+	}
+
+	if cfg.TokenCacheTTL < 0 {
+		return nil, fmt.Errorf("token_cache_ttl must not be negative, got %s", cfg.TokenCacheTTL)
+	}
+
+	if cfg.DefaultSlippage < 1 || cfg.DefaultSlippage > 5000 {
+		return nil, fmt.Errorf("default_slippage must be between 1 and 5000 basis points, got %d", cfg.DefaultSlippage)
+	}
+	if cfg.DefaultDeadline < time.Minute || cfg.DefaultDeadline > 7*24*time.Hour {
+		return nil, fmt.Errorf("default_deadline must be between 1m and 168h, got %s", cfg.DefaultDeadline)
+	}
+
+	if cfg.MaxUnverifiedExecutions < 0 {
+		return nil, fmt.Errorf("max_unverified_executions must not be negative, got %d", cfg.MaxUnverifiedExecutions)
+	}
+	if cfg.MaxDestinationFailures < 0 {
+		return nil, fmt.Errorf("max_destination_failures must not be negative, got %d", cfg.MaxDestinationFailures)
+	}
+	if cfg.MaxPlans < 0 {
+		return nil, fmt.Errorf("max_plans must not be negative, got %d", cfg.MaxPlans)
+	}
+	if cfg.PlanStorageWarnMB < 0 {
+		return nil, fmt.Errorf("plan_storage_warn_mb must not be negative, got %d", cfg.PlanStorageWarnMB)
+	}
+	switch strings.ToLower(strings.TrimSpace(cfg.OutputFormat)) {
+	case "text", "table", "json", "yaml", "csv":
+	default:
+		return nil, fmt.Errorf("output_format must be 'table' (or 'text'), 'json', 'yaml' or 'csv', got '%s'", cfg.OutputFormat)
+	}
+	switch strings.ToLower(strings.TrimSpace(cfg.LogFormat)) {
+	case "text", "json":
+	default:
+		return nil, fmt.Errorf("log_format must be 'text' or 'json', got '%s'", cfg.LogFormat)
+	}
+	switch strings.ToLower(strings.TrimSpace(cfg.LogLevel)) {
+	case "debug", "info", "warn", "warning", "error":
+	default:
+		return nil, fmt.Errorf("log_level must be 'debug', 'info', 'warn' or 'error', got '%s'", cfg.LogLevel)
+	}
+	if cfg.PlanStorageBackend != "json" && cfg.PlanStorageBackend != "sqlite" {
+		return nil, fmt.Errorf("plan_storage_backend must be 'json' or 'sqlite', got '%s'", cfg.PlanStorageBackend)
+	}
+
+	for chain, reserve := range cfg.AutoWithdraw.FeeReserve {
+		if reserve < 0 {
+			return nil, fmt.Errorf("auto_withdraw.fee_reserve.%s must not be negative, got %v", chain, reserve)
+		}
+	}
+
+	for _, route := range cfg.UnsupportedRoutes {
+		if source, dest, found := strings.Cut(route, ":"); !found || strings.TrimSpace(source) == "" || strings.TrimSpace(dest) == "" {
+			return nil, fmt.Errorf("invalid unsupported_routes entry '%s': expected <source chain>:<dest chain>", route)
+		}
+	}
+
+	for networkName, network := range cfg.AutoDeposit.EVM.Networks {
+		// Fees may be given in gwei instead of wei; everything downstream uses wei
+		if err := normalizeGwei(networkName, "gas_price", &network.GasPrice, network.GasPriceGwei); err != nil {
+			return nil, err
+		}
+		if err := normalizeGwei(networkName, "max_priority_fee", &network.MaxPriorityFee, network.MaxPriorityFeeGwei); err != nil {
+			return nil, err
+		}
+		if err := normalizeGwei(networkName, "max_fee", &network.MaxFee, network.MaxFeeGwei); err != nil {
+			return nil, err
+		}
+		cfg.AutoDeposit.EVM.Networks[networkName] = network
+
+		switch strings.ToLower(network.L2) {
+		case "", L2FeeOptimism, L2FeeArbitrum:
+		default:
+			return nil, fmt.Errorf("auto_deposit.evm.networks.%s.l2 must be '%s' or '%s', got '%s'",
+				networkName, L2FeeOptimism, L2FeeArbitrum, network.L2)
+		}
+
+		switch strings.ToLower(network.FeeMode) {
+		case "", FeeModeLegacy, FeeModeEIP1559:
+		default:
+			return nil, fmt.Errorf("auto_deposit.evm.networks.%s.fee_mode must be '%s' or '%s', got '%s'",
+				networkName, FeeModeLegacy, FeeModeEIP1559, network.FeeMode)
+		}
+		if network.MaxPriorityFee != nil && *network.MaxPriorityFee < 0 {
+			return nil, fmt.Errorf("auto_deposit.evm.networks.%s.max_priority_fee must not be negative", networkName)
+		}
+		if network.MaxFee != nil && *network.MaxFee <= 0 {
+			return nil, fmt.Errorf("auto_deposit.evm.networks.%s.max_fee must be greater than 0", networkName)
+		}
+		if network.MaxPriorityFee != nil && network.MaxFee != nil && *network.MaxPriorityFee > *network.MaxFee {
+			return nil, fmt.Errorf("auto_deposit.evm.networks.%s.max_priority_fee must not exceed max_fee", networkName)
+		}
+	}
+
+	bitcoin := &cfg.AutoDeposit.Bitcoin
+	bitcoin.FeeRate = strings.ToLower(strings.TrimSpace(bitcoin.FeeRate))
+	if bitcoin.FeeRate != "" && bitcoin.FeeRate != BitcoinFeeRateAuto {
+		if rate, err := strconv.ParseFloat(bitcoin.FeeRate, 64); err != nil || rate < 0 || math.IsInf(rate, 0) {
+			return nil, fmt.Errorf("auto_deposit.bitcoin.fee_rate must be a rate in sat/vB (0 leaves it to the wallet) or '%s', got '%s'", BitcoinFeeRateAuto, bitcoin.FeeRate)
+		}
+	}
+	// estimatesmartfee only estimates up to 1008 blocks (about a week) ahead
+	if bitcoin.FeeConfTarget < 1 || bitcoin.FeeConfTarget > 1008 {
+		return nil, fmt.Errorf("auto_deposit.bitcoin.fee_conf_target must be between 1 and 1008 blocks, got %d", bitcoin.FeeConfTarget)
+	}
+	if bitcoin.MaxFeeRate <= 0 {
+		return nil, fmt.Errorf("auto_deposit.bitcoin.max_fee_rate must be greater than 0, got %v", bitcoin.MaxFeeRate)
+	}
+
+	// A signature can still land until its blockhash expires (about 90 seconds), so a shorter
+	// window could fail a deposit that later goes through and trade the amount twice
+	if d := cfg.AutoDeposit.Solana.DroppedAfter; d != 0 && d < 2*time.Minute {
+		return nil, fmt.Errorf("auto_deposit.solana.dropped_after must be 0 (disabled) or at least 2m, got %s", d)
+	}
+
+	for chain, threshold := range cfg.AutoDeposit.LowBalance {
+		if threshold < 0 {
+			return nil, fmt.Errorf("auto_deposit.low_balance.%s must not be negative, got %v", chain, threshold)
+		}
+	}
+
+	if cfg.StatsSnapshotPath != "" && cfg.StatsSnapshotInterval < 5 {
+		return nil, fmt.Errorf("stats_snapshot_interval must be at least 5 seconds, got %d", cfg.StatsSnapshotInterval)
+	}
+
+	if cfg.MaxQuoteDivergence < 0 || cfg.MaxQuoteDivergence > 100 {
+		return nil, fmt.Errorf("max_quote_divergence must be between 0 and 100, got %v", cfg.MaxQuoteDivergence)
+	}
+
+	if cfg.MaxPriceImpact < 0 || cfg.MaxPriceImpact > 100 {
+		return nil, fmt.Errorf("max_price_impact must be between 0 and 100, got %v", cfg.MaxPriceImpact)
+	}
+
+	if err := validateNotifications(cfg.Notifications); err != nil {
+		return nil, err
+	}
+
 	// Validate JWT token
 	if cfg.JWTToken == "" {
 		return nil, fmt.Errorf("JWT token not found. Please set NEAR_SWAP_JWT_TOKEN environment variable or create a .near-swap.yaml config file")
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// loadConfig runs Load against a .near-swap.yaml holding yaml, in a fresh working directory
+func loadConfig(t *testing.T, yaml string) (*Config, error) {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".near-swap.yaml"), []byte("jwt_token: test-token\n"+yaml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(wd)
+		viper.Reset()
+	})
+
+	viper.Reset()
+	return Load()
+}
+
+func TestValidateIntegratorSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{name: "unset"},
+		{name: "referral", cfg: Config{Referral: "my-app_1.0"}},
+		{name: "referral with spaces", cfg: Config{Referral: "my app"}, wantErr: "invalid referral"},
+		{name: "referral too long", cfg: Config{Referral: strings.Repeat("a", 65)}, wantErr: "invalid referral"},
+		{name: "fee", cfg: Config{AppFee: AppFeeConfig{Recipient: "fees.near", FeeBps: 10}}},
+		{name: "fee at cap", cfg: Config{AppFee: AppFeeConfig{Recipient: "fees.near", FeeBps: maxAppFeeBps}}},
+		{name: "fee over cap", cfg: Config{AppFee: AppFeeConfig{Recipient: "fees.near", FeeBps: maxAppFeeBps + 1}}, wantErr: "fee_bps must be between"},
+		{name: "whole swap as fee", cfg: Config{AppFee: AppFeeConfig{Recipient: "fees.near", FeeBps: 10000}}, wantErr: "fee_bps must be between"},
+		{name: "zero fee", cfg: Config{AppFee: AppFeeConfig{Recipient: "fees.near"}}, wantErr: "fee_bps must be between"},
+		{name: "negative fee", cfg: Config{AppFee: AppFeeConfig{Recipient: "fees.near", FeeBps: -5}}, wantErr: "fee_bps must be between"},
+		{name: "fee without recipient", cfg: Config{AppFee: AppFeeConfig{FeeBps: 10}}, wantErr: "recipient is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateIntegratorSettings(&tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSolanaDroppedAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "off by default"},
+		{name: "enabled", yaml: "auto_deposit:\n  solana:\n    dropped_after: 5m\n", want: 5 * time.Minute},
+		{name: "shorter than a blockhash lives", yaml: "auto_deposit:\n  solana:\n    dropped_after: 1m\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadConfig(t, tt.yaml)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error: %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.AutoDeposit.Solana.DroppedAfter != tt.want {
+				t.Errorf("dropped_after = %s, want %s", cfg.AutoDeposit.Solana.DroppedAfter, tt.want)
+			}
+		})
+	}
+}
+
+func TestBitcoinFeeRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		feeRate string // YAML value; empty leaves fee_rate unset
+		want    string
+		wantErr bool
+	}{
+		{name: "unset leaves it to the wallet", want: ""},
+		{name: "zero leaves it to the wallet", feeRate: "0", want: "0"},
+		{name: "fixed rate", feeRate: "2.5", want: "2.5"},
+		{name: "auto", feeRate: "auto", want: BitcoinFeeRateAuto},
+		{name: "auto in any case", feeRate: `" AUTO "`, want: BitcoinFeeRateAuto},
+		{name: "negative", feeRate: "-1", wantErr: true},
+		{name: "not a rate", feeRate: "fast", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			yaml := ""
+			if tt.feeRate != "" {
+				yaml = "auto_deposit:\n  bitcoin:\n    fee_rate: " + tt.feeRate + "\n"
+			}
+			cfg, err := loadConfig(t, yaml)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error: %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.AutoDeposit.Bitcoin.FeeRate != tt.want {
+				t.Errorf("fee_rate = %q, want %q", cfg.AutoDeposit.Bitcoin.FeeRate, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteDefaults(t *testing.T) {
+	tests := []struct {
+		name         string
+		yaml         string
+		wantSlippage int
+		wantDeadline time.Duration
+		wantErr      bool
+	}{
+		{name: "built-in defaults", wantSlippage: 100, wantDeadline: 24 * time.Hour},
+		{name: "configured", yaml: "default_slippage: 250\ndefault_deadline: 30m\n", wantSlippage: 250, wantDeadline: 30 * time.Minute},
+		{name: "slippage too high", yaml: "default_slippage: 5001\n", wantErr: true},
+		{name: "deadline too short", yaml: "default_deadline: 30s\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadConfig(t, tt.yaml)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error: %v", err, tt.wantErr)
+			}
+			if err == nil && (cfg.DefaultSlippage != tt.wantSlippage || cfg.DefaultDeadline != tt.wantDeadline) {
+				t.Errorf("defaults = %d bps, %s; want %d bps, %s", cfg.DefaultSlippage, cfg.DefaultDeadline, tt.wantSlippage, tt.wantDeadline)
+			}
+		})
+	}
+}
+
+func TestEVMFeesInGwei(t *testing.T) {
+	tests := []struct {
+		name                     string
+		fees                     string // YAML fee fields of the ethereum network
+		wantGasPrice, wantMaxFee int64  // Wei; 0 expects the field unset
+		wantErr                  string // Empty expects no error
+	}{
+		{name: "gas price in wei", fees: "gas_price: 20000000000", wantGasPrice: 20000000000},
+		{name: "gas price in gwei", fees: "gas_price_gwei: 20", wantGasPrice: 20000000000},
+		{name: "fractional gwei", fees: "gas_price_gwei: 0.5", wantGasPrice: 500000000},
+		{name: "max fee in gwei", fees: "max_fee_gwei: 35.5", wantMaxFee: 35500000000},
+		{name: "both units", fees: "gas_price: 20000000000\n        gas_price_gwei: 20", wantErr: "sets both gas_price (wei) and gas_price_gwei"},
+		{name: "max fee in both units", fees: "max_fee: 1\n        max_fee_gwei: 1", wantErr: "sets both max_fee (wei) and max_fee_gwei"},
+		{name: "negative gwei", fees: "gas_price_gwei: -1", wantErr: "gas_price_gwei is out of range"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadConfig(t, "auto_deposit:\n  evm:\n    networks:\n      ethereum:\n        "+tt.fees+"\n")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			network := cfg.AutoDeposit.EVM.Networks["ethereum"]
+			if got := weiOrZero(network.GasPrice); got != tt.wantGasPrice {
+				t.Errorf("gas_price = %d wei, want %d", got, tt.wantGasPrice)
+			}
+			if got := weiOrZero(network.MaxFee); got != tt.wantMaxFee {
+				t.Errorf("max_fee = %d wei, want %d", got, tt.wantMaxFee)
+			}
+		})
+	}
+}
+
+// weiOrZero dereferences an optional wei fee
+func weiOrZero(wei *int64) int64 {
+	if wei == nil {
+		return 0
+	}
+	return *wei
+}
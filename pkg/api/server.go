@@ -0,0 +1,288 @@
+// Package api exposes trading plan management over a small authenticated REST API
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"near-swap/pkg/plan"
+)
+
+// Server serves the plan management REST API backed by a plan Manager
+type Server struct {
+	manager *plan.Manager
+	token   string
+	checks  plan.CreateChecks // What new plans are checked against, as for `plan create`
+	mux     *http.ServeMux
+}
+
+// CreatePlanRequest is the JSON body accepted by POST /api/plans
+type CreatePlanRequest struct {
+	Name               string              `json:"name"`
+	Description        string              `json:"description,omitempty"`
+	SourceToken        string              `json:"source_token"`
+	DestToken          string              `json:"dest_token"`
+	SourceChain        string              `json:"source_chain"`
+	DestChain          string              `json:"dest_chain"`
+	TotalAmount        string              `json:"total_amount"`
+	AmountPerTrade     string              `json:"amount_per_trade,omitempty"`
+	AmountPerTradeDest string              `json:"amount_per_trade_dest,omitempty"`
+	AmountPerDay       string              `json:"amount_per_day"`
+	TriggerPrice       string              `json:"trigger_price"`
+	PriceCondition     plan.PriceCondition `json:"price_condition"`
+	RecipientAddr      string              `json:"recipient_addr"`
+	RefundAddr         string              `json:"refund_addr,omitempty"`
+	CancelBelow        string              `json:"cancel_below,omitempty"`
+	CancelAbove        string              `json:"cancel_above,omitempty"`
+	Ladder             []plan.LadderLevel  `json:"ladder,omitempty"`
+	AmountJitter       float64             `json:"amount_jitter,omitempty"`
+	WithdrawTo         string              `json:"withdraw_to,omitempty"`
+	PriceSmoothing     int                 `json:"price_smoothing,omitempty"`
+	SlippageBps        int                 `json:"slippage_bps,omitempty"`
+	QuoteDeadline      string              `json:"quote_deadline,omitempty"` // Duration such as "30m"
+	NormalizeName      bool                `json:"normalize_name,omitempty"`
+	MaxQuoteDivergence float64             `json:"max_quote_divergence,omitempty"`
+	MaxPriceImpact     float64             `json:"max_price_impact,omitempty"`
+	DustThreshold      string              `json:"dust_threshold,omitempty"`
+	VerifyFirst        bool                `json:"verify_first,omitempty"`
+	VerifyAmount       string              `json:"verify_amount,omitempty"`
+	Force              bool                `json:"force,omitempty"`
+	SkipMarketCheck    bool                `json:"skip_market_check,omitempty"`
+	AllowSwappedAddrs  bool                `json:"allow_swapped_addresses,omitempty"`
+}
+
+// NewServer creates an API server. A non-empty bearer token is required. Plans created through
+// it are checked against checks, as `plan create` checks them.
+func NewServer(manager *plan.Manager, token string, checks plan.CreateChecks) (*Server, error) {
+	if token == "" {
+		return nil, fmt.Errorf("API bearer token is required")
+	}
+
+	s := &Server{
+		manager: manager,
+		token:   token,
+		checks:  checks,
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /api/health", s.handleHealth)
+	s.mux.HandleFunc("GET /api/plans", s.handleListPlans)
+	s.mux.HandleFunc("POST /api/plans", s.handleCreatePlan)
+	s.mux.HandleFunc("GET /api/plans/{name}", s.handleGetPlan)
+	s.mux.HandleFunc("DELETE /api/plans/{name}", s.handleDeletePlan)
+	s.mux.HandleFunc("POST /api/plans/{name}/start", s.handleStartPlan)
+	s.mux.HandleFunc("POST /api/plans/{name}/stop", s.handleStopPlan)
+	s.mux.HandleFunc("GET /api/plans/{name}/history", s.handleHistory)
+
+	return s, nil
+}
+
+// ServeHTTP authenticates the request and dispatches it
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// authorized checks the Authorization header against the configured token
+func (s *Server) authorized(r *http.Request) bool {
+	header := r.Header.Get("Authorization")
+	provided, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(s.token)) == 1
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleListPlans(w http.ResponseWriter, r *http.Request) {
+	var plans []*plan.TradingPlan
+	if status := r.URL.Query().Get("status"); status != "" {
+		plans = s.manager.ListPlansByStatus(plan.PlanStatus(status))
+	} else {
+		plans = s.manager.ListPlans()
+	}
+
+	summaries := make([]*plan.PlanSummary, len(plans))
+	for i, p := range plans {
+		summaries[i] = p.ToSummary()
+	}
+
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+func (s *Server) handleCreatePlan(w http.ResponseWriter, r *http.Request) {
+	var req CreatePlanRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("plan name is required"))
+		return
+	}
+
+	var deadline time.Duration
+	if req.QuoteDeadline != "" {
+		var err error
+		if deadline, err = time.ParseDuration(req.QuoteDeadline); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid quote_deadline: %w", err))
+			return
+		}
+	}
+
+	checks := s.checks
+	checks.AllowSwappedAddresses = req.AllowSwappedAddrs
+	if req.SkipMarketCheck {
+		checks.MarketClient = nil
+	}
+
+	created, _, err := s.manager.CreateValidatedPlan(r.Context(), plan.PlanSpec{
+		Name:           req.Name,
+		Description:    req.Description,
+		SourceToken:    req.SourceToken,
+		DestToken:      req.DestToken,
+		SourceChain:    req.SourceChain,
+		DestChain:      req.DestChain,
+		TotalAmount:    req.TotalAmount,
+		AmountPerTrade: req.AmountPerTrade,
+		AmountPerDay:   req.AmountPerDay,
+		TriggerPrice:   req.TriggerPrice,
+		PriceCondition: req.PriceCondition,
+		RecipientAddr:  req.RecipientAddr,
+		RefundAddr:     req.RefundAddr,
+		Options: plan.CreatePlanOptions{
+			Force:              req.Force,
+			AmountPerTradeDest: req.AmountPerTradeDest,
+			CancelBelow:        req.CancelBelow,
+			CancelAbove:        req.CancelAbove,
+			Ladder:             req.Ladder,
+			AmountJitter:       req.AmountJitter,
+			WithdrawTo:         req.WithdrawTo,
+			PriceSmoothing:     req.PriceSmoothing,
+			SlippageBps:        req.SlippageBps,
+			QuoteDeadline:      deadline,
+			NormalizeName:      req.NormalizeName,
+			MaxQuoteDivergence: req.MaxQuoteDivergence,
+			MaxPriceImpact:     req.MaxPriceImpact,
+			DustThreshold:      req.DustThreshold,
+			VerifyFirst:        req.VerifyFirst,
+			VerifyAmount:       req.VerifyAmount,
+		},
+	}, checks)
+	var validationErr *plan.PlanValidationError
+	if errors.As(err, &validationErr) {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error(), "findings": validationErr.Findings})
+		return
+	}
+	if err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, created)
+}
+
+func (s *Server) handleGetPlan(w http.ResponseWriter, r *http.Request) {
+	p, err := s.manager.GetPlan(r.PathValue("name"))
+	if err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) handleDeletePlan(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := s.manager.DeletePlan(name); err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"name": name, "status": "deleted"})
+}
+
+func (s *Server) handleStartPlan(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	force, _ := strconv.ParseBool(r.URL.Query().Get("force"))
+
+	if err := s.manager.StartPlan(name, force); err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+
+	s.writePlanSummary(w, name)
+}
+
+func (s *Server) handleStopPlan(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := s.manager.StopPlan(name); err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+
+	s.writePlanSummary(w, name)
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	history, err := s.manager.GetExecutionHistory(r.PathValue("name"))
+	if err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, history)
+}
+
+// writePlanSummary responds with the current summary of a plan
+func (s *Server) writePlanSummary(w http.ResponseWriter, name string) {
+	p, err := s.manager.GetPlan(name)
+	if err != nil {
+		writeError(w, statusForError(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, p.ToSummary())
+}
+
+// statusForError maps manager errors to HTTP status codes
+func statusForError(err error) int {
+	var conflict *plan.PlanConflictError
+	switch {
+	case errors.Is(err, plan.ErrPlanNotFound):
+		return http.StatusNotFound
+	case errors.As(err, &conflict), errors.Is(err, plan.ErrPlanExists),
+		errors.Is(err, plan.ErrPlanActive), errors.Is(err, plan.ErrPlanCompleted):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(body)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"near-swap/pkg/plan"
+)
+
+func TestStatusForError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "not found", err: fmt.Errorf("plan 'p' %w", plan.ErrPlanNotFound), want: http.StatusNotFound},
+		{name: "name taken", err: fmt.Errorf("plan 'p' %w", plan.ErrPlanExists), want: http.StatusConflict},
+		{name: "already active", err: fmt.Errorf("plan 'p' %w", plan.ErrPlanActive), want: http.StatusConflict},
+		{name: "already completed", err: fmt.Errorf("plan 'p' %w", plan.ErrPlanCompleted), want: http.StatusConflict},
+		{name: "overlapping plan", err: &plan.PlanConflictError{}, want: http.StatusConflict},
+		{name: "message mentioning already", err: errors.New("amount already exceeds the total"), want: http.StatusBadRequest},
+		{name: "validation", err: errors.New("invalid total amount"), want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statusForError(tt.err); got != tt.want {
+				t.Errorf("statusForError(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+const testToken = "secret"
+
+// newTestServer returns an API server over empty plan storage in a temp dir
+func newTestServer(t *testing.T, checks plan.CreateChecks) (*Server, *plan.Manager) {
+	t.Helper()
+	manager, err := plan.NewManager(filepath.Join(t.TempDir(), "plans.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewServer(manager, testToken, checks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, manager
+}
+
+// do sends an authenticated request to the server
+func do(server *Server, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCreatePlan(t *testing.T) {
+	const btcAddress = "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+	const base = `"source_token":"BTC","dest_token":"USDC","source_chain":"btc","dest_chain":"near",
+		"total_amount":"1","amount_per_trade":"0.1","amount_per_day":"0.3",
+		"trigger_price":"60000","price_condition":"below"`
+
+	tests := []struct {
+		name          string
+		body          string
+		wantCode      int
+		wantRecipient string
+		wantRefund    string
+		wantDeadline  time.Duration
+		wantFindings  bool
+	}{
+		{
+			name:          "default addresses",
+			body:          `{"name":"defaults",` + base + `}`,
+			wantCode:      http.StatusCreated,
+			wantRecipient: "default.near",
+			wantRefund:    btcAddress,
+		},
+		{
+			name:          "refund defaults to recipient",
+			body:          `{"name":"refund",` + base + `,"recipient_addr":"me.near","refund_addr":""}`,
+			wantCode:      http.StatusCreated,
+			wantRecipient: "me.near",
+			wantRefund:    btcAddress,
+		},
+		{
+			name:     "swapped addresses",
+			body:     `{"name":"swapped",` + base + `,"recipient_addr":"` + btcAddress + `","refund_addr":"me.near"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:          "swapped addresses allowed",
+			body:          `{"name":"allowed",` + base + `,"recipient_addr":"` + btcAddress + `","refund_addr":"me.near","allow_swapped_addresses":true}`,
+			wantCode:      http.StatusCreated,
+			wantRecipient: btcAddress,
+			wantRefund:    "me.near",
+		},
+		{
+			name:          "quote deadline and verification",
+			body:          `{"name":"verify",` + base + `,"quote_deadline":"30m","verify_first":true,"verify_amount":"0.001"}`,
+			wantCode:      http.StatusCreated,
+			wantRecipient: "default.near",
+			wantRefund:    btcAddress,
+			wantDeadline:  30 * time.Minute,
+		},
+		{
+			name:     "same token",
+			body:     `{"name":"same",` + strings.NewReplacer(`"USDC"`, `"btc"`, `"near"`, `"btc"`).Replace(base) + `}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:     "bad quote deadline",
+			body:     `{"name":"deadline",` + base + `,"quote_deadline":"soon"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			// Naming a server environment variable would send its value to the 1Click API
+			name:     "plan API token variable",
+			body:     `{"name":"token",` + base + `,"api_token_env":"AWS_SECRET_ACCESS_KEY"}`,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:         "verification below the minimum send",
+			body:         `{"name":"tiny",` + base + `,"verify_first":true,"verify_amount":"0.00000001"}`,
+			wantCode:     http.StatusBadRequest,
+			wantFindings: true,
+		},
+	}
+
+	server, manager := newTestServer(t, plan.CreateChecks{DefaultRecipient: "default.near", DefaultRefundTo: btcAddress})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(server, http.MethodPost, "/api/plans", tt.body)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantCode != http.StatusCreated {
+				var body struct {
+					Error    string         `json:"error"`
+					Findings []plan.Finding `json:"findings"`
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.Error == "" {
+					t.Fatalf("error body = %s", rec.Body.String())
+				}
+				if tt.wantFindings != (len(body.Findings) > 0) {
+					t.Errorf("findings = %v, want some: %v", body.Findings, tt.wantFindings)
+				}
+				return
+			}
+
+			var created plan.TradingPlan
+			if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+				t.Fatal(err)
+			}
+			stored, err := manager.GetPlan(created.Name)
+			if err != nil {
+				t.Fatalf("plan not stored: %v", err)
+			}
+			if stored.RecipientAddr != tt.wantRecipient || stored.RefundAddr != tt.wantRefund {
+				t.Errorf("addresses = %s / %s, want %s / %s", stored.RecipientAddr, stored.RefundAddr, tt.wantRecipient, tt.wantRefund)
+			}
+			var deadline time.Duration
+			if stored.QuoteDeadline != "" {
+				deadline, _ = time.ParseDuration(stored.QuoteDeadline)
+			}
+			if deadline != tt.wantDeadline {
+				t.Errorf("quote deadline = %q, want %v", stored.QuoteDeadline, tt.wantDeadline)
+			}
+		})
+	}
+}
+
+func TestPlanLifecycleConflicts(t *testing.T) {
+	server, _ := newTestServer(t, plan.CreateChecks{DefaultRecipient: "me.near"})
+	body := `{"name":"p","source_token":"BTC","dest_token":"USDC","source_chain":"btc","dest_chain":"near",
+		"total_amount":"1","amount_per_trade":"0.1","amount_per_day":"0.3","trigger_price":"60000","price_condition":"below"}`
+
+	steps := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, "/api/plans", body, http.StatusCreated},
+		{http.MethodPost, "/api/plans", body, http.StatusConflict},
+		{http.MethodPost, "/api/plans/p/start", "", http.StatusOK},
+		{http.MethodPost, "/api/plans/p/start", "", http.StatusConflict},
+		{http.MethodPost, "/api/plans/missing/start", "", http.StatusNotFound},
+	}
+	for _, step := range steps {
+		if rec := do(server, step.method, step.path, step.body); rec.Code != step.want {
+			t.Fatalf("%s %s = %d, want %d: %s", step.method, step.path, rec.Code, step.want, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// MaxClockSkew is how far the local clock may drift from the API server before
+// daily limits and quote deadlines become unreliable
+const MaxClockSkew = 2 * time.Minute
+
+// ClockSkew compares the local clock with the API server's Date header.
+// A positive result means the local clock is ahead of the server.
+func (c *OneClickClient) ClockSkew() (time.Duration, error) {
+	if err := c.limiter.Wait(c.ctx); err != nil {
+		return 0, err
+	}
+	sent := time.Now()
+	_, httpResp, err := c.client.OneClickAPI.GetTokens(c.ctx).Execute()
+	received := time.Now()
+	if httpResp == nil {
+		return 0, fmt.Errorf("failed to reach API: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	// Compare against the midpoint of the request to discount network latency
+	local := sent.Add(received.Sub(sent) / 2)
+	return skewFromDateHeader(httpResp.Header.Get("Date"), local)
+}
+
+// skewFromDateHeader computes local minus server time from an HTTP Date header
+func skewFromDateHeader(dateHeader string, local time.Time) (time.Duration, error) {
+	if dateHeader == "" {
+		return 0, fmt.Errorf("API response has no Date header")
+	}
+
+	serverTime, err := http.ParseTime(dateHeader)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Date header %q: %w", dateHeader, err)
+	}
+
+	// The Date header has one-second resolution
+	return local.Sub(serverTime).Truncate(time.Second), nil
+}
+
+// ClockSkewWarning returns a warning when skew exceeds MaxClockSkew, or an empty string
+func ClockSkewWarning(skew time.Duration) string {
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs <= MaxClockSkew {
+		return ""
+	}
+
+	direction := "ahead of"
+	if skew < 0 {
+		direction = "behind"
+	}
+	return fmt.Sprintf("local clock is %s %s the API server; daily limits and quote deadlines may misbehave. Sync your system clock (e.g. enable NTP)",
+		abs, direction)
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSkewFromDateHeader(t *testing.T) {
+	server := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		header  string
+		local   time.Time
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "in sync", header: server.Format(http.TimeFormat), local: server.Add(400 * time.Millisecond)},
+		{name: "local ahead", header: server.Format(http.TimeFormat), local: server.Add(5 * time.Minute), want: 5 * time.Minute},
+		{name: "local behind", header: server.Format(http.TimeFormat), local: server.Add(-3 * time.Minute), want: -3 * time.Minute},
+		{name: "no header", local: server, wantErr: true},
+		{name: "bad header", header: "yesterday", local: server, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := skewFromDateHeader(tt.header, tt.local)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s: skew = %s, %v; want %s, error: %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestClockSkewWarning(t *testing.T) {
+	tests := []struct {
+		skew time.Duration
+		want string // Substring of the warning; empty when no warning is expected
+	}{
+		{skew: 0},
+		{skew: MaxClockSkew},
+		{skew: -MaxClockSkew},
+		{skew: 5 * time.Minute, want: "local clock is 5m0s ahead of the API server"},
+		{skew: -time.Hour, want: "local clock is 1h0m0s behind the API server"},
+	}
+
+	for _, tt := range tests {
+		got := ClockSkewWarning(tt.skew)
+		if (got == "") != (tt.want == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("ClockSkewWarning(%s) = %q, want %q", tt.skew, got, tt.want)
+		}
+	}
+}
+
+func TestClockSkew(t *testing.T) {
+	// A server whose clock is ten minutes behind ours
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(-10*time.Minute).UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	skew, err := NewOneClickClientWithBaseURL("test-token", server.URL).ClockSkew()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skew < 9*time.Minute || skew > 11*time.Minute {
+		t.Errorf("skew = %s, want about 10m", skew)
+	}
+	if warning := ClockSkewWarning(skew); !strings.Contains(warning, "ahead of the API server") {
+		t.Errorf("warning = %q, want the local clock reported ahead", warning)
+	}
+}
@@ -3,9 +3,11 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -14,14 +16,71 @@ import (
 	"near-swap/pkg/types"
 )
 
+// Quote defaults used when neither the request nor the client overrides them
+const (
+	DefaultSlippageBps   = 100            // 1%
+	DefaultQuoteDeadline = 24 * time.Hour // Deposit window for generated quotes
+)
+
+// Bounds for a per-swap or per-plan slippage tolerance, in basis points
+const (
+	MinSlippageBps = 1    // 0.01%
+	MaxSlippageBps = 5000 // 50%
+)
+
+// Bounds for a quote deadline, the window in which the deposit must arrive
+const (
+	MinQuoteDeadline = time.Minute
+	MaxQuoteDeadline = 7 * 24 * time.Hour
+)
+
+// ValidateQuoteDeadline checks that a quote deadline is within bounds
+func ValidateQuoteDeadline(deadline time.Duration) error {
+	if deadline < MinQuoteDeadline || deadline > MaxQuoteDeadline {
+		return fmt.Errorf("quote deadline must be between %s and %s, got %s", MinQuoteDeadline, MaxQuoteDeadline, deadline)
+	}
+	return nil
+}
+
+// ValidateSlippage checks that a slippage tolerance in basis points is within bounds
+func ValidateSlippage(bps int) error {
+	if bps < MinSlippageBps || bps > MaxSlippageBps {
+		return fmt.Errorf("slippage must be between %d and %d basis points, got %d", MinSlippageBps, MaxSlippageBps, bps)
+	}
+	return nil
+}
+
+// ErrUnauthorized is returned (wrapped) when the API rejects the JWT token with a 401 or 403
+var ErrUnauthorized = errors.New("API rejected the JWT token")
+
+// unauthorizedError returns an error wrapping ErrUnauthorized with guidance when the
+// response is a 401 or 403, and nil otherwise
+func unauthorizedError(httpResp *http.Response) error {
+	if httpResp == nil {
+		return nil
+	}
+	if httpResp.StatusCode != http.StatusUnauthorized && httpResp.StatusCode != http.StatusForbidden {
+		return nil
+	}
+	return fmt.Errorf("%w (status %d): it may be expired or invalid; refresh NEAR_SWAP_JWT_TOKEN (or jwt_token in your config)",
+		ErrUnauthorized, httpResp.StatusCode)
+}
+
 // OneClickClient wraps the 1Click SDK
 type OneClickClient struct {
 	client *oneclick.APIClient
 	ctx    context.Context
+
+	slippageBps int           // Default slippage tolerance for quotes (basis points)
+	deadline    time.Duration // Default quote deadline
+	routes      routeMatrix   // Chain pairs known not to route
+	maxRetries  int           // Retries of transient API failures (0 fails on the first error)
+	tokens      tokenCache    // Supported token list, shared by every lookup
+	limiter     *RateLimiter  // Paces API calls (nil doesn't)
 }
 
 // NewOneClickClient creates a new 1Click API client
-func NewOneClickClient(jwtToken string) *OneClickClient {
+func NewOneClickClient(jwtToken string, opts ...Option) *OneClickClient {
 	config := oneclick.NewConfiguration()
 
 	// Create authenticated context
@@ -29,15 +88,65 @@ func NewOneClickClient(jwtToken string) *OneClickClient {
 
 	client := oneclick.NewAPIClient(config)
 
-	return &OneClickClient{
-		client: client,
-		ctx:    ctx,
+	c := &OneClickClient{
+		client:      client,
+		ctx:         ctx,
+		slippageBps: DefaultSlippageBps,
+		deadline:    DefaultQuoteDeadline,
+		tokens:      tokenCache{ttl: DefaultTokenCacheTTL},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// authContext returns ctx carrying the client's API token
+func (c *OneClickClient) authContext(ctx context.Context) context.Context {
+	if ctx == c.ctx {
+		return ctx
 	}
+	return context.WithValue(ctx, oneclick.ContextAccessToken, c.ctx.Value(oneclick.ContextAccessToken))
 }
 
-// GetSupportedTokens retrieves all supported tokens
+// SetQuoteDefaults sets the slippage (basis points) and deadline used by GetQuote when a
+// request doesn't specify its own. Zero values keep the current defaults.
+func (c *OneClickClient) SetQuoteDefaults(slippageBps int, deadline time.Duration) {
+	if slippageBps > 0 {
+		c.slippageBps = slippageBps
+	}
+	if deadline > 0 {
+		c.deadline = deadline
+	}
+}
+
+// NewOneClickClientWithBaseURL creates a 1Click API client targeting a custom base URL
+// (e.g. a staging deployment or a local mock server)
+func NewOneClickClientWithBaseURL(jwtToken, baseURL string, opts ...Option) *OneClickClient {
+	c := NewOneClickClient(jwtToken, opts...)
+	if baseURL != "" {
+		c.client.GetConfig().Servers = oneclick.ServerConfigurations{
+			{URL: strings.TrimSuffix(baseURL, "/")},
+		}
+	}
+	return c
+}
+
+// GetSupportedTokens retrieves all supported tokens, from the token cache while it is fresh
 func (c *OneClickClient) GetSupportedTokens() ([]oneclick.TokenResponse, error) {
-	resp, httpResp, err := c.client.OneClickAPI.GetTokens(c.ctx).Execute()
+	return c.getSupportedTokens(c.ctx)
+}
+
+// fetchSupportedTokens retrieves all supported tokens from the API, giving up when ctx is cancelled
+func (c *OneClickClient) fetchSupportedTokens(ctx context.Context) ([]oneclick.TokenResponse, error) {
+	var resp []oneclick.TokenResponse
+	httpResp, err := c.withRetry(ctx, func() (httpResp *http.Response, err error) {
+		resp, httpResp, err = c.client.OneClickAPI.GetTokens(ctx).Execute()
+		return httpResp, err
+	})
+	if authErr := unauthorizedError(httpResp); authErr != nil {
+		return nil, authErr
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tokens: %w", err)
 	}
@@ -52,7 +161,12 @@ func (c *OneClickClient) GetSupportedTokens() ([]oneclick.TokenResponse, error)
 
 // FindToken searches for a token by symbol across all chains
 func (c *OneClickClient) FindToken(symbol string) (*oneclick.TokenResponse, error) {
-	tokens, err := c.GetSupportedTokens()
+	return c.findToken(c.ctx, symbol)
+}
+
+// findToken searches for a token by symbol across all chains, giving up when ctx is cancelled
+func (c *OneClickClient) findToken(ctx context.Context, symbol string) (*oneclick.TokenResponse, error) {
+	tokens, err := c.getSupportedTokens(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -78,7 +192,12 @@ func (c *OneClickClient) FindToken(symbol string) (*oneclick.TokenResponse, erro
 
 // FindTokenOnChain searches for a token by symbol on a specific chain
 func (c *OneClickClient) FindTokenOnChain(symbol, chain string) (*oneclick.TokenResponse, error) {
-	tokens, err := c.GetSupportedTokens()
+	return c.findTokenOnChain(c.ctx, symbol, chain)
+}
+
+// findTokenOnChain searches for a token by symbol on a specific chain, giving up when ctx is cancelled
+func (c *OneClickClient) findTokenOnChain(ctx context.Context, symbol, chain string) (*oneclick.TokenResponse, error) {
+	tokens, err := c.getSupportedTokens(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -98,36 +217,61 @@ func (c *OneClickClient) FindTokenOnChain(symbol, chain string) (*oneclick.Token
 
 // GetQuote generates a swap quote
 func (c *OneClickClient) GetQuote(req *types.SwapRequest) (*oneclick.QuoteResponse, error) {
+	return c.GetQuoteContext(c.ctx, req)
+}
+
+// GetQuoteContext generates a swap quote, giving up when ctx is cancelled
+func (c *OneClickClient) GetQuoteContext(ctx context.Context, req *types.SwapRequest) (*oneclick.QuoteResponse, error) {
+	ctx = c.authContext(ctx)
+
 	// Find source and destination tokens
 	var sourceToken, destToken *oneclick.TokenResponse
 	var err error
 
 	if req.SourceChain != "" {
-		sourceToken, err = c.FindTokenOnChain(req.SourceToken, req.SourceChain)
+		sourceToken, err = c.findTokenOnChain(ctx, req.SourceToken, req.SourceChain)
 	} else {
-		sourceToken, err = c.FindToken(req.SourceToken)
+		sourceToken, err = c.findToken(ctx, req.SourceToken)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("source token error: %w", err)
 	}
 
 	if req.DestChain != "" {
-		destToken, err = c.FindTokenOnChain(req.DestToken, req.DestChain)
+		destToken, err = c.findTokenOnChain(ctx, req.DestToken, req.DestChain)
 	} else {
-		destToken, err = c.FindToken(req.DestToken)
+		destToken, err = c.findToken(ctx, req.DestToken)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("destination token error: %w", err)
 	}
 
+	// A swap into the same asset would do nothing but burn a quote
+	if err := checkDistinctAssets(sourceToken, destToken); err != nil {
+		return nil, err
+	}
+
+	// Fail fast on chain pairs that are known not to route
+	if err := c.CheckRoute(sourceToken.GetBlockchain(), destToken.GetBlockchain()); err != nil {
+		return nil, err
+	}
+
 	// Convert amount to smallest unit (wei-like format)
 	amountFloat, err := strconv.ParseFloat(req.Amount, 64)
 	if err != nil {
 		return nil, fmt.Errorf("invalid amount: %w", err)
 	}
 
+	// EXACT_OUTPUT quotes are denominated in the destination token
+	swapType := "EXACT_INPUT"
+	amountDecimals := sourceToken.GetDecimals()
+	if req.ExactOutput {
+		swapType = "EXACT_OUTPUT"
+		amountDecimals = destToken.GetDecimals()
+	}
+
 	// Multiply by 10^decimals to get smallest unit
-	smallestUnit := amountFloat * math.Pow(10, float64(sourceToken.GetDecimals()))
+	smallestUnit := amountFloat * math.Pow(10, float64(amountDecimals))
 	amountStr := fmt.Sprintf("%.0f", smallestUnit)
 
 	// Set recipient - required for the API
@@ -142,14 +286,22 @@ func (c *OneClickClient) GetQuote(req *types.SwapRequest) (*oneclick.QuoteRespon
 		refundTo = recipient
 	}
 
-	// Calculate deadline (24 hours from now)
-	deadline := time.Now().Add(24 * time.Hour)
+	// Per-request settings override the client defaults
+	slippageBps := c.slippageBps
+	if req.SlippageBps > 0 {
+		slippageBps = req.SlippageBps
+	}
+	deadlineWindow := c.deadline
+	if req.Deadline > 0 {
+		deadlineWindow = req.Deadline
+	}
+	deadline := time.Now().Add(deadlineWindow)
 
 	// Build quote request with all required parameters
 	quoteReq := oneclick.NewQuoteRequest(
-		false,                     // dry - false to get a real deposit address
-		"EXACT_INPUT",             // swapType
-		100,                       // slippageTolerance (1%)
+		req.Dry,                   // dry - true previews the quote without a deposit address
+		swapType,                  // swapType
+		float32(slippageBps),      // slippageTolerance (basis points)
 		sourceToken.GetAssetId(),  // originAsset
 		"ORIGIN_CHAIN",            // depositType
 		destToken.GetAssetId(),    // destinationAsset
@@ -161,8 +313,18 @@ func (c *OneClickClient) GetQuote(req *types.SwapRequest) (*oneclick.QuoteRespon
 		deadline,                  // deadline
 	)
 
+	// Attach optional integrator metadata
+	applyIntegratorMetadata(quoteReq, req)
+
 	// Execute quote request
-	resp, httpResp, err := c.client.OneClickAPI.GetQuote(c.ctx).QuoteRequest(*quoteReq).Execute()
+	var resp *oneclick.QuoteResponse
+	httpResp, err := c.withRetry(ctx, func() (httpResp *http.Response, err error) {
+		resp, httpResp, err = c.client.OneClickAPI.GetQuote(ctx).QuoteRequest(*quoteReq).Execute()
+		return httpResp, err
+	})
+	if authErr := unauthorizedError(httpResp); authErr != nil {
+		return nil, authErr
+	}
 	if err != nil {
 		// Try to extract the actual error message from the response
 		if httpResp != nil {
@@ -173,6 +335,14 @@ func (c *OneClickClient) GetQuote(req *types.SwapRequest) (*oneclick.QuoteRespon
 				var errorResp map[string]interface{}
 				if jsonErr := json.Unmarshal(bodyBytes, &errorResp); jsonErr == nil {
 					if message, ok := errorResp["message"].(string); ok {
+						if isUnroutableMessage(message) {
+							c.learnUnsupportedRoute(sourceToken.GetBlockchain(), destToken.GetBlockchain(), message)
+							return nil, &UnsupportedRouteError{
+								SourceChain: sourceToken.GetBlockchain(),
+								DestChain:   destToken.GetBlockchain(),
+								Reason:      message,
+							}
+						}
 						return nil, fmt.Errorf("API error (status %d): %s", httpResp.StatusCode, message)
 					}
 					if errors, ok := errorResp["errors"]; ok {
@@ -200,9 +370,26 @@ func (c *OneClickClient) GetQuote(req *types.SwapRequest) (*oneclick.QuoteRespon
 	return resp, nil
 }
 
+// applyIntegratorMetadata sets the referral and app fee on a quote request when configured
+func applyIntegratorMetadata(quoteReq *oneclick.QuoteRequest, req *types.SwapRequest) {
+	if req.Referral != "" {
+		quoteReq.SetReferral(req.Referral)
+	}
+	if req.AppFeeRecipient != "" && req.AppFeeBps > 0 {
+		quoteReq.SetAppFees([]oneclick.AppFee{*oneclick.NewAppFee(req.AppFeeRecipient, req.AppFeeBps)})
+	}
+}
+
 // GetSwapStatus checks the execution status of a swap
 func (c *OneClickClient) GetSwapStatus(depositAddress string) (*oneclick.GetExecutionStatusResponse, error) {
-	resp, httpResp, err := c.client.OneClickAPI.GetExecutionStatus(c.ctx).DepositAddress(depositAddress).Execute()
+	var resp *oneclick.GetExecutionStatusResponse
+	httpResp, err := c.withRetry(c.ctx, func() (httpResp *http.Response, err error) {
+		resp, httpResp, err = c.client.OneClickAPI.GetExecutionStatus(c.ctx).DepositAddress(depositAddress).Execute()
+		return httpResp, err
+	})
+	if authErr := unauthorizedError(httpResp); authErr != nil {
+		return nil, authErr
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get status: %w", err)
 	}
@@ -219,7 +406,13 @@ func (c *OneClickClient) GetSwapStatus(depositAddress string) (*oneclick.GetExec
 func (c *OneClickClient) SubmitDepositTx(depositAddress, txHash string) error {
 	req := oneclick.NewSubmitDepositTxRequest(depositAddress, txHash)
 
+	if err := c.limiter.Wait(c.ctx); err != nil {
+		return err
+	}
 	_, httpResp, err := c.client.OneClickAPI.SubmitDepositTx(c.ctx).SubmitDepositTxRequest(*req).Execute()
+	if authErr := unauthorizedError(httpResp); authErr != nil {
+		return authErr
+	}
 	if err != nil {
 		return fmt.Errorf("failed to submit deposit: %w", err)
 	}
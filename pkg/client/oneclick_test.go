@@ -0,0 +1,42 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUnauthorizedResponses(t *testing.T) {
+	tests := []struct {
+		status   int
+		wantAuth bool
+	}{
+		{status: http.StatusUnauthorized, wantAuth: true},
+		{status: http.StatusForbidden, wantAuth: true},
+		{status: http.StatusBadRequest},
+		{status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(tt.status)
+			w.Write([]byte(`{"message":"nope"}`))
+		}))
+
+		_, err := NewOneClickClientWithBaseURL("expired-token", server.URL).GetSwapStatus("deposit-address")
+		server.Close()
+		if err == nil {
+			t.Errorf("status %d: no error", tt.status)
+			continue
+		}
+		if errors.Is(err, ErrUnauthorized) != tt.wantAuth {
+			t.Errorf("status %d: error = %v, want ErrUnauthorized: %v", tt.status, err, tt.wantAuth)
+		}
+		if tt.wantAuth && !strings.Contains(err.Error(), "refresh NEAR_SWAP_JWT_TOKEN") {
+			t.Errorf("status %d: error = %v, want guidance to refresh the token", tt.status, err)
+		}
+	}
+}
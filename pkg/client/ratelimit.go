@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"math"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter paces 1Click API calls with a token bucket. One limiter can be shared by several
+// clients, such as the daemon's per-plan clients, so they draw on a single request budget.
+type RateLimiter struct {
+	limiter *rate.Limiter
+}
+
+// NewRateLimiter allows requestsPerSecond API calls on average, in bursts of up to one second's
+// worth. It returns nil, which never waits, when requestsPerSecond is not positive.
+func NewRateLimiter(requestsPerSecond float64) *RateLimiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	burst := int(math.Max(1, math.Ceil(requestsPerSecond)))
+	return &RateLimiter{limiter: rate.NewLimiter(rate.Limit(requestsPerSecond), burst)}
+}
+
+// Wait blocks until the next API call may be made, or ctx is cancelled. A nil limiter never waits.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	return l.limiter.Wait(ctx)
+}
+
+// WithRateLimit paces every API call the client makes, retries included, through limiter
+func WithRateLimit(limiter *RateLimiter) Option {
+	return func(c *OneClickClient) {
+		c.limiter = limiter
+	}
+}
+
+// RateLimiter returns the limiter pacing the client's API calls, or nil when they aren't paced
+func (c *OneClickClient) RateLimiter() *RateLimiter {
+	if c == nil {
+		return nil
+	}
+	return c.limiter
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		requestsPerSecond float64
+		wantNil           bool
+		wantBurst         int
+	}{
+		{requestsPerSecond: 0, wantNil: true},
+		{requestsPerSecond: -1, wantNil: true},
+		{requestsPerSecond: 0.5, wantBurst: 1},
+		{requestsPerSecond: 1, wantBurst: 1},
+		{requestsPerSecond: 2.5, wantBurst: 3},
+		{requestsPerSecond: 10, wantBurst: 10},
+	}
+
+	for _, tt := range tests {
+		l := NewRateLimiter(tt.requestsPerSecond)
+		if (l == nil) != tt.wantNil {
+			t.Errorf("NewRateLimiter(%v) = %v, want nil: %v", tt.requestsPerSecond, l, tt.wantNil)
+			continue
+		}
+		if l != nil && l.limiter.Burst() != tt.wantBurst {
+			t.Errorf("NewRateLimiter(%v) burst = %d, want %d", tt.requestsPerSecond, l.limiter.Burst(), tt.wantBurst)
+		}
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	var unlimited *RateLimiter
+	if err := unlimited.Wait(context.Background()); err != nil {
+		t.Fatalf("nil limiter waited: %v", err)
+	}
+
+	// The burst goes through at once, the call after it waits for a token
+	l := NewRateLimiter(20)
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited < 40*time.Millisecond {
+		t.Errorf("call past the burst went through after %s, want about 50ms", waited)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err == nil {
+		t.Error("Wait on a cancelled context should fail")
+	}
+}
+
+func TestSharedRateLimiterPacesEveryClient(t *testing.T) {
+	l := NewRateLimiter(1)
+	a := NewOneClickClient("a", WithRateLimit(l))
+	b := NewOneClickClient("b", WithRateLimit(l))
+	if a.RateLimiter() != l || b.RateLimiter() != l {
+		t.Fatal("clients don't share the limiter")
+	}
+
+	// a uses the only token in the burst, so b's call has to wait for the next one
+	call := func() (*http.Response, error) { return &http.Response{StatusCode: http.StatusOK}, nil }
+	if _, err := a.withRetry(context.Background(), call); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := b.withRetry(ctx, call); err == nil {
+		t.Error("second client wasn't paced by the shared limiter")
+	}
+
+	var unpaced *OneClickClient
+	if unpaced.RateLimiter() != nil {
+		t.Error("nil client has a limiter")
+	}
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Backoff between retries of a failed API call
+const (
+	retryBaseDelay = 500 * time.Millisecond // Delay before the first retry; doubles for each retry after it
+	retryMaxDelay  = 10 * time.Second       // Cap on the backoff delay
+	MaxRetryAfter  = time.Minute            // Longest Retry-After the client waits out; longer ones fail the call
+)
+
+// Option configures a OneClickClient
+type Option func(*OneClickClient)
+
+// WithRetry retries API calls that fail with a connection error, a 429 or a 5xx response up
+// to maxRetries times, with exponential backoff and jitter. Other 4xx responses (an invalid
+// token or request) are never retried.
+func WithRetry(maxRetries int) Option {
+	return func(c *OneClickClient) {
+		if maxRetries > 0 {
+			c.maxRetries = maxRetries
+		}
+	}
+}
+
+// withRetry runs call until it succeeds, fails with an error that isn't transient, or the
+// client's retries are used up, and returns the last response and error. call is expected to
+// store the decoded result itself. Waiting between retries stops when ctx is cancelled.
+func (c *OneClickClient) withRetry(ctx context.Context, call func() (*http.Response, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		httpResp, err := call()
+		if attempt >= c.maxRetries || !retryable(httpResp, err) {
+			return httpResp, err
+		}
+
+		delay := backoffDelay(attempt)
+		if httpResp != nil {
+			if after, ok := retryAfter(httpResp.Header.Get("Retry-After"), time.Now()); ok {
+				if after > MaxRetryAfter {
+					return httpResp, err
+				}
+				delay = after
+			}
+			httpResp.Body.Close()
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryable reports whether a failed call may succeed if repeated: connection errors, rate
+// limiting and server errors are transient, other responses are not
+func retryable(httpResp *http.Response, err error) bool {
+	if httpResp == nil {
+		return err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return httpResp.StatusCode == http.StatusTooManyRequests || httpResp.StatusCode >= 500
+}
+
+// backoffDelay returns the jittered delay before retry number attempt+1: somewhere between
+// half and all of retryBaseDelay doubled attempt times, capped at retryMaxDelay
+func backoffDelay(attempt int) time.Duration {
+	delay := retryMaxDelay
+	if attempt < 16 {
+		if d := retryBaseDelay << attempt; d < retryMaxDelay {
+			delay = d
+		}
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if wait := at.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{attempt: 0, min: 250 * time.Millisecond, max: 500 * time.Millisecond},
+		{attempt: 1, min: 500 * time.Millisecond, max: time.Second},
+		{attempt: 3, min: 2 * time.Second, max: 4 * time.Second},
+		{attempt: 5, min: 5 * time.Second, max: 10 * time.Second},
+		{attempt: 40, min: 5 * time.Second, max: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			if d := backoffDelay(tt.attempt); d < tt.min || d > tt.max {
+				t.Fatalf("backoffDelay(%d) = %s, want between %s and %s", tt.attempt, d, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		{header: ""},
+		{header: "30", want: 30 * time.Second, wantOK: true},
+		{header: "0", want: 0, wantOK: true},
+		{header: "-5"},
+		{header: "soon"},
+		{header: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second, wantOK: true},
+		{header: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("retryAfter(%q) = %s, %v; want %s, %v", tt.header, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int // 0 means no response
+		err    error
+		want   bool
+	}{
+		{name: "connection error", err: errors.New("connection refused"), want: true},
+		{name: "cancelled", err: context.Canceled},
+		{name: "deadline exceeded", err: context.DeadlineExceeded},
+		{name: "rate limited", status: http.StatusTooManyRequests, want: true},
+		{name: "server error", status: http.StatusBadGateway, want: true},
+		{name: "bad request", status: http.StatusBadRequest},
+		{name: "unauthorized", status: http.StatusUnauthorized},
+		{name: "success", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var httpResp *http.Response
+			if tt.status != 0 {
+				httpResp = &http.Response{StatusCode: tt.status}
+			}
+			if got := retryable(httpResp, tt.err); got != tt.want {
+				t.Errorf("retryable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// response returns a response with status code, carrying a Retry-After header when one is given
+func response(status int, retryAfter string) *http.Response {
+	header := http.Header{}
+	if retryAfter != "" {
+		header.Set("Retry-After", retryAfter)
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(""))}
+}
+
+func TestWithRetry(t *testing.T) {
+	type outcome struct {
+		resp *http.Response
+		err  error
+	}
+	errRefused := errors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		outcomes   []outcome // What each call returns, in order
+		wantCalls  int
+		wantStatus int // 0 expects no response
+		wantErr    bool
+	}{
+		{name: "first call succeeds", maxRetries: 3, outcomes: []outcome{{resp: response(200, "")}}, wantCalls: 1, wantStatus: 200},
+		{
+			name:       "server error then success",
+			maxRetries: 3,
+			outcomes:   []outcome{{resp: response(503, "0")}, {resp: response(200, "")}},
+			wantCalls:  2,
+			wantStatus: 200,
+		},
+		{
+			name:       "connection error then success",
+			maxRetries: 1,
+			outcomes:   []outcome{{err: errRefused}, {resp: response(200, "")}},
+			wantCalls:  2,
+			wantStatus: 200,
+		},
+		{
+			name:       "retries used up",
+			maxRetries: 2,
+			outcomes:   []outcome{{resp: response(429, "0")}, {resp: response(429, "0")}, {resp: response(429, "0")}},
+			wantCalls:  3,
+			wantStatus: 429,
+		},
+		{name: "no retries configured", outcomes: []outcome{{resp: response(503, "0")}}, wantCalls: 1, wantStatus: 503},
+		{name: "bad request is not retried", maxRetries: 3, outcomes: []outcome{{resp: response(400, "0")}}, wantCalls: 1, wantStatus: 400},
+		{name: "rejected token is not retried", maxRetries: 3, outcomes: []outcome{{resp: response(401, "0")}}, wantCalls: 1, wantStatus: 401},
+		{
+			name:       "Retry-After beyond the limit gives up",
+			maxRetries: 3,
+			outcomes:   []outcome{{resp: response(503, "120")}},
+			wantCalls:  1,
+			wantStatus: 503,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewOneClickClient("token", WithRetry(tt.maxRetries))
+			calls := 0
+			httpResp, err := c.withRetry(context.Background(), func() (*http.Response, error) {
+				o := tt.outcomes[calls]
+				calls++
+				return o.resp, o.err
+			})
+
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error: %v", err, tt.wantErr)
+			}
+			status := 0
+			if httpResp != nil {
+				status = httpResp.StatusCode
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestWithRetryStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := NewOneClickClient("token", WithRetry(5))
+	calls := 0
+	_, err := c.withRetry(ctx, func() (*http.Response, error) {
+		calls++
+		return nil, errors.New("connection refused")
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("error = %v after %d calls, want context.Canceled after 1", err, calls)
+	}
+}
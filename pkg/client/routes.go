@@ -0,0 +1,147 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
+)
+
+// CommonRouteTargets are the destination symbols probed by FindRoutes when none are given
+var CommonRouteTargets = []string{"USDC", "USDT", "ETH", "BTC", "SOL", "NEAR", "WNEAR", "ZEC", "XMR", "BNB", "DAI"}
+
+// routeProbeAccount is the Intents account used as recipient and refund address for dry route
+// quotes. Dry quotes never create a deposit address, so no funds can reach it.
+const routeProbeAccount = "near-swap-probe.near"
+
+// routeProbeConcurrency limits how many dry quotes are in flight at once
+const routeProbeConcurrency = 6
+
+// RouteResult is the outcome of probing one destination token
+type RouteResult struct {
+	Symbol    string `json:"symbol"`
+	Chain     string `json:"chain"`
+	AssetID   string `json:"asset_id"`
+	AmountOut string `json:"amount_out,omitempty"` // Quoted output for the probe amount
+	Error     string `json:"error,omitempty"`      // Why the route could not be quoted
+}
+
+// Viable reports whether the route returned a quote
+func (r RouteResult) Viable() bool {
+	return r.Error == ""
+}
+
+// ProbeRoute requests a dry quote for swapping amount of source into dest inside NEAR Intents
+// and returns the formatted output amount. It only checks that the pair is currently tradable;
+// chain-specific deposit and recipient addresses are not involved.
+func (c *OneClickClient) ProbeRoute(source, dest oneclick.TokenResponse, amount string) (string, error) {
+	amountFloat, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid amount: %w", err)
+	}
+	amountStr := fmt.Sprintf("%.0f", amountFloat*math.Pow(10, float64(source.GetDecimals())))
+
+	quoteReq := oneclick.NewQuoteRequest(
+		true, // dry - no deposit address is generated
+		"EXACT_INPUT",
+		float32(c.slippageBps),
+		source.GetAssetId(),
+		"INTENTS",
+		dest.GetAssetId(),
+		amountStr,
+		routeProbeAccount,
+		"INTENTS",
+		routeProbeAccount,
+		"INTENTS",
+		time.Now().Add(c.deadline),
+	)
+
+	if err := c.limiter.Wait(c.ctx); err != nil {
+		return "", err
+	}
+	resp, httpResp, err := c.client.OneClickAPI.GetQuote(c.ctx).QuoteRequest(*quoteReq).Execute()
+	if authErr := unauthorizedError(httpResp); authErr != nil {
+		return "", authErr
+	}
+	if err != nil {
+		if httpResp != nil {
+			return "", fmt.Errorf("API error (status %d)", httpResp.StatusCode)
+		}
+		return "", fmt.Errorf("failed to get quote from API: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	if resp == nil {
+		return "", fmt.Errorf("empty quote response")
+	}
+
+	quote := resp.GetQuote()
+	return quote.GetAmountOutFormatted(), nil
+}
+
+// FindRoutes probes dry quotes from source to every supported token whose symbol is in
+// targets (CommonRouteTargets when empty), concurrently, and returns one result per
+// destination sorted by chain and symbol. The source token itself is skipped.
+func (c *OneClickClient) FindRoutes(source oneclick.TokenResponse, targets []string, amount string) ([]RouteResult, error) {
+	tokens, err := c.GetSupportedTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(targets) == 0 {
+		targets = CommonRouteTargets
+	}
+	wanted := make(map[string]bool, len(targets))
+	for _, symbol := range targets {
+		wanted[strings.ToUpper(strings.TrimSpace(symbol))] = true
+	}
+
+	var candidates []oneclick.TokenResponse
+	for _, token := range tokens {
+		if token.GetAssetId() == source.GetAssetId() || !wanted[strings.ToUpper(token.GetSymbol())] {
+			continue
+		}
+		candidates = append(candidates, token)
+	}
+
+	results := make([]RouteResult, len(candidates))
+	sem := make(chan struct{}, routeProbeConcurrency)
+	var wg sync.WaitGroup
+
+	for i, dest := range candidates {
+		wg.Add(1)
+		go func(i int, dest oneclick.TokenResponse) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := RouteResult{
+				Symbol:  dest.GetSymbol(),
+				Chain:   dest.GetBlockchain(),
+				AssetID: dest.GetAssetId(),
+			}
+			amountOut, err := c.ProbeRoute(source, dest, amount)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.AmountOut = amountOut
+			}
+			results[i] = result
+		}(i, dest)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Chain != results[j].Chain {
+			return results[i].Chain < results[j].Chain
+		}
+		return results[i].Symbol < results[j].Symbol
+	})
+
+	return results, nil
+}
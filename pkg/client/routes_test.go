@@ -0,0 +1,70 @@
+package client_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"near-swap/pkg/mockserver"
+
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
+)
+
+func TestFindRoutes(t *testing.T) {
+	server := mockserver.New()
+	t.Cleanup(server.Close)
+	btc := server.AddToken("BTC", "btc", 8, 60000)
+	server.AddToken("USDC", "near", 6, 1)
+	server.AddToken("ETH", "eth", 18, 3000)
+	sol := server.AddToken("SOL", "sol", 9, 150)
+	server.AddToken("DOGE", "doge", 8, 0.1) // Not a route target
+
+	// Every route quotes except SOL, which the API refuses
+	server.SetQuoteHandler(func(req oneclick.QuoteRequest) (*oneclick.QuoteResponse, int) {
+		if req.DestinationAsset == sol {
+			return nil, http.StatusBadRequest
+		}
+		quote := oneclick.NewQuote(req.Amount, "1", "0", req.Amount, "2", "2", "0", "0", 10)
+		return oneclick.NewQuoteResponse(time.Now(), "mock-signature", req, *quote), http.StatusOK
+	})
+
+	c := server.Client("t")
+	source, err := c.FindToken("BTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source.GetAssetId() != btc {
+		t.Fatalf("FindToken(BTC) = %s, want %s", source.GetAssetId(), btc)
+	}
+
+	results, err := c.FindRoutes(*source, nil, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		chain, symbol string
+		viable        bool
+	}{
+		{chain: "eth", symbol: "ETH", viable: true},
+		{chain: "near", symbol: "USDC", viable: true},
+		{chain: "sol", symbol: "SOL"},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d routes (%+v), want %d", len(results), results, len(want))
+	}
+	for i, w := range want {
+		got := results[i]
+		if got.Chain != w.chain || got.Symbol != w.symbol || got.Viable() != w.viable {
+			t.Errorf("route %d = %s/%s viable %v, want %s/%s viable %v",
+				i, got.Chain, got.Symbol, got.Viable(), w.chain, w.symbol, w.viable)
+		}
+		if w.viable && got.AmountOut != "2" {
+			t.Errorf("%s amount out = %q, want 2", got.Symbol, got.AmountOut)
+		}
+		if !w.viable && !strings.Contains(got.Error, "400") {
+			t.Errorf("%s error = %q, want the API status", got.Symbol, got.Error)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"fmt"
+
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
+)
+
+// SameAssetError is returned when a swap's source and destination tokens resolve to the same
+// asset, e.g. the same symbol on the same chain or two aliases of one token
+type SameAssetError struct {
+	AssetID string
+	Source  string // Source token as resolved, e.g. USDC@eth
+	Dest    string // Destination token as resolved
+}
+
+func (e *SameAssetError) Error() string {
+	if e.Source == e.Dest {
+		return fmt.Sprintf("cannot swap %s to itself (asset %s); pick a different destination token or chain", e.Source, e.AssetID)
+	}
+	return fmt.Sprintf("%s and %s are the same asset (%s); pick a different destination token or chain", e.Source, e.Dest, e.AssetID)
+}
+
+// checkDistinctAssets returns a *SameAssetError if source and dest are the same asset
+func checkDistinctAssets(source, dest *oneclick.TokenResponse) error {
+	if source.GetAssetId() != dest.GetAssetId() {
+		return nil
+	}
+	return &SameAssetError{
+		AssetID: source.GetAssetId(),
+		Source:  fmt.Sprintf("%s@%s", source.GetSymbol(), source.GetBlockchain()),
+		Dest:    fmt.Sprintf("%s@%s", dest.GetSymbol(), dest.GetBlockchain()),
+	}
+}
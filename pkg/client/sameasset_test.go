@@ -0,0 +1,56 @@
+package client_test
+
+import (
+	"errors"
+	"testing"
+
+	"near-swap/pkg/client"
+	"near-swap/pkg/mockserver"
+	"near-swap/pkg/types"
+)
+
+func TestQuoteRejectsSameAssetSwaps(t *testing.T) {
+	server := mockserver.New()
+	t.Cleanup(server.Close)
+	usdc := server.AddToken("USDC", "near", 6, 1)
+	server.AddToken("USDC", "eth", 6, 1)
+
+	c := server.Client("t")
+
+	tests := []struct {
+		name                   string
+		source, dest           string
+		sourceChain, destChain string
+		wantErr                bool
+	}{
+		{name: "same symbol on the same chain", source: "USDC", dest: "usdc", sourceChain: "near", destChain: "NEAR", wantErr: true},
+		// A partial symbol and a chainless lookup both resolve to USDC on near
+		{name: "alias of the same asset", source: "USDC", dest: "USD", sourceChain: "near", wantErr: true},
+		{name: "same symbol on another chain", source: "USDC", dest: "USDC", sourceChain: "near", destChain: "eth"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := server.RequestCount("/v0/quote")
+			_, err := c.GetQuote(&types.SwapRequest{Amount: "1", SourceToken: tt.source, DestToken: tt.dest,
+				SourceChain: tt.sourceChain, DestChain: tt.destChain, RecipientAddr: "me.near", RefundAddr: "refund", Dry: true})
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var sameAsset *client.SameAssetError
+			if !errors.As(err, &sameAsset) {
+				t.Fatalf("error = %v, want a same-asset error", err)
+			}
+			if sameAsset.AssetID != usdc {
+				t.Errorf("asset = %s, want %s", sameAsset.AssetID, usdc)
+			}
+			if n := server.RequestCount("/v0/quote") - before; n != 0 {
+				t.Errorf("%d quote requests sent for a same-asset swap, want none", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
+)
+
+// DefaultTokenCacheTTL is how long the supported token list is reused before it is fetched again
+const DefaultTokenCacheTTL = 5 * time.Minute
+
+// WithTokenCacheTTL sets how long the supported token list is cached. Zero or less disables
+// the cache, so every lookup fetches the list.
+func WithTokenCacheTTL(ttl time.Duration) Option {
+	return func(c *OneClickClient) {
+		c.tokens.ttl = ttl
+	}
+}
+
+// tokenCache holds the supported token list. The mutex is held while fetching, so concurrent
+// lookups on an expired cache wait for one request instead of each making their own.
+type tokenCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	tokens  []oneclick.TokenResponse
+	fetched time.Time
+}
+
+// getSupportedTokens returns the cached token list, fetching it when it is missing or older
+// than the cache TTL
+func (c *OneClickClient) getSupportedTokens(ctx context.Context) ([]oneclick.TokenResponse, error) {
+	c.tokens.mu.Lock()
+	defer c.tokens.mu.Unlock()
+
+	if c.tokens.ttl > 0 && c.tokens.tokens != nil && time.Since(c.tokens.fetched) < c.tokens.ttl {
+		return slices.Clone(c.tokens.tokens), nil
+	}
+	return c.refreshTokensLocked(ctx)
+}
+
+// RefreshSupportedTokens fetches the supported token list from the API even if the cached one
+// is still fresh, and caches the result
+func (c *OneClickClient) RefreshSupportedTokens() ([]oneclick.TokenResponse, error) {
+	c.tokens.mu.Lock()
+	defer c.tokens.mu.Unlock()
+	return c.refreshTokensLocked(c.ctx)
+}
+
+// refreshTokensLocked fetches and caches the token list; the caller holds c.tokens.mu.
+// A failed fetch keeps the previous list cached.
+func (c *OneClickClient) refreshTokensLocked(ctx context.Context) ([]oneclick.TokenResponse, error) {
+	tokens, err := c.fetchSupportedTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if c.tokens.ttl > 0 {
+		c.tokens.tokens = tokens
+		c.tokens.fetched = time.Now()
+	}
+	return slices.Clone(tokens), nil
+}
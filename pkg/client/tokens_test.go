@@ -0,0 +1,69 @@
+package client_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"near-swap/pkg/client"
+	"near-swap/pkg/mockserver"
+)
+
+func TestSupportedTokensAreCached(t *testing.T) {
+	server := mockserver.New()
+	t.Cleanup(server.Close)
+	server.AddToken("BTC", "btc", 8, 60000)
+	server.AddToken("USDC", "near", 6, 1)
+
+	c := server.Client("t")
+
+	// Lookups from many plan goroutines share one fetch
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.FindToken("BTC"); err != nil {
+				t.Error(err)
+			}
+			if _, err := c.FindTokenOnChain("USDC", "near"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := server.RequestCount("/v0/tokens"); n != 1 {
+		t.Errorf("%d token list requests within the TTL, want 1", n)
+	}
+
+	// A forced refresh always fetches, and refills the cache
+	if _, err := c.RefreshSupportedTokens(); err != nil {
+		t.Fatal(err)
+	}
+	c.FindToken("BTC")
+	if n := server.RequestCount("/v0/tokens"); n != 2 {
+		t.Errorf("%d token list requests after a refresh, want 2", n)
+	}
+
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want int // Requests for two lookups 20ms apart
+	}{
+		{name: "expired list is fetched again", ttl: 10 * time.Millisecond, want: 2},
+		{name: "zero TTL disables the cache", ttl: 0, want: 2},
+		{name: "fresh list is reused", ttl: time.Minute, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := client.NewOneClickClientWithBaseURL("t", server.URL, client.WithTokenCacheTTL(tt.ttl))
+			before := server.RequestCount("/v0/tokens")
+			c.FindToken("BTC")
+			time.Sleep(20 * time.Millisecond)
+			c.FindToken("BTC")
+			if n := server.RequestCount("/v0/tokens") - before; n != tt.want {
+				t.Errorf("%d token list requests, want %d", n, tt.want)
+			}
+		})
+	}
+}
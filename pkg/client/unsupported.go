@@ -0,0 +1,114 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// unsupportedRouteTTL is how long a route the API refused is remembered before it is tried again
+const unsupportedRouteTTL = time.Hour
+
+// UnsupportedRouteError is returned when quoting between two chains that are known not to route
+type UnsupportedRouteError struct {
+	SourceChain string
+	DestChain   string
+	Reason      string // API message that marked the route unsupported, if learned from a quote
+}
+
+func (e *UnsupportedRouteError) Error() string {
+	msg := fmt.Sprintf("route from %s to %s is not supported; try routing through NEAR/USDC", e.SourceChain, e.DestChain)
+	if e.Reason != "" {
+		msg += fmt.Sprintf(" (%s)", e.Reason)
+	}
+	return msg
+}
+
+// routeMatrix tracks chain pairs known not to route. Configured pairs never expire; pairs
+// learned from refused quotes expire after unsupportedRouteTTL so new routes are picked up.
+type routeMatrix struct {
+	mu      sync.Mutex
+	entries map[string]routeEntry
+}
+
+type routeEntry struct {
+	reason  string
+	expires time.Time // Zero for configured pairs
+}
+
+// routeKey normalizes a chain pair into a map key
+func routeKey(sourceChain, destChain string) string {
+	return strings.ToLower(sourceChain) + ":" + strings.ToLower(destChain)
+}
+
+// ParseRoute splits a "source:dest" chain pair
+func ParseRoute(route string) (string, string, error) {
+	source, dest, found := strings.Cut(strings.TrimSpace(route), ":")
+	source, dest = strings.TrimSpace(source), strings.TrimSpace(dest)
+	if !found || source == "" || dest == "" {
+		return "", "", fmt.Errorf("invalid route '%s', expected <source chain>:<dest chain>", route)
+	}
+	return source, dest, nil
+}
+
+// MarkRouteUnsupported records that swaps from sourceChain to destChain can't be routed,
+// so GetQuote fails fast for the pair
+func (c *OneClickClient) MarkRouteUnsupported(sourceChain, destChain string) {
+	c.routes.set(sourceChain, destChain, routeEntry{})
+}
+
+// CheckRoute returns an *UnsupportedRouteError if the chain pair is known not to route
+func (c *OneClickClient) CheckRoute(sourceChain, destChain string) error {
+	entry, ok := c.routes.get(sourceChain, destChain)
+	if !ok {
+		return nil
+	}
+	return &UnsupportedRouteError{SourceChain: sourceChain, DestChain: destChain, Reason: entry.reason}
+}
+
+// learnUnsupportedRoute remembers a pair whose quote the API refused as unroutable
+func (c *OneClickClient) learnUnsupportedRoute(sourceChain, destChain, reason string) {
+	c.routes.set(sourceChain, destChain, routeEntry{reason: reason, expires: time.Now().Add(unsupportedRouteTTL)})
+}
+
+func (m *routeMatrix) set(sourceChain, destChain string, entry routeEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.entries == nil {
+		m.entries = make(map[string]routeEntry)
+	}
+	// A configured pair is never downgraded to an expiring one
+	if existing, ok := m.entries[routeKey(sourceChain, destChain)]; ok && existing.expires.IsZero() {
+		return
+	}
+	m.entries[routeKey(sourceChain, destChain)] = entry
+}
+
+func (m *routeMatrix) get(sourceChain, destChain string) (routeEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := routeKey(sourceChain, destChain)
+	entry, ok := m.entries[key]
+	if !ok {
+		return routeEntry{}, false
+	}
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		delete(m.entries, key)
+		return routeEntry{}, false
+	}
+	return entry, true
+}
+
+// isUnroutableMessage reports whether an API error message says the pair can't be routed,
+// as opposed to a problem with the amount, addresses or a single token
+func isUnroutableMessage(message string) bool {
+	msg := strings.ToLower(message)
+	if !strings.Contains(msg, "route") {
+		return false
+	}
+	return strings.Contains(msg, "no route") ||
+		strings.Contains(msg, "not found") ||
+		strings.Contains(msg, "not supported") ||
+		strings.Contains(msg, "unsupported")
+}
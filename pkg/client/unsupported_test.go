@@ -0,0 +1,57 @@
+package client_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"near-swap/pkg/client"
+	"near-swap/pkg/mockserver"
+	"near-swap/pkg/types"
+)
+
+func TestQuoteRejectsUnsupportedRoutes(t *testing.T) {
+	server := mockserver.New()
+	t.Cleanup(server.Close)
+	server.AddToken("XMR", "xmr", 12, 150)
+	server.AddToken("ZEC", "zec", 8, 40)
+	server.AddToken("USDC", "near", 6, 1)
+
+	c := server.Client("t")
+	c.MarkRouteUnsupported("XMR", "ZEC") // Chains match regardless of case
+
+	tests := []struct {
+		name         string
+		source, dest string
+		wantErr      bool
+	}{
+		{name: "known unsupported pair", source: "XMR", dest: "ZEC", wantErr: true},
+		{name: "reverse direction still routes", source: "ZEC", dest: "XMR"},
+		{name: "routing through USDC", source: "XMR", dest: "USDC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := server.RequestCount("/v0/quote")
+			_, err := c.GetQuote(&types.SwapRequest{Amount: "1", SourceToken: tt.source, DestToken: tt.dest,
+				RecipientAddr: "me.near", RefundAddr: "refund", Dry: true})
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var unsupported *client.UnsupportedRouteError
+			if !errors.As(err, &unsupported) {
+				t.Fatalf("error = %v, want an unsupported route", err)
+			}
+			if !strings.Contains(err.Error(), "route from xmr to zec is not supported; try routing through NEAR/USDC") {
+				t.Errorf("error = %q, want guidance to route through NEAR/USDC", err)
+			}
+			if n := server.RequestCount("/v0/quote") - before; n != 0 {
+				t.Errorf("%d quote requests sent for an unsupported pair, want none", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package deposit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Address formats of the chains we can recognize. They are loose shape checks (no checksum
+// validation), enough to tell one chain's addresses from another's.
+var (
+	evmAddressPattern      = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	bitcoinAddressPattern  = regexp.MustCompile(`^(bc1[02-9ac-hj-np-z]{11,71}|[13][1-9A-HJ-NP-Za-km-z]{25,34})$`)
+	solanaAddressPattern   = regexp.MustCompile(`^[1-9A-HJ-NP-Za-km-z]{32,44}$`)
+	nearAccountPattern     = regexp.MustCompile(`^(([a-z0-9]+[-_])*[a-z0-9]+\.)+(near|tg)$|^[0-9a-f]{64}$|^0x[0-9a-f]{40}$`)
+	zcashAddressPattern    = regexp.MustCompile(`^(t[13][1-9A-HJ-NP-Za-km-z]{33}|zs1[02-9ac-hj-np-z]{75}|u1[02-9ac-hj-np-z]{100,})$`)
+	litecoinAddressPattern = regexp.MustCompile(`^(ltc1[02-9ac-hj-np-z]{11,71}|[LM3][1-9A-HJ-NP-Za-km-z]{25,34})$`)
+	moneroAddressPattern   = regexp.MustCompile(`^[48][1-9A-HJ-NP-Za-km-z]{94}([1-9A-HJ-NP-Za-km-z]{11})?$`)
+)
+
+// evmChains are the canonical names of chains using 20-byte hex addresses
+var evmChains = map[string]bool{
+	"ethereum": true, "bsc": true, "polygon": true, "avalanche": true, "arbitrum": true,
+	"optimism": true, "base": true, "fantom": true, "arb": true, "op": true, "gnosis": true, "bera": true,
+}
+
+// addressPattern returns the address format of a chain, or nil when it isn't known
+func addressPattern(chain string) *regexp.Regexp {
+	canonical := CanonicalChain(chain)
+	if evmChains[canonical] {
+		return evmAddressPattern
+	}
+	switch canonical {
+	case "bitcoin":
+		return bitcoinAddressPattern
+	case "solana":
+		return solanaAddressPattern
+	case "near":
+		return nearAccountPattern
+	case "zcash":
+		return zcashAddressPattern
+	case "litecoin":
+		return litecoinAddressPattern
+	case "monero":
+		return moneroAddressPattern
+	default:
+		return nil
+	}
+}
+
+// MatchesChain reports whether address has the format of the chain's addresses. known is
+// false when the chain's format isn't recognized, in which case matches is meaningless.
+func MatchesChain(chain, address string) (matches bool, known bool) {
+	pattern := addressPattern(chain)
+	if pattern == nil {
+		return false, false
+	}
+	return pattern.MatchString(strings.TrimSpace(address)), true
+}
+
+// AddressRoleError is returned when the recipient or refund address doesn't look like an
+// address of the chain it will be used on
+type AddressRoleError struct {
+	Swapped       bool   // The recipient looks like a source-chain address and the refund like a dest-chain one
+	Field         string // "recipient" or "refund" when only one address is off
+	Address       string
+	ExpectedChain string
+}
+
+func (e *AddressRoleError) Error() string {
+	if e.Swapped {
+		return "recipient and refund addresses look swapped: the recipient should be an address on the destination chain " +
+			"and the refund address one on the source chain"
+	}
+	return fmt.Sprintf("%s address %s doesn't look like a %s address", e.Field, e.Address, e.ExpectedChain)
+}
+
+// CheckAddressRoles cross-checks the recipient against the destination chain's address format
+// and the refund address against the source chain's. It returns an *AddressRoleError with
+// Swapped set when the two appear to have been passed the wrong way round, or describing the
+// first address that doesn't fit its chain. Chains with unknown formats are not checked, and
+// same-format chains (e.g. two EVM networks) can't be told apart so never count as swapped.
+func CheckAddressRoles(sourceChain, destChain, recipientAddr, refundAddr string) error {
+	if refundAddr != "" && recipientAddr != "" && addressPattern(sourceChain) != addressPattern(destChain) {
+		recipientFitsDest, destKnown := MatchesChain(destChain, recipientAddr)
+		refundFitsSource, sourceKnown := MatchesChain(sourceChain, refundAddr)
+		if destKnown && sourceKnown && !recipientFitsDest && !refundFitsSource {
+			recipientFitsSource, _ := MatchesChain(sourceChain, recipientAddr)
+			refundFitsDest, _ := MatchesChain(destChain, refundAddr)
+			if recipientFitsSource && refundFitsDest {
+				return &AddressRoleError{Swapped: true}
+			}
+		}
+	}
+
+	if matches, known := MatchesChain(destChain, recipientAddr); known && recipientAddr != "" && !matches {
+		return &AddressRoleError{Field: "recipient", Address: recipientAddr, ExpectedChain: destChain}
+	}
+	if matches, known := MatchesChain(sourceChain, refundAddr); known && refundAddr != "" && !matches {
+		return &AddressRoleError{Field: "refund", Address: refundAddr, ExpectedChain: sourceChain}
+	}
+	return nil
+}
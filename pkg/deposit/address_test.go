@@ -0,0 +1,51 @@
+package deposit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckAddressRoles(t *testing.T) {
+	const (
+		btcAddr  = "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+		evmAddr  = "0x742d35Cc6634C0532925a3b844Bc454e4438f44e"
+		evmAddr2 = "0x52908400098527886E0F7030069857D2E4169EE7"
+		nearAddr = "alice.near"
+	)
+
+	tests := []struct {
+		name              string
+		source, dest      string
+		recipient, refund string
+		wantSwapped       bool
+		wantField         string // Field of a single misfit address; empty with wantSwapped false expects no error
+	}{
+		{name: "correct roles", source: "btc", dest: "eth", recipient: evmAddr, refund: btcAddr},
+		{name: "swapped", source: "btc", dest: "eth", recipient: btcAddr, refund: evmAddr, wantSwapped: true},
+		{name: "swapped into near", source: "bitcoin", dest: "near", recipient: btcAddr, refund: nearAddr, wantSwapped: true},
+		{name: "same format chains can't be swapped", source: "eth", dest: "base", recipient: evmAddr2, refund: evmAddr},
+		{name: "recipient on the wrong chain", source: "btc", dest: "eth", recipient: nearAddr, refund: btcAddr, wantField: "recipient"},
+		{name: "refund on the wrong chain", source: "btc", dest: "eth", recipient: evmAddr, refund: nearAddr, wantField: "refund"},
+		{name: "unknown chain formats aren't checked", source: "ton", dest: "eth", recipient: evmAddr, refund: btcAddr},
+		{name: "no refund address", source: "btc", dest: "eth", recipient: evmAddr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckAddressRoles(tt.source, tt.dest, tt.recipient, tt.refund)
+			if !tt.wantSwapped && tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("error = %v, want none", err)
+				}
+				return
+			}
+			var roleErr *AddressRoleError
+			if !errors.As(err, &roleErr) {
+				t.Fatalf("error = %v, want an address role error", err)
+			}
+			if roleErr.Swapped != tt.wantSwapped || roleErr.Field != tt.wantField {
+				t.Errorf("swapped %v, field %q; want %v, %q", roleErr.Swapped, roleErr.Field, tt.wantSwapped, tt.wantField)
+			}
+		})
+	}
+}
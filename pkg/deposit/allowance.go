@@ -0,0 +1,189 @@
+package deposit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ERC20 allowance and approve function ABI
+const erc20AllowanceABI = `[{"constant":true,"inputs":[{"name":"_owner","type":"address"},{"name":"_spender","type":"address"}],"name":"allowance","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":false,"inputs":[{"name":"_spender","type":"address"},{"name":"_value","type":"uint256"}],"name":"approve","outputs":[{"name":"","type":"bool"}],"type":"function"}]`
+
+// approvalConfirmTimeout bounds how long EnsureAllowance waits for an approve to be mined
+const approvalConfirmTimeout = 3 * time.Minute
+
+// approvalBackend is the subset of the RPC client needed to check and raise an allowance
+type approvalBackend interface {
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// EnsureAllowance makes sure spender may transfer at least amount of an ERC20 token on behalf of
+// the depositor, as needed before deposits that route through a contract rather than a direct
+// transfer. It is a no-op returning "" when the current allowance already covers amount.
+// Otherwise it sends approve() for amount (or the maximum uint256 when infinite_approval is set
+// for the network), waits for it to be mined, and returns the approve transaction hash.
+func (e *EVMDepositor) EnsureAllowance(token, spender, amount string) (string, error) {
+	if !common.IsHexAddress(token) {
+		return "", fmt.Errorf("invalid token contract address: %s", token)
+	}
+	if !common.IsHexAddress(spender) {
+		return "", fmt.Errorf("invalid spender address: %s", spender)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), approvalConfirmTimeout)
+	defer cancel()
+
+	decimals, err := e.getERC20Decimals(ctx, common.HexToAddress(token))
+	if err != nil {
+		return "", err
+	}
+	amountTokens, err := parseTokenAmount(amount, decimals)
+	if err != nil {
+		return "", fmt.Errorf("invalid amount: %w", err)
+	}
+
+	publicKeyECDSA, ok := e.privateKey.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return "", fmt.Errorf("failed to get public key")
+	}
+
+	approver := &allowanceApprover{
+		backend:    e.client,
+		privateKey: e.privateKey,
+		owner:      crypto.PubkeyToAddress(*publicKeyECDSA),
+		chainID:    big.NewInt(e.network.ChainID),
+		gasPrice:   e.network.GasPrice,
+		gasLimit:   e.network.GasLimit,
+		infinite:   e.network.InfiniteApproval,
+	}
+
+	return approver.ensure(ctx, common.HexToAddress(token), common.HexToAddress(spender), amountTokens)
+}
+
+// allowanceApprover checks and raises ERC20 allowances for a single owner account
+type allowanceApprover struct {
+	backend    approvalBackend
+	privateKey *ecdsa.PrivateKey
+	owner      common.Address
+	chainID    *big.Int
+	gasPrice   *int64
+	gasLimit   *uint64
+	infinite   bool
+}
+
+// ensure approves spender for amount of token unless the current allowance already covers it
+func (a *allowanceApprover) ensure(ctx context.Context, token, spender common.Address, amount *big.Int) (string, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(erc20AllowanceABI))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse ERC20 ABI: %w", err)
+	}
+
+	current, err := a.allowance(ctx, parsedABI, token, spender)
+	if err != nil {
+		return "", err
+	}
+	if current.Cmp(amount) >= 0 {
+		return "", nil
+	}
+
+	approveAmount := amount
+	if a.infinite {
+		approveAmount = math.MaxBig256
+	}
+
+	data, err := parsedABI.Pack("approve", spender, approveAmount)
+	if err != nil {
+		return "", fmt.Errorf("failed to pack approve data: %w", err)
+	}
+
+	nonce, err := a.backend.PendingNonceAt(ctx, a.owner)
+	if err != nil {
+		return "", fmt.Errorf("failed to get nonce: %w", err)
+	}
+
+	var gasPrice *big.Int
+	if a.gasPrice != nil {
+		gasPrice = big.NewInt(*a.gasPrice)
+	} else if gasPrice, err = a.backend.SuggestGasPrice(ctx); err != nil {
+		return "", fmt.Errorf("failed to get gas price: %w", err)
+	}
+
+	gasLimit := uint64(100000) // Typical ERC20 approve
+	if a.gasLimit != nil {
+		gasLimit = *a.gasLimit
+	} else {
+		msg := ethereum.CallMsg{From: a.owner, To: &token, Data: data}
+		if estimatedGas, err := a.backend.EstimateGas(ctx, msg); err == nil {
+			gasLimit = estimatedGas * 120 / 100 // Add 20% buffer
+		}
+	}
+
+	tx := types.NewTransaction(nonce, token, big.NewInt(0), gasLimit, gasPrice, data)
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(a.chainID), a.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign approve transaction: %w", err)
+	}
+
+	if err := a.backend.SendTransaction(ctx, signedTx); err != nil {
+		return "", fmt.Errorf("failed to send approve transaction: %w", err)
+	}
+
+	// The spender can only pull funds once the approval is mined
+	if err := a.waitMined(ctx, signedTx.Hash()); err != nil {
+		return signedTx.Hash().Hex(), err
+	}
+
+	return signedTx.Hash().Hex(), nil
+}
+
+// allowance reads how much spender may currently transfer from the owner
+func (a *allowanceApprover) allowance(ctx context.Context, parsedABI abi.ABI, token, spender common.Address) (*big.Int, error) {
+	data, err := parsedABI.Pack("allowance", a.owner, spender)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack allowance data: %w", err)
+	}
+
+	result, err := a.backend.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call allowance: %w", err)
+	}
+
+	return new(big.Int).SetBytes(result), nil
+}
+
+// waitMined polls for the approve receipt until it is mined or the context expires
+func (a *allowanceApprover) waitMined(ctx context.Context, txHash common.Hash) error {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := a.backend.TransactionReceipt(ctx, txHash)
+		if err == nil {
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return fmt.Errorf("approve transaction %s reverted", txHash.Hex())
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("approve transaction %s not confirmed: %w", txHash.Hex(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package deposit
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// fakeERC20 is an approvalBackend holding one token's allowance for a single owner and spender.
+// Approvals take effect when sent, and are mined as reverted when revert is set.
+type fakeERC20 struct {
+	allowance *big.Int
+	revert    bool
+	sent      []*types.Transaction
+}
+
+func (f *fakeERC20) CallContract(context.Context, ethereum.CallMsg, *big.Int) ([]byte, error) {
+	return common.LeftPadBytes(f.allowance.Bytes(), 32), nil
+}
+
+func (f *fakeERC20) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return uint64(len(f.sent)), nil
+}
+
+func (f *fakeERC20) SuggestGasPrice(context.Context) (*big.Int, error) {
+	return big.NewInt(2e9), nil
+}
+
+func (f *fakeERC20) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	return 50000, nil
+}
+
+func (f *fakeERC20) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	parsedABI, err := abi.JSON(strings.NewReader(erc20AllowanceABI))
+	if err != nil {
+		return err
+	}
+	args, err := parsedABI.Methods["approve"].Inputs.Unpack(tx.Data()[4:])
+	if err != nil {
+		return err
+	}
+	f.sent = append(f.sent, tx)
+	if !f.revert {
+		f.allowance = args[1].(*big.Int)
+	}
+	return nil
+}
+
+func (f *fakeERC20) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	for _, tx := range f.sent {
+		if tx.Hash() == txHash {
+			status := types.ReceiptStatusSuccessful
+			if f.revert {
+				status = types.ReceiptStatusFailed
+			}
+			return &types.Receipt{Status: status, TxHash: txHash}, nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+func TestEnsureAllowance(t *testing.T) {
+	token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	spender := common.HexToAddress("0x000000000022D473030F116dDEE9F6B43aC78BA3")
+	amount := big.NewInt(5_000_000)
+	gasPrice, gasLimit := int64(3e9), uint64(70000)
+
+	tests := []struct {
+		name         string
+		allowance    *big.Int
+		infinite     bool
+		fixedGas     bool
+		revert       bool
+		wantApproved *big.Int // Amount approved; nil expects no approve
+		wantGasPrice int64
+		wantGasLimit uint64
+		wantErr      string
+	}{
+		{name: "allowance already covers it", allowance: big.NewInt(5_000_000)},
+		{name: "larger allowance", allowance: big.NewInt(9_000_000)},
+		{name: "approves the exact amount", allowance: big.NewInt(1), wantApproved: amount, wantGasPrice: 2e9, wantGasLimit: 60000},
+		{name: "infinite approval", allowance: big.NewInt(0), infinite: true, wantApproved: math.MaxBig256, wantGasPrice: 2e9, wantGasLimit: 60000},
+		{name: "configured gas", allowance: big.NewInt(0), fixedGas: true, wantApproved: amount, wantGasPrice: gasPrice, wantGasLimit: gasLimit},
+		{name: "reverted approval", allowance: big.NewInt(0), revert: true, wantApproved: amount, wantGasPrice: 2e9, wantGasLimit: 60000,
+			wantErr: "reverted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := crypto.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			backend := &fakeERC20{allowance: tt.allowance, revert: tt.revert}
+			approver := &allowanceApprover{
+				backend:    backend,
+				privateKey: key,
+				owner:      crypto.PubkeyToAddress(key.PublicKey),
+				chainID:    big.NewInt(1),
+				infinite:   tt.infinite,
+			}
+			if tt.fixedGas {
+				approver.gasPrice, approver.gasLimit = &gasPrice, &gasLimit
+			}
+
+			hash, err := approver.ensure(context.Background(), token, spender, amount)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to mention %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantApproved == nil {
+				if hash != "" || len(backend.sent) != 0 {
+					t.Fatalf("sent %d approvals (hash %q), want none", len(backend.sent), hash)
+				}
+				return
+			}
+			if len(backend.sent) != 1 {
+				t.Fatalf("sent %d approvals, want 1", len(backend.sent))
+			}
+			tx := backend.sent[0]
+			if hash != tx.Hash().Hex() || *tx.To() != token {
+				t.Errorf("approve %s to %s, want %s to the token contract", hash, tx.To(), tx.Hash().Hex())
+			}
+			if tx.GasPrice().Int64() != tt.wantGasPrice || tx.Gas() != tt.wantGasLimit {
+				t.Errorf("gas %d at %s, want %d at %d", tx.Gas(), tx.GasPrice(), tt.wantGasLimit, tt.wantGasPrice)
+			}
+			if tt.revert {
+				return
+			}
+			if backend.allowance.Cmp(tt.wantApproved) != 0 {
+				t.Errorf("approved %s, want %s", backend.allowance, tt.wantApproved)
+			}
+
+			// Once approved, a second call is a no-op
+			if hash, err := approver.ensure(context.Background(), token, spender, amount); err != nil || hash != "" || len(backend.sent) != 1 {
+				t.Errorf("second ensure sent %d approvals (hash %q, error %v), want none", len(backend.sent)-1, hash, err)
+			}
+		})
+	}
+}
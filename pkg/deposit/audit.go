@@ -0,0 +1,175 @@
+package deposit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultAuditLogFileName is the deposit audit log kept in the home directory when
+// audit_log_path is not set
+const DefaultAuditLogFileName = ".near-swap-deposits.log"
+
+// DuplicateDepositWindow is how long a recorded deposit to an address blocks another
+// auto-deposit to it; a quote's deposit address is only meant to be funded once
+const DuplicateDepositWindow = 24 * time.Hour
+
+// AuditRecord is a single entry in the deposit audit log. It intentionally
+// carries no key material or RPC credentials.
+type AuditRecord struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Source      string    `json:"source"` // "swap" or "plan"
+	Chain       string    `json:"chain"`
+	Token       string    `json:"token"`
+	Amount      string    `json:"amount"`
+	ToAddress   string    `json:"to_address"`
+	TxID        string    `json:"txid"`
+	PlanName    string    `json:"plan_name,omitempty"`
+	ExecutionID string    `json:"execution_id,omitempty"`
+}
+
+// auditMu serializes writers within this process so records never interleave
+var auditMu sync.Mutex
+
+// AppendAuditRecord appends a record to the JSON-lines audit log at path and fsyncs it.
+// The file is only ever opened for appending, and is created with owner-only permissions.
+func AppendAuditRecord(path string, record AuditRecord) error {
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now().UTC()
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	line = append(line, '\n')
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+
+	return nil
+}
+
+// AuditLogPath returns the configured audit log, or the default one in the home directory
+func (m *Manager) AuditLogPath() (string, error) {
+	if m.config.AuditLogPath != "" {
+		return m.config.AuditLogPath, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory for the audit log: %w", err)
+	}
+	return filepath.Join(home, DefaultAuditLogFileName), nil
+}
+
+// RecordAudit appends a record to the audit log
+func (m *Manager) RecordAudit(record AuditRecord) error {
+	path, err := m.AuditLogPath()
+	if err != nil {
+		return err
+	}
+	return AppendAuditRecord(path, record)
+}
+
+// DuplicateDepositError is returned when the audit log shows a recent deposit to the same
+// deposit address, or one whose swap is still pending, i.e. the same quote is about to be
+// funded twice
+type DuplicateDepositError struct {
+	DepositAddress string
+	TxID           string
+	SentAt         time.Time
+	Pending        bool // The earlier deposit's swap is still in progress
+}
+
+func (e *DuplicateDepositError) Error() string {
+	state := ""
+	if e.Pending {
+		state = " and its swap is still pending"
+	}
+	return fmt.Sprintf("a deposit was already sent to %s at %s (TX: %s)%s; refusing to send it again. "+
+		"Check it with 'near-swap status %s', or pass --force if you really mean to deposit twice",
+		e.DepositAddress, e.SentAt.Local().Format("2006-01-02 15:04:05"), e.TxID, state, e.DepositAddress)
+}
+
+// SwapPendingFunc reports whether the swap funded through a deposit address is still in
+// progress
+type SwapPendingFunc func(depositAddress string) (bool, error)
+
+// FindRecentDeposit returns the latest deposit to address recorded in the audit log at path
+// after since, or nil if there is none. A missing log has no deposits.
+func FindRecentDeposit(path, address string, since time.Time) (*AuditRecord, error) {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var found *AuditRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record AuditRecord
+		// Skip lines that don't parse (e.g. a torn write) rather than failing the check
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		if record.Timestamp.After(since) && sameAddress(record.ToAddress, address) {
+			found = &record
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return found, nil
+}
+
+// CheckDuplicateDeposit returns a *DuplicateDepositError when the audit log has a deposit to
+// depositAddress within DuplicateDepositWindow, or an older one whose swap swapPending reports
+// as still in progress. swapPending may be nil, and a status that can't be looked up doesn't
+// block the deposit.
+func (m *Manager) CheckDuplicateDeposit(depositAddress string, swapPending SwapPendingFunc) error {
+	path, err := m.AuditLogPath()
+	if err != nil {
+		return err
+	}
+	record, err := FindRecentDeposit(path, depositAddress, time.Time{})
+	if err != nil || record == nil {
+		return err
+	}
+
+	duplicate := &DuplicateDepositError{DepositAddress: depositAddress, TxID: record.TxID, SentAt: record.Timestamp}
+	if time.Since(record.Timestamp) < DuplicateDepositWindow {
+		return duplicate
+	}
+	if swapPending != nil {
+		if pending, err := swapPending(depositAddress); err == nil && pending {
+			duplicate.Pending = true
+			return duplicate
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package deposit
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"near-swap/config"
+)
+
+func TestCheckDuplicateDeposit(t *testing.T) {
+	const address = "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd"
+
+	stillPending := func(string) (bool, error) { return true, nil }
+	settled := func(string) (bool, error) { return false, nil }
+	unreachable := func(string) (bool, error) { return false, errors.New("status API down") }
+
+	tests := []struct {
+		name        string
+		sentAgo     time.Duration // 0 records no deposit
+		to          string
+		swapPending SwapPendingFunc
+		wantBlocked bool
+		wantPending bool
+	}{
+		{name: "no earlier deposit", swapPending: stillPending},
+		{name: "recent deposit", sentAgo: time.Hour, to: address, swapPending: settled, wantBlocked: true},
+		{name: "recent deposit without status lookup", sentAgo: time.Hour, to: address, wantBlocked: true},
+		{name: "recent deposit to another case of the address", sentAgo: time.Hour, to: "0xABCDEFABCDEFABCDEFABCDEFABCDEFABCDEFABCD", wantBlocked: true},
+		{name: "recent deposit to another address", sentAgo: time.Hour, to: "0x2222222222222222222222222222222222222222", swapPending: stillPending},
+		{name: "old deposit still pending", sentAgo: 48 * time.Hour, to: address, swapPending: stillPending, wantBlocked: true, wantPending: true},
+		{name: "old deposit settled", sentAgo: 48 * time.Hour, to: address, swapPending: settled},
+		{name: "old deposit with unknown status", sentAgo: 48 * time.Hour, to: address, swapPending: unreachable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "deposits.log")
+			m := NewManager(config.AutoDepositConfig{AuditLogPath: path})
+			if tt.sentAgo > 0 {
+				record := AuditRecord{Timestamp: time.Now().Add(-tt.sentAgo), Source: "swap", Chain: "eth", ToAddress: tt.to, TxID: "0xabc"}
+				if err := m.RecordAudit(record); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := m.CheckDuplicateDeposit(address, tt.swapPending)
+			var duplicate *DuplicateDepositError
+			if blocked := errors.As(err, &duplicate); blocked != tt.wantBlocked {
+				t.Fatalf("blocked = %v (err %v), want %v", blocked, err, tt.wantBlocked)
+			}
+			if !tt.wantBlocked && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantBlocked && duplicate.Pending != tt.wantPending {
+				t.Errorf("pending = %v, want %v", duplicate.Pending, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestSecondAutoDepositToPendingAddressIsBlocked(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// No audit_log_path: deposits are still recorded to the default ledger
+	m := NewManager(config.AutoDepositConfig{})
+	const address = "deposit.near"
+	pending := func(string) (bool, error) { return true, nil }
+
+	if err := m.CheckDuplicateDeposit(address, pending); err != nil {
+		t.Fatalf("first deposit blocked: %v", err)
+	}
+	if err := m.RecordAudit(AuditRecord{Source: "swap", Chain: "near", ToAddress: address, TxID: "tx1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var duplicate *DuplicateDepositError
+	if err := m.CheckDuplicateDeposit(address, pending); !errors.As(err, &duplicate) {
+		t.Fatalf("second deposit error = %v, want *DuplicateDepositError", err)
+	}
+	if duplicate.TxID != "tx1" {
+		t.Errorf("duplicate TX = %s, want tx1", duplicate.TxID)
+	}
+
+	path, err := m.AuditLogPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != DefaultAuditLogFileName {
+		t.Errorf("audit log = %s, want the default %s", path, DefaultAuditLogFileName)
+	}
+}
@@ -41,18 +41,27 @@ func (b *BitcoinDepositor) SendDeposit(address string, amount string) (string, e
 		return "", fmt.Errorf("invalid amount: %w", err)
 	}
 
+	// Refuse dust outputs the node would reject
+	if err := checkMinimumSend("bitcoin", amountFloat, MinBitcoinSend); err != nil {
+		return "", err
+	}
+
 	// Check if we have enough balance
 	if balance < amountFloat {
 		return "", fmt.Errorf("insufficient balance: have %.8f BTC, need %.8f BTC", balance, amountFloat)
 	}
 
-	// Build the sendtoaddress command
-	args := b.buildBaseArgs()
-	args = append(args, "sendtoaddress", address, amount)
+	feeRate, err := b.feeRate()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine fee rate: %w", err)
+	}
 
-	// Note: bitcoin-cli sendtoaddress has many optional parameters
-	// We're using the simple form here. For advanced options like fee_estimatemode,
-	// users can customize via CLI args in config
+	// Build the sendtoaddress command; named arguments let fee_rate skip the optional ones before it
+	args := b.buildBaseArgs()
+	args = append(args, "-named", "sendtoaddress", "address="+address, "amount="+amount)
+	if feeRate > 0 {
+		args = append(args, "fee_rate="+formatFeeRate(feeRate))
+	}
 
 	// Execute the command
 	cmd := exec.Command(b.config.CLIPath, args...)
@@ -70,7 +79,12 @@ func (b *BitcoinDepositor) SendDeposit(address string, amount string) (string, e
 	return txid, nil
 }
 
-// GetBalance returns the wallet balance
+// GetBalance returns the wallet balance in BTC
+func (b *BitcoinDepositor) GetBalance() (float64, error) {
+	return b.getBalance()
+}
+
+// getBalance returns the wallet balance
 func (b *BitcoinDepositor) getBalance() (float64, error) {
 	args := b.buildBaseArgs()
 	args = append(args, "getbalance")
@@ -126,11 +140,6 @@ func (b *BitcoinDepositor) buildBaseArgs() []string {
 	return args
 }
 
-// formatFeeRate formats the fee rate for bitcoin-cli
-func (b *BitcoinDepositor) formatFeeRate() string {
-	return fmt.Sprintf("%.8f", b.config.FeeRate)
-}
-
 // GetTransactionInfo retrieves information about a transaction
 func (b *BitcoinDepositor) GetTransactionInfo(txid string) (map[string]interface{}, error) {
 	args := b.buildBaseArgs()
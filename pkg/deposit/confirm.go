@@ -0,0 +1,102 @@
+package deposit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	confirmationPollInterval = 5 * time.Second  // How often the receipt and chain head are polled
+	confirmationTimeout      = 30 * time.Minute // Upper bound on waiting for the configured depth
+)
+
+// errConfirmationTimeout is returned when a deposit is not buried deep enough in time
+var errConfirmationTimeout = errors.New("timed out waiting for confirmations")
+
+// confirmationBackend is the subset of the RPC client needed to wait for confirmations
+type confirmationBackend interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// waitForConfirmations blocks until tx is included and buried under depth blocks (the inclusion
+// block counts as the first). The receipt's block must still be canonical when the depth is
+// reached. If a transaction seen mined disappears because its block was reorged out, the same
+// signed transaction is rebroadcast so it can be mined again; it fails for good only if it
+// reverts or its nonce is taken by another transaction.
+func waitForConfirmations(ctx context.Context, backend confirmationBackend, tx *types.Transaction, depth uint64, pollInterval time.Duration) (*types.Receipt, error) {
+	mined := false
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := backend.TransactionReceipt(ctx, tx.Hash())
+		switch {
+		case errors.Is(err, ethereum.NotFound):
+			if mined {
+				// The block holding the transaction was reorged out
+				mined = false
+				if err := backend.SendTransaction(ctx, tx); err != nil && !isAlreadyKnown(err) {
+					if strings.Contains(err.Error(), "nonce too low") {
+						return nil, fmt.Errorf("transaction %s was reorged out and replaced by another transaction with the same nonce", tx.Hash().Hex())
+					}
+					return nil, fmt.Errorf("failed to resubmit reorged transaction %s: %w", tx.Hash().Hex(), err)
+				}
+			}
+		case err != nil:
+			// Transient RPC error, try again on the next tick
+		case receipt.Status != types.ReceiptStatusSuccessful:
+			return receipt, fmt.Errorf("transaction %s reverted", tx.Hash().Hex())
+		default:
+			mined = true
+			if buried, err := isBuried(ctx, backend, receipt, depth); err == nil && buried {
+				return receipt, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("transaction %s: %w", tx.Hash().Hex(), errConfirmationTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// isBuried reports whether the receipt's block is canonical and at least depth blocks deep
+func isBuried(ctx context.Context, backend confirmationBackend, receipt *types.Receipt, depth uint64) (bool, error) {
+	head, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+
+	if head.Number.Cmp(receipt.BlockNumber) < 0 {
+		return false, nil
+	}
+	confirmations := new(big.Int).Sub(head.Number, receipt.BlockNumber).Uint64() + 1
+	if confirmations < depth {
+		return false, nil
+	}
+
+	// A stale receipt may still point at a block that has since been replaced
+	block, err := backend.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return false, err
+	}
+	return block.Hash() == receipt.BlockHash, nil
+}
+
+// isAlreadyKnown reports whether a broadcast failed only because the node already has the transaction
+func isAlreadyKnown(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "already known") || strings.Contains(msg, "known transaction")
+}
@@ -0,0 +1,179 @@
+package deposit
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// receiptStep is what one receipt lookup returns
+type receiptStep struct {
+	block    int64  // Block the transaction was mined in; 0 means not found
+	fork     string // Which fork's block at that height the receipt points at
+	reverted bool
+	err      error
+}
+
+// fakeConfirmationBackend replays scripted receipts and chain heads, repeating the last of
+// each once it runs out. Canonical blocks belong to fork "a" unless canonical says otherwise.
+type fakeConfirmationBackend struct {
+	receipts  []receiptStep
+	heads     []int64
+	canonical map[int64]string
+	sendErr   error
+	sent      int
+}
+
+// header returns the block header at height number on fork
+func header(number int64, fork string) *types.Header {
+	return &types.Header{Number: big.NewInt(number), Extra: []byte(fork)}
+}
+
+func (f *fakeConfirmationBackend) TransactionReceipt(context.Context, common.Hash) (*types.Receipt, error) {
+	step := f.receipts[0]
+	if len(f.receipts) > 1 {
+		f.receipts = f.receipts[1:]
+	}
+	switch {
+	case step.err != nil:
+		return nil, step.err
+	case step.block == 0:
+		return nil, ethereum.NotFound
+	}
+	receipt := &types.Receipt{Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(step.block), BlockHash: header(step.block, step.fork).Hash()}
+	if step.reverted {
+		receipt.Status = types.ReceiptStatusFailed
+	}
+	return receipt, nil
+}
+
+func (f *fakeConfirmationBackend) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	if number != nil {
+		fork := "a"
+		if canonical, ok := f.canonical[number.Int64()]; ok {
+			fork = canonical
+		}
+		return header(number.Int64(), fork), nil
+	}
+	head := f.heads[0]
+	if len(f.heads) > 1 {
+		f.heads = f.heads[1:]
+	}
+	return header(head, "a"), nil
+}
+
+func (f *fakeConfirmationBackend) SendTransaction(context.Context, *types.Transaction) error {
+	f.sent++
+	return f.sendErr
+}
+
+func TestWaitForConfirmations(t *testing.T) {
+	mined := func(block int64) receiptStep { return receiptStep{block: block, fork: "a"} }
+
+	tests := []struct {
+		name      string
+		backend   fakeConfirmationBackend
+		depth     uint64
+		wantBlock int64
+		wantSent  int
+		wantErr   string
+	}{
+		{
+			name:      "already deep enough",
+			backend:   fakeConfirmationBackend{receipts: []receiptStep{mined(10)}, heads: []int64{12}},
+			depth:     3,
+			wantBlock: 10,
+		},
+		{
+			name:      "waits for the depth",
+			backend:   fakeConfirmationBackend{receipts: []receiptStep{{}, mined(10)}, heads: []int64{10, 11, 12}},
+			depth:     3,
+			wantBlock: 10,
+		},
+		{
+			name:      "transient receipt errors are retried",
+			backend:   fakeConfirmationBackend{receipts: []receiptStep{{err: errors.New("timeout")}, mined(10)}, heads: []int64{10}},
+			depth:     1,
+			wantBlock: 10,
+		},
+		{
+			name:      "reorged out and mined again",
+			backend:   fakeConfirmationBackend{receipts: []receiptStep{mined(10), {}, mined(11)}, heads: []int64{10, 13}},
+			depth:     3,
+			wantBlock: 11,
+			wantSent:  1,
+		},
+		{
+			name:      "rebroadcast the node already has",
+			backend:   fakeConfirmationBackend{receipts: []receiptStep{mined(10), {}, mined(11)}, heads: []int64{10, 13}, sendErr: errors.New("already known")},
+			depth:     3,
+			wantBlock: 11,
+			wantSent:  1,
+		},
+		{
+			name: "stale receipt on a replaced block",
+			backend: fakeConfirmationBackend{
+				receipts:  []receiptStep{{block: 10, fork: "b"}, mined(11)},
+				heads:     []int64{13},
+				canonical: map[int64]string{10: "a"},
+			},
+			depth:     3,
+			wantBlock: 11,
+		},
+		{
+			name:    "reverted",
+			backend: fakeConfirmationBackend{receipts: []receiptStep{{block: 10, fork: "a", reverted: true}}, heads: []int64{12}},
+			depth:   1,
+			wantErr: "reverted",
+		},
+		{
+			name:     "nonce taken after the reorg",
+			backend:  fakeConfirmationBackend{receipts: []receiptStep{mined(10), {}}, heads: []int64{10}, sendErr: errors.New("nonce too low")},
+			depth:    3,
+			wantSent: 1,
+			wantErr:  "replaced by another transaction",
+		},
+		{
+			name:     "rebroadcast fails",
+			backend:  fakeConfirmationBackend{receipts: []receiptStep{mined(10), {}}, heads: []int64{10}, sendErr: errors.New("connection refused")},
+			depth:    3,
+			wantSent: 1,
+			wantErr:  "failed to resubmit",
+		},
+		{
+			name:    "never mined",
+			backend: fakeConfirmationBackend{receipts: []receiptStep{{}}, heads: []int64{10}},
+			depth:   1,
+			wantErr: errConfirmationTimeout.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			tx := types.NewTx(&types.LegacyTx{Nonce: 7, Gas: 21000, GasPrice: big.NewInt(1)})
+
+			receipt, err := waitForConfirmations(ctx, &tt.backend, tx, tt.depth, time.Millisecond)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to mention %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if receipt.BlockNumber.Int64() != tt.wantBlock {
+				t.Errorf("confirmed in block %d, want %d", receipt.BlockNumber.Int64(), tt.wantBlock)
+			}
+			if tt.backend.sent != tt.wantSent {
+				t.Errorf("rebroadcasts = %d, want %d", tt.backend.sent, tt.wantSent)
+			}
+		})
+	}
+}
@@ -10,11 +10,13 @@ import (
 // Depositor interface for blockchain-specific depositors
 type Depositor interface {
 	SendDeposit(address string, amount string) (string, error)
+	GetBalance() (float64, error) // Native balance of the funding wallet
 }
 
 // Manager handles auto-deposit for different blockchains
 type Manager struct {
-	config config.AutoDepositConfig
+	config    config.AutoDepositConfig
+	blockhash string // Recent blockhash of the last Solana deposit sent
 }
 
 // NewManager creates a new deposit manager
@@ -29,44 +31,105 @@ func (m *Manager) IsEnabled() bool {
 	return m.config.Enabled
 }
 
+// ChainStatus describes whether auto-deposit can be used for a chain
+type ChainStatus int
+
+const (
+	ChainReady         ChainStatus = iota // Enabled and fully configured
+	ChainDisabled                         // Auto-deposit intentionally turned off (globally or for the chain)
+	ChainMisconfigured                    // Enabled but missing required settings
+	ChainUnsupported                      // No depositor exists for the chain
+)
+
 // IsEnabledForChain returns whether auto-deposit is enabled for a specific blockchain
 func (m *Manager) IsEnabledForChain(chain string) bool {
+	status, _ := m.IsConfiguredForChain(chain)
+	return status == ChainReady
+}
+
+// IsConfiguredForChain reports whether auto-deposit is usable for a chain, with a
+// human-readable reason when it is not
+func (m *Manager) IsConfiguredForChain(chain string) (ChainStatus, string) {
 	if !m.config.Enabled {
-		return false
+		return ChainDisabled, "auto-deposit is disabled (auto_deposit.enabled is false)"
 	}
 
 	chain = strings.ToLower(chain)
 	switch chain {
 	case "btc", "bitcoin":
-		return m.config.Bitcoin.Enabled
+		if !m.config.Bitcoin.Enabled {
+			return ChainDisabled, "bitcoin auto-deposit is disabled"
+		}
+		if m.config.Bitcoin.CLIPath == "" {
+			return ChainMisconfigured, "bitcoin is enabled but has no cli_path configured"
+		}
 	case "xmr", "monero":
-		return m.config.Monero.Enabled
+		if !m.config.Monero.Enabled {
+			return ChainDisabled, "monero auto-deposit is disabled"
+		}
+		if m.config.Monero.Host == "" || m.config.Monero.Port == 0 {
+			return ChainMisconfigured, "monero is enabled but has no wallet RPC host/port configured"
+		}
 	case "zec", "zcash":
-		return m.config.Zcash.Enabled
+		if !m.config.Zcash.Enabled {
+			return ChainDisabled, "zcash auto-deposit is disabled"
+		}
+		if m.config.Zcash.CLIPath == "" {
+			return ChainMisconfigured, "zcash is enabled but has no cli_path configured"
+		}
+	case "ltc", "litecoin":
+		if !m.config.Litecoin.Enabled {
+			return ChainDisabled, "litecoin auto-deposit is disabled"
+		}
+		if m.config.Litecoin.CLIPath == "" {
+			return ChainMisconfigured, "litecoin is enabled but has no cli_path configured"
+		}
 	case "eth", "ethereum", "bsc", "bnb", "pol", "polygon", "matic", "avalanche", "avax", "arbitrum", "optimism", "base", "fantom":
 		// For EVM chains, check if the network is configured
 		if !m.config.EVM.Enabled {
-			return false
+			return ChainDisabled, "EVM auto-deposit is disabled"
 		}
 		networkName := m.getEVMNetworkName(chain)
-		_, exists := m.config.EVM.Networks[networkName]
-		return exists
+		network, exists := m.config.EVM.Networks[networkName]
+		if !exists {
+			return ChainMisconfigured, fmt.Sprintf("EVM auto-deposit is enabled but %s has no network configured under auto_deposit.evm.networks", networkName)
+		}
+		if network.RPCUrl == "" {
+			return ChainMisconfigured, fmt.Sprintf("%s is enabled but has no RPC configured", networkName)
+		}
+		if network.PrivateKey == "" {
+			return ChainMisconfigured, fmt.Sprintf("%s is enabled but has no private key (set private_key_env)", networkName)
+		}
 	case "sol", "solana":
-		return m.config.Solana.Enabled
+		if !m.config.Solana.Enabled {
+			return ChainDisabled, "solana auto-deposit is disabled"
+		}
+		if m.config.Solana.RPCUrl == "" {
+			return ChainMisconfigured, "solana is enabled but has no RPC configured"
+		}
+		if m.config.Solana.PrivateKey == "" {
+			return ChainMisconfigured, "solana is enabled but has no private key (set private_key_env)"
+		}
 	// Add more chains here as they're implemented
 	default:
-		return false
+		return ChainUnsupported, fmt.Sprintf("auto-deposit is not supported for chain: %s", chain)
 	}
+
+	return ChainReady, ""
 }
 
-// SendDeposit sends a deposit for the specified chain
-func (m *Manager) SendDeposit(chain, address, amount string) (string, error) {
-	if !m.IsEnabled() {
-		return "", fmt.Errorf("auto-deposit is not enabled in configuration")
+// CheckChain returns an error describing why auto-deposit can't be used for a chain, or nil if it can
+func (m *Manager) CheckChain(chain string) error {
+	if status, reason := m.IsConfiguredForChain(chain); status != ChainReady {
+		return fmt.Errorf("%s", reason)
 	}
+	return nil
+}
 
-	if !m.IsEnabledForChain(chain) {
-		return "", fmt.Errorf("auto-deposit is not enabled for chain: %s", chain)
+// SendDeposit sends a deposit for the specified chain
+func (m *Manager) SendDeposit(chain, address, amount string) (string, error) {
+	if err := m.CheckChain(chain); err != nil {
+		return "", err
 	}
 
 	chain = strings.ToLower(chain)
@@ -77,6 +140,8 @@ func (m *Manager) SendDeposit(chain, address, amount string) (string, error) {
 		return m.sendMoneroDeposit(address, amount)
 	case "zec", "zcash":
 		return m.sendZcashDeposit(address, amount)
+	case "ltc", "litecoin":
+		return m.sendLitecoinDeposit(address, amount)
 	case "eth", "ethereum", "bsc", "bnb", "pol", "polygon", "matic", "avalanche", "avax", "arbitrum", "optimism", "base", "fantom":
 		return m.sendEVMDeposit(chain, address, amount)
 	case "sol", "solana":
@@ -87,6 +152,95 @@ func (m *Manager) SendDeposit(chain, address, amount string) (string, error) {
 	}
 }
 
+// GetTransactionInfo looks up a transaction on the specified chain using that chain's depositor
+func (m *Manager) GetTransactionInfo(chain, txid string) (map[string]interface{}, error) {
+	if err := m.CheckChain(chain); err != nil {
+		return nil, err
+	}
+
+	chain = strings.ToLower(chain)
+	switch chain {
+	case "btc", "bitcoin":
+		return NewBitcoinDepositor(m.config.Bitcoin).GetTransactionInfo(txid)
+	case "xmr", "monero":
+		return NewMoneroDepositor(m.config.Monero).GetTransactionInfo(txid)
+	case "zec", "zcash":
+		return NewZcashDepositor(m.config.Zcash).GetTransactionInfo(txid)
+	case "ltc", "litecoin":
+		return NewLitecoinDepositor(m.config.Litecoin).GetTransactionInfo(txid)
+	case "eth", "ethereum", "bsc", "bnb", "pol", "polygon", "matic", "avalanche", "avax", "arbitrum", "optimism", "base", "fantom":
+		depositor, err := NewEVMDepositor(m.config.EVM, m.getEVMNetworkName(chain))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create EVM depositor: %w", err)
+		}
+		defer depositor.Close()
+		return depositor.GetTransactionInfo(txid)
+	case "sol", "solana":
+		depositor, err := NewSolanaDepositor(m.config.Solana)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Solana depositor: %w", err)
+		}
+		defer depositor.Close()
+		return depositor.GetTransactionInfo(txid)
+	default:
+		return nil, fmt.Errorf("transaction lookup not supported for chain: %s", chain)
+	}
+}
+
+// GetBalance returns the native balance of the funding wallet for a chain
+func (m *Manager) GetBalance(chain string) (float64, error) {
+	if err := m.CheckChain(chain); err != nil {
+		return 0, err
+	}
+
+	chain = strings.ToLower(chain)
+	switch chain {
+	case "btc", "bitcoin":
+		return NewBitcoinDepositor(m.config.Bitcoin).GetBalance()
+	case "xmr", "monero":
+		return NewMoneroDepositor(m.config.Monero).GetBalance()
+	case "zec", "zcash":
+		return NewZcashDepositor(m.config.Zcash).GetBalance()
+	case "ltc", "litecoin":
+		return NewLitecoinDepositor(m.config.Litecoin).GetBalance()
+	case "eth", "ethereum", "bsc", "bnb", "pol", "polygon", "matic", "avalanche", "avax", "arbitrum", "optimism", "base", "fantom":
+		depositor, err := NewEVMDepositor(m.config.EVM, m.getEVMNetworkName(chain))
+		if err != nil {
+			return 0, fmt.Errorf("failed to create EVM depositor: %w", err)
+		}
+		defer depositor.Close()
+		return depositor.GetBalance()
+	case "sol", "solana":
+		depositor, err := NewSolanaDepositor(m.config.Solana)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create Solana depositor: %w", err)
+		}
+		defer depositor.Close()
+		return depositor.GetBalance()
+	default:
+		return 0, fmt.Errorf("balance lookup not supported for chain: %s", chain)
+	}
+}
+
+// CanonicalChain maps chain aliases (e.g. "btc", "eth", "matic") to the name used in config
+func CanonicalChain(chain string) string {
+	chain = strings.ToLower(chain)
+	switch chain {
+	case "btc":
+		return "bitcoin"
+	case "xmr":
+		return "monero"
+	case "zec":
+		return "zcash"
+	case "ltc":
+		return "litecoin"
+	case "sol":
+		return "solana"
+	default:
+		return (&Manager{}).getEVMNetworkName(chain)
+	}
+}
+
 // sendBitcoinDeposit sends a Bitcoin deposit
 func (m *Manager) sendBitcoinDeposit(address, amount string) (string, error) {
 	depositor := NewBitcoinDepositor(m.config.Bitcoin)
@@ -105,6 +259,12 @@ func (m *Manager) sendZcashDeposit(address, amount string) (string, error) {
 	return depositor.SendDeposit(address, amount)
 }
 
+// sendLitecoinDeposit sends a Litecoin deposit
+func (m *Manager) sendLitecoinDeposit(address, amount string) (string, error) {
+	depositor := NewLitecoinDepositor(m.config.Litecoin)
+	return depositor.SendDeposit(address, amount)
+}
+
 // sendEVMDeposit sends an EVM deposit
 func (m *Manager) sendEVMDeposit(chain, address, amount string) (string, error) {
 	networkName := m.getEVMNetworkName(chain)
@@ -125,7 +285,16 @@ func (m *Manager) sendSolanaDeposit(address, amount string) (string, error) {
 	}
 	defer depositor.Close()
 
-	return depositor.SendDeposit(address, amount)
+	txid, err := depositor.SendDeposit(address, amount)
+	m.blockhash = depositor.LastBlockhash()
+	return txid, err
+}
+
+// LastBlockhash returns the recent blockhash the last Solana deposit sent through this manager
+// was signed with, which CheckDepositLanded needs to tell a dropped deposit from a pending one.
+// It is empty if no Solana deposit was sent.
+func (m *Manager) LastBlockhash() string {
+	return m.blockhash
 }
 
 // getEVMNetworkName maps chain names to network names in config
@@ -169,6 +338,10 @@ func (m *Manager) GetSupportedChains() []string {
 		supported = append(supported, "zcash")
 	}
 
+	if m.config.Litecoin.Enabled {
+		supported = append(supported, "litecoin")
+	}
+
 	if m.config.EVM.Enabled {
 		for network := range m.config.EVM.Networks {
 			supported = append(supported, network)
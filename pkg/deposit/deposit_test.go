@@ -0,0 +1,68 @@
+package deposit
+
+import (
+	"testing"
+
+	"near-swap/config"
+)
+
+func TestIsConfiguredForChain(t *testing.T) {
+	ready := config.AutoDepositConfig{
+		Enabled: true,
+		Bitcoin: config.BitcoinConfig{Enabled: true, CLIPath: "bitcoin-cli"},
+		Monero:  config.MoneroConfig{Enabled: true, Host: "localhost", Port: 18082},
+		EVM: config.EVMConfig{Enabled: true, Networks: map[string]config.EVMNetwork{
+			"ethereum": {RPCUrl: "http://localhost:8545", PrivateKey: "key"},
+			"base":     {PrivateKey: "key"},
+			"polygon":  {RPCUrl: "http://localhost:8546"},
+		}},
+		Solana: config.SolanaConfig{Enabled: true, RPCUrl: "http://localhost:8899"},
+	}
+	disabled := ready
+	disabled.Enabled = false
+
+	tests := []struct {
+		name       string
+		cfg        config.AutoDepositConfig
+		chain      string
+		wantStatus ChainStatus
+		wantReason string
+	}{
+		{name: "bitcoin ready", cfg: ready, chain: "BTC", wantStatus: ChainReady},
+		{name: "ethereum ready", cfg: ready, chain: "eth", wantStatus: ChainReady},
+		{name: "monero ready", cfg: ready, chain: "monero", wantStatus: ChainReady},
+		{name: "auto-deposit off", cfg: disabled, chain: "btc", wantStatus: ChainDisabled,
+			wantReason: "auto-deposit is disabled (auto_deposit.enabled is false)"},
+		{name: "chain off", cfg: ready, chain: "zec", wantStatus: ChainDisabled, wantReason: "zcash auto-deposit is disabled"},
+		{name: "evm network missing", cfg: ready, chain: "arbitrum", wantStatus: ChainMisconfigured,
+			wantReason: "EVM auto-deposit is enabled but arbitrum has no network configured under auto_deposit.evm.networks"},
+		{name: "evm network without rpc", cfg: ready, chain: "base", wantStatus: ChainMisconfigured,
+			wantReason: "base is enabled but has no RPC configured"},
+		{name: "evm network without key", cfg: ready, chain: "matic", wantStatus: ChainMisconfigured,
+			wantReason: "polygon is enabled but has no private key (set private_key_env)"},
+		{name: "solana without key", cfg: ready, chain: "sol", wantStatus: ChainMisconfigured,
+			wantReason: "solana is enabled but has no private key (set private_key_env)"},
+		{name: "unsupported chain", cfg: ready, chain: "doge", wantStatus: ChainUnsupported,
+			wantReason: "auto-deposit is not supported for chain: doge"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager(tt.cfg)
+			status, reason := m.IsConfiguredForChain(tt.chain)
+			if status != tt.wantStatus || reason != tt.wantReason {
+				t.Errorf("IsConfiguredForChain(%s) = %d, %q; want %d, %q", tt.chain, status, reason, tt.wantStatus, tt.wantReason)
+			}
+			if ready := m.IsEnabledForChain(tt.chain); ready != (tt.wantStatus == ChainReady) {
+				t.Errorf("IsEnabledForChain(%s) = %v", tt.chain, ready)
+			}
+
+			// Sends are refused with the same reason before touching any chain
+			if tt.wantStatus != ChainReady {
+				if _, err := m.SendDeposit(tt.chain, "deposit-address", "1"); err == nil || err.Error() != tt.wantReason {
+					t.Errorf("SendDeposit error = %v, want %q", err, tt.wantReason)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package deposit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// signatureCheckTimeout bounds the signature status lookup
+const signatureCheckTimeout = 30 * time.Second
+
+// DroppedDepositError reports a deposit transaction that never landed on chain, or landed but
+// failed, so no funds were sent
+type DroppedDepositError struct {
+	Chain  string
+	TxID   string
+	Reason string
+}
+
+func (e *DroppedDepositError) Error() string {
+	return fmt.Sprintf("deposit %s on %s %s", e.TxID, e.Chain, e.Reason)
+}
+
+// signatureStatusReader is the subset of the Solana RPC client needed to look up signatures
+// and whether the blockhash they were signed with can still land them
+type signatureStatusReader interface {
+	GetSignatureStatuses(ctx context.Context, searchTransactionHistory bool, transactionSignatures ...solana.Signature) (*rpc.GetSignatureStatusesResult, error)
+	IsBlockhashValid(ctx context.Context, blockHash solana.Hash, commitment rpc.CommitmentType) (*rpc.IsValidBlockhashResult, error)
+}
+
+// checkSignatureLanded returns a *DroppedDepositError if the signature's transaction failed, or
+// if the cluster doesn't know it and its blockhash has expired so it can never land. An unknown
+// signature is not proof on its own: the RPC node may be behind or have pruned its history.
+// It returns nil if the transaction landed successfully or can still land.
+func checkSignatureLanded(ctx context.Context, reader signatureStatusReader, txSignature, blockhash string) error {
+	sig, err := solana.SignatureFromBase58(txSignature)
+	if err != nil {
+		return fmt.Errorf("invalid transaction signature: %w", err)
+	}
+
+	result, err := reader.GetSignatureStatuses(ctx, true, sig)
+	if err != nil {
+		return fmt.Errorf("failed to get signature status: %w", err)
+	}
+	if result == nil || len(result.Value) == 0 || result.Value[0] == nil {
+		return checkBlockhashExpired(ctx, reader, txSignature, blockhash)
+	}
+	if status := result.Value[0]; status.Err != nil {
+		return &DroppedDepositError{Chain: "solana", TxID: txSignature, Reason: fmt.Sprintf("failed on chain: %v", status.Err)}
+	}
+	return nil
+}
+
+// checkBlockhashExpired returns a *DroppedDepositError for a signature the cluster doesn't know
+// once the blockhash it was signed with is no longer valid. Without the blockhash there is no
+// telling a dropped deposit from a pending one, so it returns an error instead.
+func checkBlockhashExpired(ctx context.Context, reader signatureStatusReader, txSignature, blockhash string) error {
+	if blockhash == "" {
+		return fmt.Errorf("deposit %s is unknown to the cluster, but its blockhash wasn't recorded to tell whether it was dropped", txSignature)
+	}
+	hash, err := solana.HashFromBase58(blockhash)
+	if err != nil {
+		return fmt.Errorf("invalid blockhash: %w", err)
+	}
+
+	// A node that is behind still sees the blockhash as valid, so it errs on the side of waiting
+	valid, err := reader.IsBlockhashValid(ctx, hash, rpc.CommitmentProcessed)
+	if err != nil {
+		return fmt.Errorf("failed to check blockhash: %w", err)
+	}
+	if valid == nil || valid.Value {
+		return nil
+	}
+	return &DroppedDepositError{Chain: "solana", TxID: txSignature, Reason: "was dropped: the cluster has no record of it and its blockhash expired"}
+}
+
+// CheckDepositLanded returns a *DroppedDepositError if a sent deposit never landed or failed.
+// blockhash is the recent blockhash it was signed with (see SolanaDepositor.LastBlockhash).
+func (s *SolanaDepositor) CheckDepositLanded(txSignature, blockhash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), signatureCheckTimeout)
+	defer cancel()
+	return checkSignatureLanded(ctx, s.client, txSignature, blockhash)
+}
+
+// CheckDepositLanded returns a *DroppedDepositError if a deposit sent on chain never landed
+// or failed there. blockhash is the recent blockhash a Solana deposit was signed with (see
+// LastBlockhash). Only Solana deposits can be checked; other chains return errors.ErrUnsupported.
+func (m *Manager) CheckDepositLanded(chain, txid, blockhash string) error {
+	if err := m.CheckChain(chain); err != nil {
+		return err
+	}
+
+	switch strings.ToLower(chain) {
+	case "sol", "solana":
+		depositor, err := NewSolanaDepositor(m.config.Solana)
+		if err != nil {
+			return fmt.Errorf("failed to create Solana depositor: %w", err)
+		}
+		defer depositor.Close()
+		return depositor.CheckDepositLanded(txid, blockhash)
+	default:
+		return fmt.Errorf("dropped deposit detection on %s: %w", chain, errors.ErrUnsupported)
+	}
+}
@@ -0,0 +1,82 @@
+package deposit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// fakeSignatureReader answers signature status lookups with a fixed result, and reports
+// whether blockhashes are still valid
+type fakeSignatureReader struct {
+	result         *rpc.GetSignatureStatusesResult
+	err            error
+	blockhashValid bool
+	blockhashErr   error
+}
+
+func (f *fakeSignatureReader) GetSignatureStatuses(context.Context, bool, ...solana.Signature) (*rpc.GetSignatureStatusesResult, error) {
+	return f.result, f.err
+}
+
+func (f *fakeSignatureReader) IsBlockhashValid(context.Context, solana.Hash, rpc.CommitmentType) (*rpc.IsValidBlockhashResult, error) {
+	if f.blockhashErr != nil {
+		return nil, f.blockhashErr
+	}
+	return &rpc.IsValidBlockhashResult{Value: f.blockhashValid}, nil
+}
+
+func TestCheckSignatureLanded(t *testing.T) {
+	signature := solana.Signature{1, 2, 3}.String()
+	blockhash := solana.Hash{4, 5, 6}.String()
+	statuses := func(values ...*rpc.SignatureStatusesResult) *rpc.GetSignatureStatusesResult {
+		return &rpc.GetSignatureStatusesResult{Value: values}
+	}
+
+	tests := []struct {
+		name        string
+		signature   string
+		blockhash   string
+		reader      fakeSignatureReader
+		wantDropped bool
+		wantErr     bool
+	}{
+		{name: "landed", signature: signature, blockhash: blockhash, reader: fakeSignatureReader{result: statuses(&rpc.SignatureStatusesResult{})}},
+		{name: "unknown with an expired blockhash", signature: signature, blockhash: blockhash, reader: fakeSignatureReader{result: statuses(nil)}, wantDropped: true},
+		{name: "unknown with a blockhash still valid", signature: signature, blockhash: blockhash, reader: fakeSignatureReader{result: statuses(nil), blockhashValid: true}},
+		{name: "unknown without a recorded blockhash", signature: signature, reader: fakeSignatureReader{result: statuses(nil)}, wantErr: true},
+		{name: "no statuses returned", signature: signature, blockhash: blockhash, reader: fakeSignatureReader{result: statuses()}, wantDropped: true},
+		{name: "nil result", signature: signature, blockhash: blockhash, reader: fakeSignatureReader{}, wantDropped: true},
+		{name: "nil result from a node that is behind", signature: signature, blockhash: blockhash, reader: fakeSignatureReader{blockhashValid: true}},
+		{
+			name:        "failed on chain",
+			signature:   signature,
+			reader:      fakeSignatureReader{result: statuses(&rpc.SignatureStatusesResult{Err: map[string]interface{}{"InstructionError": []interface{}{0, "Custom"}}})},
+			wantDropped: true,
+		},
+		{name: "RPC error is not a drop", signature: signature, blockhash: blockhash, reader: fakeSignatureReader{err: errors.New("connection refused")}, wantErr: true},
+		{name: "blockhash check error is not a drop", signature: signature, blockhash: blockhash,
+			reader: fakeSignatureReader{result: statuses(nil), blockhashErr: errors.New("connection refused")}, wantErr: true},
+		{name: "invalid blockhash", signature: signature, blockhash: "not-base58!", reader: fakeSignatureReader{result: statuses(nil)}, wantErr: true},
+		{name: "invalid signature", signature: "not-base58!", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSignatureLanded(context.Background(), &tt.reader, tt.signature, tt.blockhash)
+			var dropped *DroppedDepositError
+			if errors.As(err, &dropped) != tt.wantDropped {
+				t.Fatalf("error = %v, want dropped: %v", err, tt.wantDropped)
+			}
+			if tt.wantDropped && (dropped.TxID != tt.signature || dropped.Chain != "solana") {
+				t.Errorf("dropped deposit = %+v", dropped)
+			}
+			if !tt.wantDropped && (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package deposit
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Smallest native amounts worth broadcasting on each chain. Anything below is rejected by
+// nodes as dust (or, on Solana, can't fund a fresh deposit account) with opaque errors.
+const (
+	MinBitcoinSend  = 0.00000546     // 546 sat dust limit for standard outputs
+	MinZcashSend    = 0.00000054     // 54 zatoshi dust threshold
+	MinLitecoinSend = 0.0000546      // 5460 litoshi dust limit for standard outputs
+	MinMoneroSend   = 0.000000000001 // 1 piconero; Monero has no dust limit beyond a non-zero amount
+	MinSolanaSend   = 0.00089088     // Rent-exempt minimum for a new system account
+)
+
+// BelowMinimumError is returned when a deposit is too small to be accepted on its chain
+type BelowMinimumError struct {
+	Chain   string
+	Amount  string
+	Minimum string
+}
+
+func (e *BelowMinimumError) Error() string {
+	return fmt.Sprintf("amount %s is below the %s minimum of %s", e.Amount, e.Chain, e.Minimum)
+}
+
+// checkMinimumSend rejects a native amount below the chain's minimum send
+func checkMinimumSend(chain string, amount float64, minimum float64) error {
+	if amount < minimum {
+		return &BelowMinimumError{
+			Chain:   chain,
+			Amount:  strconv.FormatFloat(amount, 'f', -1, 64),
+			Minimum: strconv.FormatFloat(minimum, 'f', -1, 64),
+		}
+	}
+	return nil
+}
+
+// checkMinimumUnits rejects a token amount that rounds to zero smallest units
+func checkMinimumUnits(chain string, amount string, units uint64, decimals uint8) error {
+	if units == 0 {
+		return &BelowMinimumError{
+			Chain:   chain,
+			Amount:  amount,
+			Minimum: strconv.FormatFloat(1/pow10(decimals), 'f', -1, 64),
+		}
+	}
+	return nil
+}
+
+// pow10 returns 10^decimals as a float
+func pow10(decimals uint8) float64 {
+	result := 1.0
+	for i := uint8(0); i < decimals; i++ {
+		result *= 10
+	}
+	return result
+}
+
+// MinimumSend returns the minimum send of a chain whose deposits are always its native coin.
+// ok is false for chains that also carry tokens, where the minimum depends on the asset.
+func MinimumSend(chain string) (minimum float64, ok bool) {
+	switch CanonicalChain(chain) {
+	case "bitcoin":
+		return MinBitcoinSend, true
+	case "zcash":
+		return MinZcashSend, true
+	case "litecoin":
+		return MinLitecoinSend, true
+	case "monero":
+		return MinMoneroSend, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,160 @@
+package deposit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"near-swap/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gagliardetto/solana-go"
+)
+
+// fakeCLI writes a node CLI stand-in with a funded wallet that logs every command it runs
+func fakeCLI(t *testing.T) (path, log string) {
+	t.Helper()
+	dir := t.TempDir()
+	path, log = filepath.Join(dir, "node-cli"), filepath.Join(dir, "calls.log")
+	script := `#!/bin/sh
+echo "$*" >> '` + log + `'
+for arg in "$@"; do
+	case "$arg" in
+	getblockchaininfo) echo '{}'; exit 0 ;;
+	getbalance) echo 10; exit 0 ;;
+	sendtoaddress) echo deadbeef; exit 0 ;;
+	esac
+done
+exit 1
+`
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path, log
+}
+
+// fakeMoneroWallet serves a funded monero-wallet-rpc that refuses transfers
+func fakeMoneroWallet(t *testing.T) config.MoneroConfig {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req MoneroRPCRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": "0"}
+		switch req.Method {
+		case "get_version":
+			resp["result"] = map[string]interface{}{"version": 1}
+		case "get_balance":
+			resp["result"] = map[string]interface{}{"balance": 10e12, "unlocked_balance": 10e12}
+		default:
+			resp["error"] = map[string]interface{}{"code": -1, "message": "unexpected method " + req.Method}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	portNumber, _ := strconv.Atoi(port)
+	return config.MoneroConfig{Host: host, Port: portNumber}
+}
+
+func TestDepositorsRejectDust(t *testing.T) {
+	bitcoinCLI, bitcoinLog := fakeCLI(t)
+	litecoinCLI, litecoinLog := fakeCLI(t)
+	zcashCLI, zcashLog := fakeCLI(t)
+	monero := NewMoneroDepositor(fakeMoneroWallet(t))
+
+	mint, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcServer := httptest.NewServer(&fakeSolanaRPC{mint: mint.PublicKey(), created: map[solana.PublicKey]bool{}})
+	t.Cleanup(rpcServer.Close)
+	sol, err := NewSolanaDepositor(config.SolanaConfig{RPCUrl: rpcServer.URL, PrivateKey: key.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient := solana.PublicKey{7}.String()
+
+	evm := &EVMDepositor{networkName: "ethereum"}
+
+	tests := []struct {
+		chain  string
+		send   func(amount string) error
+		log    string // CLI call log; dust must never reach sendtoaddress
+		dust   string
+		enough string // Smallest amount accepted; empty skips the check
+	}{
+		{chain: "bitcoin", dust: "0.00000545", enough: "0.00000546", log: bitcoinLog, send: func(amount string) error {
+			_, err := NewBitcoinDepositor(config.BitcoinConfig{CLIPath: bitcoinCLI}).SendDeposit("bc1qdeposit", amount)
+			return err
+		}},
+		{chain: "litecoin", dust: "0.0000545", enough: "0.0000546", log: litecoinLog, send: func(amount string) error {
+			_, err := NewLitecoinDepositor(config.LitecoinConfig{CLIPath: litecoinCLI}).SendDeposit("ltc1qdeposit", amount)
+			return err
+		}},
+		{chain: "zcash", dust: "0.00000053", enough: "0.00000054", log: zcashLog, send: func(amount string) error {
+			_, err := NewZcashDepositor(config.ZcashConfig{CLIPath: zcashCLI}).SendDeposit("t1deposit", amount)
+			return err
+		}},
+		{chain: "monero", dust: "0.0000000000001", enough: "0.000000000001", send: func(amount string) error {
+			_, err := monero.SendDeposit("4deposit", amount)
+			return err
+		}},
+		{chain: "solana", dust: "0.00089", enough: "0.00089088", send: func(amount string) error {
+			_, err := sol.buildDeposit(context.Background(), recipient, amount)
+			return err
+		}},
+		{chain: "solana", dust: "0.0000001", enough: "0.000001", send: func(amount string) error {
+			_, err := sol.buildDeposit(context.Background(), recipient+"|"+mint.PublicKey().String(), amount)
+			return err
+		}},
+		// Without a node only the dust check can run
+		{chain: "ethereum", dust: "0.0000000000000000001", send: func(amount string) error {
+			_, err := evm.sendNativeToken(context.Background(), common.Address{}, "0x0000000000000000000000000000000000000001", amount, 0, nil)
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chain+" "+tt.dust, func(t *testing.T) {
+			err := tt.send(tt.dust)
+			var below *BelowMinimumError
+			if !errors.As(err, &below) || below.Chain != tt.chain {
+				t.Fatalf("dust send error = %v, want an amount below the %s minimum", err, tt.chain)
+			}
+			if !strings.Contains(err.Error(), "below the "+tt.chain+" minimum") {
+				t.Errorf("error = %q, want it to name the chain minimum", err)
+			}
+			if tt.log != "" {
+				if calls, _ := os.ReadFile(tt.log); strings.Contains(string(calls), "sendtoaddress") {
+					t.Errorf("dust was broadcast: %s", calls)
+				}
+			}
+
+			// Anything at the minimum gets past the check
+			if tt.enough == "" {
+				return
+			}
+			if err := tt.send(tt.enough); errors.As(err, &below) {
+				t.Errorf("minimum send rejected: %v", err)
+			}
+			if tt.log != "" {
+				if calls, _ := os.ReadFile(tt.log); !strings.Contains(string(calls), "sendtoaddress") {
+					t.Errorf("minimum send wasn't broadcast: %s", calls)
+				}
+			}
+		})
+	}
+}
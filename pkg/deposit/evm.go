@@ -3,9 +3,13 @@ package deposit
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"near-swap/config"
 
@@ -24,11 +28,35 @@ type EVMDepositor struct {
 	network     config.EVMNetwork
 	client      *ethclient.Client
 	privateKey  *ecdsa.PrivateKey
+
+	decimalsMu sync.Mutex
+	decimals   map[common.Address]uint8 // Token decimals, queried once per contract
+}
+
+// chainIDReader is the subset of the RPC client needed to verify the chain id
+type chainIDReader interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// contractCaller is the subset of the RPC client needed for read-only contract calls
+type contractCaller interface {
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// codeReader is the subset of the RPC client needed to check that an address is a contract
+type codeReader interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
 }
 
+// chainIDCheckTimeout bounds the chain id query made when creating a depositor
+const chainIDCheckTimeout = 15 * time.Second
+
 // ERC20 transfer function ABI
 const erc20TransferABI = `[{"constant":false,"inputs":[{"name":"_to","type":"address"},{"name":"_value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"type":"function"}]`
 
+// ERC20 decimals function ABI
+const erc20DecimalsABI = `[{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"}]`
+
 // NewEVMDepositor creates a new EVM depositor for a specific network
 func NewEVMDepositor(cfg config.EVMConfig, networkName string) (*EVMDepositor, error) {
 	// Get network configuration
@@ -51,9 +79,18 @@ func NewEVMDepositor(cfg config.EVMConfig, networkName string) (*EVMDepositor, e
 		return nil, fmt.Errorf("failed to connect to RPC endpoint: %w", err)
 	}
 
+	// Make sure we sign for the chain the RPC actually serves
+	ctx, cancel := context.WithTimeout(context.Background(), chainIDCheckTimeout)
+	defer cancel()
+	if err := verifyChainID(ctx, client, networkName, network.ChainID); err != nil {
+		client.Close()
+		return nil, err
+	}
+
 	// Parse private key
 	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(network.PrivateKey, "0x"))
 	if err != nil {
+		client.Close()
 		return nil, fmt.Errorf("invalid private key: %w", err)
 	}
 
@@ -63,15 +100,58 @@ func NewEVMDepositor(cfg config.EVMConfig, networkName string) (*EVMDepositor, e
 		network:     network,
 		client:      client,
 		privateKey:  privateKey,
+		decimals:    make(map[common.Address]uint8),
 	}, nil
 }
 
+// verifyChainID checks that the RPC endpoint serves the configured chain id.
+// Signing with the wrong EIP-155 chain id produces transactions that fail or are replayable elsewhere.
+func verifyChainID(ctx context.Context, reader chainIDReader, networkName string, configured int64) error {
+	actual, err := reader.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to query chain id for network %s: %w", networkName, err)
+	}
+
+	if configured == 0 {
+		return fmt.Errorf("chain_id not configured for network %s (RPC reports %s)", networkName, actual.String())
+	}
+
+	if actual.Cmp(big.NewInt(configured)) != 0 {
+		return fmt.Errorf("chain id mismatch for network %s: configured %d but RPC reports %s; check rpc_url and chain_id", networkName, configured, actual.String())
+	}
+
+	return nil
+}
+
+// verifyTokenContract checks that a token address has contract code on this network. A call to
+// an address without code (an EOA, or a contract on another chain) succeeds without moving any
+// tokens, so a mistyped token address would otherwise look like a successful deposit.
+func verifyTokenContract(ctx context.Context, reader codeReader, networkName string, tokenAddress common.Address) error {
+	code, err := reader.CodeAt(ctx, tokenAddress, nil)
+	if err != nil {
+		return fmt.Errorf("failed to check token contract %s on %s: %w", tokenAddress.Hex(), networkName, err)
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("token address %s has no contract code on %s; check the token contract address", tokenAddress.Hex(), networkName)
+	}
+	return nil
+}
+
 // SendDeposit sends a deposit to the specified address
 // For native tokens, address is the recipient
 // For ERC20 tokens, address format is: "recipient|tokenContract"
 func (e *EVMDepositor) SendDeposit(address string, amount string) (string, error) {
 	ctx := context.Background()
 
+	tx, err := e.buildDeposit(ctx, address, amount)
+	if err != nil {
+		return "", err
+	}
+	return e.broadcast(ctx, tx)
+}
+
+// buildDeposit builds and signs the deposit transaction for SendDeposit without sending it
+func (e *EVMDepositor) buildDeposit(ctx context.Context, address string, amount string) (*types.Transaction, error) {
 	// Parse address - check if it contains token contract address for ERC20
 	parts := strings.Split(address, "|")
 	recipientAddr := parts[0]
@@ -82,53 +162,64 @@ func (e *EVMDepositor) SendDeposit(address string, amount string) (string, error
 
 	// Validate recipient address
 	if !common.IsHexAddress(recipientAddr) {
-		return "", fmt.Errorf("invalid recipient address: %s", recipientAddr)
+		return nil, fmt.Errorf("invalid recipient address: %s", recipientAddr)
 	}
 
 	// Get sender address from private key
 	publicKey := e.privateKey.Public()
 	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
 	if !ok {
-		return "", fmt.Errorf("failed to get public key")
+		return nil, fmt.Errorf("failed to get public key")
 	}
 	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
 
 	// Get nonce
 	nonce, err := e.client.PendingNonceAt(ctx, fromAddress)
 	if err != nil {
-		return "", fmt.Errorf("failed to get nonce: %w", err)
+		return nil, fmt.Errorf("failed to get nonce: %w", err)
 	}
 
-	// Get gas price
-	gasPrice, err := e.getGasPrice(ctx)
+	// Price the transaction (gas price, or EIP-1559 tip and fee cap)
+	fees, err := suggestFees(ctx, e.client, e.networkName, e.network)
 	if err != nil {
-		return "", fmt.Errorf("failed to get gas price: %w", err)
+		return nil, err
 	}
 
 	// Determine if this is a native token or ERC20 transfer
-	var tx *types.Transaction
 	if tokenContract == "" {
 		// Native token transfer (ETH, BNB, MATIC, etc.)
-		tx, err = e.sendNativeToken(ctx, fromAddress, recipientAddr, amount, nonce, gasPrice)
-	} else {
-		// ERC20 token transfer
-		tx, err = e.sendERC20Token(ctx, fromAddress, recipientAddr, tokenContract, amount, nonce, gasPrice)
-	}
-
-	if err != nil {
-		return "", err
+		return e.sendNativeToken(ctx, fromAddress, recipientAddr, amount, nonce, fees)
 	}
+	// ERC20 token transfer
+	return e.sendERC20Token(ctx, fromAddress, recipientAddr, tokenContract, amount, nonce, fees)
+}
 
+// broadcast sends a signed deposit transaction and, when the network requires confirmations,
+// waits for them
+func (e *EVMDepositor) broadcast(ctx context.Context, tx *types.Transaction) (string, error) {
 	// Send transaction
 	if err := e.client.SendTransaction(ctx, tx); err != nil {
 		return "", fmt.Errorf("failed to send transaction: %w", err)
 	}
 
+	// Optionally wait until the deposit is buried deep enough to be reorg-safe
+	if e.network.Confirmations > 0 {
+		waitCtx, cancel := context.WithTimeout(ctx, confirmationTimeout)
+		defer cancel()
+		if _, err := waitForConfirmations(waitCtx, e.client, tx, e.network.Confirmations, confirmationPollInterval); err != nil {
+			// The deposit was broadcast; a slow chain is left to swap verification
+			if errors.Is(err, errConfirmationTimeout) {
+				return tx.Hash().Hex(), nil
+			}
+			return "", err
+		}
+	}
+
 	return tx.Hash().Hex(), nil
 }
 
 // sendNativeToken sends native blockchain tokens (ETH, BNB, etc.)
-func (e *EVMDepositor) sendNativeToken(ctx context.Context, from common.Address, to string, amount string, nonce uint64, gasPrice *big.Int) (*types.Transaction, error) {
+func (e *EVMDepositor) sendNativeToken(ctx context.Context, from common.Address, to string, amount string, nonce uint64, fees *txFees) (*types.Transaction, error) {
 	toAddress := common.HexToAddress(to)
 
 	// Parse amount (assuming it's in Ether/BNB/etc., convert to Wei)
@@ -136,6 +227,9 @@ func (e *EVMDepositor) sendNativeToken(ctx context.Context, from common.Address,
 	if err != nil {
 		return nil, fmt.Errorf("invalid amount: %w", err)
 	}
+	if amountWei.Sign() <= 0 {
+		return nil, &BelowMinimumError{Chain: e.networkName, Amount: amount, Minimum: "0.000000000000000001"}
+	}
 
 	// Check balance
 	balance, err := e.client.BalanceAt(ctx, from, nil)
@@ -151,21 +245,28 @@ func (e *EVMDepositor) sendNativeToken(ctx context.Context, from common.Address,
 	gasLimit := uint64(21000) // Standard ETH transfer
 	if e.network.GasLimit != nil {
 		gasLimit = *e.network.GasLimit
+	} else if l2Model(e.network) != "" {
+		msg := ethereum.CallMsg{
+			From:  from,
+			To:    &toAddress,
+			Value: amountWei,
+		}
+		if gasLimit, err = estimateL2GasLimit(ctx, e.client, e.networkName, msg); err != nil {
+			return nil, err
+		}
 	}
 
 	// Create transaction
-	tx := types.NewTransaction(
-		nonce,
-		toAddress,
-		amountWei,
-		gasLimit,
-		gasPrice,
-		nil,
-	)
+	chainID := big.NewInt(e.network.ChainID)
+	tx := newTransaction(chainID, nonce, toAddress, amountWei, gasLimit, fees, nil)
+
+	// On L2s the fees, including any L1 data fee, come out of the same balance as the amount
+	if err := e.checkL2Funds(ctx, from, amountWei, tx); err != nil {
+		return nil, err
+	}
 
 	// Sign transaction
-	chainID := big.NewInt(e.network.ChainID)
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), e.privateKey)
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), e.privateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign transaction: %w", err)
 	}
@@ -174,7 +275,7 @@ func (e *EVMDepositor) sendNativeToken(ctx context.Context, from common.Address,
 }
 
 // sendERC20Token sends ERC20 tokens
-func (e *EVMDepositor) sendERC20Token(ctx context.Context, from common.Address, to string, tokenContract string, amount string, nonce uint64, gasPrice *big.Int) (*types.Transaction, error) {
+func (e *EVMDepositor) sendERC20Token(ctx context.Context, from common.Address, to string, tokenContract string, amount string, nonce uint64, fees *txFees) (*types.Transaction, error) {
 	toAddress := common.HexToAddress(to)
 	tokenAddress := common.HexToAddress(tokenContract)
 
@@ -183,12 +284,23 @@ func (e *EVMDepositor) sendERC20Token(ctx context.Context, from common.Address,
 		return nil, fmt.Errorf("invalid token contract address: %s", tokenContract)
 	}
 
-	// Parse amount (assuming it's in token units, convert to smallest unit)
-	// Note: This assumes 18 decimals. For production, you should query the token's decimals() function
-	amountTokens, err := parseAmount(amount)
+	// Make sure the token is a contract before calling it
+	if err := verifyTokenContract(ctx, e.client, e.networkName, tokenAddress); err != nil {
+		return nil, err
+	}
+
+	// Convert the amount from token units to the token's smallest unit
+	decimals, err := e.getERC20Decimals(ctx, tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	amountTokens, err := parseTokenAmount(amount, decimals)
 	if err != nil {
 		return nil, fmt.Errorf("invalid amount: %w", err)
 	}
+	if amountTokens.Sign() <= 0 {
+		return nil, &BelowMinimumError{Chain: e.networkName, Amount: amount, Minimum: strconv.FormatFloat(1/pow10(decimals), 'f', -1, 64)}
+	}
 
 	// Get token balance
 	balance, err := e.getERC20Balance(ctx, tokenAddress, from)
@@ -223,46 +335,32 @@ func (e *EVMDepositor) sendERC20Token(ctx context.Context, from common.Address,
 			To:   &tokenAddress,
 			Data: data,
 		}
-		estimatedGas, err := e.client.EstimateGas(ctx, msg)
-		if err == nil {
+		if l2Model(e.network) != "" {
+			// The typical L1 limit is no safe fallback where gas also pays for L1 data
+			if gasLimit, err = estimateL2GasLimit(ctx, e.client, e.networkName, msg); err != nil {
+				return nil, err
+			}
+		} else if estimatedGas, err := e.client.EstimateGas(ctx, msg); err == nil {
 			gasLimit = estimatedGas * 120 / 100 // Add 20% buffer
 		}
 	}
 
-	// Create transaction
-	tx := types.NewTransaction(
-		nonce,
-		tokenAddress,
-		big.NewInt(0), // No ETH value for ERC20 transfer
-		gasLimit,
-		gasPrice,
-		data,
-	)
-
-	// Sign transaction
+	// Create transaction (no ETH value for ERC20 transfer)
 	chainID := big.NewInt(e.network.ChainID)
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), e.privateKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to sign transaction: %w", err)
-	}
-
-	return signedTx, nil
-}
+	tx := newTransaction(chainID, nonce, tokenAddress, big.NewInt(0), gasLimit, fees, data)
 
-// getGasPrice returns the gas price to use for transactions
-func (e *EVMDepositor) getGasPrice(ctx context.Context) (*big.Int, error) {
-	// Use configured gas price if available
-	if e.network.GasPrice != nil {
-		return big.NewInt(*e.network.GasPrice), nil
+	// On L2s make sure the native balance covers the fees, including any L1 data fee
+	if err := e.checkL2Funds(ctx, from, big.NewInt(0), tx); err != nil {
+		return nil, err
 	}
 
-	// Otherwise, get current gas price from network
-	gasPrice, err := e.client.SuggestGasPrice(ctx)
+	// Sign transaction
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), e.privateKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get gas price: %w", err)
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
-	return gasPrice, nil
+	return signedTx, nil
 }
 
 // getERC20Balance gets the balance of an ERC20 token for an address
@@ -289,6 +387,10 @@ func (e *EVMDepositor) getERC20Balance(ctx context.Context, tokenAddress common.
 	if err != nil {
 		return nil, fmt.Errorf("failed to call balanceOf: %w", err)
 	}
+	// A token contract returns a uint256; anything shorter means it doesn't implement balanceOf
+	if len(result) < 32 {
+		return nil, fmt.Errorf("contract %s did not return a balance; it does not look like an ERC20 token", tokenAddress.Hex())
+	}
 
 	balance := new(big.Int)
 	balance.SetBytes(result)
@@ -296,25 +398,74 @@ func (e *EVMDepositor) getERC20Balance(ctx context.Context, tokenAddress common.
 	return balance, nil
 }
 
+// getERC20Decimals returns a token's decimals, querying the contract the first time it is used
+func (e *EVMDepositor) getERC20Decimals(ctx context.Context, tokenAddress common.Address) (uint8, error) {
+	return e.cachedERC20Decimals(ctx, e.client, tokenAddress)
+}
+
+// cachedERC20Decimals returns a token's cached decimals, asking caller only on a cache miss
+func (e *EVMDepositor) cachedERC20Decimals(ctx context.Context, caller contractCaller, tokenAddress common.Address) (uint8, error) {
+	e.decimalsMu.Lock()
+	defer e.decimalsMu.Unlock()
+
+	if decimals, ok := e.decimals[tokenAddress]; ok {
+		return decimals, nil
+	}
+
+	decimals, err := fetchERC20Decimals(ctx, caller, tokenAddress)
+	if err != nil {
+		return 0, err
+	}
+	e.decimals[tokenAddress] = decimals
+	return decimals, nil
+}
+
+// fetchERC20Decimals calls a token's decimals() function
+func fetchERC20Decimals(ctx context.Context, caller contractCaller, tokenAddress common.Address) (uint8, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(erc20DecimalsABI))
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse decimals ABI: %w", err)
+	}
+
+	data, err := parsedABI.Pack("decimals")
+	if err != nil {
+		return 0, fmt.Errorf("failed to pack decimals data: %w", err)
+	}
+
+	result, err := caller.CallContract(ctx, ethereum.CallMsg{To: &tokenAddress, Data: data}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call decimals on token %s: %w", tokenAddress.Hex(), err)
+	}
+	if len(result) < 32 {
+		return 0, fmt.Errorf("token %s did not return its decimals; it does not look like an ERC20 token", tokenAddress.Hex())
+	}
+
+	decimals := new(big.Int).SetBytes(result[:32])
+	if !decimals.IsUint64() || decimals.Uint64() > 77 {
+		return 0, fmt.Errorf("token %s reports unsupported decimals %s", tokenAddress.Hex(), decimals.String())
+	}
+	return uint8(decimals.Uint64()), nil
+}
+
 // parseAmount converts a string amount to wei/smallest unit
-// Assumes the amount is in the main unit (e.g., ETH, not wei) with up to 18 decimals
+// Assumes the amount is in the main unit (e.g., ETH, not wei) with 18 decimals
 func parseAmount(amount string) (*big.Int, error) {
-	// Parse as float and convert to wei (multiply by 10^18)
-	amountFloat := new(big.Float)
-	_, ok := amountFloat.SetString(amount)
+	return parseTokenAmount(amount, 18)
+}
+
+// parseTokenAmount converts a decimal amount in token units to the token's smallest unit.
+// Digits beyond the token's precision are truncated.
+func parseTokenAmount(amount string, decimals uint8) (*big.Int, error) {
+	value, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
 	if !ok {
 		return nil, fmt.Errorf("invalid amount format: %s", amount)
 	}
 
-	// Multiply by 10^18 to convert to wei
-	weiPerEther := new(big.Float).SetInt(big.NewInt(1e18))
-	amountWei := new(big.Float).Mul(amountFloat, weiPerEther)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	value.Mul(value, new(big.Rat).SetInt(scale))
 
-	// Convert to big.Int
-	result := new(big.Int)
-	amountWei.Int(result)
-
-	return result, nil
+	// Quo truncates toward zero
+	return new(big.Int).Quo(value.Num(), value.Denom()), nil
 }
 
 // GetTransactionInfo retrieves information about a transaction
@@ -364,3 +515,19 @@ func (e *EVMDepositor) Close() {
 		e.client.Close()
 	}
 }
+
+// GetBalance returns the native token balance (ETH, BNB, etc.) of the depositor account
+func (e *EVMDepositor) GetBalance() (float64, error) {
+	publicKeyECDSA, ok := e.privateKey.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return 0, fmt.Errorf("failed to get public key")
+	}
+
+	balance, err := e.client.BalanceAt(context.Background(), crypto.PubkeyToAddress(*publicKeyECDSA), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	ether, _ := new(big.Float).Quo(new(big.Float).SetInt(balance), big.NewFloat(1e18)).Float64()
+	return ether, nil
+}
@@ -0,0 +1,146 @@
+package deposit
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeChainIDReader reports a fixed chain id
+type fakeChainIDReader struct {
+	id  int64
+	err error
+}
+
+func (f fakeChainIDReader) ChainID(context.Context) (*big.Int, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return big.NewInt(f.id), nil
+}
+
+// fakeCodeReader returns fixed contract code for every address
+type fakeCodeReader struct {
+	code []byte
+	err  error
+}
+
+func (f fakeCodeReader) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return f.code, f.err
+}
+
+func TestVerifyChainID(t *testing.T) {
+	tests := []struct {
+		name       string
+		reader     fakeChainIDReader
+		configured int64
+		wantErr    string // Empty expects no error
+	}{
+		{name: "matches", reader: fakeChainIDReader{id: 1}, configured: 1},
+		{name: "matches a large id", reader: fakeChainIDReader{id: 42161}, configured: 42161},
+		{name: "mismatch", reader: fakeChainIDReader{id: 56}, configured: 1, wantErr: "configured 1 but RPC reports 56"},
+		{name: "not configured", reader: fakeChainIDReader{id: 137}, wantErr: "chain_id not configured for network polygon (RPC reports 137)"},
+		{name: "RPC error", reader: fakeChainIDReader{err: errors.New("connection refused")}, configured: 1, wantErr: "connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyChainID(context.Background(), tt.reader, "polygon", tt.configured)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyTokenContract(t *testing.T) {
+	token := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+
+	tests := []struct {
+		name    string
+		reader  fakeCodeReader
+		wantErr string // Empty expects no error
+	}{
+		{name: "contract", reader: fakeCodeReader{code: []byte{0x60, 0x80, 0x60, 0x40}}},
+		{name: "no code", reader: fakeCodeReader{}, wantErr: "has no contract code on ethereum"},
+		{name: "empty code", reader: fakeCodeReader{code: []byte{}}, wantErr: "has no contract code"},
+		{name: "RPC error", reader: fakeCodeReader{err: errors.New("connection refused")}, wantErr: "failed to check token contract"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyTokenContract(context.Background(), tt.reader, "ethereum", token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// fakeDecimalsCaller answers every decimals() call with a fixed ABI-encoded result, counting calls
+type fakeDecimalsCaller struct {
+	result []byte
+	calls  int
+}
+
+func (f *fakeDecimalsCaller) CallContract(context.Context, ethereum.CallMsg, *big.Int) ([]byte, error) {
+	f.calls++
+	return f.result, nil
+}
+
+func TestERC20AmountsUseTokenDecimals(t *testing.T) {
+	usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	caller := &fakeDecimalsCaller{result: common.LeftPadBytes([]byte{6}, 32)}
+	e := &EVMDepositor{networkName: "ethereum", decimals: make(map[common.Address]uint8)}
+
+	tests := []struct {
+		amount string
+		want   string // Base units
+	}{
+		{amount: "100", want: "100000000"},
+		{amount: "0.5", want: "500000"},
+		{amount: "1.23456789", want: "1234567"}, // Digits beyond 6 decimals are truncated
+	}
+	for _, tt := range tests {
+		decimals, err := e.cachedERC20Decimals(context.Background(), caller, usdc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := parseTokenAmount(tt.amount, decimals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != tt.want {
+			t.Errorf("%s USDC = %s base units, want %s", tt.amount, got, tt.want)
+		}
+	}
+	if caller.calls != 1 {
+		t.Errorf("decimals() called %d times, want once per token", caller.calls)
+	}
+
+	// A contract that doesn't answer decimals() isn't cached as a token
+	notToken := &fakeDecimalsCaller{}
+	if _, err := e.cachedERC20Decimals(context.Background(), notToken, common.Address{1}); err == nil || !strings.Contains(err.Error(), "does not look like an ERC20 token") {
+		t.Errorf("error = %v, want a non-ERC20 contract rejected", err)
+	}
+	if _, cached := e.decimals[common.Address{1}]; cached {
+		t.Error("failed lookup was cached")
+	}
+}
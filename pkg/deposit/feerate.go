@@ -0,0 +1,81 @@
+package deposit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"near-swap/config"
+)
+
+// minRelayFeeRate is the lowest fee rate in sat/vB that nodes relay by default
+const minRelayFeeRate = 1.0
+
+// satPerVBytePerBTCPerKvB converts estimatesmartfee's BTC/kvB into sat/vB
+const satPerVBytePerBTCPerKvB = 1e8 / 1000
+
+// smartFeeEstimate is the output of estimatesmartfee
+type smartFeeEstimate struct {
+	FeeRate *float64 `json:"feerate"` // BTC per kvB; missing when the node has no estimate
+	Errors  []string `json:"errors"`
+	Blocks  int      `json:"blocks"`
+}
+
+// parseSmartFeeRate converts estimatesmartfee output into a fee rate in sat/vB, rounded up to
+// the 0.001 sat/vB the wallet accepts and clamped between the minimum relay fee and maxRate.
+// It reports false when the node has no estimate yet, e.g. right after it started.
+func parseSmartFeeRate(output []byte, maxRate float64) (float64, bool, error) {
+	var estimate smartFeeEstimate
+	if err := json.Unmarshal(output, &estimate); err != nil {
+		return 0, false, fmt.Errorf("failed to parse fee estimate: %w", err)
+	}
+	if estimate.FeeRate == nil || *estimate.FeeRate <= 0 {
+		return 0, false, nil
+	}
+
+	rate := math.Max(math.Ceil(*estimate.FeeRate*satPerVBytePerBTCPerKvB*1000)/1000, minRelayFeeRate)
+	if maxRate > 0 {
+		rate = math.Min(rate, maxRate)
+	}
+	return rate, true, nil
+}
+
+// feeRate returns the fee rate in sat/vB to send with, or 0 to leave it to the wallet. A
+// fee_rate of "auto" is estimated with estimatesmartfee for fee_conf_target blocks and capped
+// at max_fee_rate; when the node has no estimate the wallet picks the fee itself.
+func (b *BitcoinDepositor) feeRate() (float64, error) {
+	switch b.config.FeeRate {
+	case "":
+		return 0, nil
+	case config.BitcoinFeeRateAuto:
+	default:
+		rate, err := strconv.ParseFloat(b.config.FeeRate, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid fee_rate '%s': %w", b.config.FeeRate, err)
+		}
+		return rate, nil
+	}
+
+	args := b.buildBaseArgs()
+	args = append(args, "estimatesmartfee", strconv.Itoa(b.config.FeeConfTarget))
+
+	cmd := exec.Command(b.config.CLIPath, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return 0, fmt.Errorf("bitcoin-cli estimatesmartfee failed: %w\nOutput: %s", err, string(output))
+	}
+
+	rate, ok, err := parseSmartFeeRate(output, b.config.MaxFeeRate)
+	if err != nil || !ok {
+		return 0, err
+	}
+	return rate, nil
+}
+
+// formatFeeRate formats a fee rate in sat/vB for bitcoin-cli
+func formatFeeRate(rate float64) string {
+	return strings.TrimRight(strings.TrimRight(strconv.FormatFloat(rate, 'f', 3, 64), "0"), ".")
+}
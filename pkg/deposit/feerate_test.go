@@ -0,0 +1,68 @@
+package deposit
+
+import (
+	"strings"
+	"testing"
+
+	"near-swap/config"
+)
+
+func TestFeeRate(t *testing.T) {
+	tests := []struct {
+		feeRate string
+		want    float64
+		wantErr string // Empty expects no error
+	}{
+		{feeRate: "", want: 0},
+		{feeRate: "0", want: 0},
+		{feeRate: "2.5", want: 2.5},
+		{feeRate: "fast", wantErr: "invalid fee_rate"},
+		// auto asks the node, which doesn't exist here
+		{feeRate: config.BitcoinFeeRateAuto, wantErr: "estimatesmartfee failed"},
+	}
+
+	for _, tt := range tests {
+		b := NewBitcoinDepositor(config.BitcoinConfig{CLIPath: "/nonexistent/bitcoin-cli", FeeRate: tt.feeRate, FeeConfTarget: 6})
+		got, err := b.feeRate()
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("feeRate(%q) error = %v, want it to mention %q", tt.feeRate, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("feeRate(%q) = %v, %v; want %v", tt.feeRate, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseSmartFeeRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		maxRate float64
+		want    float64
+		wantOK  bool
+		wantErr bool
+	}{
+		{name: "estimate", output: `{"feerate":0.00012345,"blocks":6}`, maxRate: 100, want: 12.345, wantOK: true},
+		{name: "rounded up", output: `{"feerate":0.000123456,"blocks":6}`, maxRate: 100, want: 12.346, wantOK: true},
+		{name: "raised to the relay minimum", output: `{"feerate":0.000001,"blocks":6}`, maxRate: 100, want: minRelayFeeRate, wantOK: true},
+		{name: "capped", output: `{"feerate":0.005,"blocks":6}`, maxRate: 100, want: 100, wantOK: true},
+		{name: "no estimate yet", output: `{"errors":["Insufficient data or no feerate found"],"blocks":0}`, maxRate: 100},
+		{name: "not JSON", output: `error code: -32601`, maxRate: 100, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := parseSmartFeeRate([]byte(tt.output), tt.maxRate)
+			if (err != nil) != tt.wantErr || ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseSmartFeeRate = %v, %v, %v; want %v, %v, error: %v", got, ok, err, tt.want, tt.wantOK, tt.wantErr)
+			}
+		})
+	}
+
+	if got := formatFeeRate(12.5); got != "12.5" {
+		t.Errorf("formatFeeRate(12.5) = %q", got)
+	}
+}
@@ -0,0 +1,103 @@
+package deposit
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"near-swap/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// baseFeeMultiplier is how many times the latest base fee an EIP-1559 fee cap allows for, so a
+// transaction stays includable through several blocks of rising base fees
+const baseFeeMultiplier = 2
+
+// feeBackend is the subset of the RPC client needed to price a transaction
+type feeBackend interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// txFees prices a transaction: GasPrice for legacy transactions, or TipCap and FeeCap for
+// EIP-1559 dynamic fee transactions
+type txFees struct {
+	GasPrice *big.Int
+	TipCap   *big.Int
+	FeeCap   *big.Int
+}
+
+// dynamic reports whether the fees are for an EIP-1559 transaction
+func (f *txFees) dynamic() bool {
+	return f.FeeCap != nil
+}
+
+// suggestFees prices a transaction for a network according to its fee_mode, preferring the
+// network's configured values over the node's suggestions
+func suggestFees(ctx context.Context, backend feeBackend, networkName string, network config.EVMNetwork) (*txFees, error) {
+	if !strings.EqualFold(network.FeeMode, config.FeeModeEIP1559) {
+		if network.GasPrice != nil {
+			return &txFees{GasPrice: big.NewInt(*network.GasPrice)}, nil
+		}
+		gasPrice, err := backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get gas price: %w", err)
+		}
+		return &txFees{GasPrice: gasPrice}, nil
+	}
+
+	header, err := backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block header: %w", err)
+	}
+	if header.BaseFee == nil {
+		return nil, fmt.Errorf("network %s does not report a base fee; set fee_mode to %s", networkName, config.FeeModeLegacy)
+	}
+
+	var tipCap *big.Int
+	if network.MaxPriorityFee != nil {
+		tipCap = big.NewInt(*network.MaxPriorityFee)
+	} else if tipCap, err = backend.SuggestGasTipCap(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get priority fee: %w", err)
+	}
+
+	feeCap := new(big.Int).Mul(header.BaseFee, big.NewInt(baseFeeMultiplier))
+	feeCap.Add(feeCap, tipCap)
+	if network.MaxFee != nil {
+		maxFee := big.NewInt(*network.MaxFee)
+		if maxFee.Cmp(header.BaseFee) < 0 {
+			return nil, fmt.Errorf("max_fee %s is below the current base fee %s on %s; the transaction would not be mined",
+				maxFee.String(), header.BaseFee.String(), networkName)
+		}
+		if feeCap.Cmp(maxFee) > 0 {
+			feeCap = maxFee
+		}
+	}
+	// The tip can never exceed the fee cap
+	if tipCap.Cmp(feeCap) > 0 {
+		tipCap = new(big.Int).Set(feeCap)
+	}
+
+	return &txFees{TipCap: tipCap, FeeCap: feeCap}, nil
+}
+
+// newTransaction builds an unsigned legacy or dynamic fee transaction priced by fees
+func newTransaction(chainID *big.Int, nonce uint64, to common.Address, value *big.Int, gasLimit uint64, fees *txFees, data []byte) *types.Transaction {
+	if fees.dynamic() {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: fees.TipCap,
+			GasFeeCap: fees.FeeCap,
+			Gas:       gasLimit,
+			To:        &to,
+			Value:     value,
+			Data:      data,
+		})
+	}
+	return types.NewTransaction(nonce, to, value, gasLimit, fees.GasPrice, data)
+}
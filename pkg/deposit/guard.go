@@ -0,0 +1,49 @@
+package deposit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SelfDepositError is returned when a quote's deposit address points back at one of the
+// user's own addresses, so an auto-deposit would never reach the 1Click protocol
+type SelfDepositError struct {
+	DepositAddress string
+	MatchedField   string // "recipient" or "refund"
+}
+
+func (e *SelfDepositError) Error() string {
+	return fmt.Sprintf("deposit address %s is the same as your %s address; sending funds there would not start the swap. "+
+		"This happens for some same-chain routes that settle internally - refusing to auto-deposit, check the quote manually",
+		e.DepositAddress, e.MatchedField)
+}
+
+// CheckDepositAddress refuses deposit addresses that equal the refund address, or the
+// recipient address when the swap stays on the same chain
+func CheckDepositAddress(sourceChain, destChain, depositAddress, recipientAddr, refundAddr string) error {
+	if depositAddress == "" {
+		return fmt.Errorf("quote did not include a deposit address")
+	}
+
+	if sameAddress(depositAddress, refundAddr) {
+		return &SelfDepositError{DepositAddress: depositAddress, MatchedField: "refund"}
+	}
+
+	if strings.EqualFold(sourceChain, destChain) && sameAddress(depositAddress, recipientAddr) {
+		return &SelfDepositError{DepositAddress: depositAddress, MatchedField: "recipient"}
+	}
+
+	return nil
+}
+
+// sameAddress compares addresses, ignoring case for hex (EVM) addresses whose checksum casing varies
+func sameAddress(a, b string) bool {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	if a == "" || b == "" {
+		return false
+	}
+	if strings.HasPrefix(strings.ToLower(a), "0x") {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
@@ -0,0 +1,45 @@
+package deposit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckDepositAddress(t *testing.T) {
+	const (
+		recipient = "me.near"
+		refund    = "0xAbC0000000000000000000000000000000000001"
+	)
+
+	tests := []struct {
+		name           string
+		sourceChain    string
+		destChain      string
+		depositAddress string
+		wantMatched    string // Field the deposit address matched; empty when allowed
+		wantErr        bool
+	}{
+		{name: "protocol address", sourceChain: "near", destChain: "near", depositAddress: "deposit.near"},
+		{name: "same chain, deposit to recipient", sourceChain: "near", destChain: "NEAR", depositAddress: "me.near", wantMatched: "recipient", wantErr: true},
+		{name: "cross chain, recipient address reused", sourceChain: "eth", destChain: "near", depositAddress: "me.near"},
+		{name: "deposit to refund", sourceChain: "eth", destChain: "near", depositAddress: refund, wantMatched: "refund", wantErr: true},
+		{name: "hex checksum casing ignored", sourceChain: "eth", destChain: "near", depositAddress: "0xabc0000000000000000000000000000000000001", wantMatched: "refund", wantErr: true},
+		{name: "no deposit address", sourceChain: "near", destChain: "near", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckDepositAddress(tt.sourceChain, tt.destChain, tt.depositAddress, recipient, refund)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error: %v", err, tt.wantErr)
+			}
+			var selfDeposit *SelfDepositError
+			if errors.As(err, &selfDeposit) != (tt.wantMatched != "") {
+				t.Fatalf("error = %v, want a self-deposit error: %v", err, tt.wantMatched != "")
+			}
+			if selfDeposit != nil && selfDeposit.MatchedField != tt.wantMatched {
+				t.Errorf("matched %s, want %s", selfDeposit.MatchedField, tt.wantMatched)
+			}
+		})
+	}
+}
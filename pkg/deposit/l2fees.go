@@ -0,0 +1,116 @@
+package deposit
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"near-swap/config"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// gasPriceOracleAddress is the OP stack GasPriceOracle predeploy, which prices the L1 data fee
+var gasPriceOracleAddress = common.HexToAddress("0x420000000000000000000000000000000000000F")
+
+// GasPriceOracle getL1Fee function ABI
+const gasPriceOracleABI = `[{"inputs":[{"name":"_data","type":"bytes"}],"name":"getL1Fee","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+// l2GasBuffer is the percentage added to estimated gas on L2s, whose L1 component moves with
+// L1 prices between estimation and inclusion
+const l2GasBuffer = 120
+
+// gasEstimator is the subset of the RPC client needed to estimate a transaction's gas
+type gasEstimator interface {
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+}
+
+// l2Model returns the network's L2 fee model, or an empty string for L1-style networks
+func l2Model(network config.EVMNetwork) string {
+	return strings.ToLower(network.L2)
+}
+
+// estimateL2GasLimit estimates the gas for msg with a safety buffer. The flat 21000 of an L1
+// transfer is wrong on Arbitrum, where gas also pays for the transaction's L1 calldata.
+func estimateL2GasLimit(ctx context.Context, estimator gasEstimator, networkName string, msg ethereum.CallMsg) (uint64, error) {
+	gas, err := estimator.EstimateGas(ctx, msg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate gas on %s: %w", networkName, err)
+	}
+	return gas * l2GasBuffer / 100, nil
+}
+
+// l1DataFee asks the OP stack gas price oracle what tx will be charged for posting its data
+// to L1. The fee is taken from the sender's balance on top of the L2 gas.
+func l1DataFee(ctx context.Context, caller contractCaller, tx *types.Transaction) (*big.Int, error) {
+	encoded, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(gasPriceOracleABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse gas price oracle ABI: %w", err)
+	}
+	data, err := parsedABI.Pack("getL1Fee", encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack getL1Fee data: %w", err)
+	}
+
+	result, err := caller.CallContract(ctx, ethereum.CallMsg{To: &gasPriceOracleAddress, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query L1 data fee: %w", err)
+	}
+	values, err := parsedABI.Unpack("getL1Fee", result)
+	if err != nil || len(values) != 1 {
+		return nil, fmt.Errorf("gas price oracle returned an invalid L1 data fee")
+	}
+	fee, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("gas price oracle returned an invalid L1 data fee")
+	}
+	return fee, nil
+}
+
+// maxTransactionFee returns the most tx can cost its sender in fees: its gas limit at the
+// highest price per gas it allows, plus the L1 data fee on OP stack networks
+func maxTransactionFee(ctx context.Context, caller contractCaller, network config.EVMNetwork, tx *types.Transaction) (*big.Int, error) {
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasFeeCap())
+	if l2Model(network) != config.L2FeeOptimism {
+		return fee, nil
+	}
+
+	l1Fee, err := l1DataFee(ctx, caller, tx)
+	if err != nil {
+		return nil, err
+	}
+	return fee.Add(fee, l1Fee), nil
+}
+
+// checkL2Funds makes sure the sender's native balance covers value plus tx's L2 and L1 fees,
+// so an L2 deposit fails here instead of on-chain. L1-style networks aren't checked.
+func (e *EVMDepositor) checkL2Funds(ctx context.Context, from common.Address, value *big.Int, tx *types.Transaction) error {
+	if l2Model(e.network) == "" {
+		return nil
+	}
+
+	fee, err := maxTransactionFee(ctx, e.client, e.network, tx)
+	if err != nil {
+		return err
+	}
+	balance, err := e.client.BalanceAt(ctx, from, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	need := new(big.Int).Add(value, fee)
+	if balance.Cmp(need) < 0 {
+		return fmt.Errorf("insufficient balance on %s: have %s wei, need %s wei (%s wei plus up to %s wei in fees)",
+			e.networkName, balance.String(), need.String(), value.String(), fee.String())
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package deposit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"near-swap/config"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeGasEstimator returns a fixed gas estimate
+type fakeGasEstimator struct {
+	gas uint64
+	err error
+}
+
+func (f fakeGasEstimator) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	return f.gas, f.err
+}
+
+// fakeGasPriceOracle answers getL1Fee with a fixed fee, failing calls that don't carry the
+// encoded transaction to the oracle predeploy
+type fakeGasPriceOracle struct {
+	fee   *big.Int
+	tx    []byte // Encoded transaction the fee is asked for
+	calls int
+}
+
+func (f *fakeGasPriceOracle) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	f.calls++
+	parsedABI, err := abi.JSON(strings.NewReader(gasPriceOracleABI))
+	if err != nil {
+		return nil, err
+	}
+	if msg.To == nil || *msg.To != gasPriceOracleAddress {
+		return nil, errors.New("call not sent to the gas price oracle")
+	}
+	args, err := parsedABI.Methods["getL1Fee"].Inputs.Unpack(msg.Data[4:])
+	if err != nil || !bytes.Equal(args[0].([]byte), f.tx) {
+		return nil, errors.New("getL1Fee not called with the encoded transaction")
+	}
+	return common.LeftPadBytes(f.fee.Bytes(), 32), nil
+}
+
+func TestEstimateL2GasLimit(t *testing.T) {
+	gas, err := estimateL2GasLimit(context.Background(), fakeGasEstimator{gas: 700000}, "arbitrum", ethereum.CallMsg{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gas != 840000 {
+		t.Errorf("gas limit = %d, want the 700000 estimate plus 20%%", gas)
+	}
+
+	_, err = estimateL2GasLimit(context.Background(), fakeGasEstimator{err: errors.New("execution reverted")}, "arbitrum", ethereum.CallMsg{})
+	if err == nil || !strings.Contains(err.Error(), "failed to estimate gas on arbitrum") {
+		t.Errorf("error = %v, want the estimate failure", err)
+	}
+}
+
+func TestMaxTransactionFee(t *testing.T) {
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID: big.NewInt(10), Nonce: 3, To: &to, Value: big.NewInt(1e15),
+		Gas: 30000, GasFeeCap: big.NewInt(2e9), GasTipCap: big.NewInt(1e6),
+	})
+	encoded, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		l2        string
+		wantFee   string // Wei
+		wantCalls int
+	}{
+		{name: "L1 network pays gas only", wantFee: "60000000000000"},
+		{name: "arbitrum pays gas only", l2: config.L2FeeArbitrum, wantFee: "60000000000000"},
+		{name: "optimism adds the L1 data fee", l2: config.L2FeeOptimism, wantFee: "60000123456789", wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oracle := &fakeGasPriceOracle{fee: big.NewInt(123456789), tx: encoded}
+			fee, err := maxTransactionFee(context.Background(), oracle, config.EVMNetwork{L2: tt.l2}, tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fee.String() != tt.wantFee {
+				t.Errorf("max fee = %s wei, want %s", fee, tt.wantFee)
+			}
+			if oracle.calls != tt.wantCalls {
+				t.Errorf("oracle called %d times, want %d", oracle.calls, tt.wantCalls)
+			}
+		})
+	}
+}
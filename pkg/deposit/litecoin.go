@@ -0,0 +1,154 @@
+package deposit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"near-swap/config"
+)
+
+// litecoinCommand builds the litecoin-cli invocations; replaced to run without a node
+var litecoinCommand = exec.Command
+
+// LitecoinDepositor handles Litecoin deposits using litecoin-cli
+type LitecoinDepositor struct {
+	config config.LitecoinConfig
+}
+
+// NewLitecoinDepositor creates a new Litecoin depositor
+func NewLitecoinDepositor(cfg config.LitecoinConfig) *LitecoinDepositor {
+	return &LitecoinDepositor{
+		config: cfg,
+	}
+}
+
+// SendDeposit sends Litecoin to the specified address
+func (l *LitecoinDepositor) SendDeposit(address string, amount string) (string, error) {
+	// Validate litecoin-cli is available
+	if err := l.validateCLI(); err != nil {
+		return "", fmt.Errorf("litecoin-cli validation failed: %w", err)
+	}
+
+	// Get wallet balance first
+	balance, err := l.getBalance()
+	if err != nil {
+		return "", fmt.Errorf("failed to get wallet balance: %w", err)
+	}
+
+	// Parse amount
+	amountFloat, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid amount: %w", err)
+	}
+
+	// Refuse dust outputs the node would reject
+	if err := checkMinimumSend("litecoin", amountFloat, MinLitecoinSend); err != nil {
+		return "", err
+	}
+
+	// Check if we have enough balance
+	if balance < amountFloat {
+		return "", fmt.Errorf("insufficient balance: have %.8f LTC, need %.8f LTC", balance, amountFloat)
+	}
+
+	args := l.buildBaseArgs()
+	args = append(args, "sendtoaddress", address, amount)
+
+	output, err := l.run(args...)
+	if err != nil {
+		return "", fmt.Errorf("litecoin-cli sendtoaddress failed: %w\nOutput: %s", err, string(output))
+	}
+
+	// Extract transaction ID
+	txid := strings.TrimSpace(string(output))
+	if txid == "" {
+		return "", fmt.Errorf("empty transaction ID returned")
+	}
+
+	return txid, nil
+}
+
+// GetBalance returns the wallet balance in LTC
+func (l *LitecoinDepositor) GetBalance() (float64, error) {
+	return l.getBalance()
+}
+
+// getBalance returns the wallet balance
+func (l *LitecoinDepositor) getBalance() (float64, error) {
+	args := l.buildBaseArgs()
+	args = append(args, "getbalance")
+
+	output, err := l.run(args...)
+	if err != nil {
+		return 0, fmt.Errorf("litecoin-cli getbalance failed: %w\nOutput: %s", err, string(output))
+	}
+
+	balance, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// validateCLI checks if litecoin-cli is available and working
+func (l *LitecoinDepositor) validateCLI() error {
+	args := l.buildBaseArgs()
+	args = append(args, "getblockchaininfo")
+
+	output, err := l.run(args...)
+	if err != nil {
+		return fmt.Errorf("litecoin-cli not accessible: %w\nOutput: %s", err, string(output))
+	}
+
+	// Try to parse the output as JSON to verify it's working
+	var info map[string]interface{}
+	if err := json.Unmarshal(output, &info); err != nil {
+		return fmt.Errorf("invalid litecoin-cli response: %w", err)
+	}
+
+	return nil
+}
+
+// buildBaseArgs constructs the base arguments for litecoin-cli
+func (l *LitecoinDepositor) buildBaseArgs() []string {
+	args := make([]string, 0)
+
+	// Add any custom CLI arguments from config
+	if len(l.config.CLIArgs) > 0 {
+		args = append(args, l.config.CLIArgs...)
+	}
+
+	// Add wallet if specified
+	if l.config.Wallet != "" {
+		args = append(args, fmt.Sprintf("-rpcwallet=%s", l.config.Wallet))
+	}
+
+	return args
+}
+
+// run executes litecoin-cli with args and returns its combined output
+func (l *LitecoinDepositor) run(args ...string) ([]byte, error) {
+	return litecoinCommand(l.config.CLIPath, args...).CombinedOutput()
+}
+
+// GetTransactionInfo retrieves information about a transaction
+func (l *LitecoinDepositor) GetTransactionInfo(txid string) (map[string]interface{}, error) {
+	args := l.buildBaseArgs()
+	args = append(args, "gettransaction", txid)
+
+	output, err := l.run(args...)
+	if err != nil {
+		return nil, fmt.Errorf("litecoin-cli gettransaction failed: %w\nOutput: %s", err, string(output))
+	}
+
+	var txInfo map[string]interface{}
+	if err := json.Unmarshal(output, &txInfo); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction info: %w", err)
+	}
+
+	return txInfo, nil
+}
@@ -0,0 +1,108 @@
+package deposit
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"near-swap/config"
+)
+
+// fakeLitecoinCommand replaces litecoin-cli with this test binary acting as a node with balance
+// LTC, recording the arguments of every call
+func fakeLitecoinCommand(t *testing.T, balance string) *[]string {
+	t.Helper()
+	var calls []string
+	original := litecoinCommand
+	litecoinCommand = func(name string, args ...string) *exec.Cmd {
+		calls = append(calls, strings.Join(args, " "))
+		cmd := exec.Command(os.Args[0], append([]string{"-test.run=TestLitecoinHelperProcess", "--"}, args...)...)
+		cmd.Env = append(os.Environ(), "LITECOIN_HELPER_BALANCE="+balance)
+		return cmd
+	}
+	t.Cleanup(func() { litecoinCommand = original })
+	return &calls
+}
+
+// TestLitecoinHelperProcess is the litecoin-cli stand-in run by fakeLitecoinCommand
+func TestLitecoinHelperProcess(t *testing.T) {
+	balance, ok := os.LookupEnv("LITECOIN_HELPER_BALANCE")
+	if !ok {
+		return
+	}
+	for _, arg := range os.Args {
+		switch arg {
+		case "getblockchaininfo":
+			if balance == "offline" {
+				fmt.Println("error: Could not connect to the server 127.0.0.1:9332")
+				os.Exit(1)
+			}
+			fmt.Println(`{"chain":"main"}`)
+			os.Exit(0)
+		case "getbalance":
+			fmt.Println(balance)
+			os.Exit(0)
+		case "sendtoaddress":
+			fmt.Println("ltc-txid")
+			os.Exit(0)
+		}
+	}
+	os.Exit(1)
+}
+
+func TestLitecoinDepositor(t *testing.T) {
+	cfg := config.LitecoinConfig{Enabled: true, CLIPath: "litecoin-cli", CLIArgs: []string{"-testnet"}, Wallet: "hot"}
+
+	tests := []struct {
+		name      string
+		balance   string
+		wantTxID  string
+		wantErr   string // Empty expects the send to succeed
+		wantCalls []string
+	}{
+		{name: "sends after checking the balance", balance: "10", wantTxID: "ltc-txid", wantCalls: []string{
+			"-testnet -rpcwallet=hot getblockchaininfo",
+			"-testnet -rpcwallet=hot getbalance",
+			"-testnet -rpcwallet=hot sendtoaddress ltc1qdeposit 1.5",
+		}},
+		{name: "insufficient balance", balance: "1", wantErr: "insufficient balance: have 1.00000000 LTC, need 1.50000000 LTC", wantCalls: []string{
+			"-testnet -rpcwallet=hot getblockchaininfo",
+			"-testnet -rpcwallet=hot getbalance",
+		}},
+		{name: "node unreachable", balance: "offline", wantErr: "litecoin-cli validation failed", wantCalls: []string{
+			"-testnet -rpcwallet=hot getblockchaininfo",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := fakeLitecoinCommand(t, tt.balance)
+
+			// The manager routes both chain names to the Litecoin depositor
+			for _, chain := range []string{"ltc", "litecoin"} {
+				*calls = nil
+				txid, err := NewManager(config.AutoDepositConfig{Enabled: true, Litecoin: cfg}).SendDeposit(chain, "ltc1qdeposit", "1.5")
+				if tt.wantErr == "" && err != nil {
+					t.Fatalf("%s: %v", chain, err)
+				}
+				if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+					t.Fatalf("%s: error = %v, want one containing %q", chain, err, tt.wantErr)
+				}
+				if txid != tt.wantTxID {
+					t.Errorf("%s: txid = %q, want %q", chain, txid, tt.wantTxID)
+				}
+				if !slices.Equal(*calls, tt.wantCalls) {
+					t.Errorf("%s: litecoin-cli calls = %q, want %q", chain, *calls, tt.wantCalls)
+				}
+			}
+		})
+	}
+
+	m := NewManager(config.AutoDepositConfig{Enabled: true, Litecoin: cfg})
+	if !m.IsEnabledForChain("ltc") || !slices.Contains(m.GetSupportedChains(), "litecoin") {
+		t.Errorf("litecoin not reported as supported: %v", m.GetSupportedChains())
+	}
+}
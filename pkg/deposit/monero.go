@@ -66,6 +66,11 @@ func (m *MoneroDepositor) SendDeposit(address string, amount string) (string, er
 		return "", fmt.Errorf("invalid amount: %w", err)
 	}
 
+	// Refuse amounts that round to nothing
+	if err := checkMinimumSend("monero", amountFloat, MinMoneroSend); err != nil {
+		return "", err
+	}
+
 	// Convert to atomic units
 	amountAtomic := uint64(amountFloat * 1e12)
 
@@ -116,6 +121,15 @@ func (m *MoneroDepositor) SendDeposit(address string, amount string) (string, er
 	return transferResult.TxHash, nil
 }
 
+// GetBalance returns the unlocked wallet balance in XMR
+func (m *MoneroDepositor) GetBalance() (float64, error) {
+	atomic, err := m.getBalance()
+	if err != nil {
+		return 0, err
+	}
+	return float64(atomic) / 1e12, nil
+}
+
 // getBalance returns the wallet balance in atomic units
 func (m *MoneroDepositor) getBalance() (uint64, error) {
 	params := map[string]interface{}{
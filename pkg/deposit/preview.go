@@ -0,0 +1,306 @@
+package deposit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+)
+
+// solanaFeePerSignature is the base fee per signature, used when the cluster can't price a message
+const solanaFeePerSignature = 5000
+
+// TxPreview describes a signed deposit transaction before it is broadcast. Every field is
+// decoded from the signed transaction itself, so it shows exactly what will be sent.
+type TxPreview struct {
+	Chain       string   `json:"chain"`
+	From        string   `json:"from"`
+	To          string   `json:"to"`              // Account that receives the funds
+	Token       string   `json:"token,omitempty"` // Token contract or mint; empty for the native coin
+	AmountUnits string   `json:"amount_units"`    // Amount in the token's smallest unit
+	Decimals    uint8    `json:"decimals"`
+	Fee         string   `json:"fee,omitempty"` // Most the transaction can cost in fees, in FeeUnit
+	FeeUnit     string   `json:"fee_unit"`
+	Nonce       *uint64  `json:"nonce,omitempty"`     // EVM only
+	Blockhash   string   `json:"blockhash,omitempty"` // Solana only
+	TxID        string   `json:"txid"`
+	Notes       []string `json:"notes,omitempty"`
+}
+
+// Amount returns the amount in whole tokens
+func (p *TxPreview) Amount() string {
+	units, ok := new(big.Int).SetString(p.AmountUnits, 10)
+	if !ok {
+		return p.AmountUnits
+	}
+	return formatUnits(units, p.Decimals)
+}
+
+// formatUnits formats an amount of a token's smallest unit in whole tokens, without trailing zeros
+func formatUnits(units *big.Int, decimals uint8) string {
+	value := new(big.Rat).SetFrac(units, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	formatted := value.FloatString(int(decimals))
+	if strings.Contains(formatted, ".") {
+		formatted = strings.TrimRight(strings.TrimRight(formatted, "0"), ".")
+	}
+	return formatted
+}
+
+// PreparedDeposit is a signed deposit transaction that hasn't been broadcast yet
+type PreparedDeposit struct {
+	Preview   *TxPreview
+	broadcast func() (string, error)
+	close     func()
+}
+
+// Broadcast sends the prepared transaction and returns its transaction ID
+func (p *PreparedDeposit) Broadcast() (string, error) {
+	return p.broadcast()
+}
+
+// Close releases the depositor behind the prepared transaction
+func (p *PreparedDeposit) Close() {
+	if p.close != nil {
+		p.close()
+	}
+}
+
+// PrepareDeposit builds and signs a deposit like SendDeposit, but returns it with a decoded
+// preview instead of sending it. The transaction should be broadcast promptly: its nonce or
+// blockhash can go stale. Chains without a preview return errors.ErrUnsupported.
+func (m *Manager) PrepareDeposit(chain, address, amount string) (*PreparedDeposit, error) {
+	if err := m.CheckChain(chain); err != nil {
+		return nil, err
+	}
+
+	chain = strings.ToLower(chain)
+	switch chain {
+	case "eth", "ethereum", "bsc", "bnb", "pol", "polygon", "matic", "avalanche", "avax", "arbitrum", "optimism", "base", "fantom":
+		depositor, err := NewEVMDepositor(m.config.EVM, m.getEVMNetworkName(chain))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create EVM depositor: %w", err)
+		}
+		prepared, err := depositor.prepareDeposit(context.Background(), address, amount)
+		if err != nil {
+			depositor.Close()
+			return nil, err
+		}
+		return prepared, nil
+	case "sol", "solana":
+		depositor, err := NewSolanaDepositor(m.config.Solana)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Solana depositor: %w", err)
+		}
+		prepared, err := depositor.prepareDeposit(context.Background(), address, amount)
+		if err != nil {
+			depositor.Close()
+			return nil, err
+		}
+		return prepared, nil
+	default:
+		return nil, fmt.Errorf("transaction preview on %s: %w", chain, errors.ErrUnsupported)
+	}
+}
+
+// prepareDeposit builds, signs and previews a deposit
+func (e *EVMDepositor) prepareDeposit(ctx context.Context, address, amount string) (*PreparedDeposit, error) {
+	tx, err := e.buildDeposit(ctx, address, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	decimals := uint8(18)
+	if len(tx.Data()) > 0 {
+		if decimals, err = e.getERC20Decimals(ctx, *tx.To()); err != nil {
+			return nil, err
+		}
+	}
+	preview, err := previewEVMTransaction(e.networkName, tx, decimals)
+	if err != nil {
+		return nil, err
+	}
+
+	fee, err := maxTransactionFee(ctx, e.client, e.network, tx)
+	if err != nil {
+		return nil, err
+	}
+	preview.Fee = fee.String()
+
+	return &PreparedDeposit{
+		Preview:   preview,
+		broadcast: func() (string, error) { return e.broadcast(ctx, tx) },
+		close:     e.Close,
+	}, nil
+}
+
+// previewEVMTransaction decodes a signed native or ERC20 transfer. decimals is the ERC20
+// token's, and is ignored for native transfers. The fee is left for the caller to fill in.
+func previewEVMTransaction(networkName string, tx *types.Transaction, decimals uint8) (*TxPreview, error) {
+	if tx.To() == nil {
+		return nil, fmt.Errorf("deposit transaction has no recipient")
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover transaction sender: %w", err)
+	}
+
+	nonce := tx.Nonce()
+	preview := &TxPreview{
+		Chain:       networkName,
+		From:        from.Hex(),
+		To:          tx.To().Hex(),
+		AmountUnits: tx.Value().String(),
+		Decimals:    18,
+		FeeUnit:     "wei",
+		Nonce:       &nonce,
+		TxID:        tx.Hash().Hex(),
+	}
+	if len(tx.Data()) == 0 {
+		return preview, nil
+	}
+
+	// Anything carrying call data must be an ERC20 transfer(address,uint256)
+	parsedABI, err := abi.JSON(strings.NewReader(erc20TransferABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+	method, err := parsedABI.MethodById(tx.Data())
+	if err != nil || method.Name != "transfer" {
+		return nil, fmt.Errorf("deposit transaction calls an unexpected contract method")
+	}
+	args, err := method.Inputs.Unpack(tx.Data()[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode token transfer: %w", err)
+	}
+	recipient, okTo := args[0].(common.Address)
+	value, okValue := args[1].(*big.Int)
+	if !okTo || !okValue {
+		return nil, fmt.Errorf("failed to decode token transfer")
+	}
+
+	preview.Token = tx.To().Hex()
+	preview.To = recipient.Hex()
+	preview.AmountUnits = value.String()
+	preview.Decimals = decimals
+	if tx.Value().Sign() != 0 {
+		preview.Notes = append(preview.Notes, fmt.Sprintf("also sends %s wei of the native coin to the token contract", tx.Value()))
+	}
+	return preview, nil
+}
+
+// prepareDeposit builds, signs and previews a deposit
+func (s *SolanaDepositor) prepareDeposit(ctx context.Context, address, amount string) (*PreparedDeposit, error) {
+	tx, err := s.buildDeposit(ctx, address, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	decimals := uint8(9)
+	var mint string
+	if parts := strings.Split(address, "|"); len(parts) > 1 {
+		mint = parts[1]
+		mintKey, err := solana.PublicKeyFromBase58(mint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token mint address: %w", err)
+		}
+		if decimals, err = s.getTokenDecimals(ctx, mintKey); err != nil {
+			return nil, fmt.Errorf("failed to get token decimals: %w", err)
+		}
+	}
+	preview, err := previewSolanaTransaction(tx, mint, decimals)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cluster prices the exact message; fall back to the base signature fee
+	fee := uint64(solanaFeePerSignature) * uint64(tx.Message.Header.NumRequiredSignatures)
+	if result, err := s.client.GetFeeForMessage(ctx, tx.Message.ToBase64(), s.getCommitment()); err == nil && result.Value != nil {
+		fee = *result.Value
+	} else {
+		preview.Notes = append(preview.Notes, "fee estimated from the base signature fee")
+	}
+	preview.Fee = fmt.Sprintf("%d", fee)
+
+	return &PreparedDeposit{
+		Preview:   preview,
+		broadcast: func() (string, error) { return s.broadcast(ctx, tx) },
+		close:     s.Close,
+	}, nil
+}
+
+// previewSolanaTransaction decodes a signed SOL or SPL token transfer. mint and decimals
+// describe the token being sent; mint is empty for native SOL. The fee is left for the
+// caller to fill in.
+func previewSolanaTransaction(tx *solana.Transaction, mint string, decimals uint8) (*TxPreview, error) {
+	if len(tx.Message.AccountKeys) == 0 || len(tx.Signatures) == 0 {
+		return nil, fmt.Errorf("deposit transaction is not signed")
+	}
+
+	preview := &TxPreview{
+		Chain:     "solana",
+		From:      tx.Message.AccountKeys[0].String(), // The fee payer
+		Token:     mint,
+		Decimals:  decimals,
+		FeeUnit:   "lamports",
+		Blockhash: tx.Message.RecentBlockhash.String(),
+		TxID:      tx.Signatures[0].String(),
+	}
+
+	var transfers int
+	for _, ci := range tx.Message.Instructions {
+		programID, err := tx.ResolveProgramIDIndex(ci.ProgramIDIndex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve program: %w", err)
+		}
+		accounts, err := ci.ResolveInstructionAccounts(&tx.Message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve instruction accounts: %w", err)
+		}
+
+		switch {
+		case programID.Equals(solana.SystemProgramID):
+			inst, err := system.DecodeInstruction(accounts, ci.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode system instruction: %w", err)
+			}
+			transfer, ok := inst.Impl.(*system.Transfer)
+			if !ok || transfer.Lamports == nil {
+				return nil, fmt.Errorf("deposit transaction has an unexpected system instruction")
+			}
+			preview.To = transfer.GetRecipientAccount().PublicKey.String()
+			preview.AmountUnits = fmt.Sprintf("%d", *transfer.Lamports)
+			transfers++
+		case programID.Equals(solana.TokenProgramID):
+			inst, err := token.DecodeInstruction(accounts, ci.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode token instruction: %w", err)
+			}
+			transfer, ok := inst.Impl.(*token.Transfer)
+			if !ok || transfer.Amount == nil {
+				return nil, fmt.Errorf("deposit transaction has an unexpected token instruction")
+			}
+			preview.To = transfer.GetDestinationAccount().PublicKey.String()
+			preview.AmountUnits = fmt.Sprintf("%d", *transfer.Amount)
+			transfers++
+		case programID.Equals(solana.SPLAssociatedTokenAccountProgramID):
+			if len(accounts) > 2 {
+				preview.Notes = append(preview.Notes, fmt.Sprintf("creates the token account %s for %s, paid by the sender", accounts[1].PublicKey, accounts[2].PublicKey))
+			}
+		default:
+			return nil, fmt.Errorf("deposit transaction calls an unexpected program %s", programID)
+		}
+	}
+	if transfers != 1 {
+		return nil, fmt.Errorf("deposit transaction has %d transfers, expected 1", transfers)
+	}
+
+	return preview, nil
+}
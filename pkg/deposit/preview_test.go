@@ -0,0 +1,154 @@
+package deposit
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"near-swap/config"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gagliardetto/solana-go"
+)
+
+func TestPreviewEVMTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	recipient := common.HexToAddress("0x00000000000000000000000000000000DeaDBeef")
+	usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+
+	parsedABI, err := abi.JSON(strings.NewReader(erc20TransferABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfer, err := parsedABI.Pack("transfer", recipient, big.NewInt(2500000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		to        common.Address
+		value     *big.Int
+		data      []byte
+		decimals  uint8
+		wantTo    string
+		wantToken string
+		wantUnits string
+		wantErr   string
+	}{
+		{name: "native", to: recipient, value: big.NewInt(1.5e18), decimals: 6,
+			wantTo: recipient.Hex(), wantUnits: "1500000000000000000"},
+		{name: "ERC20", to: usdc, value: big.NewInt(0), data: transfer, decimals: 6,
+			wantTo: recipient.Hex(), wantToken: usdc.Hex(), wantUnits: "2500000"},
+		{name: "unexpected call", to: usdc, value: big.NewInt(0), data: []byte{1, 2, 3, 4}, wantErr: "unexpected contract method"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+				ChainID: big.NewInt(1), Nonce: 42, To: &tt.to, Value: tt.value, Data: tt.data,
+				Gas: 60000, GasFeeCap: big.NewInt(3e10), GasTipCap: big.NewInt(1e9),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			preview, err := previewEVMTransaction("ethereum", tx, tt.decimals)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Everything shown comes from the signed transaction
+			if preview.From != crypto.PubkeyToAddress(key.PublicKey).Hex() || preview.To != tt.wantTo ||
+				preview.Token != tt.wantToken || preview.AmountUnits != tt.wantUnits {
+				t.Errorf("preview = %+v, want from the signer to %s of %s units of %q", preview, tt.wantTo, tt.wantUnits, tt.wantToken)
+			}
+			if preview.Nonce == nil || *preview.Nonce != 42 || preview.TxID != tx.Hash().Hex() {
+				t.Errorf("nonce %v, txid %s; want 42 and %s", preview.Nonce, preview.TxID, tx.Hash().Hex())
+			}
+		})
+	}
+}
+
+func TestPrepareSolanaDeposit(t *testing.T) {
+	mint, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient := solana.PublicKey{7}
+	ata, _, err := solana.FindAssociatedTokenAddress(recipient, mint.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		address   string
+		amount    string
+		wantTo    string
+		wantToken string
+		wantUnits string
+		decimals  uint8
+	}{
+		{name: "SOL", address: recipient.String(), amount: "0.25",
+			wantTo: recipient.String(), wantUnits: "250000000", decimals: 9},
+		{name: "SPL token", address: recipient.String() + "|" + mint.PublicKey().String(), amount: "1.5",
+			wantTo: ata.String(), wantToken: mint.PublicKey().String(), wantUnits: "1500000", decimals: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &fakeSolanaRPC{mint: mint.PublicKey(), created: map[solana.PublicKey]bool{}}
+			rpcServer := httptest.NewServer(chain)
+			t.Cleanup(rpcServer.Close)
+			s, err := NewSolanaDepositor(config.SolanaConfig{RPCUrl: rpcServer.URL, PrivateKey: key.String()})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			prepared, err := s.prepareDeposit(context.Background(), tt.address, tt.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			preview := prepared.Preview
+			if preview.From != key.PublicKey().String() || preview.To != tt.wantTo || preview.Token != tt.wantToken ||
+				preview.AmountUnits != tt.wantUnits || preview.Decimals != tt.decimals || preview.Amount() != tt.amount {
+				t.Errorf("preview = %+v, want %s (%s units of %q) to %s", preview, tt.amount, tt.wantUnits, tt.wantToken, tt.wantTo)
+			}
+			if preview.Blockhash != (solana.Hash{1}).String() || preview.Fee != "5000" {
+				t.Errorf("blockhash %s, fee %s; want the fetched blockhash and the base signature fee", preview.Blockhash, preview.Fee)
+			}
+			if chain.sent != 0 {
+				t.Fatal("preparing a deposit broadcast it")
+			}
+
+			// Broadcasting sends exactly the previewed transaction
+			txid, err := prepared.Broadcast()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if txid != preview.TxID || chain.sent != 1 {
+				t.Errorf("broadcast %s (%d sent), want the previewed %s", txid, chain.sent, preview.TxID)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package deposit
+
+import "time"
+
+// DepositResult describes a deposit that was sent to a swap's deposit address
+type DepositResult struct {
+	Chain     string    `json:"chain"`
+	Token     string    `json:"token"`
+	Amount    string    `json:"amount"`
+	ToAddress string    `json:"to_address"`
+	TxID      string    `json:"txid"`
+	Fee       string    `json:"fee,omitempty"` // Network fee paid, when the chain's depositor reports it
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// NewDepositResult describes a deposit of amount token sent on chain to toAddress in txid
+func NewDepositResult(chain, token, amount, toAddress, txid string) *DepositResult {
+	return &DepositResult{
+		Chain:     chain,
+		Token:     token,
+		Amount:    amount,
+		ToAddress: toAddress,
+		TxID:      txid,
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+// AuditRecord returns the audit log entry for the deposit; source is "swap" or "plan"
+func (r *DepositResult) AuditRecord(source string) AuditRecord {
+	return AuditRecord{
+		Timestamp: r.Timestamp,
+		Source:    source,
+		Chain:     r.Chain,
+		Token:     r.Token,
+		Amount:    r.Amount,
+		ToAddress: r.ToAddress,
+		TxID:      r.TxID,
+	}
+}
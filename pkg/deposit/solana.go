@@ -21,6 +21,7 @@ type SolanaDepositor struct {
 	client     *rpc.Client
 	privateKey solana.PrivateKey
 	publicKey  solana.PublicKey
+	blockhash  solana.Hash // Recent blockhash the last broadcast transaction was signed with
 }
 
 // NewSolanaDepositor creates a new Solana depositor
@@ -58,6 +59,15 @@ func NewSolanaDepositor(cfg config.SolanaConfig) (*SolanaDepositor, error) {
 func (s *SolanaDepositor) SendDeposit(address string, amount string) (string, error) {
 	ctx := context.Background()
 
+	tx, err := s.buildDeposit(ctx, address, amount)
+	if err != nil {
+		return "", err
+	}
+	return s.broadcast(ctx, tx)
+}
+
+// buildDeposit builds and signs the deposit transaction for SendDeposit without sending it
+func (s *SolanaDepositor) buildDeposit(ctx context.Context, address string, amount string) (*solana.Transaction, error) {
 	// Parse address - check if it contains token mint address for SPL tokens
 	parts := strings.Split(address, "|")
 	recipientAddr := parts[0]
@@ -69,32 +79,53 @@ func (s *SolanaDepositor) SendDeposit(address string, amount string) (string, er
 	// Validate recipient address
 	recipient, err := solana.PublicKeyFromBase58(recipientAddr)
 	if err != nil {
-		return "", fmt.Errorf("invalid recipient address: %w", err)
+		return nil, fmt.Errorf("invalid recipient address: %w", err)
 	}
 
 	// Determine if this is a native SOL or SPL token transfer
-	var signature solana.Signature
 	if tokenMint == "" {
 		// Native SOL transfer
-		signature, err = s.sendNativeSOL(ctx, recipient, amount)
-	} else {
-		// SPL token transfer
-		signature, err = s.sendSPLToken(ctx, recipient, tokenMint, amount)
+		return s.buildNativeSOL(ctx, recipient, amount)
 	}
+	// SPL token transfer
+	return s.buildSPLTransfer(ctx, recipient, tokenMint, amount)
+}
 
+// broadcast sends a signed deposit transaction and returns its signature
+func (s *SolanaDepositor) broadcast(ctx context.Context, tx *solana.Transaction) (string, error) {
+	opts := rpc.TransactionOpts{
+		SkipPreflight:       s.config.SkipPreflight,
+		PreflightCommitment: s.getCommitment(),
+	}
+
+	sig, err := s.client.SendTransactionWithOpts(ctx, tx, opts)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to send transaction: %w", err)
 	}
+	s.blockhash = tx.Message.RecentBlockhash
 
-	return signature.String(), nil
+	return sig.String(), nil
 }
 
-// sendNativeSOL sends native SOL tokens
-func (s *SolanaDepositor) sendNativeSOL(ctx context.Context, recipient solana.PublicKey, amount string) (solana.Signature, error) {
+// LastBlockhash returns the recent blockhash of the last deposit sent, or "" if none was sent
+func (s *SolanaDepositor) LastBlockhash() string {
+	if s.blockhash.IsZero() {
+		return ""
+	}
+	return s.blockhash.String()
+}
+
+// buildNativeSOL builds and signs a native SOL transfer
+func (s *SolanaDepositor) buildNativeSOL(ctx context.Context, recipient solana.PublicKey, amount string) (*solana.Transaction, error) {
 	// Parse amount (in SOL, convert to lamports: 1 SOL = 1e9 lamports)
 	amountFloat, err := strconv.ParseFloat(amount, 64)
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("invalid amount: %w", err)
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+
+	// Deposit addresses are fresh accounts, which must receive at least the rent-exempt minimum
+	if err := checkMinimumSend("solana", amountFloat, MinSolanaSend); err != nil {
+		return nil, err
 	}
 
 	// Convert to lamports
@@ -103,7 +134,7 @@ func (s *SolanaDepositor) sendNativeSOL(ctx context.Context, recipient solana.Pu
 	// Get balance
 	balance, err := s.getBalance(ctx)
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to get balance: %w", err)
+		return nil, fmt.Errorf("failed to get balance: %w", err)
 	}
 
 	// Check if we have enough balance (including for fees)
@@ -112,13 +143,13 @@ func (s *SolanaDepositor) sendNativeSOL(ctx context.Context, recipient solana.Pu
 	if balance < minRequired {
 		balanceSOL := float64(balance) / 1e9
 		requiredSOL := float64(minRequired) / 1e9
-		return solana.Signature{}, fmt.Errorf("insufficient balance: have %.9f SOL, need %.9f SOL (including fees)", balanceSOL, requiredSOL)
+		return nil, fmt.Errorf("insufficient balance: have %.9f SOL, need %.9f SOL (including fees)", balanceSOL, requiredSOL)
 	}
 
 	// Get recent blockhash
 	recent, err := s.client.GetRecentBlockhash(ctx, rpc.CommitmentFinalized)
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to get recent blockhash: %w", err)
+		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
 	}
 
 	// Create transfer instruction
@@ -135,7 +166,7 @@ func (s *SolanaDepositor) sendNativeSOL(ctx context.Context, recipient solana.Pu
 		solana.TransactionPayer(s.publicKey),
 	)
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to create transaction: %w", err)
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
 
 	// Sign transaction
@@ -146,41 +177,30 @@ func (s *SolanaDepositor) sendNativeSOL(ctx context.Context, recipient solana.Pu
 		return nil
 	})
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to sign transaction: %w", err)
-	}
-
-	// Send transaction
-	opts := rpc.TransactionOpts{
-		SkipPreflight:       s.config.SkipPreflight,
-		PreflightCommitment: s.getCommitment(),
-	}
-
-	sig, err := s.client.SendTransactionWithOpts(ctx, tx, opts)
-	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to send transaction: %w", err)
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
-	return sig, nil
+	return tx, nil
 }
 
-// sendSPLToken sends SPL tokens
-func (s *SolanaDepositor) sendSPLToken(ctx context.Context, recipient solana.PublicKey, tokenMintStr string, amount string) (solana.Signature, error) {
+// buildSPLTransfer builds and signs an SPL token transfer
+func (s *SolanaDepositor) buildSPLTransfer(ctx context.Context, recipient solana.PublicKey, tokenMintStr string, amount string) (*solana.Transaction, error) {
 	// Parse token mint address
 	tokenMint, err := solana.PublicKeyFromBase58(tokenMintStr)
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("invalid token mint address: %w", err)
+		return nil, fmt.Errorf("invalid token mint address: %w", err)
 	}
 
 	// Parse amount
 	amountFloat, err := strconv.ParseFloat(amount, 64)
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("invalid amount: %w", err)
+		return nil, fmt.Errorf("invalid amount: %w", err)
 	}
 
 	// Get token decimals
 	decimals, err := s.getTokenDecimals(ctx, tokenMint)
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to get token decimals: %w", err)
+		return nil, fmt.Errorf("failed to get token decimals: %w", err)
 	}
 
 	// Convert to token smallest unit
@@ -189,53 +209,57 @@ func (s *SolanaDepositor) sendSPLToken(ctx context.Context, recipient solana.Pub
 		multiplier *= 10
 	}
 	tokenAmount := uint64(amountFloat * float64(multiplier))
+	if err := checkMinimumUnits("solana", amount, tokenAmount, decimals); err != nil {
+		return nil, err
+	}
 
 	// Get source token account (our token account)
 	sourceTokenAccount, err := s.getAssociatedTokenAddress(s.publicKey, tokenMint)
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to get source token account: %w", err)
+		return nil, fmt.Errorf("failed to get source token account: %w", err)
 	}
 
 	// Check token balance
 	balance, err := s.getTokenBalance(ctx, sourceTokenAccount)
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to get token balance: %w", err)
+		return nil, fmt.Errorf("failed to get token balance: %w", err)
 	}
 
 	if balance < tokenAmount {
 		balanceFormatted := float64(balance) / float64(multiplier)
 		amountFormatted := float64(tokenAmount) / float64(multiplier)
-		return solana.Signature{}, fmt.Errorf("insufficient token balance: have %f, need %f", balanceFormatted, amountFormatted)
+		return nil, fmt.Errorf("insufficient token balance: have %f, need %f", balanceFormatted, amountFormatted)
 	}
 
 	// Get or create destination token account
 	destTokenAccount, err := s.getAssociatedTokenAddress(recipient, tokenMint)
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to get destination token account: %w", err)
+		return nil, fmt.Errorf("failed to get destination token account: %w", err)
 	}
 
 	// Check if destination token account exists
 	destAccountExists, err := s.accountExists(ctx, destTokenAccount)
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to check destination account: %w", err)
+		return nil, fmt.Errorf("failed to check destination account: %w", err)
 	}
 
 	// Get recent blockhash
 	recent, err := s.client.GetRecentBlockhash(ctx, rpc.CommitmentFinalized)
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to get recent blockhash: %w", err)
+		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
 	}
 
 	// Build instructions
 	instructions := []solana.Instruction{}
 
-	// Create associated token account if it doesn't exist
+	// Create associated token account if it doesn't exist. The idempotent variant still
+	// succeeds if a concurrent deposit creates the account before this transaction lands.
 	if !destAccountExists {
-		createAccountIx := associatedtokenaccount.NewCreateInstruction(
-			s.publicKey,      // payer
-			recipient,        // wallet
-			tokenMint,        // mint
-		).Build()
+		createAccountIx := newCreateIdempotentATAInstruction(
+			s.publicKey, // payer
+			recipient,   // wallet
+			tokenMint,   // mint
+		)
 		instructions = append(instructions, createAccountIx)
 	}
 
@@ -256,7 +280,7 @@ func (s *SolanaDepositor) sendSPLToken(ctx context.Context, recipient solana.Pub
 		solana.TransactionPayer(s.publicKey),
 	)
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to create transaction: %w", err)
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
 
 	// Sign transaction
@@ -267,21 +291,34 @@ func (s *SolanaDepositor) sendSPLToken(ctx context.Context, recipient solana.Pub
 		return nil
 	})
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to sign transaction: %w", err)
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
-	// Send transaction
-	opts := rpc.TransactionOpts{
-		SkipPreflight:       s.config.SkipPreflight,
-		PreflightCommitment: s.getCommitment(),
-	}
+	return tx, nil
+}
 
-	sig, err := s.client.SendTransactionWithOpts(ctx, tx, opts)
+// createIdempotentATAInstructionID is the associated token program's CreateIdempotent instruction
+const createIdempotentATAInstructionID = 1
+
+// newCreateIdempotentATAInstruction builds a CreateIdempotent instruction for the associated
+// token account of wallet and mint, which is a no-op when the account already exists.
+// It takes the same accounts as the legacy Create instruction.
+func newCreateIdempotentATAInstruction(payer, wallet, mint solana.PublicKey) solana.Instruction {
+	create := associatedtokenaccount.NewCreateInstruction(payer, wallet, mint).Build()
+	return solana.NewInstruction(
+		solana.SPLAssociatedTokenAccountProgramID,
+		create.Accounts(),
+		[]byte{createIdempotentATAInstructionID},
+	)
+}
+
+// GetBalance returns the native SOL balance
+func (s *SolanaDepositor) GetBalance() (float64, error) {
+	lamports, err := s.getBalance(context.Background())
 	if err != nil {
-		return solana.Signature{}, fmt.Errorf("failed to send transaction: %w", err)
+		return 0, err
 	}
-
-	return sig, nil
+	return float64(lamports) / float64(solana.LAMPORTS_PER_SOL), nil
 }
 
 // getBalance returns the SOL balance in lamports
@@ -0,0 +1,160 @@
+package deposit
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"near-swap/config"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// fakeSolanaRPC is a JSON-RPC endpoint for a wallet holding 10 SOL and one SPL mint. Like the associated token program,
+// it rejects a legacy create of a token account that already exists.
+type fakeSolanaRPC struct {
+	mint solana.PublicKey
+
+	mu      sync.Mutex
+	created map[solana.PublicKey]bool // Token accounts created by sent transactions
+	sent    int
+}
+
+func (f *fakeSolanaRPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var param string
+	if len(req.Params) > 0 {
+		json.Unmarshal(req.Params[0], &param)
+	}
+
+	context := map[string]interface{}{"slot": 1}
+	var result interface{}
+	var rpcErr string
+	switch req.Method {
+	case "getAccountInfo":
+		// Every token account looks missing when the deposit is built
+		var value interface{}
+		if param == f.mint.String() {
+			data := make([]byte, 82)
+			data[44] = 6 // Decimals
+			value = map[string]interface{}{
+				"data": []string{base64.StdEncoding.EncodeToString(data), "base64"}, "executable": false,
+				"lamports": 1461600, "owner": solana.TokenProgramID.String(), "rentEpoch": 0,
+			}
+		}
+		result = map[string]interface{}{"context": context, "value": value}
+	case "getBalance":
+		result = map[string]interface{}{"context": context, "value": 10 * solana.LAMPORTS_PER_SOL}
+	case "getTokenAccountBalance":
+		result = map[string]interface{}{"context": context,
+			"value": map[string]interface{}{"amount": "1000000000", "decimals": 6, "uiAmountString": "1000"}}
+	case "getRecentBlockhash":
+		result = map[string]interface{}{"context": context, "value": map[string]interface{}{
+			"blockhash": solana.Hash{1}.String(), "feeCalculator": map[string]interface{}{"lamportsPerSignature": 5000}}}
+	case "sendTransaction":
+		tx, err := solana.TransactionFromBase64(param)
+		if err != nil {
+			rpcErr = err.Error()
+			break
+		}
+		if rpcErr = f.apply(tx); rpcErr == "" {
+			result = tx.Signatures[0].String()
+		}
+	default:
+		rpcErr = "unexpected method " + req.Method
+	}
+
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	if rpcErr != "" {
+		resp["error"] = map[string]interface{}{"code": -32002, "message": rpcErr}
+	} else {
+		resp["result"] = result
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// apply runs the associated token program instructions of tx, returning the error a
+// validator would report
+func (f *fakeSolanaRPC) apply(tx *solana.Transaction) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent++
+
+	keys := tx.Message.AccountKeys
+	for _, ix := range tx.Message.Instructions {
+		if !keys[ix.ProgramIDIndex].Equals(solana.SPLAssociatedTokenAccountProgramID) {
+			continue
+		}
+		account := keys[ix.Accounts[1]]
+		idempotent := bytes.Equal(ix.Data, []byte{createIdempotentATAInstructionID})
+		if f.created[account] && !idempotent {
+			return "Transaction simulation failed: Provided owner is not allowed (account already in use)"
+		}
+		f.created[account] = true
+	}
+	return ""
+}
+
+func TestSPLDepositsRacingToCreateATokenAccount(t *testing.T) {
+	mint, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient, err := solana.NewRandomPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := &fakeSolanaRPC{mint: mint.PublicKey(), created: map[solana.PublicKey]bool{}}
+	rpcServer := httptest.NewServer(chain)
+	t.Cleanup(rpcServer.Close)
+
+	s, err := NewSolanaDepositor(config.SolanaConfig{RPCUrl: rpcServer.URL, PrivateKey: key.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both deposits are built before either lands, so both see the recipient's token account
+	// missing and create it
+	address := recipient.PublicKey().String() + "|" + mint.PublicKey().String()
+	ctx := context.Background()
+	first, err := s.buildDeposit(ctx, address, "1.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.buildDeposit(ctx, address, "2.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tx := range []*solana.Transaction{first, second} {
+		if _, err := s.broadcast(ctx, tx); err != nil {
+			t.Fatalf("deposit %d: %v", i+1, err)
+		}
+	}
+
+	ata, _, err := solana.FindAssociatedTokenAddress(recipient.PublicKey(), mint.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chain.sent != 2 || !chain.created[ata] {
+		t.Errorf("sent %d transactions, token account created: %v; want both sent and the account created", chain.sent, chain.created[ata])
+	}
+}
@@ -0,0 +1,75 @@
+package deposit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// TxSummary is the chain-independent part of a GetTransactionInfo result
+type TxSummary struct {
+	Amount  string // Amount sent in token units ("" when the chain's info doesn't report it)
+	Failed  bool   // The transaction was mined but reverted or errored
+	Pending bool   // The transaction has not been mined yet
+}
+
+// SummarizeTransaction extracts the sent amount and outcome from a GetTransactionInfo result
+// for chain. EVM amounts are only known for native transfers: an ERC20 transfer carries its
+// amount in the call data, so its Amount is left empty.
+func SummarizeTransaction(chain string, info map[string]interface{}) TxSummary {
+	var summary TxSummary
+
+	switch strings.ToLower(chain) {
+	case "btc", "bitcoin", "zec", "zcash", "ltc", "litecoin":
+		// gettransaction reports a send as a negative amount, excluding the fee
+		if amount, ok := infoRat(info["amount"]); ok {
+			summary.Amount = amount.Abs(amount).FloatString(8)
+		}
+		if confirmations, ok := infoRat(info["confirmations"]); ok && confirmations.Sign() == 0 {
+			summary.Pending = true
+		}
+	case "xmr", "monero":
+		transfer, _ := info["transfer"].(map[string]interface{})
+		if amount, ok := infoRat(transfer["amount"]); ok {
+			summary.Amount = amount.Quo(amount, big.NewRat(1e12, 1)).FloatString(12)
+		}
+		if txType, _ := transfer["type"].(string); txType == "pending" || txType == "pool" {
+			summary.Pending = true
+		}
+	case "sol", "solana":
+		summary.Failed = info["err"] != nil
+	default:
+		// EVM networks
+		if value, ok := infoRat(info["value"]); ok && value.Sign() > 0 {
+			summary.Amount = value.Quo(value, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))).FloatString(18)
+		}
+		summary.Pending, _ = info["pending"].(bool)
+		if status, ok := infoRat(info["status"]); ok && status.Sign() == 0 {
+			summary.Failed = true
+		}
+	}
+
+	return summary
+}
+
+// infoRat reads a numeric field of a transaction info map, which may be decoded from JSON
+// as a float64 or json.Number, or set by a depositor as an integer or decimal string
+func infoRat(value interface{}) (*big.Rat, bool) {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		text = v
+	case json.Number:
+		text = v.String()
+	case float64:
+		return new(big.Rat).SetFloat64(v), true
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	r, ok := new(big.Rat).SetString(text)
+	return r, ok
+}
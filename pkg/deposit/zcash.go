@@ -41,6 +41,11 @@ func (z *ZcashDepositor) SendDeposit(address string, amount string) (string, err
 		return "", fmt.Errorf("invalid amount: %w", err)
 	}
 
+	// Refuse dust outputs the node would reject
+	if err := checkMinimumSend("zcash", amountFloat, MinZcashSend); err != nil {
+		return "", err
+	}
+
 	// Check if we have enough balance
 	if balance < amountFloat {
 		return "", fmt.Errorf("insufficient balance: have %.8f ZEC, need %.8f ZEC", balance, amountFloat)
@@ -70,7 +75,12 @@ func (z *ZcashDepositor) SendDeposit(address string, amount string) (string, err
 	return txid, nil
 }
 
-// GetBalance returns the wallet balance
+// GetBalance returns the wallet balance in ZEC
+func (z *ZcashDepositor) GetBalance() (float64, error) {
+	return z.getBalance()
+}
+
+// getBalance returns the wallet balance
 func (z *ZcashDepositor) getBalance() (float64, error) {
 	args := z.buildBaseArgs()
 	args = append(args, "getbalance")
@@ -0,0 +1,147 @@
+// Package logging builds the leveled, structured logger the daemon logs through. Messages are
+// constant and the details travel as fields (plan, execution_id, event, tx_hash, ...). Text
+// output prints "[Component] message key=value ..." lines for a terminal; JSON output carries
+// the same message and fields for log collectors.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Log formats
+const (
+	FormatText = "text" // "[Component] message" lines for interactive use
+	FormatJSON = "json" // One JSON object per line for log collectors
+)
+
+// ComponentKey is the attribute naming the part of the daemon that logged a record
+const ComponentKey = "component"
+
+// ParseLevel parses a log level name: debug, info, warn (or warning) or error
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level '%s' (use debug, info, warn or error)", name)
+	}
+}
+
+// New returns a logger writing records at level and above to w in format
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	minLevel, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", FormatText:
+		return slog.New(&textHandler{w: w, mu: &sync.Mutex{}, level: minLevel}), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: minLevel})), nil
+	default:
+		return nil, fmt.Errorf("unknown log format '%s' (use text or json)", format)
+	}
+}
+
+// Default returns a text logger writing info and above to stdout
+func Default() *slog.Logger {
+	logger, _ := New(os.Stdout, FormatText, "info")
+	return logger
+}
+
+// Component returns logger tagged with the component that logs through it, e.g. "executor"
+func Component(logger *slog.Logger, name string) *slog.Logger {
+	return logger.With(ComponentKey, name)
+}
+
+// textHandler writes each record as "[Component] message key=value ...", quoting values that
+// contain spaces. The component goes in the prefix rather than the fields.
+type textHandler struct {
+	w         io.Writer
+	mu        *sync.Mutex
+	level     slog.Level
+	component string
+	attrs     []slog.Attr // Fields added with WithAttrs, written before the record's own
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	component := h.component
+	for _, a := range h.attrs {
+		writeAttr(&b, a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == ComponentKey {
+			component = a.Value.String()
+		} else {
+			writeAttr(&b, a)
+		}
+		return true
+	})
+
+	line := r.Message + b.String() + "\n"
+	if component != "" {
+		line = "[" + label(component) + "] " + line
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, line)
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		if a.Key == ComponentKey {
+			clone.component = a.Value.String()
+		} else {
+			clone.attrs = append(clone.attrs, a)
+		}
+	}
+	return &clone
+}
+
+// writeAttr appends " key=value" to b, quoting a value that is empty or contains spaces,
+// quotes or '='
+func writeAttr(b *strings.Builder, a slog.Attr) {
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	value := a.Value.Resolve().String()
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		value = strconv.Quote(value)
+	}
+	b.WriteString(" " + a.Key + "=" + value)
+}
+
+func (h *textHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// label capitalizes a component name for the text prefix: "executor" becomes "Executor"
+func label(component string) string {
+	runes := []rune(component)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
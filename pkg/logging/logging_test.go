@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestTextFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		log   func(logger *slog.Logger)
+		level string
+		want  string
+	}{
+		{
+			name: "component and fields",
+			log: func(logger *slog.Logger) {
+				Component(logger, "executor").Info("Swap completed", "plan", "btc-dca", "output", "12.5")
+			},
+			want: "[Executor] Swap completed plan=btc-dca output=12.5\n",
+		},
+		{
+			name: "quoted values",
+			log: func(logger *slog.Logger) {
+				logger.Warn("Paused plan", "reason", "API token rejected", "note", "", "pair", "a=b")
+			},
+			want: `Paused plan reason="API token rejected" note="" pair="a=b"` + "\n",
+		},
+		{
+			name: "fields added with With come first",
+			log: func(logger *slog.Logger) {
+				Component(logger, "verifier").With("plan", "p").Error("Error recording refund", "error", errors.New("disk full"))
+			},
+			want: `[Verifier] Error recording refund plan=p error="disk full"` + "\n",
+		},
+		{
+			name: "below the level",
+			log: func(logger *slog.Logger) {
+				logger.Info("Started monitoring plan", "plan", "p")
+			},
+			level: "warn",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(&buf, FormatText, tt.level)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.log(logger)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, FormatJSON, "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Component(logger, "executor").Info("Trigger condition met", "plan", "p", "price", "60000")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("not JSON: %q", buf.String())
+	}
+	for key, want := range map[string]string{"msg": "Trigger condition met", ComponentKey: "executor", "plan": "p", "price": "60000"} {
+		if record[key] != want {
+			t.Errorf("%s = %v, want %s", key, record[key], want)
+		}
+	}
+}
+
+func TestNewRejectsBadSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		level   string
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{name: "json debug", format: "json", level: "debug"},
+		{name: "mixed case", format: " TEXT ", level: "Warning"},
+		{name: "unknown format", format: "xml", wantErr: true},
+		{name: "unknown level", level: "verbose", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(&bytes.Buffer{}, tt.format, tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New(%q, %q) error = %v, want error %v", tt.format, tt.level, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+// Package mockserver provides a self-contained, scriptable 1Click API server
+// for exercising the client, swap and plan execution flows without live credentials.
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
+
+	"near-swap/pkg/client"
+)
+
+// QuoteHandler builds the response for a quote request.
+// Returning a non-2xx status code makes the server reply with an error body instead.
+type QuoteHandler func(req oneclick.QuoteRequest) (*oneclick.QuoteResponse, int)
+
+// Server is an httptest-based mock of the 1Click API
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	tokens        []oneclick.TokenResponse
+	rates         map[string]float64
+	quoteHandler  QuoteHandler
+	statuses      map[string][]string
+	failures      map[string][]int
+	quoteRequests []oneclick.QuoteRequest
+	deposits      []oneclick.SubmitDepositTxRequest
+	requestCounts map[string]int
+	depositSeq    int
+}
+
+// New starts a mock server with no tokens configured
+func New() *Server {
+	s := &Server{
+		rates:         make(map[string]float64),
+		statuses:      make(map[string][]string),
+		failures:      make(map[string][]int),
+		requestCounts: make(map[string]int),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v0/tokens", s.handleTokens)
+	mux.HandleFunc("/v0/quote", s.handleQuote)
+	mux.HandleFunc("/v0/status", s.handleStatus)
+	mux.HandleFunc("/v0/deposit/submit", s.handleSubmitDeposit)
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Client returns a OneClickClient targeting this server
+func (s *Server) Client(jwtToken string) *client.OneClickClient {
+	return client.NewOneClickClientWithBaseURL(jwtToken, s.URL)
+}
+
+// AddToken registers a token returned by GetTokens
+func (s *Server) AddToken(symbol, blockchain string, decimals int, price float64) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assetID := fmt.Sprintf("nep141:%s-%s.mock.near", blockchain, symbol)
+	token := oneclick.NewTokenResponse(assetID, float32(decimals), blockchain, symbol, float32(price), time.Now())
+	s.tokens = append(s.tokens, *token)
+
+	return assetID
+}
+
+// SetRate sets how many destination units one source unit buys for the default quote handler
+func (s *Server) SetRate(originAsset, destinationAsset string, rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rates[originAsset+"|"+destinationAsset] = rate
+}
+
+// SetQuoteHandler overrides the default quote handler
+func (s *Server) SetQuoteHandler(handler QuoteHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quoteHandler = handler
+}
+
+// QueueStatus scripts the statuses returned for a deposit address, one per status call.
+// The last status is repeated once the queue is drained.
+func (s *Server) QueueStatus(depositAddress string, statuses ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statuses[depositAddress] = append(s.statuses[depositAddress], statuses...)
+}
+
+// FailNext makes the next calls to an endpoint path (e.g. "/v0/quote") reply with the given status codes, in order
+func (s *Server) FailNext(path string, statusCodes ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[path] = append(s.failures[path], statusCodes...)
+}
+
+// QuoteRequests returns every quote request received so far
+func (s *Server) QuoteRequests() []oneclick.QuoteRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]oneclick.QuoteRequest(nil), s.quoteRequests...)
+}
+
+// SubmittedDeposits returns every deposit submission received so far
+func (s *Server) SubmittedDeposits() []oneclick.SubmitDepositTxRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]oneclick.SubmitDepositTxRequest(nil), s.deposits...)
+}
+
+// RequestCount returns how many requests hit an endpoint path
+func (s *Server) RequestCount(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requestCounts[path]
+}
+
+// nextFailure records the request and pops a scripted failure for the path, if any
+func (s *Server) nextFailure(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requestCounts[path]++
+	queue := s.failures[path]
+	if len(queue) == 0 {
+		return 0
+	}
+	s.failures[path] = queue[1:]
+	return queue[0]
+}
+
+func (s *Server) handleTokens(w http.ResponseWriter, r *http.Request) {
+	if code := s.nextFailure(r.URL.Path); code != 0 {
+		writeError(w, code, "scripted failure")
+		return
+	}
+
+	s.mu.Lock()
+	tokens := append([]oneclick.TokenResponse{}, s.tokens...)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+func (s *Server) handleQuote(w http.ResponseWriter, r *http.Request) {
+	if code := s.nextFailure(r.URL.Path); code != 0 {
+		writeError(w, code, "scripted failure")
+		return
+	}
+
+	var req oneclick.QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.quoteRequests = append(s.quoteRequests, req)
+	handler := s.quoteHandler
+	s.mu.Unlock()
+
+	if handler == nil {
+		handler = s.defaultQuote
+	}
+
+	resp, code := handler(req)
+	if code < 200 || code >= 300 {
+		writeError(w, code, "quote rejected")
+		return
+	}
+
+	writeJSON(w, code, resp)
+}
+
+// defaultQuote prices the request using the configured rate (1:1 when unset)
+func (s *Server) defaultQuote(req oneclick.QuoteRequest) (*oneclick.QuoteResponse, int) {
+	s.mu.Lock()
+	rate, ok := s.rates[req.OriginAsset+"|"+req.DestinationAsset]
+	if !ok {
+		rate = 1
+	}
+	inDecimals, inFound := s.decimalsFor(req.OriginAsset)
+	outDecimals, outFound := s.decimalsFor(req.DestinationAsset)
+	s.depositSeq++
+	depositAddress := fmt.Sprintf("mock-deposit-%d", s.depositSeq)
+	s.mu.Unlock()
+
+	if !inFound || !outFound {
+		return nil, http.StatusBadRequest
+	}
+
+	amount, ok := new(big.Float).SetString(req.Amount)
+	if !ok {
+		return nil, http.StatusBadRequest
+	}
+
+	// EXACT_OUTPUT requests carry the destination amount; derive the input from the rate
+	var inFormatted, outFormatted float64
+	if req.SwapType == "EXACT_OUTPUT" {
+		outFormatted, _ = new(big.Float).Quo(amount, pow10(outDecimals)).Float64()
+		inFormatted = outFormatted / rate
+	} else {
+		inFormatted, _ = new(big.Float).Quo(amount, pow10(inDecimals)).Float64()
+		outFormatted = inFormatted * rate
+	}
+
+	amountInInt, _ := new(big.Float).Mul(big.NewFloat(inFormatted), pow10(inDecimals)).Int(nil)
+	amountOutInt, _ := new(big.Float).Mul(big.NewFloat(outFormatted), pow10(outDecimals)).Int(nil)
+
+	quote := oneclick.NewQuote(
+		amountInInt.String(),
+		strconv.FormatFloat(inFormatted, 'f', -1, 64),
+		"0",
+		amountInInt.String(),
+		amountOutInt.String(),
+		strconv.FormatFloat(outFormatted, 'f', -1, 64),
+		"0",
+		amountOutInt.String(),
+		10,
+	)
+	if !req.Dry {
+		quote.SetDepositAddress(depositAddress)
+		quote.SetDeadline(req.Deadline)
+	}
+
+	return oneclick.NewQuoteResponse(time.Now(), "mock-signature", req, *quote), http.StatusOK
+}
+
+// decimalsFor looks up a registered token's decimals (must be called with lock held)
+func (s *Server) decimalsFor(assetID string) (int, bool) {
+	for _, token := range s.tokens {
+		if token.GetAssetId() == assetID {
+			return int(token.GetDecimals()), true
+		}
+	}
+	return 0, false
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if code := s.nextFailure(r.URL.Path); code != 0 {
+		writeError(w, code, "scripted failure")
+		return
+	}
+
+	depositAddress := r.URL.Query().Get("depositAddress")
+
+	s.mu.Lock()
+	status := "PENDING_DEPOSIT"
+	if queue := s.statuses[depositAddress]; len(queue) > 0 {
+		status = queue[0]
+		if len(queue) > 1 {
+			s.statuses[depositAddress] = queue[1:]
+		}
+	}
+	s.mu.Unlock()
+
+	details := oneclick.NewSwapDetails([]string{}, []string{}, []oneclick.TransactionDetails{}, []oneclick.TransactionDetails{})
+	if status == "SUCCESS" {
+		details.SetAmountOutFormatted("1")
+		details.DestinationChainTxHashes = []oneclick.TransactionDetails{
+			*oneclick.NewTransactionDetails("mock-dest-tx-"+depositAddress, ""),
+		}
+	}
+	if status == "REFUNDED" {
+		details.SetRefundedAmountFormatted("1")
+		details.OriginChainTxHashes = []oneclick.TransactionDetails{
+			*oneclick.NewTransactionDetails("mock-refund-tx-"+depositAddress, ""),
+		}
+	}
+
+	quoteResp := oneclick.NewQuoteResponseWithDefaults()
+	resp := oneclick.NewGetExecutionStatusResponse(*quoteResp, status, time.Now(), *details)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleSubmitDeposit(w http.ResponseWriter, r *http.Request) {
+	if code := s.nextFailure(r.URL.Path); code != 0 {
+		writeError(w, code, "scripted failure")
+		return
+	}
+
+	var req oneclick.SubmitDepositTxRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.deposits = append(s.deposits, req)
+	s.mu.Unlock()
+
+	details := oneclick.NewSwapDetails([]string{}, []string{}, []oneclick.TransactionDetails{}, []oneclick.TransactionDetails{})
+	resp := oneclick.NewSubmitDepositTxResponse(*oneclick.NewQuoteResponseWithDefaults(), "PENDING_DEPOSIT", time.Now(), *details)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"message": message})
+}
+
+func pow10(decimals int) *big.Float {
+	return big.NewFloat(math.Pow(10, float64(decimals)))
+}
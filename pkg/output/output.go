@@ -0,0 +1,168 @@
+// Package output renders command results in the machine-readable formats the CLI supports.
+// Table output stays with each command, which knows how to lay out its own data.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Output formats
+const (
+	Table = "table" // Human-readable output rendered by the command
+	JSON  = "json"
+	YAML  = "yaml"
+	CSV   = "csv" // Only for results that are a list of records
+)
+
+// Formats lists the supported formats, in the order they are documented
+var Formats = []string{Table, JSON, YAML, CSV}
+
+// ParseFormat normalizes a format name. "text" is accepted as an alias of table, since it is
+// the output_format config default.
+func ParseFormat(name string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(name)); format {
+	case "", "text":
+		return Table, nil
+	case Table, JSON, YAML, CSV:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown output format '%s' (use %s)", name, strings.Join(Formats, ", "))
+	}
+}
+
+// Write renders v as JSON, YAML or CSV. Values are encoded through their JSON form, so every
+// format uses the same field names. CSV needs v to encode as a list of objects: each object is
+// a row, the columns are the keys in the order first seen, and nested values are written as
+// compact JSON.
+func Write(w io.Writer, format string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	switch format {
+	case JSON:
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", "  "); err != nil {
+			return err
+		}
+		indented.WriteString("\n")
+		_, err := indented.WriteTo(w)
+		return err
+	case YAML:
+		return writeYAML(w, data)
+	case CSV:
+		return writeCSV(w, data)
+	default:
+		return fmt.Errorf("output format '%s' can't be written generically", format)
+	}
+}
+
+// writeYAML converts JSON to block-style YAML, keeping the order of object keys
+func writeYAML(w io.Writer, data []byte) error {
+	// JSON is valid YAML, so parsing it gives a node tree in document order
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to convert output to YAML: %w", err)
+	}
+	blockStyle(&doc)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle clears the flow and quoting styles parsed from JSON so the encoder picks YAML's own
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// writeCSV writes a JSON list of objects as CSV with a header row
+func writeCSV(w io.Writer, data []byte) error {
+	records, columns, err := decodeRecords(data)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	for _, record := range records {
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = record[column]
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// decodeRecords decodes a JSON list of objects into string fields and the union of their keys
+// in the order first seen
+func decodeRecords(data []byte) ([]map[string]string, []string, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, nil, fmt.Errorf("csv output needs a list of records")
+	}
+
+	records := make([]map[string]string, 0, len(items))
+	var columns []string
+	seen := make(map[string]bool)
+
+	for _, item := range items {
+		decoder := json.NewDecoder(bytes.NewReader(item))
+		decoder.UseNumber()
+		if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+			return nil, nil, fmt.Errorf("csv output needs a list of records")
+		}
+
+		record := make(map[string]string)
+		for decoder.More() {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil, nil, err
+			}
+			key := token.(string)
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				return nil, nil, err
+			}
+			record[key] = csvField(value)
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, columns, nil
+}
+
+// csvField formats a JSON value as a CSV field: strings unquoted, null empty, anything else as JSON
+func csvField(value json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return s
+	}
+	if string(value) == "null" {
+		return ""
+	}
+	return string(value)
+}
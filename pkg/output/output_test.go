@@ -0,0 +1,87 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "", want: Table},
+		{name: "text", want: Table},
+		{name: "table", want: Table},
+		{name: " JSON ", want: JSON},
+		{name: "yaml", want: YAML},
+		{name: "csv", want: CSV},
+		{name: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q, error: %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+type record struct {
+	Name   string            `json:"name"`
+	Amount float64           `json:"amount"`
+	Note   *string           `json:"note"`
+	Tags   map[string]string `json:"tags,omitempty"`
+}
+
+func TestWrite(t *testing.T) {
+	note := "has, a comma"
+	records := []record{
+		{Name: "first", Amount: 0.5, Note: &note},
+		{Name: "second", Amount: 1200, Tags: map[string]string{"chain": "near"}},
+	}
+
+	tests := []struct {
+		name    string
+		format  string
+		v       interface{}
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "json",
+			format: JSON,
+			v:      records[0],
+			want:   "{\n  \"name\": \"first\",\n  \"amount\": 0.5,\n  \"note\": \"has, a comma\"\n}\n",
+		},
+		{
+			name:   "yaml keeps field order",
+			format: YAML,
+			v:      records[1],
+			want:   "name: second\namount: 1200\nnote: null\ntags:\n  chain: near\n",
+		},
+		{
+			name:   "csv",
+			format: CSV,
+			v:      records,
+			want:   "name,amount,note,tags\nfirst,0.5,\"has, a comma\",\nsecond,1200,,\"{\"\"chain\"\":\"\"near\"\"}\"\n",
+		},
+		{name: "csv needs a list", format: CSV, v: records[0], wantErr: true},
+		{name: "csv needs records", format: CSV, v: []int{1, 2}, wantErr: true},
+		{name: "table is rendered by the command", format: Table, v: records, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Write(&buf, tt.format, tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error: %v", err, tt.wantErr)
+			}
+			if got := buf.String(); !tt.wantErr && got != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
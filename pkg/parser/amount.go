@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numericPattern matches the first number in a formatted amount, allowing thousands
+// separators and an optional exponent (e.g. "1,234.56", "1234.56 USDC", "1.5e-7")
+var numericPattern = regexp.MustCompile(`[-+]?(?:[0-9][0-9,]*(?:\.[0-9]*)?|\.[0-9]+)(?:[eE][-+]?[0-9]+)?`)
+
+// NormalizeFormattedAmount extracts a plain decimal string from an API "formatted"
+// amount that may contain thousands separators, whitespace or a trailing symbol
+func NormalizeFormattedAmount(formatted string) (string, error) {
+	match := numericPattern.FindString(strings.TrimSpace(formatted))
+	if match == "" {
+		return "", fmt.Errorf("no numeric value in amount %q", formatted)
+	}
+
+	normalized := strings.ReplaceAll(match, ",", "")
+	if _, err := strconv.ParseFloat(normalized, 64); err != nil {
+		return "", fmt.Errorf("invalid amount %q: %w", formatted, err)
+	}
+
+	return normalized, nil
+}
+
+// ParseFormattedAmount parses an API "formatted" amount into a float
+func ParseFormattedAmount(formatted string) (float64, error) {
+	normalized, err := NormalizeFormattedAmount(formatted)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(normalized, 64)
+}
@@ -0,0 +1,52 @@
+package parser
+
+import "testing"
+
+func TestNormalizeFormattedAmount(t *testing.T) {
+	tests := []struct {
+		formatted string
+		want      string
+		wantErr   bool
+	}{
+		{formatted: "0.5", want: "0.5"},
+		{formatted: "1,234.56", want: "1234.56"},
+		{formatted: "1234.56 USDC", want: "1234.56"},
+		{formatted: "  12,000  ", want: "12000"},
+		{formatted: "~ 3.25 BTC", want: "3.25"},
+		{formatted: "1.5e-7", want: "1.5e-7"},
+		{formatted: ".25", want: ".25"},
+		{formatted: "-4", want: "-4"},
+		{formatted: "", wantErr: true},
+		{formatted: "USDC", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeFormattedAmount(tt.formatted)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeFormattedAmount(%q) error = %v, want error: %v", tt.formatted, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeFormattedAmount(%q) = %q, want %q", tt.formatted, got, tt.want)
+		}
+	}
+}
+
+func TestParseFormattedAmount(t *testing.T) {
+	tests := []struct {
+		formatted string
+		want      float64
+		wantErr   bool
+	}{
+		{formatted: "1,234.5 USDC", want: 1234.5},
+		{formatted: "0.00012", want: 0.00012},
+		{formatted: "n/a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFormattedAmount(tt.formatted)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormattedAmount(%q) = %v, %v; want %v, error: %v", tt.formatted, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -8,11 +8,16 @@ import (
 	"near-swap/pkg/types"
 )
 
-// ParseSwapCommand parses a natural language swap command
+// swapCommandPattern matches <amount> <source_token>[@chain] TO <dest_token>[@chain]
+// e.g. "1 SOL TO USDC", "1.5 ETH TO BTC", "100.25 USDC@SOL TO ETH@ARB"
+var swapCommandPattern = regexp.MustCompile(`^(\d+\.?\d*)\s+([A-Z0-9]+)(?:@([A-Z0-9_-]+))?\s+TO\s+([A-Z0-9]+)(?:@([A-Z0-9_-]+))?$`)
+
+// ParseSwapCommand parses a natural language swap command. A token may name its chain
+// with an @chain suffix, which fills in SourceChain or DestChain.
 // Examples:
 //   - "swap 1 SOL to USDC"
 //   - "1.5 ETH to BTC"
-//   - "100 USDC to SOL"
+//   - "100 USDC@sol to ETH@arb"
 func ParseSwapCommand(command string) (*types.SwapRequest, error) {
 	// Normalize the command
 	command = strings.TrimSpace(strings.ToUpper(command))
@@ -20,20 +25,26 @@ func ParseSwapCommand(command string) (*types.SwapRequest, error) {
 	// Remove the word "SWAP" if present at the beginning
 	command = strings.TrimPrefix(command, "SWAP ")
 
-	// Pattern: <amount> <source_token> TO <dest_token>
-	// Matches: "1 SOL TO USDC", "1.5 ETH TO BTC", "100.25 USDC TO SOL"
-	pattern := regexp.MustCompile(`^(\d+\.?\d*)\s+([A-Z0-9]+)\s+TO\s+([A-Z0-9]+)$`)
-
-	matches := pattern.FindStringSubmatch(command)
+	matches := swapCommandPattern.FindStringSubmatch(command)
 	if matches == nil {
-		return nil, fmt.Errorf("invalid swap command format. Expected: 'swap <amount> <token> to <token>' (e.g., 'swap 1 SOL to USDC')")
+		return nil, fmt.Errorf("invalid swap command format. Expected: 'swap <amount> <token>[@chain] to <token>[@chain]' (e.g., 'swap 1 SOL to USDC' or 'swap 1 USDC@sol to ETH@arb')")
 	}
 
-	return &types.SwapRequest{
+	req := &types.SwapRequest{
 		Amount:      matches[1],
 		SourceToken: matches[2],
-		DestToken:   matches[3],
-	}, nil
+		SourceChain: strings.ToLower(matches[3]),
+		DestToken:   matches[4],
+		DestChain:   strings.ToLower(matches[5]),
+	}
+
+	// The same token on the same chain can't be swapped; other aliases are caught once the
+	// tokens are resolved to assets
+	if strings.EqualFold(req.SourceToken, req.DestToken) && req.SourceChain != "" && strings.EqualFold(req.SourceChain, req.DestChain) {
+		return nil, fmt.Errorf("cannot swap %s@%s to itself; pick a different destination token or chain", req.SourceToken, req.SourceChain)
+	}
+
+	return req, nil
 }
 
 // ValidateSwapRequest validates that a swap request has all required fields
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSwapCommand(t *testing.T) {
+	tests := []struct {
+		command                string
+		amount, source, dest   string
+		sourceChain, destChain string
+		wantErr                string // Empty expects the command to parse
+	}{
+		{command: "swap 1 SOL to USDC", amount: "1", source: "SOL", dest: "USDC"},
+		{command: "1.5 eth to btc", amount: "1.5", source: "ETH", dest: "BTC"},
+		{command: "swap 1 USDC@solana to ETH@arbitrum", amount: "1", source: "USDC", dest: "ETH", sourceChain: "solana", destChain: "arbitrum"},
+		{command: "100.25 usdc@sol to eth", amount: "100.25", source: "USDC", dest: "ETH", sourceChain: "sol"},
+		{command: "0.001 BTC to USDC@near", amount: "0.001", source: "BTC", dest: "USDC", destChain: "near"},
+		{command: "  swap 2. usdt@bsc to usdt@eth  ", amount: "2.", source: "USDT", dest: "USDT", sourceChain: "bsc", destChain: "eth"},
+		{command: "swap 1 USDC@sol to USDC@SOL", wantErr: "cannot swap USDC@sol to itself"},
+		{command: "swap 1 USDC@ to ETH", wantErr: "invalid swap command format"},
+		{command: "swap one SOL to USDC", wantErr: "invalid swap command format"},
+		{command: "swap 1 SOL@sol@near to USDC", wantErr: "invalid swap command format"},
+	}
+
+	for _, tt := range tests {
+		req, err := ParseSwapCommand(tt.command)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseSwapCommand(%q) error = %v, want one containing %q", tt.command, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSwapCommand(%q): %v", tt.command, err)
+			continue
+		}
+		if req.Amount != tt.amount || req.SourceToken != tt.source || req.DestToken != tt.dest ||
+			req.SourceChain != tt.sourceChain || req.DestChain != tt.destChain {
+			t.Errorf("ParseSwapCommand(%q) = %s %s@%s to %s@%s; want %s %s@%s to %s@%s", tt.command,
+				req.Amount, req.SourceToken, req.SourceChain, req.DestToken, req.DestChain,
+				tt.amount, tt.source, tt.sourceChain, tt.dest, tt.destChain)
+		}
+	}
+}
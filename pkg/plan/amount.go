@@ -0,0 +1,116 @@
+package plan
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// AmountPrecision is the number of decimal places amounts are stored with
+const AmountPrecision = 8
+
+// smallestAmount is the smallest nonzero amount representable at AmountPrecision
+var smallestAmount = new(big.Rat).SetFrac64(1, 100000000)
+
+// parseDecimal parses a decimal amount string into an exact rational
+func parseDecimal(amount string) (*big.Rat, error) {
+	amount = strings.TrimSpace(amount)
+	if amount == "" {
+		return new(big.Rat), nil
+	}
+
+	r, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal amount: %s", amount)
+	}
+	return r, nil
+}
+
+// formatDecimal formats an exact rational with the storage precision
+func formatDecimal(r *big.Rat) string {
+	return r.FloatString(AmountPrecision)
+}
+
+// TradeBreakdown splits a total into full per-trade executions and the final partial trade.
+// The partial amount is "0" when the per-trade amount divides the total evenly.
+func TradeBreakdown(totalAmount, amountPerTrade string) (int64, string, error) {
+	total, err := parseDecimal(totalAmount)
+	if err != nil {
+		return 0, "", err
+	}
+	perTrade, err := parseDecimal(amountPerTrade)
+	if err != nil {
+		return 0, "", err
+	}
+	if perTrade.Sign() <= 0 {
+		return 0, "", fmt.Errorf("amount per trade must be positive")
+	}
+
+	quotient := new(big.Rat).Quo(total, perTrade)
+	fullTrades := new(big.Int).Quo(quotient.Num(), quotient.Denom())
+
+	partial := new(big.Rat).Sub(total, new(big.Rat).Mul(new(big.Rat).SetInt(fullTrades), perTrade))
+
+	return fullTrades.Int64(), trimDecimal(formatDecimal(partial)), nil
+}
+
+// SplitAmount divides totalAmount into parts equal amounts, for deriving per-trade and per-day
+// limits from a trade or day count. The total must divide exactly at AmountPrecision.
+func SplitAmount(totalAmount string, parts int) (string, error) {
+	if parts < 1 {
+		return "", fmt.Errorf("must split into at least 1 part, got %d", parts)
+	}
+	total, err := parseDecimal(totalAmount)
+	if err != nil {
+		return "", err
+	}
+	if total.Sign() <= 0 {
+		return "", fmt.Errorf("total amount must be positive")
+	}
+
+	share := new(big.Rat).Quo(total, big.NewRat(int64(parts), 1))
+	rounded, _ := new(big.Rat).SetString(formatDecimal(share))
+	if rounded.Cmp(share) != 0 {
+		return "", fmt.Errorf("total %s does not divide evenly into %d parts at %d decimal places", totalAmount, parts, AmountPrecision)
+	}
+
+	return trimDecimal(formatDecimal(share)), nil
+}
+
+// TradeSplitWarning describes the final partial trade when AmountPerTrade doesn't evenly divide
+// TotalAmount. It returns an empty string when the amounts divide evenly.
+func (tp *TradingPlan) TradeSplitWarning() string {
+	fullTrades, partial, err := TradeBreakdown(tp.TotalAmount, tp.AmountPerTrade)
+	if err != nil || partial == "0" {
+		return ""
+	}
+
+	return fmt.Sprintf("total %s %s is not a multiple of per-trade %s: expect %d full trade(s) of %s and a final partial trade of %s %s",
+		tp.TotalAmount, tp.SourceToken, tp.AmountPerTrade, fullTrades, tp.AmountPerTrade, partial, tp.SourceToken)
+}
+
+// trimDecimal removes insignificant trailing zeros from a decimal string
+func trimDecimal(amount string) string {
+	if !strings.Contains(amount, ".") {
+		return amount
+	}
+	amount = strings.TrimRight(amount, "0")
+	return strings.TrimSuffix(amount, ".")
+}
+
+// sourceLimit returns the most source a trade may spend right now: the smaller of what is
+// left of the plan's daily and total amounts
+func (tp *TradingPlan) sourceLimit() (*big.Rat, error) {
+	daily, err := parseDecimal(tp.GetRemainingDailyAmount())
+	if err != nil {
+		return nil, err
+	}
+	total, err := parseDecimal(tp.RemainingAmount)
+	if err != nil {
+		return nil, err
+	}
+	if total.Cmp(daily) < 0 {
+		return total, nil
+	}
+	return daily, nil
+}
@@ -0,0 +1,70 @@
+package plan
+
+import "testing"
+
+func TestTradeBreakdown(t *testing.T) {
+	tests := []struct {
+		total, perTrade string
+		wantTrades      int64
+		wantPartial     string
+		wantErr         bool
+	}{
+		{total: "1", perTrade: "0.1", wantTrades: 10, wantPartial: "0"},
+		{total: "10", perTrade: "0.3", wantTrades: 33, wantPartial: "0.1"},
+		{total: "0.25", perTrade: "1", wantTrades: 0, wantPartial: "0.25"},
+		{total: "1", perTrade: "0", wantErr: true},
+		{total: "lots", perTrade: "1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		trades, partial, err := TradeBreakdown(tt.total, tt.perTrade)
+		if (err != nil) != tt.wantErr || trades != tt.wantTrades || partial != tt.wantPartial {
+			t.Errorf("TradeBreakdown(%s, %s) = %d, %q, %v; want %d, %q, error: %v",
+				tt.total, tt.perTrade, trades, partial, err, tt.wantTrades, tt.wantPartial, tt.wantErr)
+		}
+	}
+}
+
+func TestTradeSplitWarning(t *testing.T) {
+	tests := []struct {
+		total, perTrade string
+		want            string
+	}{
+		{total: "10", perTrade: "0.3",
+			want: "total 10 BTC is not a multiple of per-trade 0.3: expect 33 full trade(s) of 0.3 and a final partial trade of 0.1 BTC"},
+		{total: "1", perTrade: "0.1"},
+		{total: "0.9", perTrade: "0.3"},
+	}
+
+	for _, tt := range tests {
+		p := &TradingPlan{SourceToken: "BTC", TotalAmount: tt.total, AmountPerTrade: tt.perTrade}
+		if got := p.TradeSplitWarning(); got != tt.want {
+			t.Errorf("TradeSplitWarning(%s / %s) = %q, want %q", tt.total, tt.perTrade, got, tt.want)
+		}
+	}
+}
+
+func TestSplitAmount(t *testing.T) {
+	tests := []struct {
+		total   string
+		parts   int
+		want    string
+		wantErr bool
+	}{
+		{total: "5", parts: 10, want: "0.5"},                // --num-trades 10 on a total of 5
+		{total: "5", parts: 5, want: "1"},                   // --days 5
+		{total: "0.00000003", parts: 3, want: "0.00000001"}, // Exact at the storage precision
+		{total: "1", parts: 3, wantErr: true},               // 0.333... doesn't divide evenly
+		{total: "0.00000001", parts: 2, wantErr: true},      // Below the storage precision
+		{total: "5", parts: 0, wantErr: true},
+		{total: "0", parts: 2, wantErr: true},
+		{total: "lots", parts: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := SplitAmount(tt.total, tt.parts)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("SplitAmount(%s, %d) = %q, %v; want %q, error: %v", tt.total, tt.parts, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
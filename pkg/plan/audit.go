@@ -0,0 +1,117 @@
+package plan
+
+import (
+	"fmt"
+	"time"
+
+	"near-swap/pkg/deposit"
+)
+
+// Audit results for an execution's deposit transaction
+const (
+	AuditOK         = "ok"         // Found on chain with the recorded amount
+	AuditUnverified = "unverified" // Found on chain, but the chain doesn't report the amount
+	AuditPending    = "pending"    // Found on chain, not mined yet
+	AuditNoTx       = "no_tx"      // No transaction hash was recorded
+	AuditMissing    = "missing"    // The recorded transaction could not be found
+	AuditFailed     = "failed"     // The transaction was mined but failed
+	AuditMismatch   = "mismatch"   // The transaction sent a different amount than recorded
+)
+
+// txLooker looks up a deposit transaction on a chain; deposit.Manager satisfies it
+type txLooker interface {
+	GetTransactionInfo(chain, txid string) (map[string]interface{}, error)
+}
+
+// AuditFinding is the result of checking one execution's deposit on chain
+type AuditFinding struct {
+	ExecutionID   string          `json:"execution_id"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Status        ExecutionStatus `json:"status"`
+	TxHash        string          `json:"tx_hash,omitempty"`
+	Amount        string          `json:"amount"`
+	OnChainAmount string          `json:"on_chain_amount,omitempty"`
+	Result        string          `json:"result"`
+	Detail        string          `json:"detail,omitempty"`
+}
+
+// Discrepancy reports whether the finding points at a recording bug or tampering
+func (f AuditFinding) Discrepancy() bool {
+	switch f.Result {
+	case AuditNoTx, AuditMissing, AuditFailed, AuditMismatch:
+		return true
+	}
+	return false
+}
+
+// AuditDeposits checks that every deposited or completed execution of a plan has a matching
+// transaction on the plan's source chain
+func AuditDeposits(plan *TradingPlan, looker txLooker) []AuditFinding {
+	var findings []AuditFinding
+
+	for _, exec := range plan.ExecutionHistory {
+		if exec.Status != ExecutionDeposited && exec.Status != ExecutionCompleted && !exec.cancelledAfterDeposit() {
+			continue
+		}
+
+		finding := AuditFinding{
+			ExecutionID: exec.ID,
+			Timestamp:   exec.Timestamp,
+			Status:      exec.Status,
+			TxHash:      exec.TxHash,
+			Amount:      exec.Amount,
+		}
+		auditExecution(&finding, plan.SourceChain, looker)
+		findings = append(findings, finding)
+	}
+
+	return findings
+}
+
+// auditExecution looks up a finding's transaction and fills in its result
+func auditExecution(finding *AuditFinding, chain string, looker txLooker) {
+	if finding.TxHash == "" {
+		finding.Result = AuditNoTx
+		finding.Detail = "execution counts toward progress but has no deposit transaction"
+		return
+	}
+
+	info, err := looker.GetTransactionInfo(chain, finding.TxHash)
+	if err != nil {
+		finding.Result = AuditMissing
+		finding.Detail = err.Error()
+		return
+	}
+
+	summary := deposit.SummarizeTransaction(chain, info)
+	finding.OnChainAmount = summary.Amount
+
+	switch {
+	case summary.Failed:
+		finding.Result = AuditFailed
+		finding.Detail = "transaction failed on chain"
+	case summary.Amount == "":
+		finding.Result = AuditUnverified
+		finding.Detail = "chain does not report the transferred amount"
+	case !sameAmount(summary.Amount, finding.Amount):
+		finding.Result = AuditMismatch
+		finding.Detail = fmt.Sprintf("recorded %s but %s was sent", trimDecimal(finding.Amount), trimDecimal(summary.Amount))
+	case summary.Pending:
+		finding.Result = AuditPending
+	default:
+		finding.Result = AuditOK
+	}
+}
+
+// sameAmount compares two decimal amounts at the storage precision
+func sameAmount(a, b string) bool {
+	ra, err := parseDecimal(a)
+	if err != nil {
+		return false
+	}
+	rb, err := parseDecimal(b)
+	if err != nil {
+		return false
+	}
+	return formatDecimal(ra) == formatDecimal(rb)
+}
@@ -0,0 +1,63 @@
+package plan
+
+import (
+	"fmt"
+	"testing"
+)
+
+// fakeLooker serves fixed transaction infos by txid; unknown transactions are not found
+type fakeLooker map[string]map[string]interface{}
+
+func (f fakeLooker) GetTransactionInfo(chain, txid string) (map[string]interface{}, error) {
+	info, ok := f[txid]
+	if !ok {
+		return nil, fmt.Errorf("transaction %s not found on %s", txid, chain)
+	}
+	return info, nil
+}
+
+func TestAuditDeposits(t *testing.T) {
+	plan := &TradingPlan{Name: "p", SourceChain: "btc", ExecutionHistory: []Execution{
+		{ID: "ok", Amount: "0.1", Status: ExecutionCompleted, TxHash: "tx-ok"},
+		{ID: "missing", Amount: "0.1", Status: ExecutionDeposited, TxHash: "tx-missing"},
+		{ID: "no-tx", Amount: "0.1", Status: ExecutionCompleted},
+		{ID: "mismatch", Amount: "0.1", Status: ExecutionCompleted, TxHash: "tx-mismatch"},
+		{ID: "pending", Amount: "0.1", Status: ExecutionDeposited, TxHash: "tx-pending"},
+		{ID: "never-deposited", Amount: "0.1", Status: ExecutionFailed, TxHash: "tx-missing"},
+	}}
+	looker := fakeLooker{
+		"tx-ok":       {"amount": -0.1, "confirmations": 3.0},
+		"tx-mismatch": {"amount": -0.01, "confirmations": 3.0},
+		"tx-pending":  {"amount": "-0.10000000", "confirmations": 0.0},
+	}
+
+	want := []struct {
+		id          string
+		result      string
+		discrepancy bool
+	}{
+		{id: "ok", result: AuditOK},
+		{id: "missing", result: AuditMissing, discrepancy: true},
+		{id: "no-tx", result: AuditNoTx, discrepancy: true},
+		{id: "mismatch", result: AuditMismatch, discrepancy: true},
+		{id: "pending", result: AuditPending},
+	}
+
+	findings := AuditDeposits(plan, looker)
+	if len(findings) != len(want) {
+		t.Fatalf("got %d findings (%+v), want %d; executions that never deposited aren't audited", len(findings), findings, len(want))
+	}
+	for i, w := range want {
+		got := findings[i]
+		if got.ExecutionID != w.id || got.Result != w.result || got.Discrepancy() != w.discrepancy {
+			t.Errorf("finding %d = %s %s (discrepancy %v), want %s %s (discrepancy %v)",
+				i, got.ExecutionID, got.Result, got.Discrepancy(), w.id, w.result, w.discrepancy)
+		}
+	}
+	if detail := findings[3].Detail; detail != "recorded 0.1 but 0.01 was sent" {
+		t.Errorf("mismatch detail = %q", detail)
+	}
+	if findings[1].Detail == "" {
+		t.Error("missing transaction has no detail")
+	}
+}
@@ -0,0 +1,57 @@
+package plan
+
+import (
+	"errors"
+
+	"near-swap/pkg/client"
+)
+
+// UnauthorizedPauseThreshold is how many consecutive 401/403 responses the executor tolerates
+// before pausing all plans instead of retrying with a token the API keeps rejecting
+const UnauthorizedPauseThreshold = 3
+
+// authPauseReason is the pause reason recorded for plans paused because the JWT was rejected
+const authPauseReason = "API token rejected"
+
+// recordAPIResult tracks consecutive authorization failures across all plans (they share one
+// token) and pauses every active plan once the threshold is reached. Any other outcome resets
+// the count.
+func (e *Executor) recordAPIResult(err error) {
+	if err == nil || !errors.Is(err, client.ErrUnauthorized) {
+		e.authFailures.Store(0)
+		return
+	}
+
+	if e.authFailures.Add(1) != UnauthorizedPauseThreshold {
+		return
+	}
+
+	e.log.Error("The API rejected the JWT token repeatedly", "failures", UnauthorizedPauseThreshold, "error", err)
+	for _, p := range e.manager.GetActivePlans() {
+		if err := e.manager.PausePlan(p.Name, authPauseReason); err != nil {
+			e.log.Error("Error pausing plan", "plan", p.Name, "error", err)
+			continue
+		}
+		e.StopPlan(p.Name)
+		e.log.Warn("Paused plan",
+			"plan", p.Name, "event", "plan_paused", "reason", authPauseReason)
+	}
+	e.log.Info("Refresh NEAR_SWAP_JWT_TOKEN and restart the daemon; paused plans resume automatically on startup.")
+}
+
+// resumeAuthPausedPlans reactivates plans paused for a rejected token, on the assumption
+// that the daemon was restarted with a refreshed one. If it wasn't, they are paused again
+// after UnauthorizedPauseThreshold failures.
+func (e *Executor) resumeAuthPausedPlans() {
+	for _, p := range e.manager.ListPlansByStatus(StatusPaused) {
+		if p.PauseReason != authPauseReason {
+			continue
+		}
+		if err := e.manager.ResumePlan(p.Name, authPauseReason); err != nil {
+			e.log.Error("Error resuming plan", "plan", p.Name, "error", err)
+			continue
+		}
+		e.log.Info("Resumed plan",
+			"plan", p.Name, "event", "plan_resumed", "paused_for", authPauseReason)
+	}
+}
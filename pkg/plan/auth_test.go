@@ -0,0 +1,53 @@
+package plan
+
+import "testing"
+
+func TestExecutorPausesOnRejectedToken(t *testing.T) {
+	e, server := newMockExecutor(t)
+
+	// Every price check is rejected; the plan keeps its place until the threshold
+	codes := make([]int, UnauthorizedPauseThreshold)
+	for i := range codes {
+		codes[i] = 401
+	}
+	codes[0] = 403
+	server.FailNext("/v0/quote", codes...)
+	for check := 1; check <= UnauthorizedPauseThreshold; check++ {
+		if p, _ := e.manager.GetPlan("p"); p.Status != StatusActive {
+			t.Fatalf("before check %d: plan is %s, want active", check, p.Status)
+		}
+		e.checkAndExecutePlan("p", nil)
+	}
+
+	p, _ := e.manager.GetPlan("p")
+	if p.Status != StatusPaused || p.PauseReason != authPauseReason {
+		t.Fatalf("plan is %s (%q), want paused for the rejected token", p.Status, p.PauseReason)
+	}
+	if len(p.ExecutionHistory) != 0 {
+		t.Errorf("executions = %d, want no trade with a rejected token", len(p.ExecutionHistory))
+	}
+
+	// A restart with a working token resumes the plan
+	e.resumeAuthPausedPlans()
+	if p, _ := e.manager.GetPlan("p"); p.Status != StatusActive || p.PauseReason != "" {
+		t.Errorf("after restart plan is %s (%q), want active", p.Status, p.PauseReason)
+	}
+}
+
+func TestRecordAPIResultResetsOnSuccess(t *testing.T) {
+	e, server := newMockExecutor(t)
+
+	// Failures that don't come in an unbroken run never pause
+	server.FailNext("/v0/quote", 401, 401)
+	e.checkAndExecutePlan("p", nil)
+	e.checkAndExecutePlan("p", nil)
+	server.SetRate("nep141:btc-BTC.mock.near", "nep141:near-USDC.mock.near", 80000) // Above the trigger, so no trade
+	e.checkAndExecutePlan("p", nil)
+	server.FailNext("/v0/quote", 401, 401)
+	e.checkAndExecutePlan("p", nil)
+	e.checkAndExecutePlan("p", nil)
+
+	if p, _ := e.manager.GetPlan("p"); p.Status != StatusActive {
+		t.Errorf("plan is %s (%q), want active", p.Status, p.PauseReason)
+	}
+}
@@ -0,0 +1,82 @@
+package plan
+
+import (
+	"fmt"
+	"time"
+
+	"near-swap/pkg/deposit"
+)
+
+// BalanceCheckInterval is how often funding wallet balances are checked
+const BalanceCheckInterval = 5 * time.Minute
+
+// lowBalanceReason is the pause reason recorded for plans paused by the balance monitor
+func lowBalanceReason(chain string) string {
+	return fmt.Sprintf("low balance on %s", chain)
+}
+
+// monitorBalances periodically checks funding wallet balances until the executor stops
+func (e *Executor) monitorBalances() {
+	e.checkBalances()
+
+	ticker := time.NewTicker(BalanceCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stopChan:
+			return
+		case <-ticker.C:
+			e.checkBalances()
+		}
+	}
+}
+
+// checkBalances pauses plans sourcing from chains whose funding balance dropped below the
+// configured threshold, and resumes plans it paused once the balance recovers
+func (e *Executor) checkBalances() {
+	balanceOf := e.balanceOf
+	if balanceOf == nil {
+		balanceOf = deposit.NewManager(e.config.AutoDeposit).GetBalance
+	}
+
+	for chain, threshold := range e.config.AutoDeposit.LowBalance {
+		chain = deposit.CanonicalChain(chain)
+		reason := lowBalanceReason(chain)
+
+		balance, err := balanceOf(chain)
+		if err != nil {
+			e.log.Warn("Could not check balance", "chain", chain, "error", err)
+			continue
+		}
+
+		if balance < threshold {
+			for _, p := range e.manager.GetActivePlans() {
+				if deposit.CanonicalChain(p.SourceChain) != chain {
+					continue
+				}
+				if err := e.manager.PausePlan(p.Name, reason); err != nil {
+					e.log.Error("Error pausing plan", "plan", p.Name, "error", err)
+					continue
+				}
+				e.StopPlan(p.Name)
+				e.log.Warn("Paused plan",
+					"plan", p.Name, "event", "plan_paused", "reason", reason, "chain", chain, "balance", balance, "threshold", threshold)
+			}
+			continue
+		}
+
+		for _, p := range e.manager.ListPlansByStatus(StatusPaused) {
+			if p.PauseReason != reason {
+				continue
+			}
+			if err := e.manager.ResumePlan(p.Name, reason); err != nil {
+				e.log.Error("Error resuming plan", "plan", p.Name, "error", err)
+				continue
+			}
+			e.StartPlan(p.Name)
+			e.log.Info("Resumed plan, balance recovered",
+				"plan", p.Name, "event", "plan_resumed", "chain", chain, "balance", balance)
+		}
+	}
+}
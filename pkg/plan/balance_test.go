@@ -0,0 +1,76 @@
+package plan
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckBalances(t *testing.T) {
+	e, _ := newMockExecutor(t)
+	e.config.AutoDeposit.LowBalance = map[string]float64{"BTC": 0.5}
+	for _, name := range []string{"manual", "other-chain"} {
+		source, chain, refund := "BTC", "btc", testRefundAddr
+		if name == "other-chain" {
+			source, chain, refund = "ETH", "eth", "0x0000000000000000000000000000000000000001"
+		}
+		if _, err := e.manager.CreatePlan(name, source, "USDC", chain, "near", "1", "0.1", "0.2", "50000", PriceBelow,
+			"me.near", refund, "", CreatePlanOptions{Force: true}); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.manager.StartPlan(name, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.manager.PausePlan("manual", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	balance := 0.1
+	var balanceErr error
+	e.balanceOf = func(chain string) (float64, error) {
+		if chain != "bitcoin" {
+			t.Errorf("balance checked on %s, want bitcoin", chain)
+		}
+		return balance, balanceErr
+	}
+	statuses := func() map[string]PlanStatus {
+		got := make(map[string]PlanStatus)
+		for _, p := range e.manager.ListPlans() {
+			got[p.Name] = p.Status
+		}
+		return got
+	}
+
+	// A low balance pauses only the active plans funded from that chain
+	e.checkBalances()
+	want := map[string]PlanStatus{"p": StatusPaused, "manual": StatusPaused, "other-chain": StatusActive}
+	for name, status := range statuses() {
+		if status != want[name] {
+			t.Errorf("low balance: %s is %s, want %s", name, status, want[name])
+		}
+	}
+	if p, _ := e.manager.GetPlan("p"); p.PauseReason != "low balance on bitcoin" {
+		t.Errorf("pause reason = %q, want low balance on bitcoin", p.PauseReason)
+	}
+
+	// An unreadable balance changes nothing
+	balance, balanceErr = 5, errors.New("node unreachable")
+	e.checkBalances()
+	if got := statuses()["p"]; got != StatusPaused {
+		t.Errorf("unreadable balance: p is %s, want paused", got)
+	}
+
+	// A refill resumes what the monitor paused and leaves manual pauses alone
+	balanceErr = nil
+	e.checkBalances()
+	defer e.StopPlan("p")
+	want = map[string]PlanStatus{"p": StatusActive, "manual": StatusPaused, "other-chain": StatusActive}
+	for name, status := range statuses() {
+		if status != want[name] {
+			t.Errorf("refilled: %s is %s, want %s", name, status, want[name])
+		}
+	}
+	if p, _ := e.manager.GetPlan("p"); p.PauseReason != "" {
+		t.Errorf("pause reason = %q after resuming, want none", p.PauseReason)
+	}
+}
@@ -0,0 +1,88 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ErrExecutionCancelled is returned when updating an execution the user has cancelled
+var ErrExecutionCancelled = errors.New("execution was cancelled")
+
+// defaultCancelReason is recorded when an execution is cancelled without a reason
+const defaultCancelReason = "cancelled by user"
+
+// CancelExecution cancels a pending or deposited execution so the executor no longer deposits
+// for, verifies or adjusts it. A pending execution's reserved amount is given back to the plan's
+// limits. A deposited execution's amount stays counted: its funds have already left the wallet,
+// and the 1Click API settles or refunds the swap on its own. The cancelled execution is returned.
+func (m *Manager) CancelExecution(planName, executionID, reason string) (*Execution, error) {
+	defer m.lockPlan(planName)()
+
+	plan, err := m.storage.Get(planName)
+	if err != nil {
+		return nil, err
+	}
+
+	exec := plan.findExecution(executionID)
+	if exec == nil {
+		return nil, fmt.Errorf("execution '%s' not found in plan '%s'", executionID, planName)
+	}
+	if exec.Status != ExecutionPending && exec.Status != ExecutionDeposited {
+		return nil, fmt.Errorf("execution '%s' is %s; only pending or deposited executions can be cancelled", executionID, exec.Status)
+	}
+
+	if reason == "" {
+		reason = defaultCancelReason
+	}
+	now := time.Now()
+	exec.Status = ExecutionCancelled
+	exec.CancelReason = reason
+	exec.CancelledAt = &now
+	plan.settleReservation(exec)
+
+	plan.LastUpdated = now
+
+	cancelled := *exec
+	return &cancelled, m.storage.Update(plan)
+}
+
+// cancelledAfterDeposit reports whether the execution was cancelled after its deposit was sent,
+// so its amount still counts toward the plan's limits
+func (exec *Execution) cancelledAfterDeposit() bool {
+	return exec.Status == ExecutionCancelled && exec.TxHash != ""
+}
+
+// recordCancelledDeposit stores the deposit of an execution that was cancelled while its
+// deposit was being sent. The funds left the wallet regardless, so a first deposit is counted
+// toward the plan's limits again; the execution stays cancelled.
+func (tp *TradingPlan) recordCancelledDeposit(exec *Execution, txHash string) error {
+	if txHash == "" || exec.TxHash != "" {
+		return nil
+	}
+	exec.TxHash = txHash
+
+	amount, err := parseDecimal(exec.Amount)
+	if err != nil {
+		return fmt.Errorf("invalid execution amount: %w", err)
+	}
+	countedToday := exec.Timestamp.Format("2006-01-02") == tp.LastExecutionDate
+	if _, err := tp.addProgress(new(big.Rat).Set(amount), countedToday); err != nil {
+		return err
+	}
+	if exec.LadderPrice != "" {
+		tp.recordLadderFill(exec.LadderPrice, amount)
+	}
+	return nil
+}
+
+// IsExecutionCancelled reports whether the user has cancelled an execution
+func (m *Manager) IsExecutionCancelled(planName, executionID string) bool {
+	plan, err := m.storage.Get(planName)
+	if err != nil {
+		return false
+	}
+	exec := plan.findExecution(executionID)
+	return exec != nil && exec.Status == ExecutionCancelled
+}
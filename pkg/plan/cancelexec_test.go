@@ -0,0 +1,84 @@
+package plan
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCancelExecution(t *testing.T) {
+	tests := []struct {
+		name          string
+		deposited     bool
+		wantRemaining string // Cancelling a pending trade gives its amount back
+	}{
+		{name: "pending", wantRemaining: "0.20000000"},
+		{name: "deposited", deposited: true, wantRemaining: "0.10000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, server := newMockExecutor(t)
+			e.checkAndExecutePlan("p", nil)
+			_, exec := lastExecution(t, e)
+			if tt.deposited {
+				if err := e.manager.UpdateExecutionStatus("p", exec.ID, ExecutionDeposited, "deposit-tx", ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cancelled, err := e.manager.CancelExecution("p", exec.ID, "fat-fingered trigger")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cancelled.Status != ExecutionCancelled || cancelled.CancelReason != "fat-fingered trigger" || cancelled.CancelledAt == nil {
+				t.Errorf("cancelled execution = %+v, want it cancelled with the reason recorded", cancelled)
+			}
+
+			// Verification stops, and a swap that settles anyway doesn't change the books
+			p, _ := e.manager.GetPlan("p")
+			p.ExecutionHistory[0].Timestamp = time.Now().Add(-time.Hour)
+			if err := e.manager.storage.Update(p); err != nil {
+				t.Fatal(err)
+			}
+			polls := server.RequestCount("/v0/status")
+			e.verifyPendingSwaps()
+			if n := server.RequestCount("/v0/status") - polls; n != 0 {
+				t.Errorf("%d status polls for a cancelled execution, want none", n)
+			}
+			server.QueueStatus(exec.DepositAddress, "SUCCESS")
+			if !e.checkSwapStatus("p", exec.ID, exec.DepositAddress) {
+				t.Error("verifier kept polling a cancelled execution")
+			}
+			if err := e.manager.UpdateExecutionStatus("p", exec.ID, ExecutionFailed, "", "late failure"); err == nil {
+				t.Error("updating a cancelled execution succeeded")
+			}
+
+			p, err = e.manager.RecomputeProgress("p")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, exec := lastExecution(t, e); exec.Status != ExecutionCancelled {
+				t.Errorf("execution is %s, want it to stay cancelled", exec.Status)
+			}
+			if p.RemainingAmount != tt.wantRemaining {
+				t.Errorf("remaining = %s, want %s", p.RemainingAmount, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestCancelExecutionRejectsSettledExecutions(t *testing.T) {
+	e, server := newMockExecutor(t)
+	e.checkAndExecutePlan("p", nil)
+	_, exec := lastExecution(t, e)
+	server.QueueStatus(exec.DepositAddress, "SUCCESS")
+	e.checkSwapStatus("p", exec.ID, exec.DepositAddress)
+
+	if _, err := e.manager.CancelExecution("p", exec.ID, ""); err == nil || !strings.Contains(err.Error(), "only pending or deposited executions can be cancelled") {
+		t.Errorf("cancelling a completed execution: error = %v", err)
+	}
+	if _, err := e.manager.CancelExecution("p", "missing", ""); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("cancelling a missing execution: error = %v", err)
+	}
+}
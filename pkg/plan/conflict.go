@@ -0,0 +1,123 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PlanConflictError is returned when a plan overlaps one or more active plans
+type PlanConflictError struct {
+	Plan      string
+	Conflicts []string
+}
+
+func (e *PlanConflictError) Error() string {
+	return fmt.Sprintf("plan '%s' overlaps active plan(s) %s trading the same pair with an overlapping trigger; use --force to proceed anyway",
+		e.Plan, strings.Join(e.Conflicts, ", "))
+}
+
+// CheckConflicts returns a PlanConflictError if another active plan trades the same
+// source/dest token and chain with a trigger range that overlaps this plan's trigger
+func (m *Manager) CheckConflicts(plan *TradingPlan) error {
+	conflicts := make([]string, 0)
+	for _, other := range m.storage.ListByStatus(StatusActive) {
+		if other.Name == plan.Name {
+			continue
+		}
+		if plansConflict(plan, other) {
+			conflicts = append(conflicts, other.Name)
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	sort.Strings(conflicts)
+	return &PlanConflictError{Plan: plan.Name, Conflicts: conflicts}
+}
+
+// plansConflict reports whether two plans would fire on the same pair at the same prices
+func plansConflict(a, b *TradingPlan) bool {
+	if !strings.EqualFold(a.SourceToken, b.SourceToken) ||
+		!strings.EqualFold(a.DestToken, b.DestToken) ||
+		!strings.EqualFold(a.SourceChain, b.SourceChain) ||
+		!strings.EqualFold(a.DestChain, b.DestChain) {
+		return false
+	}
+
+	aLow, aHigh, okA := triggerRange(a)
+	bLow, bHigh, okB := triggerRange(b)
+	if !okA || !okB {
+		// One of them has no known firing prices, so there is nothing to overlap
+		return false
+	}
+
+	return aLow <= bHigh && bLow <= aHigh
+}
+
+// triggerRange returns the closed price interval in which a plan's trigger fires. A ladder
+// fires from its nearest unfilled level onward. A trailing stop fires at or below its stop
+// price for the peak recorded so far; one without a peak yet, or a ladder with every level
+// filled, reports false. A trigger that can't be parsed covers every price.
+func triggerRange(p *TradingPlan) (float64, float64, bool) {
+	if p.IsTrailingStop() {
+		if p.PeakPrice <= 0 {
+			return 0, 0, false
+		}
+		return math.Inf(-1), p.TrailingStopPrice(p.PeakPrice), true
+	}
+
+	trigger, err := strconv.ParseFloat(p.TriggerPrice, 64)
+	if p.HasLadder() {
+		trigger, err = ladderTrigger(p)
+		if errors.Is(err, errLadderFilled) {
+			return 0, 0, false
+		}
+	}
+	if err != nil {
+		// Can't reason about the trigger, assume the worst
+		return math.Inf(-1), math.Inf(1), true
+	}
+
+	switch p.PriceCondition {
+	case PriceAbove:
+		return trigger, math.Inf(1), true
+	case PriceBelow:
+		return math.Inf(-1), trigger, true
+	case PriceAt:
+		tolerance := trigger * 0.005
+		return trigger - tolerance, trigger + tolerance, true
+	default:
+		return math.Inf(-1), math.Inf(1), true
+	}
+}
+
+// errLadderFilled is returned by ladderTrigger for a ladder with no level left to fire
+var errLadderFilled = errors.New("every ladder level is filled")
+
+// ladderTrigger returns the unfilled ladder level the price reaches first: the lowest for an
+// above ladder, the highest for a below one
+func ladderTrigger(p *TradingPlan) (float64, error) {
+	trigger, found := 0.0, false
+	for _, level := range p.Ladder {
+		if level.Filled {
+			continue
+		}
+		price, err := strconv.ParseFloat(level.Price, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ladder price '%s'", level.Price)
+		}
+		if !found || (p.PriceCondition == PriceAbove && price < trigger) || (p.PriceCondition == PriceBelow && price > trigger) {
+			trigger, found = price, true
+		}
+	}
+	if !found {
+		return 0, errLadderFilled
+	}
+	return trigger, nil
+}
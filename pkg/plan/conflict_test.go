@@ -0,0 +1,121 @@
+package plan
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlansConflict(t *testing.T) {
+	pair := func(condition PriceCondition, trigger string) *TradingPlan {
+		return &TradingPlan{SourceToken: "BTC", DestToken: "USDC", SourceChain: "btc", DestChain: "near",
+			PriceCondition: condition, TriggerPrice: trigger}
+	}
+	ladder := func(condition PriceCondition, levels ...LadderLevel) *TradingPlan {
+		p := pair(condition, levels[0].Price)
+		p.Ladder = levels
+		return p
+	}
+	trailing := func(peak float64) *TradingPlan {
+		p := pair(PriceTrailingStop, "")
+		p.TrailPercent, p.PeakPrice = 10, peak
+		return p
+	}
+	otherPair := pair(PriceBelow, "60000")
+	otherPair.DestToken = "ETH"
+
+	tests := []struct {
+		name string
+		a, b *TradingPlan
+		want bool
+	}{
+		{name: "both below", a: pair(PriceBelow, "60000"), b: pair(PriceBelow, "50000"), want: true},
+		{name: "above and below apart", a: pair(PriceAbove, "70000"), b: pair(PriceBelow, "60000")},
+		{name: "above and below overlapping", a: pair(PriceAbove, "50000"), b: pair(PriceBelow, "60000"), want: true},
+		{name: "at inside a below range", a: pair(PriceAt, "55000"), b: pair(PriceBelow, "60000"), want: true},
+		{name: "at well apart", a: pair(PriceAt, "55000"), b: pair(PriceAt, "60000")},
+		{name: "different pair", a: pair(PriceBelow, "60000"), b: otherPair},
+		{name: "token case is ignored", a: pair(PriceBelow, "60000"), b: &TradingPlan{SourceToken: "btc", DestToken: "usdc",
+			SourceChain: "BTC", DestChain: "NEAR", PriceCondition: PriceBelow, TriggerPrice: "50000"}, want: true},
+		{name: "unparsable trigger assumes the worst", a: pair(PriceAbove, "soon"), b: pair(PriceBelow, "1"), want: true},
+
+		{name: "trailing stop without a peak", a: trailing(0), b: pair(PriceBelow, "60000")},
+		{name: "two trailing stops without peaks", a: trailing(0), b: trailing(0)},
+		{name: "trailing stop below an above plan", a: trailing(70000), b: pair(PriceAbove, "65000")},
+		{name: "trailing stop reaching an above plan", a: trailing(80000), b: pair(PriceAbove, "65000"), want: true},
+		{name: "trailing stop and a below plan", a: trailing(70000), b: pair(PriceBelow, "50000"), want: true},
+
+		{
+			name: "ladder past its first level",
+			a: ladder(PriceAbove, LadderLevel{Price: "60000", Filled: true}, LadderLevel{Price: "70000"},
+				LadderLevel{Price: "80000"}),
+			b: pair(PriceBelow, "65000"),
+		},
+		{
+			name: "ladder level inside another range",
+			a:    ladder(PriceAbove, LadderLevel{Price: "60000"}, LadderLevel{Price: "70000"}),
+			b:    pair(PriceBelow, "65000"),
+			want: true,
+		},
+		{
+			name: "below ladder",
+			a:    ladder(PriceBelow, LadderLevel{Price: "50000", Filled: true}, LadderLevel{Price: "40000"}),
+			b:    pair(PriceAbove, "35000"),
+			want: true,
+		},
+		{
+			name: "below ladder past its first level",
+			a:    ladder(PriceBelow, LadderLevel{Price: "50000", Filled: true}, LadderLevel{Price: "40000"}),
+			b:    pair(PriceAbove, "45000"),
+		},
+		{
+			name: "filled ladder",
+			a:    ladder(PriceAbove, LadderLevel{Price: "60000", Filled: true}, LadderLevel{Price: "70000", Filled: true}),
+			b:    pair(PriceAbove, "65000"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := plansConflict(tt.a, tt.b); got != tt.want {
+				t.Errorf("plansConflict = %v, want %v", got, tt.want)
+			}
+			if got := plansConflict(tt.b, tt.a); got != tt.want {
+				t.Errorf("plansConflict reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckConflicts(t *testing.T) {
+	manager := newTestManager(t, "1", "0.1", "0.2") // Active "p" triggers below 70000
+	if _, err := manager.CreatePlan("paused", "BTC", "USDC", "btc", "near", "1", "0.1", "0.2", "60000", PriceBelow,
+		"me.near", testRefundAddr, "", CreatePlanOptions{Force: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		condition PriceCondition
+		trigger   string
+		wantErr   bool
+	}{
+		{name: "overlaps the active plan", condition: PriceBelow, trigger: "65000", wantErr: true},
+		{name: "clear of the active plan", condition: PriceAbove, trigger: "75000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidate := &TradingPlan{Name: "new", SourceToken: "BTC", DestToken: "USDC", SourceChain: "btc", DestChain: "near",
+				PriceCondition: tt.condition, TriggerPrice: tt.trigger}
+			err := manager.CheckConflicts(candidate)
+			var conflict *PlanConflictError
+			if errors.As(err, &conflict) != tt.wantErr {
+				t.Fatalf("error = %v, want conflict: %v", err, tt.wantErr)
+			}
+			// Plans that aren't active never conflict
+			if tt.wantErr && (len(conflict.Conflicts) != 1 || conflict.Conflicts[0] != "p") {
+				t.Errorf("conflicts = %v, want [p]", conflict.Conflicts)
+			}
+		})
+	}
+}
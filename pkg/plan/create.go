@@ -0,0 +1,135 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"near-swap/pkg/client"
+	"near-swap/pkg/deposit"
+)
+
+// PlanSpec describes a plan to create, as given by the user
+type PlanSpec struct {
+	Name           string
+	Description    string
+	SourceToken    string
+	DestToken      string
+	SourceChain    string
+	DestChain      string
+	TotalAmount    string
+	AmountPerTrade string
+	AmountPerDay   string
+	TriggerPrice   string
+	PriceCondition PriceCondition
+	RecipientAddr  string // Empty uses CreateChecks.DefaultRecipient
+	RefundAddr     string // Empty uses CreateChecks.DefaultRefundTo, then the recipient
+	Options        CreatePlanOptions
+}
+
+// CreateChecks holds what CreateValidatedPlan checks a new plan against
+type CreateChecks struct {
+	DefaultRecipient      string // Recipient used when the spec has none (default_recipient)
+	DefaultRefundTo       string // Refund address used when the spec has none (default_refund_to)
+	AllowSwappedAddresses bool   // Accept recipient and refund addresses that look swapped
+
+	// MarketClient returns the client the market checks quote with for a plan whose own token
+	// is in apiTokenEnv (empty for the default token), or nil when no token is configured. A
+	// nil MarketClient skips the market checks.
+	MarketClient func(apiTokenEnv string) *client.OneClickClient
+}
+
+// PlanValidationError is returned when a new plan fails validation. Findings holds every
+// problem found, warnings included.
+type PlanValidationError struct {
+	Findings []Finding
+}
+
+func (e *PlanValidationError) Error() string {
+	for _, f := range e.Findings {
+		if f.Severity == SeverityError {
+			if f.Field != "" {
+				return fmt.Sprintf("plan failed validation: %s: %s", f.Field, f.Message)
+			}
+			return "plan failed validation: " + f.Message
+		}
+	}
+	return "plan failed validation"
+}
+
+// CreateValidatedPlan fills in default addresses, checks the recipient and refund addresses
+// fit their chains, runs ValidatePlan (and the market checks when checks.MarketClient is set)
+// on the plan, and creates it if no errors were found. It returns the plan with the warnings
+// found; a plan with errors is not created and fails with a *PlanValidationError. With
+// spec.Options.DryRun set the plan is validated but not saved.
+func (m *Manager) CreateValidatedPlan(ctx context.Context, spec PlanSpec, checks CreateChecks) (*TradingPlan, []Finding, error) {
+	if spec.RecipientAddr == "" {
+		spec.RecipientAddr = checks.DefaultRecipient
+	}
+	if spec.RefundAddr == "" {
+		spec.RefundAddr = checks.DefaultRefundTo
+	}
+	if spec.RecipientAddr == "" {
+		return nil, nil, fmt.Errorf("recipient address is required: pass one or set default_recipient in your config")
+	}
+
+	var fs findings
+	var roleErr *deposit.AddressRoleError
+	if err := deposit.CheckAddressRoles(spec.SourceChain, spec.DestChain, spec.RecipientAddr, spec.RefundAddr); errors.As(err, &roleErr) {
+		if roleErr.Swapped && !checks.AllowSwappedAddresses {
+			return nil, nil, roleErr
+		}
+		fs.warn("", "%v", roleErr)
+	}
+
+	if spec.RefundAddr == "" {
+		spec.RefundAddr = spec.RecipientAddr
+	}
+
+	create := func(dryRun bool) (*TradingPlan, error) {
+		opts := spec.Options
+		opts.DryRun = dryRun
+		return m.CreatePlan(
+			spec.Name,
+			spec.SourceToken, spec.DestToken,
+			spec.SourceChain, spec.DestChain,
+			spec.TotalAmount, spec.AmountPerTrade, spec.AmountPerDay,
+			spec.TriggerPrice, spec.PriceCondition,
+			spec.RecipientAddr, spec.RefundAddr,
+			spec.Description,
+			opts,
+		)
+	}
+
+	// Check the plan before saving it
+	draft, err := create(true)
+	if err != nil {
+		return nil, nil, err
+	}
+	fs = append(fs, ValidatePlanWith(ctx, draft, checks.MarketClient)...)
+	if HasErrors(fs) {
+		return nil, fs, &PlanValidationError{Findings: fs}
+	}
+	if spec.Options.DryRun {
+		return draft, fs, nil
+	}
+
+	created, err := create(false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return created, fs, nil
+}
+
+// ValidatePlanWith runs ValidatePlan and, when marketClient is set, the market checks with the
+// client it returns for the plan's own API token
+func ValidatePlanWith(ctx context.Context, tp *TradingPlan, marketClient func(apiTokenEnv string) *client.OneClickClient) []Finding {
+	if marketClient == nil {
+		return ValidatePlan(tp)
+	}
+	apiClient := marketClient(tp.APITokenEnv)
+	if apiClient == nil {
+		return append(ValidatePlan(tp), Finding{Severity: SeverityWarning, Message: "market checks skipped: no JWT token configured"})
+	}
+	return ValidatePlanAgainstMarket(ctx, tp, apiClient)
+}
@@ -0,0 +1,78 @@
+package plan
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"near-swap/pkg/client"
+)
+
+// envVarPattern matches a valid environment variable name
+var envVarPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateAPITokenEnv checks that a plan's API token variable is a usable environment variable name
+func validateAPITokenEnv(name string) error {
+	if name != "" && !envVarPattern.MatchString(name) {
+		return fmt.Errorf("invalid API token environment variable name '%s'", name)
+	}
+	return nil
+}
+
+// HasOwnAPIToken returns true if the plan trades with its own 1Click JWT instead of the global one
+func (tp *TradingPlan) HasOwnAPIToken() bool {
+	return tp.APITokenEnv != ""
+}
+
+// planClient is an API client, and a pricer using it, built for one plan-specific token
+type planClient struct {
+	token  string
+	client *client.OneClickClient
+	pricer *Pricer
+}
+
+// SetClientFactory sets how the executor builds API clients for plans with their own token,
+// so they get the same retry and quote settings as the shared client
+func (e *Executor) SetClientFactory(factory func(jwtToken string) *client.OneClickClient) {
+	e.clientsMu.Lock()
+	defer e.clientsMu.Unlock()
+
+	e.clientFactory = factory
+	e.planClients = make(map[string]*planClient)
+}
+
+// clientFor returns the API client and pricer a plan trades with: the executor's shared ones,
+// or ones built for the token in the plan's APITokenEnv. The token is read from the
+// environment on every call, so it is never stored and a rotated token gets a fresh client.
+// Plans naming the same variable share a client.
+func (e *Executor) clientFor(plan *TradingPlan) (*client.OneClickClient, *Pricer, error) {
+	if !plan.HasOwnAPIToken() {
+		return e.apiClient, e.pricer, nil
+	}
+
+	token := os.Getenv(plan.APITokenEnv)
+	if token == "" {
+		return nil, nil, fmt.Errorf("API token environment variable %s is not set", plan.APITokenEnv)
+	}
+
+	e.clientsMu.Lock()
+	defer e.clientsMu.Unlock()
+
+	if pc, ok := e.planClients[plan.APITokenEnv]; ok && pc.token == token {
+		return pc.client, pc.pricer, nil
+	}
+
+	var apiClient *client.OneClickClient
+	if e.clientFactory != nil {
+		apiClient = e.clientFactory(token)
+	} else {
+		apiClient = client.NewOneClickClient(token, client.WithRetry(e.config.MaxRetries), client.WithTokenCacheTTL(e.config.TokenCacheTTL),
+			client.WithRateLimit(e.apiClient.RateLimiter()))
+	}
+	pricer := NewPricer(apiClient, e.config.PriceSources...)
+	pricer.log = e.pricer.log
+	pc := &planClient{token: token, client: apiClient, pricer: pricer}
+	e.planClients[plan.APITokenEnv] = pc
+
+	return pc.client, pc.pricer, nil
+}
@@ -0,0 +1,120 @@
+package plan
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"near-swap/pkg/client"
+	"near-swap/pkg/mockserver"
+)
+
+func TestValidateAPITokenEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: ""},
+		{name: "ONECLICK_JWT"},
+		{name: "_plan_token2"},
+		{name: "2FAST", wantErr: true},
+		{name: "MY-TOKEN", wantErr: true},
+		{name: "$HOME", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := validateAPITokenEnv(tt.name); (err != nil) != tt.wantErr {
+			t.Errorf("validateAPITokenEnv(%q) = %v, want error: %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestClientFor(t *testing.T) {
+	e, server := newMockExecutor(t)
+	var built []string
+	e.SetClientFactory(func(jwtToken string) *client.OneClickClient {
+		built = append(built, jwtToken)
+		return server.Client(jwtToken)
+	})
+
+	// Plans without their own token fall back to the global client
+	shared, pricer, err := e.clientFor(&TradingPlan{Name: "p"})
+	if err != nil || shared != e.apiClient || pricer != e.pricer {
+		t.Fatalf("clientFor without a token variable = %p, %v; want the shared client", shared, err)
+	}
+
+	own := &TradingPlan{Name: "own", APITokenEnv: "NEAR_SWAP_TEST_PLAN_TOKEN"}
+	t.Setenv(own.APITokenEnv, "")
+	if _, _, err := e.clientFor(own); err == nil || !strings.Contains(err.Error(), own.APITokenEnv) {
+		t.Errorf("unset token variable: error = %v, want it to name the variable", err)
+	}
+
+	t.Setenv(own.APITokenEnv, "plan-token")
+	first, _, err := e.clientFor(own)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == e.apiClient {
+		t.Error("plan with its own token got the shared client")
+	}
+	if again, _, _ := e.clientFor(own); again != first {
+		t.Error("same token built a second client")
+	}
+
+	// A rotated token gets a fresh client
+	t.Setenv(own.APITokenEnv, "rotated-token")
+	if rotated, _, _ := e.clientFor(own); rotated == first {
+		t.Error("rotated token kept the old client")
+	}
+	if len(built) != 2 || built[0] != "plan-token" || built[1] != "rotated-token" {
+		t.Errorf("clients built for %q, want the plan token then the rotated one", built)
+	}
+}
+
+func TestExecutorTradesWithPlanToken(t *testing.T) {
+	e, shared := newMockExecutor(t)
+
+	// The plan's account is served by its own mock API, so its requests are easy to tell apart
+	own := mockserver.New()
+	t.Cleanup(own.Close)
+	btc := own.AddToken("BTC", "btc", 8, 60000)
+	usdc := own.AddToken("USDC", "near", 6, 1)
+	own.SetRate(btc, usdc, 60000)
+	var tokens []string
+	e.SetClientFactory(func(jwtToken string) *client.OneClickClient {
+		tokens = append(tokens, jwtToken)
+		return own.Client(jwtToken)
+	})
+
+	p, _ := e.manager.storage.Get("p")
+	p.APITokenEnv = "NEAR_SWAP_TEST_PLAN_TOKEN"
+	if err := e.manager.storage.Update(p); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(p.APITokenEnv, "secret-plan-token")
+
+	sharedQuotes := shared.RequestCount("/v0/quote")
+	e.checkAndExecutePlan("p", nil)
+
+	if _, exec := lastExecution(t, e); exec.DepositAddress == "" {
+		t.Fatal("plan didn't trade")
+	}
+	if len(tokens) != 1 || tokens[0] != "secret-plan-token" {
+		t.Errorf("clients built for %q, want one for the plan's token", tokens)
+	}
+	if n := own.RequestCount("/v0/quote"); n == 0 {
+		t.Error("no quotes went through the plan's own client")
+	}
+	if n := shared.RequestCount("/v0/quote") - sharedQuotes; n != 0 {
+		t.Errorf("%d quotes went through the shared client, want none", n)
+	}
+
+	// Only the variable name is stored, never the token
+	data, err := os.ReadFile(e.manager.storage.(*JSONStorage).GetFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-plan-token") || !strings.Contains(string(data), p.APITokenEnv) {
+		t.Error("plan file should name the token variable without holding the token")
+	}
+}
@@ -0,0 +1,38 @@
+package plan
+
+import (
+	"fmt"
+	"time"
+
+	"near-swap/pkg/client"
+)
+
+// parseQuoteDeadline parses a plan's quote deadline; an empty deadline is 0 and uses the
+// configured default_deadline
+func parseQuoteDeadline(deadline string) (time.Duration, error) {
+	if deadline == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(deadline)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quote deadline %q: %w", deadline, err)
+	}
+	if err := client.ValidateQuoteDeadline(d); err != nil {
+		return 0, err
+	}
+	return d, nil
+}
+
+// formatQuoteDeadline stores a quote deadline on a plan; 0 is stored empty
+func formatQuoteDeadline(deadline time.Duration) string {
+	if deadline == 0 {
+		return ""
+	}
+	return deadline.String()
+}
+
+// quoteDeadline returns how long the plan's quotes accept deposits, or 0 for the client default
+func (tp *TradingPlan) quoteDeadline() time.Duration {
+	d, _ := parseQuoteDeadline(tp.QuoteDeadline)
+	return d
+}
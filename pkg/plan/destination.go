@@ -0,0 +1,31 @@
+package plan
+
+// destinationPauseReason is the pause reason recorded for plans paused because their swaps
+// keep failing after the deposit went through
+const destinationPauseReason = "consecutive swaps failed or were refunded after deposit"
+
+// haltOnDestinationFailures pauses a plan once max_destination_failures of its swaps in a row
+// have failed or been refunded after the deposit, so it stops trading into a broken route.
+// Failed deposits don't count; those never reach the swap.
+func (e *Executor) haltOnDestinationFailures(planName string) {
+	limit := e.config.MaxDestinationFailures
+	if limit <= 0 {
+		return
+	}
+
+	plan, err := e.manager.GetPlan(planName)
+	if err != nil || plan.Status != StatusActive || plan.DestinationFailures < limit {
+		return
+	}
+
+	if err := e.manager.PausePlan(planName, destinationPauseReason); err != nil {
+		e.verifyLog.Error("Error pausing plan", "plan", planName, "error", err)
+		return
+	}
+	e.StopPlan(planName)
+
+	e.verifyLog.Warn("Paused plan",
+		"plan", planName, "event", "plan_paused", "reason", destinationPauseReason, "failures", plan.DestinationFailures,
+		"source_token", plan.SourceToken, "dest_token", plan.DestToken, "dest_chain", plan.DestChain)
+	e.verifyLog.Info("Check the route, then resume with: near-swap plan start", "plan", planName)
+}
@@ -0,0 +1,117 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldChange is a trading parameter that differs between two plans
+type FieldChange struct {
+	Field string `json:"field"` // JSON name of the plan field
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// diffIgnoredFields are identity and runtime fields that DiffPlans never compares
+var diffIgnoredFields = map[string]bool{
+	"name": true, "created": true, "last_updated": true,
+	"status": true, "pause_reason": true, "cancel_reason": true, "completion_reason": true,
+	"destination_failures": true, "peak_price": true, "price_samples": true,
+	"total_executed": true, "remaining_amount": true, "execution_history": true, "execution_count": true,
+	"last_execution_date": true, "today_executed": true,
+}
+
+// diffDecimalFields are compared by value, so "100" and "100.00" are the same trigger
+var diffDecimalFields = map[string]bool{
+	"total_amount": true, "amount_per_trade": true, "amount_per_trade_dest": true, "amount_per_day": true,
+	"trigger_price": true, "cancel_below": true, "cancel_above": true, "dust_threshold": true,
+}
+
+// DiffPlans returns the trading parameters that differ between a and b, in field order.
+// Identity (name, timestamps) and runtime state (status, progress, executions, ladder fills)
+// are ignored.
+func DiffPlans(a, b *TradingPlan) []FieldChange {
+	changes := make([]FieldChange, 0)
+
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	t := va.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if field == "" || field == "-" || diffIgnoredFields[field] {
+			continue
+		}
+
+		from, to := formatDiffValue(va.Field(i)), formatDiffValue(vb.Field(i))
+		if from == to || (diffDecimalFields[field] && sameDecimal(from, to)) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: field, From: from, To: to})
+	}
+
+	return changes
+}
+
+// formatDiffValue renders a plan field for comparison and display. Ladders are rendered as
+// their levels only, leaving out fill progress.
+func formatDiffValue(v reflect.Value) string {
+	if levels, ok := v.Interface().([]LadderLevel); ok {
+		parts := make([]string, len(levels))
+		for i, level := range levels {
+			parts[i] = fmt.Sprintf("%s:%s%%", level.Price, strconv.FormatFloat(level.Fraction*100, 'f', -1, 64))
+		}
+		return strings.Join(parts, ",")
+	}
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if v.Float() == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Int, reflect.Int64:
+		if v.Int() == 0 {
+			return ""
+		}
+		return strconv.FormatInt(v.Int(), 10)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// sameDecimal reports whether two amounts are equal by value
+func sameDecimal(a, b string) bool {
+	ra, err := parseDecimal(a)
+	if err != nil {
+		return false
+	}
+	rb, err := parseDecimal(b)
+	if err != nil {
+		return false
+	}
+	return ra.Cmp(rb) == 0
+}
+
+// LoadPlanDefinition reads a plan from a JSON file in the format printed by
+// `plan view --json`. Unknown fields are rejected so a misspelt parameter isn't silently
+// dropped.
+func LoadPlanDefinition(path string) (*TradingPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan definition: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var plan TradingPlan
+	if err := decoder.Decode(&plan); err != nil {
+		return nil, fmt.Errorf("invalid plan definition %s: %w", path, err)
+	}
+
+	return &plan, nil
+}
@@ -0,0 +1,80 @@
+package plan
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// diffBasePlan is a laddered plan part way through trading
+func diffBasePlan() *TradingPlan {
+	return &TradingPlan{
+		Name: "a", SourceToken: "BTC", DestToken: "USDC", SourceChain: "btc", DestChain: "near",
+		TotalAmount: "1", AmountPerDay: "0.5", TriggerPrice: "100000", PriceCondition: PriceAbove,
+		Ladder:        []LadderLevel{{Price: "100000", Fraction: 0.5}, {Price: "110000", Fraction: 0.5}},
+		RecipientAddr: "me.near", RefundAddr: testRefundAddr, SlippageBps: 100,
+		Status: StatusActive, TotalExecuted: "0.25", RemainingAmount: "0.75",
+	}
+}
+
+func TestDiffPlans(t *testing.T) {
+	a := diffBasePlan()
+
+	b := diffBasePlan()
+	b.Name = "b"
+	b.TriggerPrice = "100000.00" // Same value
+	b.AmountPerDay = "0.25"
+	b.SlippageBps = 0
+	b.Ladder[1].Price = "120000"
+	// Runtime state differs but isn't a parameter
+	b.Status = StatusPaused
+	b.TotalExecuted, b.RemainingAmount = "0", "1"
+	b.Ladder[0].Executed, b.Ladder[0].Filled = "0.5", true
+	b.ExecutionHistory = []Execution{{ID: "e1", Amount: "0.25", Timestamp: time.Now()}}
+	b.LastUpdated = time.Now()
+
+	want := []FieldChange{
+		{Field: "amount_per_day", From: "0.5", To: "0.25"},
+		{Field: "ladder", From: "100000:50%,110000:50%", To: "100000:50%,120000:50%"},
+		{Field: "slippage_bps", From: "100", To: ""},
+	}
+	if got := DiffPlans(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffPlans = %+v, want %+v", got, want)
+	}
+	if got := DiffPlans(a, diffBasePlan()); len(got) != 0 {
+		t.Errorf("identical plans differ: %+v", got)
+	}
+}
+
+func TestLoadPlanDefinition(t *testing.T) {
+	dir := t.TempDir()
+	data, err := json.Marshal(diffBasePlan())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "plan.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadPlanDefinition(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := DiffPlans(diffBasePlan(), loaded); len(changes) != 0 {
+		t.Errorf("round-tripped definition differs: %+v", changes)
+	}
+
+	// A misspelt parameter is an error rather than silently ignored
+	misspelt := filepath.Join(dir, "misspelt.json")
+	if err := os.WriteFile(misspelt, []byte(`{"name":"a","triger_price":"90000"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPlanDefinition(misspelt); err == nil || !strings.Contains(err.Error(), "triger_price") {
+		t.Errorf("error = %v, want the unknown field named", err)
+	}
+}
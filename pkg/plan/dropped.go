@@ -0,0 +1,106 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"near-swap/pkg/deposit"
+)
+
+// droppedDepositPauseReason is recorded on a completed plan reopened by a dropped deposit
+const droppedDepositPauseReason = "a deposit was dropped after the plan completed"
+
+// MarkDepositDropped fails a deposited execution whose deposit never landed on chain and gives
+// its amount back to the plan's daily and total limits, so the plan can trade it again. A plan
+// the execution had completed is reopened paused for review.
+func (m *Manager) MarkDepositDropped(planName, executionID, reason string) error {
+	defer m.lockPlan(planName)()
+
+	plan, err := m.storage.Get(planName)
+	if err != nil {
+		return err
+	}
+
+	exec := plan.findExecution(executionID)
+	if exec == nil {
+		return fmt.Errorf("execution '%s' not found in plan '%s'", executionID, planName)
+	}
+	if exec.Status != ExecutionDeposited {
+		return fmt.Errorf("execution '%s' is %s, not deposited", executionID, exec.Status)
+	}
+
+	if err := plan.releaseCredit(exec, droppedDepositPauseReason); err != nil {
+		return err
+	}
+	exec.Status = ExecutionFailed
+	exec.ErrorMessage = reason
+
+	plan.LastUpdated = time.Now()
+
+	return m.storage.Update(plan)
+}
+
+// RecordDepositBlockhash stores the recent blockhash an execution's Solana deposit was signed
+// with, which tells when a deposit the cluster doesn't know can no longer land
+func (m *Manager) RecordDepositBlockhash(planName, executionID, blockhash string) error {
+	defer m.lockPlan(planName)()
+
+	plan, err := m.storage.Get(planName)
+	if err != nil {
+		return err
+	}
+
+	exec := plan.findExecution(executionID)
+	if exec == nil {
+		return fmt.Errorf("execution '%s' not found in plan '%s'", executionID, planName)
+	}
+	exec.DepositBlockhash = blockhash
+
+	plan.LastUpdated = time.Now()
+
+	return m.storage.Update(plan)
+}
+
+// checkDroppedDeposit looks up the deposit of a Solana execution the 1Click API hasn't seen
+// yet. Once solana.dropped_after has passed and the cluster has no record of the signature and
+// its blockhash has expired (or it failed on chain), the execution is marked dropped. It
+// reports whether it was.
+func (e *Executor) checkDroppedDeposit(plan *TradingPlan, executionID string) bool {
+	window := e.config.AutoDeposit.Solana.DroppedAfter
+	if window <= 0 || deposit.CanonicalChain(plan.SourceChain) != "solana" {
+		return false
+	}
+
+	exec := plan.findExecution(executionID)
+	if exec == nil || exec.Status != ExecutionDeposited || exec.TxHash == "" || time.Since(exec.Timestamp) < window {
+		return false
+	}
+
+	depositMgr := deposit.NewManager(e.config.AutoDeposit)
+	if !depositMgr.IsEnabledForChain(plan.SourceChain) {
+		return false
+	}
+
+	err := depositMgr.CheckDepositLanded(plan.SourceChain, exec.TxHash, exec.DepositBlockhash)
+	var dropped *deposit.DroppedDepositError
+	if !errors.As(err, &dropped) {
+		if err != nil {
+			e.verifyLog.Warn("Could not check deposit",
+				"plan", plan.Name, "execution_id", executionID, "tx_hash", exec.TxHash, "error", err)
+		}
+		return false
+	}
+
+	if err := e.manager.MarkDepositDropped(plan.Name, executionID, dropped.Error()); err != nil {
+		e.verifyLog.Error("Error marking deposit dropped",
+			"plan", plan.Name, "execution_id", executionID, "error", err)
+		return false
+	}
+
+	e.verifyLog.Error("Deposit dropped, its amount can be traded again",
+		"plan", plan.Name, "execution_id", executionID, "event", EventDepositFailed, "tx_hash", exec.TxHash,
+		"reason", dropped.Error(), "amount", trimDecimal(exec.Amount), "token", plan.SourceToken)
+	e.notifyExecution(EventDepositFailed, plan.Name, executionID)
+	return true
+}
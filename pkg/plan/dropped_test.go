@@ -0,0 +1,70 @@
+package plan
+
+import "testing"
+
+func TestMarkDepositDropped(t *testing.T) {
+	tests := []struct {
+		name          string
+		total         string
+		status        ExecutionStatus // Status the execution reached before the drop was found
+		wantErr       bool
+		wantPlan      PlanStatus
+		wantRemaining string
+	}{
+		{name: "deposit gives its amount back", total: "1", status: ExecutionDeposited, wantPlan: StatusActive, wantRemaining: "1.00000000"},
+		{name: "plan it completed reopens paused", total: "0.1", status: ExecutionDeposited, wantPlan: StatusPaused, wantRemaining: "0.10000000"},
+		{name: "pending execution", total: "1", status: ExecutionPending, wantErr: true},
+		{name: "completed swap", total: "1", status: ExecutionCompleted, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t, tt.total, "0.1", "0.1")
+			id, err := manager.ReserveExecution("p", Execution{Amount: "0.1", DepositAddress: "deposit-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.status != ExecutionPending {
+				if err := manager.UpdateExecutionStatus("p", id, tt.status, "sig", ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+			before, _ := manager.GetPlan("p")
+
+			err = manager.MarkDepositDropped("p", id, "was dropped")
+			p, _ := manager.GetPlan("p")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if p.RemainingAmount != before.RemainingAmount || p.Status != before.Status {
+					t.Errorf("failed drop changed the plan")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exec := p.findExecution(id)
+			if exec.Status != ExecutionFailed || exec.ErrorMessage != "was dropped" {
+				t.Errorf("execution = %s (%q), want failed with the reason", exec.Status, exec.ErrorMessage)
+			}
+			if p.Status != tt.wantPlan || p.RemainingAmount != tt.wantRemaining || p.TodayExecuted != "0.00000000" {
+				t.Errorf("plan %s, remaining %s, today %s; want %s, %s, 0", p.Status, p.RemainingAmount, p.TodayExecuted, tt.wantPlan, tt.wantRemaining)
+			}
+			if tt.wantPlan == StatusPaused && p.PauseReason != droppedDepositPauseReason {
+				t.Errorf("pause reason = %q, want %q", p.PauseReason, droppedDepositPauseReason)
+			}
+
+			if err := manager.MarkDepositDropped("p", id, "was dropped"); err == nil {
+				t.Error("dropping the same deposit twice should fail")
+			}
+		})
+	}
+
+	manager := newTestManager(t, "1", "0.1", "0.2")
+	if err := manager.MarkDepositDropped("p", "missing", "was dropped"); err == nil {
+		t.Error("dropping an unknown execution should fail")
+	}
+}
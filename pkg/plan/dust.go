@@ -0,0 +1,49 @@
+package plan
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// dustReason returns why a plan's remaining amount is too small to trade at price, or an
+// empty string if it is worth at least the plan's dust threshold (or the plan has none).
+// Trading a dust remainder would spend more on fees than it is worth.
+func (tp *TradingPlan) dustReason(price float64) string {
+	if tp.DustThreshold == "" || price <= 0 {
+		return ""
+	}
+	threshold, err := strconv.ParseFloat(tp.DustThreshold, 64)
+	if err != nil || threshold <= 0 {
+		return ""
+	}
+	remaining, err := strconv.ParseFloat(tp.RemainingAmount, 64)
+	if err != nil || remaining <= 0 {
+		return ""
+	}
+
+	value := remaining * price
+	if value >= threshold {
+		return ""
+	}
+	return fmt.Sprintf("dust remaining: %s %s is worth %s %s, below the %s %s threshold",
+		trimDecimal(tp.RemainingAmount), tp.SourceToken, trimDecimal(fmt.Sprintf("%.8f", value)),
+		tp.DestToken, tp.DustThreshold, tp.DestToken)
+}
+
+// completeDustPlan completes a plan whose remainder isn't worth trading and stops monitoring it
+func (e *Executor) completeDustPlan(planName, reason string) {
+	if err := e.manager.CompletePlanWithReason(planName, reason); err != nil {
+		e.log.Error("Error completing plan", "plan", planName, "error", err)
+		return
+	}
+
+	e.log.Info("Plan completed",
+		"plan", planName, "event", "plan_completed", "reason", reason)
+
+	e.mu.Lock()
+	if pe, exists := e.activePlans[planName]; exists {
+		close(pe.stopChan)
+		delete(e.activePlans, planName)
+	}
+	e.mu.Unlock()
+}
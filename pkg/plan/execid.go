@@ -0,0 +1,38 @@
+package plan
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// executionIDNamespace scopes the name-based UUIDs derived by executionID
+var executionIDNamespace = uuid.NewSHA1(uuid.NameSpaceOID, []byte("near-swap/execution"))
+
+// executionID returns the ID for a new execution of a plan. Executions with a deposit address
+// get an ID derived from the plan name, deposit address, amount and UTC day, so recording the
+// same execution again (e.g. a retry after a crash) yields the same ID instead of a duplicate.
+// Executions without a deposit address, such as observed ones, get a random ID.
+func executionID(planName string, exec Execution, now time.Time) string {
+	if exec.DepositAddress == "" {
+		return uuid.New().String()
+	}
+
+	amount := exec.Amount
+	if value, err := parseDecimal(amount); err == nil {
+		amount = formatDecimal(value)
+	}
+	key := strings.Join([]string{planName, exec.DepositAddress, amount, now.UTC().Format("2006-01-02")}, "|")
+	return uuid.NewSHA1(executionIDNamespace, []byte(key)).String()
+}
+
+// findExecution returns the plan's execution with id, or nil
+func (tp *TradingPlan) findExecution(id string) *Execution {
+	for i := range tp.ExecutionHistory {
+		if tp.ExecutionHistory[i].ID == id {
+			return &tp.ExecutionHistory[i]
+		}
+	}
+	return nil
+}
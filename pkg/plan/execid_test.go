@@ -0,0 +1,69 @@
+package plan
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExecutionID(t *testing.T) {
+	noon := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	base := Execution{Amount: "0.1", DepositAddress: "deposit-1"}
+	id := executionID("p", base, noon)
+
+	tests := []struct {
+		name     string
+		plan     string
+		exec     Execution
+		at       time.Time
+		wantSame bool
+	}{
+		{name: "same execution later that day", plan: "p", exec: base, at: noon.Add(11 * time.Hour), wantSame: true},
+		{name: "same amount written differently", plan: "p", exec: Execution{Amount: "0.10000000", DepositAddress: "deposit-1"}, at: noon, wantSame: true},
+		{name: "same UTC day in another zone", plan: "p", exec: base, at: noon.In(time.FixedZone("UTC+13", 13*3600)), wantSame: true},
+		{name: "other fields don't matter", plan: "p", exec: Execution{Amount: "0.1", DepositAddress: "deposit-1", Status: ExecutionFailed, TxHash: "0xabc"}, at: noon, wantSame: true},
+		{name: "another plan", plan: "q", exec: base, at: noon},
+		{name: "another deposit address", plan: "p", exec: Execution{Amount: "0.1", DepositAddress: "deposit-2"}, at: noon},
+		{name: "another amount", plan: "p", exec: Execution{Amount: "0.2", DepositAddress: "deposit-1"}, at: noon},
+		{name: "next day", plan: "p", exec: base, at: noon.Add(12 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := executionID(tt.plan, tt.exec, tt.at)
+			if (got == id) != tt.wantSame {
+				t.Errorf("executionID = %s, base %s; want same: %v", got, id, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestExecutionIDWithoutDepositAddressIsRandom(t *testing.T) {
+	now := time.Now()
+	observed := Execution{Amount: "0.1"}
+	if a, b := executionID("p", observed, now), executionID("p", observed, now); a == b {
+		t.Errorf("executions without a deposit address share ID %s", a)
+	}
+}
+
+func TestRecordingAnExecutionTwiceKeepsOne(t *testing.T) {
+	manager := newTestManager(t, "1", "0.1", "0.2")
+	exec := Execution{Amount: "0.1", DepositAddress: "deposit-1", Status: ExecutionDeposited}
+
+	first, err := manager.AddExecution("p", exec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := manager.AddExecution("p", exec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Errorf("retried recording got ID %s, want %s", second, first)
+	}
+
+	p, _ := manager.GetPlan("p")
+	if len(p.ExecutionHistory) != 1 || p.ExecutionCount != 1 || p.TotalExecuted != "0.10000000" {
+		t.Errorf("executions %d (count %d), executed %s; want one execution of 0.1",
+			len(p.ExecutionHistory), p.ExecutionCount, p.TotalExecuted)
+	}
+}
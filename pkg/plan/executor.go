@@ -1,15 +1,22 @@
 package plan
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	oneclick "github.com/defuse-protocol/one-click-sdk-go"
 	"near-swap/config"
 	"near-swap/pkg/client"
 	"near-swap/pkg/deposit"
+	"near-swap/pkg/logging"
+	"near-swap/pkg/parser"
 	"near-swap/pkg/types"
 )
 
@@ -18,6 +25,12 @@ const (
 	MinCheckInterval         = 10 * time.Second // Minimum interval to avoid rate limiting
 	PlanReloadInterval       = 60 * time.Second // Check for plan changes every 60 seconds
 	SwapVerificationInterval = 45 * time.Second // Check swap status every 45 seconds
+	SwapPollInterval         = 30 * time.Second // Poll a just-deposited swap every 30 seconds
+	MinVerificationWindow    = time.Hour        // Keep polling a just-deposited swap for at least an hour
+	MaxInitialPollDelay      = 30 * time.Minute // Longest wait before a swap's first poll, however slow its route
+	WarmupStagger            = 2 * time.Second  // Delay between warmup batches on startup
+	WarmupJitter             = time.Second      // Maximum random jitter added to each warmup check
+	DefaultWarmupConcurrency = 1                // Plans primed per warmup batch when not configured
 )
 
 // Executor manages the execution of trading plans
@@ -31,26 +44,63 @@ type Executor struct {
 	stopChan       chan struct{}
 	mu             sync.RWMutex
 	activePlans    map[string]*planExecutor
+	activity       *activityTracker
+	metrics        executorMetrics
+	authFailures   atomic.Int32 // Consecutive API authorization failures across all plans
+	heldPlans      sync.Map     // Plans currently holding trades for unverified executions
+	verifyingPlans sync.Map     // Plans holding their first full trade for an unsettled verification swap
+	withdrawing    sync.Map     // Executions whose output is being forwarded to a cold address
+
+	clientsMu     sync.Mutex
+	clientFactory func(jwtToken string) *client.OneClickClient // Builds clients for plans with their own token
+	planClients   map[string]*planClient                       // Clients for plan-specific tokens, by env variable
+
+	notifier *notifier // Posts execution events to the configured webhook (nil when disabled)
+
+	balanceOf  func(chain string) (float64, error) // Reads a chain's funding wallet balance (nil asks the chain's depositor)
+	withdrawer withdrawSender                      // Sends auto-withdrawals (nil uses the auto_deposit wallets)
+
+	startVerifier func(planName, executionID, depositAddress string, estimate time.Duration) // Starts a swap's verifier (nil runs verifySwapCompletion in the background)
+
+	log        *slog.Logger // Trading: price checks, triggers and deposits
+	verifyLog  *slog.Logger // Swap verification, refunds and withdrawals
+	observeLog *slog.Logger // Trades observer mode would have made
 }
 
 // planExecutor manages execution for a single plan
 type planExecutor struct {
-	plan      *TradingPlan
-	stopChan  chan struct{}
-	running   bool
+	plan         *TradingPlan
+	stopChan     chan struct{}
+	running      bool
+	initialDelay time.Duration // Delay before the first (warmup) price check
 }
 
-// NewExecutor creates a new executor instance
-func NewExecutor(manager *Manager, apiClient *client.OneClickClient, cfg *config.Config) *Executor {
+// NewExecutor creates a new executor instance. It fails if the log_format or log_level setting
+// is invalid.
+func NewExecutor(manager *Manager, apiClient *client.OneClickClient, cfg *config.Config) (*Executor, error) {
+	logger, err := logging.New(os.Stdout, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	pricer := NewPricer(apiClient, cfg.PriceSources...)
+	pricer.log = logging.Component(logger, "pricer")
+
 	return &Executor{
 		manager:       manager,
-		pricer:        NewPricer(apiClient),
+		pricer:        pricer,
 		apiClient:     apiClient,
 		config:        cfg,
 		checkInterval: DefaultCheckInterval,
 		stopChan:      make(chan struct{}),
 		activePlans:   make(map[string]*planExecutor),
-	}
+		activity:      newActivityTracker(),
+		planClients:   make(map[string]*planClient),
+		notifier:      newNotifier(cfg.Notifications, logging.Component(logger, "notifier")),
+		log:           logging.Component(logger, "executor"),
+		verifyLog:     logging.Component(logger, "verifier"),
+		observeLog:    logging.Component(logger, "observer"),
+	}, nil
 }
 
 // SetCheckInterval sets the price check interval
@@ -72,18 +122,38 @@ func (e *Executor) Start() error {
 
 	e.running = true
 
-	// Load and start all active plans
+	// Give plans paused for a rejected token another chance with the current one
+	e.resumeAuthPausedPlans()
+
+	// Load and start all active plans, staggering their first price checks
 	activePlans := e.manager.GetActivePlans()
-	for _, plan := range activePlans {
-		e.startPlanExecutor(plan)
+	for i, plan := range activePlans {
+		e.startPlanExecutor(plan, e.warmupDelay(i))
 	}
 
+	// Pick up swaps that were still in flight when the daemon last stopped
+	e.resumeInFlightSwaps()
+
 	// Start plan reload monitor in background
 	go e.monitorPlanChanges()
 
 	// Start swap verification monitor in background
 	go e.monitorSwapVerification()
 
+	// Pause plans whose funding wallet runs low when thresholds are configured
+	if len(e.config.AutoDeposit.LowBalance) > 0 {
+		go e.monitorBalances()
+	}
+
+	// Periodically dump aggregate stats for dashboards when configured
+	if e.config.StatsSnapshotPath != "" {
+		interval := time.Duration(e.config.StatsSnapshotInterval) * time.Second
+		if interval < MinSnapshotInterval {
+			interval = MinSnapshotInterval
+		}
+		go e.monitorSnapshots(e.config.StatsSnapshotPath, interval)
+	}
+
 	return nil
 }
 
@@ -127,7 +197,7 @@ func (e *Executor) StartPlan(planName string) error {
 		return fmt.Errorf("plan '%s' is not active", planName)
 	}
 
-	e.startPlanExecutor(plan)
+	e.startPlanExecutor(plan, e.warmupDelay(0))
 	return nil
 }
 
@@ -147,12 +217,28 @@ func (e *Executor) StopPlan(planName string) error {
 	return nil
 }
 
+// warmupDelay returns the delay before the first price check of the index-th plan being started.
+// Plans are primed in batches of the configured warmup concurrency, each batch WarmupStagger apart,
+// with random jitter so startup doesn't burst the API.
+func (e *Executor) warmupDelay(index int) time.Duration {
+	concurrency := e.config.WarmupConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultWarmupConcurrency
+	}
+
+	batch := index / concurrency
+	jitter := time.Duration(rand.Int63n(int64(WarmupJitter)))
+
+	return time.Duration(batch)*WarmupStagger + jitter
+}
+
 // startPlanExecutor starts a goroutine to monitor and execute a plan (must be called with lock held)
-func (e *Executor) startPlanExecutor(plan *TradingPlan) {
+func (e *Executor) startPlanExecutor(plan *TradingPlan, initialDelay time.Duration) {
 	pe := &planExecutor{
-		plan:     plan,
-		stopChan: make(chan struct{}),
-		running:  true,
+		plan:         plan,
+		stopChan:     make(chan struct{}),
+		running:      true,
+		initialDelay: initialDelay,
 	}
 
 	e.activePlans[plan.Name] = pe
@@ -163,28 +249,54 @@ func (e *Executor) startPlanExecutor(plan *TradingPlan) {
 
 // monitorPlan continuously monitors a plan and executes trades when conditions are met
 func (e *Executor) monitorPlan(pe *planExecutor) {
+	e.log.Info("Started monitoring plan", "plan", pe.plan.Name)
+
+	// Warmup: prime the plan's price after its staggered delay
+	warmup := time.NewTimer(pe.initialDelay)
+	select {
+	case <-pe.stopChan:
+		warmup.Stop()
+		e.log.Info("Stopped monitoring plan", "plan", pe.plan.Name)
+		return
+	case <-warmup.C:
+		e.checkAndExecutePlan(pe.plan.Name, pe.stopChan)
+	}
+
 	ticker := time.NewTicker(e.checkInterval)
 	defer ticker.Stop()
 
-	fmt.Printf("[Executor] Started monitoring plan: %s\n", pe.plan.Name)
-
 	for {
 		select {
 		case <-pe.stopChan:
-			fmt.Printf("[Executor] Stopped monitoring plan: %s\n", pe.plan.Name)
+			e.log.Info("Stopped monitoring plan", "plan", pe.plan.Name)
 			return
 		case <-ticker.C:
-			e.checkAndExecutePlan(pe.plan.Name)
+			e.checkAndExecutePlan(pe.plan.Name, pe.stopChan)
 		}
 	}
 }
 
-// checkAndExecutePlan checks if a plan should execute and performs the trade
-func (e *Executor) checkAndExecutePlan(planName string) {
+// errPlanStopped is returned when a plan is stopped or deleted while a trade is in progress
+var errPlanStopped = errors.New("plan was stopped")
+
+// stopRequested reports whether a plan's monitor has been told to stop
+func stopRequested(stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkAndExecutePlan checks if a plan should execute and performs the trade.
+// The stop channel is checked before each step that talks to the API or moves funds,
+// so a plan stopped or deleted mid-cycle aborts before depositing.
+func (e *Executor) checkAndExecutePlan(planName string, stop <-chan struct{}) {
 	// Reload plan to get latest state
 	plan, err := e.manager.GetPlan(planName)
 	if err != nil {
-		fmt.Printf("[Executor] Error loading plan '%s': %v\n", planName, err)
+		e.log.Error("Error loading plan", "plan", planName, "error", err)
 		return
 	}
 
@@ -194,31 +306,151 @@ func (e *Executor) checkAndExecutePlan(planName string) {
 		return
 	}
 
+	// Hold new trades while too many deposits are still awaiting verification
+	if limit := e.config.MaxUnverifiedExecutions; limit > 0 {
+		if unverified := plan.UnverifiedCount(); unverified >= limit {
+			if _, held := e.heldPlans.LoadOrStore(planName, true); !held {
+				e.log.Info("Holding plan: executions awaiting verification",
+					"plan", planName, "event", "plan_held", "unverified", unverified, "limit", limit)
+			}
+			return
+		}
+		if _, held := e.heldPlans.LoadAndDelete(planName); held {
+			e.log.Info("Plan verifications caught up, resuming trades", "plan", planName)
+		}
+	}
+
+	// The first full trade waits for the verification swap to settle
+	if plan.NeedsVerification() {
+		if exec := plan.verificationInFlight(); exec != nil {
+			if _, held := e.verifyingPlans.LoadOrStore(planName, true); !held {
+				e.log.Info("Holding plan: verification swap has not settled",
+					"plan", planName, "execution_id", exec.ID, "event", "plan_held")
+			}
+			return
+		}
+		e.verifyingPlans.Delete(planName)
+	}
+
+	// Plans with their own token use their own client
+	_, pricer, err := e.clientFor(plan)
+	if err != nil {
+		e.log.Error("Error checking price", "plan", planName, "error", err)
+		e.activity.recordError(planName, err)
+		return
+	}
+
 	// Check if plan should execute
-	shouldExecute, priceInfo, err := e.pricer.ShouldExecute(plan)
+	shouldExecute, priceInfo, err := pricer.ShouldExecute(plan)
+	if (err != nil || priceInfo != nil) && !plan.HasOwnAPIToken() {
+		e.recordAPIResult(err)
+	}
 	if err != nil {
-		fmt.Printf("[Executor] Error checking price for plan '%s': %v\n", planName, err)
+		e.log.Error("Error checking price", "plan", planName, "error", err)
+		e.activity.recordError(planName, err)
 		return
 	}
+	if priceInfo != nil {
+		e.metrics.priceChecks.Add(1)
+		e.metrics.apiSucceeded()
+		e.activity.recordPrice(planName, priceInfo.Price)
+		if plan.HasPriceSmoothing() {
+			if err := e.manager.RecordPriceSample(planName, priceInfo.PriceFloat); err != nil {
+				e.log.Error("Error recording price sample", "plan", planName, "error", err)
+			}
+		}
+		if plan.IsTrailingStop() {
+			if err := e.manager.RecordPeakPrice(planName, priceInfo.triggerValue()); err != nil {
+				e.log.Error("Error recording peak price", "plan", planName, "error", err)
+			}
+		}
+
+		// A crossed kill switch cancels the plan outright instead of trading
+		reason, err := pricer.CheckKillSwitch(plan, priceInfo)
+		if err != nil {
+			e.log.Error("Error checking kill switch", "plan", planName, "error", err)
+			return
+		}
+		if reason != "" {
+			if e.config.ObserverMode {
+				e.observeLog.Info("Plan would have been cancelled",
+					"plan", planName, "event", "plan_cancelled", "reason", reason)
+				return
+			}
+			e.killPlan(planName, reason)
+			return
+		}
+	}
 
 	if !shouldExecute {
 		// Price condition not met, continue monitoring
 		return
 	}
 
-	fmt.Printf("[Executor] Trigger condition met for plan '%s'! Price: %s %s/%s\n",
-		planName, priceInfo.Price, plan.DestToken, plan.SourceToken)
+	if stopRequested(stop) {
+		e.log.Info("Plan stopped before trading, skipping", "plan", planName)
+		return
+	}
+
+	// A remainder too small to be worth a trade's fees completes the plan instead
+	if reason := plan.dustReason(priceInfo.PriceFloat); reason != "" {
+		if e.config.ObserverMode {
+			e.observeLog.Info("Plan would have been completed",
+				"plan", planName, "event", "plan_completed", "reason", reason)
+			return
+		}
+		e.completeDustPlan(planName, reason)
+		return
+	}
+
+	// Shallow liquidity can make a full trade price far worse than the small probe quote
+	reason, err := e.checkPriceImpact(pricer, plan, priceInfo)
+	if err != nil {
+		e.log.Error("Error measuring price impact", "plan", planName, "error", err)
+		e.activity.recordError(planName, err)
+		return
+	}
+	if reason != "" {
+		if e.config.ObserverMode {
+			e.observeLog.Info("Plan would have skipped the trade",
+				"plan", planName, "event", "trade_skipped", "reason", reason)
+			return
+		}
+		e.log.Warn("Skipping trade",
+			"plan", planName, "event", "trade_skipped", "reason", reason)
+		e.activity.recordError(planName, fmt.Errorf("price impact too high: %s", reason))
+		return
+	}
+
+	// Observer mode records the trade it would have made and never deposits
+	if e.config.ObserverMode {
+		if err := e.observeTrade(plan, priceInfo); err != nil {
+			e.observeLog.Error("Error observing plan", "plan", planName, "error", err)
+			e.activity.recordError(planName, err)
+		}
+		return
+	}
+
+	e.log.Info("Trigger condition met",
+		"plan", planName, "event", EventTriggerMet, "price", priceInfo.Price, "pair", plan.DestToken+"/"+plan.SourceToken, "price_source", priceInfo.Source)
 
 	// Execute the trade
-	if err := e.executeTrade(plan, priceInfo); err != nil {
-		fmt.Printf("[Executor] Failed to execute trade for plan '%s': %v\n", planName, err)
+	e.metrics.tradesAttempted.Add(1)
+	if err := e.executeTrade(plan, priceInfo, stop); err != nil {
+		e.metrics.tradesFailed.Add(1)
+		e.log.Error("Failed to execute trade",
+			"plan", planName, "event", "trade_failed", "error", err)
+		e.activity.recordError(planName, err)
+		if !plan.HasOwnAPIToken() {
+			e.recordAPIResult(err)
+		}
 		return
 	}
 
 	// Check if plan is completed after this execution
 	plan, _ = e.manager.GetPlan(planName)
 	if plan.IsCompleted() {
-		fmt.Printf("[Executor] Plan '%s' has completed all trades!\n", planName)
+		e.log.Info("Plan has completed all trades", "plan", planName, "event", "plan_completed")
 		e.mu.Lock()
 		if pe, exists := e.activePlans[planName]; exists {
 			close(pe.stopChan)
@@ -228,107 +460,319 @@ func (e *Executor) checkAndExecutePlan(planName string) {
 	}
 }
 
+// killPlan cancels a plan whose kill switch price was hit and stops monitoring it
+func (e *Executor) killPlan(planName, reason string) {
+	if err := e.manager.CancelPlanWithReason(planName, reason); err != nil {
+		e.log.Error("Error cancelling plan", "plan", planName, "error", err)
+		return
+	}
+
+	e.log.Warn("Plan cancelled",
+		"plan", planName, "event", "plan_cancelled", "reason", reason)
+
+	e.mu.Lock()
+	if pe, exists := e.activePlans[planName]; exists {
+		close(pe.stopChan)
+		delete(e.activePlans, planName)
+	}
+	e.mu.Unlock()
+}
+
 // executeTrade performs a single trade for a plan
-func (e *Executor) executeTrade(plan *TradingPlan, priceInfo *PriceInfo) error {
+func (e *Executor) executeTrade(plan *TradingPlan, priceInfo *PriceInfo, stop <-chan struct{}) error {
 	// Calculate the amount to trade for this execution
 	// Use the smaller of: amountPerTrade, remaining daily amount, or remaining total amount
 	amountPerTrade, _ := strconv.ParseFloat(plan.AmountPerTrade, 64)
 	remainingDaily, _ := strconv.ParseFloat(plan.GetRemainingDailyAmount(), 64)
 	remainingTotal, _ := strconv.ParseFloat(plan.RemainingAmount, 64)
 
+	// Source-denominated limit for this execution
+	sourceLimit := remainingDaily
+	if remainingTotal < sourceLimit {
+		sourceLimit = remainingTotal
+	}
+
+	// Plans with jitter vary each trade around amountPerTrade so sizes aren't identical
+	if plan.HasJitter() && !plan.HasLadder() && !plan.IsDestSized() {
+		amountPerTrade = plan.jitteredAmount(amountPerTrade)
+	}
+
 	// Find the minimum
 	executeAmount := amountPerTrade
-	if remainingDaily < executeAmount {
-		executeAmount = remainingDaily
+	if sourceLimit < executeAmount {
+		executeAmount = sourceLimit
 	}
-	if remainingTotal < executeAmount {
-		executeAmount = remainingTotal
+	if plan.HasJitter() && !plan.HasLadder() && !plan.IsDestSized() {
+		baseAmount, _ := strconv.ParseFloat(plan.AmountPerTrade, 64)
+		executeAmount = settleJitter(executeAmount, baseAmount, plan.AmountJitter, sourceLimit, remainingTotal)
+	}
+
+	// Laddered plans trade the remaining tranche of the level that was crossed
+	ladderPrice := ""
+	if plan.HasLadder() {
+		index := plan.NextLadderLevel(priceInfo.triggerValue())
+		if index < 0 {
+			return fmt.Errorf("no unfilled ladder level crossed at price %s", priceInfo.Price)
+		}
+		level := plan.Ladder[index]
+		ladderPrice = level.Price
+		executeAmount = plan.LadderRemaining(level)
+		if sourceLimit < executeAmount {
+			executeAmount = sourceLimit
+		}
+		e.log.Info("Ladder level crossed",
+			"plan", plan.Name, "ladder_price", ladderPrice)
+	}
+
+	// Until a verification swap has completed, trades are tiny ones that prove the route,
+	// addresses and auto-deposit work before the full amount is committed
+	verification := plan.NeedsVerification()
+	if verification {
+		verifyAmount, _ := strconv.ParseFloat(plan.VerifyAmount, 64)
+		executeAmount = verifyAmount
+		if sourceLimit < executeAmount {
+			executeAmount = sourceLimit
+		}
+		ladderPrice = ""
 	}
 
 	executeAmountStr := fmt.Sprintf("%.8f", executeAmount)
 
-	fmt.Printf("[Executor] Executing trade for plan '%s': %s %s -> %s\n",
-		plan.Name, executeAmountStr, plan.SourceToken, plan.DestToken)
+	if verification {
+		e.log.Info("Executing verification swap",
+			"plan", plan.Name, "amount", executeAmountStr, "source_token", plan.SourceToken, "dest_token", plan.DestToken)
+	} else if plan.IsDestSized() {
+		e.log.Info("Executing trade",
+			"plan", plan.Name, "amount_out", plan.AmountPerTradeDest, "source_token", plan.SourceToken, "dest_token", plan.DestToken)
+	} else {
+		e.log.Info("Executing trade",
+			"plan", plan.Name, "amount", executeAmountStr, "source_token", plan.SourceToken, "dest_token", plan.DestToken)
+	}
 
 	// Create swap request
 	swapReq := &types.SwapRequest{
-		Amount:        executeAmountStr,
-		SourceToken:   plan.SourceToken,
-		DestToken:     plan.DestToken,
-		SourceChain:   plan.SourceChain,
-		DestChain:     plan.DestChain,
-		RecipientAddr: plan.RecipientAddr,
-		RefundAddr:    plan.RefundAddr,
+		Amount:          executeAmountStr,
+		SourceToken:     plan.SourceToken,
+		DestToken:       plan.DestToken,
+		SourceChain:     plan.SourceChain,
+		DestChain:       plan.DestChain,
+		RecipientAddr:   plan.RecipientAddr,
+		RefundAddr:      plan.RefundAddr,
+		Referral:        e.config.Referral,
+		AppFeeRecipient: e.config.AppFee.Recipient,
+		AppFeeBps:       e.config.AppFee.FeeBps,
+		SlippageBps:     plan.SlippageBps,
+		Deadline:        plan.quoteDeadline(),
+	}
+
+	// Dest-sized plans quote the fixed output and let the API determine the source spend
+	if plan.IsDestSized() && !verification {
+		swapReq.Amount = plan.AmountPerTradeDest
+		swapReq.ExactOutput = true
+	}
+
+	// Bail out before requesting a deposit address if the plan was stopped
+	if stopRequested(stop) {
+		return errPlanStopped
+	}
+
+	apiClient, _, err := e.clientFor(plan)
+	if err != nil {
+		return err
 	}
 
 	// Get quote from API
-	quote, err := e.apiClient.GetQuote(swapReq)
+	quote, err := apiClient.GetQuote(swapReq)
 	if err != nil {
 		return fmt.Errorf("failed to get quote: %w", err)
 	}
+	e.metrics.apiSucceeded()
 
 	quoteDetails := quote.GetQuote()
 
+	if swapReq.ExactOutput {
+		amountIn, err := parser.NormalizeFormattedAmount(quoteDetails.GetAmountInFormatted())
+		if err != nil {
+			return fmt.Errorf("failed to parse quoted source spend: %w", err)
+		}
+		spend, err := parseDecimal(amountIn)
+		if err != nil {
+			return fmt.Errorf("failed to parse quoted source spend: %w", err)
+		}
+		limit, err := plan.sourceLimit()
+		if err != nil {
+			return fmt.Errorf("failed to compute remaining limit: %w", err)
+		}
+
+		// Spending more than the daily/total limit allows: fall back to a final
+		// source-sized trade for whatever is left
+		if spend.Cmp(limit) > 0 {
+			e.log.Info("Trade needs more than the remaining limit, trading the limit instead",
+				"plan", plan.Name, "amount_out", plan.AmountPerTradeDest, "needed", formatDecimal(spend), "amount", formatDecimal(limit))
+			swapReq.ExactOutput = false
+			swapReq.Amount = formatDecimal(limit)
+			quote, err = apiClient.GetQuote(swapReq)
+			if err != nil {
+				return fmt.Errorf("failed to get quote: %w", err)
+			}
+			quoteDetails = quote.GetQuote()
+			spend = limit
+		}
+
+		executeAmountStr = formatDecimal(spend)
+	}
+
+	// Compare the deposit quote against the price that triggered the plan
+	depositPrice, err := quotePrice(&quoteDetails)
+	if err != nil {
+		return fmt.Errorf("failed to price deposit quote: %w", err)
+	}
+	divergence := QuoteDivergence(priceInfo.PriceFloat, depositPrice)
+
+	estimatedOutput, err := parser.NormalizeFormattedAmount(quoteDetails.GetAmountOutFormatted())
+	if err != nil {
+		e.log.Warn("Keeping unparsed estimated output", "plan", plan.Name, "error", err)
+		estimatedOutput = quoteDetails.GetAmountOutFormatted()
+	}
+
 	// Create execution record
 	execution := Execution{
 		Amount:          executeAmountStr,
 		TriggerPrice:    priceInfo.Price,
-		ActualPrice:     priceInfo.Price,
+		ActualPrice:     fmt.Sprintf("%.8f", depositPrice),
 		DepositAddress:  quoteDetails.GetDepositAddress(),
 		Status:          ExecutionPending,
-		EstimatedOutput: quoteDetails.GetAmountOutFormatted(),
+		EstimatedOutput: estimatedOutput,
+		QuoteDivergence: fmt.Sprintf("%.4f", divergence),
+		LadderPrice:     ladderPrice,
+		TimeEstimate:    float64(quoteDetails.GetTimeEstimate()),
+		Verification:    verification,
+	}
+	if deadline, ok := quoteDetails.GetDeadlineOk(); ok {
+		execution.QuoteDeadline = deadline
+	}
+
+	// Abort before depositing if the real quote is materially worse than the trigger
+	if maxDivergence := plan.QuoteDivergenceLimit(e.config.MaxQuoteDivergence); maxDivergence > 0 && divergence > maxDivergence {
+		execution.ErrorMessage = fmt.Sprintf("deposit quote price %.8f is %.2f%% worse than trigger price %s (max %.2f%%)",
+			depositPrice, divergence, priceInfo.Price, maxDivergence)
+		if _, err := e.manager.RecordDivergenceAbort(plan.Name, execution); err != nil {
+			return fmt.Errorf("failed to record execution: %w", err)
+		}
+		return fmt.Errorf("aborted: %s", execution.ErrorMessage)
 	}
 
-	// Add execution to plan and get the execution ID
-	executionID, err := e.manager.AddExecution(plan.Name, execution)
+	// Record the execution, reserving its amount against the daily and total limits so a
+	// concurrent trade can't overshoot them
+	executionID, err := e.manager.ReserveExecution(plan.Name, execution)
+	if errors.Is(err, ErrLimitReached) {
+		return fmt.Errorf("skipping trade: %w", err)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to record execution: %w", err)
 	}
 
-	fmt.Printf("[Executor] Deposit address: %s\n", quoteDetails.GetDepositAddress())
-	fmt.Printf("[Executor] Expected output: %s %s\n", quoteDetails.GetAmountOutFormatted(), plan.DestToken)
+	e.notifyExecution(EventTriggerMet, plan.Name, executionID)
+
+	e.log.Info("Quote ready for deposit",
+		"plan", plan.Name, "execution_id", executionID, "deposit_address", quoteDetails.GetDepositAddress(),
+		"estimated_output", estimatedOutput, "dest_token", plan.DestToken)
+
+	// Last chance to abort before funds are sent
+	if stopRequested(stop) {
+		e.manager.UpdateExecutionStatus(plan.Name, executionID, ExecutionFailed, "", "plan stopped before deposit")
+		return errPlanStopped
+	}
 
 	// Auto-deposit is always enabled for plans
 	if e.config.AutoDeposit.Enabled {
-		if err := e.handleAutoDeposit(plan, executionID, swapReq, &quoteDetails); err != nil {
-			fmt.Printf("[Executor] Auto-deposit failed: %v\n", err)
-			fmt.Printf("[Executor] Please manually deposit %s %s to: %s\n",
-				executeAmountStr, plan.SourceToken, quoteDetails.GetDepositAddress())
+		if _, err := e.handleAutoDeposit(plan, executionID, executeAmountStr, swapReq, &quoteDetails); err != nil {
+			e.log.Error("Auto-deposit failed",
+				"plan", plan.Name, "execution_id", executionID, "event", EventDepositFailed, "error", err)
+			var selfDeposit *deposit.SelfDepositError
+			if !errors.As(err, &selfDeposit) && !errors.Is(err, ErrExecutionCancelled) {
+				e.log.Warn("Manual deposit required",
+					"plan", plan.Name, "execution_id", executionID, "amount", executeAmountStr, "token", plan.SourceToken,
+					"deposit_address", quoteDetails.GetDepositAddress())
+			}
 		}
 	} else {
-		fmt.Printf("[Executor] WARNING: Auto-deposit is not configured. Please enable it in your config.\n")
-		fmt.Printf("[Executor] Manual deposit required: Send %s %s to %s\n",
-			executeAmountStr, plan.SourceToken, quoteDetails.GetDepositAddress())
+		e.log.Warn("Auto-deposit is not configured, enable it in your config", "plan", plan.Name)
+		e.log.Warn("Manual deposit required",
+			"plan", plan.Name, "execution_id", executionID, "amount", executeAmountStr, "token", plan.SourceToken,
+			"deposit_address", quoteDetails.GetDepositAddress())
 	}
 
 	return nil
 }
 
-// handleAutoDeposit attempts to automatically send the deposit
-func (e *Executor) handleAutoDeposit(plan *TradingPlan, executionID string, swapReq *types.SwapRequest, quoteDetails *oneclick.Quote) error {
+// handleAutoDeposit attempts to automatically send the deposit and returns what was sent. amount
+// is the source amount this execution trades, already clamped to the plan's daily and total limits.
+func (e *Executor) handleAutoDeposit(plan *TradingPlan, executionID string, amount string, swapReq *types.SwapRequest, quoteDetails *oneclick.Quote) (*deposit.DepositResult, error) {
 	depositMgr := deposit.NewManager(e.config.AutoDeposit)
 
-	if !depositMgr.IsEnabledForChain(plan.SourceChain) {
-		return fmt.Errorf("auto-deposit not enabled for chain: %s", plan.SourceChain)
+	if err := depositMgr.CheckChain(plan.SourceChain); err != nil {
+		e.manager.UpdateExecutionStatus(plan.Name, executionID, ExecutionFailed, "", err.Error())
+		return nil, err
 	}
 
 	depositAddress := quoteDetails.GetDepositAddress()
-	txid, err := depositMgr.SendDeposit(plan.SourceChain, depositAddress, plan.AmountPerTrade)
+
+	// Never send funds back to one of our own addresses
+	if err := deposit.CheckDepositAddress(plan.SourceChain, plan.DestChain, depositAddress, plan.RecipientAddr, plan.RefundAddr); err != nil {
+		e.manager.UpdateExecutionStatus(plan.Name, executionID, ExecutionFailed, "", err.Error())
+		return nil, err
+	}
+
+	// Exact-output quotes (dest-sized plans) deposit whatever source amount the quote requires
+	depositAmount := amount
+	if swapReq.ExactOutput {
+		amountIn, err := parser.NormalizeFormattedAmount(quoteDetails.GetAmountInFormatted())
+		if err != nil {
+			e.manager.UpdateExecutionStatus(plan.Name, executionID, ExecutionFailed, "", err.Error())
+			return nil, err
+		}
+		depositAmount = amountIn
+	}
+
+	// The user may have cancelled the execution since it was recorded
+	if e.manager.IsExecutionCancelled(plan.Name, executionID) {
+		return nil, fmt.Errorf("%w before its deposit was sent", ErrExecutionCancelled)
+	}
+
+	txid, err := depositMgr.SendDeposit(plan.SourceChain, depositAddress, depositAmount)
 	if err != nil {
 		// Update execution with failure
 		e.manager.UpdateExecutionStatus(plan.Name, executionID, ExecutionFailed, "", err.Error())
-		return err
+		e.notifyExecution(EventDepositFailed, plan.Name, executionID)
+		return nil, err
+	}
+
+	if blockhash := depositMgr.LastBlockhash(); blockhash != "" {
+		if err := e.manager.RecordDepositBlockhash(plan.Name, executionID, blockhash); err != nil {
+			e.log.Warn("Failed to record deposit blockhash", "plan", plan.Name, "execution_id", executionID, "error", err)
+		}
 	}
 
-	fmt.Printf("[Executor] Auto-deposit successful! TX: %s\n", txid)
+	result := deposit.NewDepositResult(plan.SourceChain, plan.SourceToken, depositAmount, depositAddress, txid)
+	e.log.Info("Auto-deposit sent",
+		"plan", plan.Name, "execution_id", executionID, "event", EventDepositSent, "tx_hash", result.TxID, "amount", depositAmount)
+
+	record := result.AuditRecord("plan")
+	record.PlanName = plan.Name
+	record.ExecutionID = executionID
+	if err := depositMgr.RecordAudit(record); err != nil {
+		e.log.Warn("Failed to record deposit audit", "plan", plan.Name, "execution_id", executionID, "error", err)
+	}
 
 	// Update execution with transaction hash
-	e.manager.UpdateExecutionStatus(plan.Name, executionID, ExecutionDeposited, txid, "")
+	e.manager.UpdateExecutionStatus(plan.Name, executionID, ExecutionDeposited, result.TxID, "")
+	e.notifyExecution(EventDepositSent, plan.Name, executionID)
 
 	// Start background verification for this swap
-	go e.verifySwapCompletion(plan.Name, executionID, quoteDetails.GetDepositAddress())
+	e.verifyInBackground(plan.Name, executionID, depositAddress, secondsDuration(float64(quoteDetails.GetTimeEstimate())))
 
-	return nil
+	return result, nil
 }
 
 // GetRunningPlans returns a list of plans currently being executed
@@ -387,20 +831,20 @@ func (e *Executor) reloadPlans() {
 	defer e.mu.Unlock()
 
 	// Find plans that should be running but aren't (new or restarted plans)
+	started := 0
 	for name, plan := range activeMap {
 		if _, isRunning := e.activePlans[name]; !isRunning {
-			fmt.Printf("[Executor] Detected new active plan: %s\n", name)
-			fmt.Printf("[Executor] Starting execution for: %s %s -> %s\n",
-				plan.TotalAmount, plan.SourceToken, plan.DestToken)
-			e.startPlanExecutor(plan)
+			e.log.Info("Detected new active plan",
+				"plan", name, "total_amount", plan.TotalAmount, "source_token", plan.SourceToken, "dest_token", plan.DestToken)
+			e.startPlanExecutor(plan, e.warmupDelay(started))
+			started++
 		}
 	}
 
 	// Find plans that are running but shouldn't be (stopped or deleted plans)
 	for name, pe := range e.activePlans {
 		if _, shouldRun := activeMap[name]; !shouldRun {
-			fmt.Printf("[Executor] Plan '%s' has been stopped or deleted\n", name)
-			fmt.Printf("[Executor] Stopping execution for: %s\n", name)
+			e.log.Info("Plan stopped or deleted, stopping execution", "plan", name)
 			close(pe.stopChan)
 			delete(e.activePlans, name)
 		}
@@ -434,33 +878,148 @@ func (e *Executor) verifyPendingSwaps() {
 
 			// Only verify if status is deposited or pending and we have a deposit address
 			if (exec.Status == ExecutionDeposited || exec.Status == ExecutionPending) && exec.DepositAddress != "" {
-				// Check if this is a recent execution (within last 24 hours)
-				if time.Since(exec.Timestamp) < 24*time.Hour {
+				// Check if this is a recent execution (within the reservation window) that could have settled by now
+				age := time.Since(exec.Timestamp)
+				initialDelay, _ := verificationSchedule(secondsDuration(exec.TimeEstimate))
+				if age < reservationWindow && age >= initialDelay {
 					e.checkSwapStatus(plan.Name, exec.ID, exec.DepositAddress)
 				}
 			}
 		}
+
+		// Quotes that were never funded no longer hold the plan's limits
+		expired, err := e.manager.ExpireReservations(plan.Name, time.Now())
+		if err != nil {
+			e.verifyLog.Error("Error expiring reservations", "plan", plan.Name, "error", err)
+		} else if expired > 0 {
+			e.verifyLog.Info("Released reservations of unfunded quotes", "plan", plan.Name, "executions", expired)
+		}
 	}
 }
 
-// verifySwapCompletion monitors a specific swap until completion (runs in background)
-func (e *Executor) verifySwapCompletion(planName, executionID, depositAddress string) {
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
+// resumeInFlightSwaps starts verifying every pending or deposited execution of every plan, so
+// swaps that settled while the daemon was down are recorded however old they are. Plans of any
+// status are scanned: a paused or completed plan can still have a deposit in flight. It returns
+// the number of executions resumed.
+func (e *Executor) resumeInFlightSwaps() int {
+	resumed := 0
+	plans := 0
+	for _, plan := range e.manager.ListPlans() {
+		planResumed := 0
+		for _, exec := range plan.ExecutionHistory {
+			if (exec.Status != ExecutionDeposited && exec.Status != ExecutionPending) || exec.DepositAddress == "" {
+				continue
+			}
+			// Time already spent counts toward the quote's estimate, so a swap that should be
+			// done by now is checked on the first poll
+			remaining := secondsDuration(exec.TimeEstimate) - time.Since(exec.Timestamp)
+			e.verifyInBackground(plan.Name, exec.ID, exec.DepositAddress, remaining)
+			planResumed++
+		}
+		if planResumed > 0 {
+			resumed += planResumed
+			plans++
+		}
+	}
+
+	if resumed > 0 {
+		e.verifyLog.Info("Resuming verification of in-flight swaps", "swaps", resumed, "plans", plans)
+	}
+	return resumed
+}
+
+// ReconcileResult summarizes a one-time verification sweep of a plan's unsettled executions
+type ReconcileResult struct {
+	Checked int // Pending or deposited executions whose status was refreshed
+	Settled int // Of those, executions that reached a terminal state
+}
+
+// ReconcilePlan refreshes the status of every pending or deposited execution of a plan from
+// the API, regardless of age, so its accounting is current before it resumes trading
+func (e *Executor) ReconcilePlan(planName string) (*ReconcileResult, error) {
+	plan, err := e.manager.GetPlan(planName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReconcileResult{}
+	for _, exec := range plan.ExecutionHistory {
+		if (exec.Status == ExecutionDeposited || exec.Status == ExecutionPending) && exec.DepositAddress != "" {
+			result.Checked++
+			if e.checkSwapStatus(planName, exec.ID, exec.DepositAddress) {
+				result.Settled++
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// verificationSchedule derives when to first poll a swap and how long to keep polling it from the
+// quote's time estimate. Polling starts once the swap could plausibly be done (capped at
+// MaxInitialPollDelay), and slow routes get a window of several times their estimate.
+func verificationSchedule(estimate time.Duration) (initialDelay, window time.Duration) {
+	initialDelay = SwapPollInterval
+	if estimate > initialDelay {
+		initialDelay = estimate
+	}
+	if initialDelay > MaxInitialPollDelay {
+		initialDelay = MaxInitialPollDelay
+	}
 
-	maxAttempts := 120 // Monitor for up to 1 hour (120 * 30s)
-	attempts := 0
+	window = MinVerificationWindow
+	if scaled := 4 * estimate; scaled > window {
+		window = scaled
+	}
+	return initialDelay, window
+}
 
-	for attempts < maxAttempts {
+// secondsDuration converts a time estimate in seconds, as quoted by the API, to a duration
+func secondsDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// verifyInBackground starts verifying a swap without waiting for it to settle
+func (e *Executor) verifyInBackground(planName, executionID, depositAddress string, estimate time.Duration) {
+	if e.startVerifier != nil {
+		e.startVerifier(planName, executionID, depositAddress, estimate)
+		return
+	}
+	go e.verifySwapCompletion(planName, executionID, depositAddress, estimate)
+}
+
+// verifySwapCompletion monitors a specific swap until completion (runs in background), starting
+// and giving up according to the quote's time estimate
+func (e *Executor) verifySwapCompletion(planName, executionID, depositAddress string, estimate time.Duration) {
+	initialDelay, window := verificationSchedule(estimate)
+
+	timer := time.NewTimer(initialDelay)
+	defer timer.Stop()
+	select {
+	case <-e.stopChan:
+		return
+	case <-timer.C:
+	}
+
+	if e.checkSwapStatus(planName, executionID, depositAddress) {
+		return
+	}
+
+	ticker := time.NewTicker(SwapPollInterval)
+	defer ticker.Stop()
+
+	deadline := time.Now().Add(window)
+	for time.Now().Before(deadline) {
 		select {
 		case <-e.stopChan:
 			return
 		case <-ticker.C:
-			completed := e.checkSwapStatus(planName, executionID, depositAddress)
-			if completed {
+			if e.checkSwapStatus(planName, executionID, depositAddress) {
 				return
 			}
-			attempts++
 		}
 	}
 }
@@ -468,11 +1027,21 @@ func (e *Executor) verifySwapCompletion(planName, executionID, depositAddress st
 // checkSwapStatus checks the status of a swap and updates the execution
 // Returns true if the swap is in a terminal state (completed/failed)
 func (e *Executor) checkSwapStatus(planName, executionID, depositAddress string) bool {
-	status, err := e.apiClient.GetSwapStatus(depositAddress)
+	plan, err := e.manager.GetPlan(planName)
+	if err != nil {
+		return false
+	}
+	apiClient, _, err := e.clientFor(plan)
+	if err != nil {
+		return false
+	}
+
+	status, err := apiClient.GetSwapStatus(depositAddress)
 	if err != nil {
 		// Silent failure - will retry next time
 		return false
 	}
+	e.metrics.apiSucceeded()
 
 	swapStatus := status.GetStatus()
 	swapDetails := status.GetSwapDetails()
@@ -481,6 +1050,12 @@ func (e *Executor) checkSwapStatus(planName, executionID, depositAddress string)
 	actualOutput := ""
 	if swapDetails.HasAmountOutFormatted() {
 		actualOutput = swapDetails.GetAmountOutFormatted()
+		if normalized, err := parser.NormalizeFormattedAmount(actualOutput); err == nil {
+			actualOutput = normalized
+		} else {
+			e.verifyLog.Warn("Keeping unparsed output amount",
+				"plan", planName, "execution_id", executionID, "error", err)
+		}
 	}
 
 	// Extract destination transaction hash
@@ -492,19 +1067,107 @@ func (e *Executor) checkSwapStatus(planName, executionID, depositAddress string)
 
 	// Update execution with swap status
 	err = e.manager.UpdateExecutionWithSwapStatus(planName, executionID, swapStatus, actualOutput, destTxHash)
+	if errors.Is(err, ErrExecutionCancelled) {
+		// The user cancelled it; stop verifying
+		return true
+	}
 	if err != nil {
-		fmt.Printf("[Verifier] Error updating execution status: %v\n", err)
+		e.verifyLog.Error("Error updating execution status",
+			"plan", planName, "execution_id", executionID, "error", err)
 		return false
 	}
 
 	// Check if swap is in terminal state
 	if swapStatus == "SUCCESS" || swapStatus == "COMPLETED" {
-		fmt.Printf("[Verifier] ✓ Swap completed for plan '%s'! Received: %s\n", planName, actualOutput)
+		e.verifyLog.Info("Swap completed",
+			"plan", planName, "execution_id", executionID, "event", EventSwapCompleted, "output", actualOutput, "tx_hash", destTxHash)
+		e.metrics.tradesCompleted.Add(1)
+		e.notifyExecution(EventSwapCompleted, planName, executionID)
+		e.autoWithdraw(planName, executionID)
 		return true
 	} else if swapStatus == "FAILED" || swapStatus == "REFUNDED" {
-		fmt.Printf("[Verifier] ✗ Swap failed for plan '%s': %s\n", planName, swapStatus)
+		e.verifyLog.Error("Swap failed",
+			"plan", planName, "execution_id", executionID, "event", EventSwapFailed, "swap_status", swapStatus)
+		if swapStatus == "REFUNDED" {
+			e.confirmRefund(planName, executionID, &swapDetails)
+		} else {
+			e.verifyLog.Info("Funds should be refunded to the refund address",
+				"plan", planName, "execution_id", executionID)
+		}
+		e.metrics.tradesFailed.Add(1)
+		e.notifyExecution(EventSwapFailed, planName, executionID)
+		e.haltOnDestinationFailures(planName)
+		e.haltOnFailedVerification(planName, executionID)
 		return true
+	} else if swapStatus == "PENDING_DEPOSIT" {
+		// 1Click hasn't seen the deposit; make sure it didn't get dropped on chain
+		return e.checkDroppedDeposit(plan, executionID)
 	}
 
 	return false
 }
+
+// confirmRefund records the refund for a REFUNDED swap and, when the source chain
+// has a depositor configured, verifies the refund transaction on-chain
+func (e *Executor) confirmRefund(planName, executionID string, swapDetails *oneclick.SwapDetails) {
+	plan, err := e.manager.GetPlan(planName)
+	if err != nil {
+		e.verifyLog.Error("Error loading plan", "plan", planName, "error", err)
+		return
+	}
+
+	var exec *Execution
+	for i := range plan.ExecutionHistory {
+		if plan.ExecutionHistory[i].ID == executionID {
+			exec = &plan.ExecutionHistory[i]
+			break
+		}
+	}
+	if exec == nil {
+		return
+	}
+
+	// The refund is the origin-chain transaction that isn't our deposit
+	refundTxHash := ""
+	for _, tx := range swapDetails.GetOriginChainTxHashes() {
+		if hash := tx.GetHash(); hash != "" && hash != exec.TxHash {
+			refundTxHash = hash
+		}
+	}
+	refundedAmount := swapDetails.GetRefundedAmountFormatted()
+	if normalized, err := parser.NormalizeFormattedAmount(refundedAmount); err == nil {
+		refundedAmount = normalized
+	}
+
+	confirmed := false
+	if refundTxHash != "" {
+		depositMgr := deposit.NewManager(e.config.AutoDeposit)
+		if depositMgr.IsEnabledForChain(plan.SourceChain) {
+			if _, err := depositMgr.GetTransactionInfo(plan.SourceChain, refundTxHash); err == nil {
+				confirmed = true
+			} else {
+				e.verifyLog.Warn("Could not confirm refund transaction",
+					"plan", planName, "execution_id", executionID, "tx_hash", refundTxHash, "chain", plan.SourceChain, "error", err)
+			}
+		}
+	}
+
+	if err := e.manager.RecordRefund(planName, executionID, refundTxHash, refundedAmount, confirmed); err != nil {
+		e.verifyLog.Error("Error recording refund", "plan", planName, "execution_id", executionID, "error", err)
+		return
+	}
+
+	switch {
+	case confirmed:
+		e.verifyLog.Info("Refund confirmed",
+			"plan", planName, "execution_id", executionID, "event", "refund_confirmed", "tx_hash", refundTxHash,
+			"amount", refundedAmount, "token", plan.SourceToken, "refund_addr", plan.RefundAddr)
+	case refundTxHash != "":
+		e.verifyLog.Info("Refund reported, not verified on-chain",
+			"plan", planName, "execution_id", executionID, "event", "refund_reported", "tx_hash", refundTxHash,
+			"amount", refundedAmount, "token", plan.SourceToken, "refund_addr", plan.RefundAddr)
+	default:
+		e.verifyLog.Info("Swap refunded, no refund transaction reported yet",
+			"plan", planName, "execution_id", executionID, "event", "refund_pending")
+	}
+}
@@ -0,0 +1,1063 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
+
+	"near-swap/config"
+	"near-swap/pkg/deposit"
+	"near-swap/pkg/mockserver"
+)
+
+const testRefundAddr = "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+
+// newMockExecutor returns an executor trading against a mock 1Click server that prices BTC at
+// 60000 USDC, and an active BTC -> USDC plan "p" of two 0.1 BTC trades triggering below 70000
+func newMockExecutor(t *testing.T) (*Executor, *mockserver.Server) {
+	t.Helper()
+
+	server := mockserver.New()
+	t.Cleanup(server.Close)
+	btc := server.AddToken("BTC", "btc", 8, 60000)
+	usdc := server.AddToken("USDC", "near", 6, 1)
+	server.SetRate(btc, usdc, 60000)
+
+	manager, err := NewManager(filepath.Join(t.TempDir(), "plans.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.CreatePlan("p", "BTC", "USDC", "btc", "near", "0.2", "0.1", "0.2", "70000", PriceBelow,
+		"me.near", testRefundAddr, "", CreatePlanOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.StartPlan("p", false); err != nil {
+		t.Fatal(err)
+	}
+
+	executor, err := NewExecutor(manager, server.Client("test-token"), &config.Config{LogLevel: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return executor, server
+}
+
+// lastExecution returns the plan's most recent execution
+func lastExecution(t *testing.T, e *Executor) (*TradingPlan, Execution) {
+	t.Helper()
+	p, err := e.manager.GetPlan("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.ExecutionHistory) == 0 {
+		t.Fatal("no execution recorded")
+	}
+	return p, p.ExecutionHistory[len(p.ExecutionHistory)-1]
+}
+
+func TestExecutorRunsPlanToCompletion(t *testing.T) {
+	e, server := newMockExecutor(t)
+
+	for trade := 1; trade <= 2; trade++ {
+		e.checkAndExecutePlan("p", nil)
+		_, exec := lastExecution(t, e)
+		if exec.Status != ExecutionPending || exec.DepositAddress == "" {
+			t.Fatalf("trade %d: execution = %s at %q, want pending with a deposit address", trade, exec.Status, exec.DepositAddress)
+		}
+		if exec.Amount != "0.10000000" || exec.EstimatedOutput != "6000" {
+			t.Errorf("trade %d: amount %s for %s, want 0.10000000 for 6000", trade, exec.Amount, exec.EstimatedOutput)
+		}
+
+		server.QueueStatus(exec.DepositAddress, "PROCESSING", "SUCCESS")
+		if e.checkSwapStatus("p", exec.ID, exec.DepositAddress) {
+			t.Fatalf("trade %d: processing swap reported as settled", trade)
+		}
+		if !e.checkSwapStatus("p", exec.ID, exec.DepositAddress) {
+			t.Fatalf("trade %d: successful swap not reported as settled", trade)
+		}
+	}
+
+	p, exec := lastExecution(t, e)
+	if exec.Status != ExecutionCompleted || exec.ActualOutput != "1" || exec.DestinationTxHash == "" {
+		t.Errorf("execution = %s, output %q, tx %q; want completed with the reported output", exec.Status, exec.ActualOutput, exec.DestinationTxHash)
+	}
+	if p.Status != StatusCompleted || p.TotalExecuted != "0.20000000" || p.RemainingAmount != "0" {
+		t.Errorf("plan = %s, executed %s, remaining %s; want completed, 0.20000000, 0", p.Status, p.TotalExecuted, p.RemainingAmount)
+	}
+	if n := len(p.ExecutionHistory); n != 2 {
+		t.Errorf("executions = %d, want 2", n)
+	}
+
+	// A completed plan places no further trades
+	e.checkAndExecutePlan("p", nil)
+	if p, _ := lastExecution(t, e); len(p.ExecutionHistory) != 2 {
+		t.Errorf("completed plan traded again")
+	}
+
+	// Each trade prices with a dry-run probe and deposits against a real quote
+	var dry, real int
+	for _, req := range server.QuoteRequests() {
+		if req.Dry {
+			dry++
+		} else {
+			real++
+		}
+	}
+	if dry != 2 || real != 2 {
+		t.Errorf("quotes = %d dry, %d real; want 2 of each", dry, real)
+	}
+}
+
+func TestExecutorSwapStatusTransitions(t *testing.T) {
+	tests := []struct {
+		name          string
+		statuses      []string
+		wantSettled   bool
+		wantExecution ExecutionStatus
+		wantRemaining string
+		wantFailures  int
+	}{
+		{name: "still processing", statuses: []string{"PROCESSING"}, wantExecution: ExecutionPending, wantRemaining: "0.10000000"},
+		{name: "success keeps the amount", statuses: []string{"SUCCESS"}, wantSettled: true, wantExecution: ExecutionCompleted, wantRemaining: "0.10000000"},
+		{name: "failure gives the amount back", statuses: []string{"FAILED"}, wantSettled: true, wantExecution: ExecutionFailed, wantRemaining: "0.20000000", wantFailures: 1},
+		{name: "refund gives the amount back", statuses: []string{"REFUNDED"}, wantSettled: true, wantExecution: ExecutionFailed, wantRemaining: "0.20000000", wantFailures: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, server := newMockExecutor(t)
+			e.checkAndExecutePlan("p", nil)
+			_, exec := lastExecution(t, e)
+
+			server.QueueStatus(exec.DepositAddress, tt.statuses...)
+			if settled := e.checkSwapStatus("p", exec.ID, exec.DepositAddress); settled != tt.wantSettled {
+				t.Errorf("settled = %v, want %v", settled, tt.wantSettled)
+			}
+
+			p, exec := lastExecution(t, e)
+			if exec.Status != tt.wantExecution || exec.SwapStatus != tt.statuses[len(tt.statuses)-1] {
+				t.Errorf("execution = %s (swap %s), want %s", exec.Status, exec.SwapStatus, tt.wantExecution)
+			}
+			if p.RemainingAmount != tt.wantRemaining || p.DestinationFailures != tt.wantFailures {
+				t.Errorf("remaining %s, destination failures %d; want %s, %d",
+					p.RemainingAmount, p.DestinationFailures, tt.wantRemaining, tt.wantFailures)
+			}
+			if p.Status != StatusActive {
+				t.Errorf("plan status = %s, want active", p.Status)
+			}
+		})
+	}
+}
+
+func TestExecutorClampsTradeToLimits(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+
+	tests := []struct {
+		name       string
+		adjust     func(p *TradingPlan)
+		wantAmount string // Empty when no trade should be placed
+	}{
+		{name: "full trade", adjust: func(p *TradingPlan) {}, wantAmount: "0.10000000"},
+		{
+			name:       "rest of the total",
+			adjust:     func(p *TradingPlan) { p.TotalExecuted, p.RemainingAmount = "0.15", "0.05" },
+			wantAmount: "0.05000000",
+		},
+		{
+			name:       "rest of the day",
+			adjust:     func(p *TradingPlan) { p.LastExecutionDate, p.TodayExecuted = today, "0.17" },
+			wantAmount: "0.03000000",
+		},
+		{
+			name:   "day used up",
+			adjust: func(p *TradingPlan) { p.LastExecutionDate, p.TodayExecuted = today, "0.2" },
+		},
+		{
+			name:       "yesterday's total doesn't limit today",
+			adjust:     func(p *TradingPlan) { p.LastExecutionDate, p.TodayExecuted = "2000-01-01", "0.2" },
+			wantAmount: "0.10000000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := newMockExecutor(t)
+			p, _ := e.manager.storage.Get("p")
+			tt.adjust(p)
+			if err := e.manager.storage.Update(p); err != nil {
+				t.Fatal(err)
+			}
+
+			e.checkAndExecutePlan("p", nil)
+			p, _ = e.manager.GetPlan("p")
+			if tt.wantAmount == "" {
+				if len(p.ExecutionHistory) != 0 {
+					t.Fatalf("traded %s, want no trade", p.ExecutionHistory[0].Amount)
+				}
+				return
+			}
+			if len(p.ExecutionHistory) != 1 || p.ExecutionHistory[0].Amount != tt.wantAmount {
+				t.Fatalf("executions = %+v, want one of %s", p.ExecutionHistory, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestExecutorExpiresUnfundedQuotes(t *testing.T) {
+	e, _ := newMockExecutor(t)
+	e.checkAndExecutePlan("p", nil)
+	_, exec := lastExecution(t, e)
+	if exec.QuoteDeadline == nil || !exec.Reserved {
+		t.Fatalf("execution = %+v, want a reservation with the quote's deadline", exec)
+	}
+
+	// Without auto-deposit nothing funds the quote; once its deadline passes the
+	// reservation no longer counts against the plan
+	p, _ := e.manager.storage.Get("p")
+	past := time.Now().Add(-time.Minute)
+	p.findExecution(exec.ID).QuoteDeadline = &past
+	if err := e.manager.storage.Update(p); err != nil {
+		t.Fatal(err)
+	}
+	e.verifyPendingSwaps()
+
+	p, exec = lastExecution(t, e)
+	if exec.Status != ExecutionFailed || exec.Reserved {
+		t.Errorf("execution = %s (reserved %v), want failed and released", exec.Status, exec.Reserved)
+	}
+	if p.RemainingAmount != "0.20000000" || p.TodayExecuted != "0.00000000" {
+		t.Errorf("remaining %s, today %s; want the whole plan available again", p.RemainingAmount, p.TodayExecuted)
+	}
+}
+
+func TestExecutorDestSizedTrades(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+
+	tests := []struct {
+		name          string
+		rate          float64 // USDC per BTC
+		todayExecuted string
+		wantAmount    string
+		wantQuotes    []string // Swap types of the deposit quotes, in order
+		wantQuoted    string   // Amount of the last deposit quote in the smallest unit
+	}{
+		{name: "quotes the dest amount", rate: 60000, wantAmount: "0.10000000",
+			wantQuotes: []string{"EXACT_OUTPUT"}, wantQuoted: "6000000000"},
+		{name: "spend exactly the rest of the day", rate: 60000, todayExecuted: "0.2", wantAmount: "0.10000000",
+			wantQuotes: []string{"EXACT_OUTPUT"}, wantQuoted: "6000000000"},
+		{name: "spend past the rest of the day trades the rest", rate: 60000, todayExecuted: "0.25", wantAmount: "0.05000000",
+			wantQuotes: []string{"EXACT_OUTPUT", "EXACT_INPUT"}, wantQuoted: "5000000"},
+		{name: "spend a sliver past the limit", rate: 59999.99, todayExecuted: "0.2", wantAmount: "0.10000000",
+			wantQuotes: []string{"EXACT_OUTPUT", "EXACT_INPUT"}, wantQuoted: "10000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, server := newMockExecutor(t)
+			server.SetRate("nep141:btc-BTC.mock.near", "nep141:near-USDC.mock.near", tt.rate)
+			if _, err := e.manager.CreatePlan("d", "BTC", "USDC", "btc", "near", "1", "", "0.3", "70000", PriceBelow,
+				"me.near", testRefundAddr, "", CreatePlanOptions{AmountPerTradeDest: "6000", Force: true}); err != nil {
+				t.Fatal(err)
+			}
+			if err := e.manager.StartPlan("d", true); err != nil {
+				t.Fatal(err)
+			}
+			if tt.todayExecuted != "" {
+				p, _ := e.manager.storage.Get("d")
+				p.LastExecutionDate, p.TodayExecuted = today, tt.todayExecuted
+				if err := e.manager.storage.Update(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			e.checkAndExecutePlan("d", nil)
+
+			p, _ := e.manager.GetPlan("d")
+			if len(p.ExecutionHistory) != 1 || p.ExecutionHistory[0].Amount != tt.wantAmount {
+				t.Fatalf("executions = %+v, want one of %s", p.ExecutionHistory, tt.wantAmount)
+			}
+			var swapTypes []string
+			var last string
+			for _, req := range server.QuoteRequests() {
+				if !req.Dry {
+					swapTypes = append(swapTypes, req.SwapType)
+					last = req.Amount
+				}
+			}
+			if strings.Join(swapTypes, ",") != strings.Join(tt.wantQuotes, ",") || last != tt.wantQuoted {
+				t.Errorf("deposit quotes = %v ending with %s, want %v ending with %s", swapTypes, last, tt.wantQuotes, tt.wantQuoted)
+			}
+		})
+	}
+}
+
+// quoteAt prices BTC -> USDC quotes at dryRate for the trigger's dry-run probes and at
+// depositRate for the real deposit quotes
+func quoteAt(dryRate, depositRate float64) mockserver.QuoteHandler {
+	return func(req oneclick.QuoteRequest) (*oneclick.QuoteResponse, int) {
+		rate := depositRate
+		if req.Dry {
+			rate = dryRate
+		}
+		amountIn, _ := strconv.ParseFloat(req.Amount, 64)
+		amountIn /= 1e8
+		amountOut := amountIn * rate
+		quote := oneclick.NewQuote(
+			req.Amount, strconv.FormatFloat(amountIn, 'f', -1, 64), "0", req.Amount,
+			strconv.FormatFloat(amountOut*1e6, 'f', 0, 64), strconv.FormatFloat(amountOut, 'f', -1, 64), "0", "0",
+			10,
+		)
+		if !req.Dry {
+			quote.SetDepositAddress(fmt.Sprintf("mock-deposit-%d", time.Now().UnixNano()))
+		}
+		return oneclick.NewQuoteResponse(time.Now(), "mock-signature", req, *quote), http.StatusOK
+	}
+}
+
+func TestExecutorAbortsDivergentQuotes(t *testing.T) {
+	e, server := newMockExecutor(t)
+	e.config.MaxQuoteDivergence = 5
+
+	// The deposit quote is 10% worse than the probe that triggered the plan
+	server.SetQuoteHandler(quoteAt(60000, 54000))
+	for check := 0; check < 3; check++ {
+		e.checkAndExecutePlan("p", nil)
+	}
+
+	p, exec := lastExecution(t, e)
+	if exec.Status != ExecutionFailed || exec.QuoteDivergence != "10.0000" || exec.DepositAddress == "" {
+		t.Errorf("execution = %s, divergence %s; want a failed abort recording 10.0000", exec.Status, exec.QuoteDivergence)
+	}
+	if len(p.ExecutionHistory) != 1 || p.ExecutionCount != 1 || exec.Aborts != 3 {
+		t.Errorf("%d executions (count %d), %d aborts; want 3 aborts folded into one execution",
+			len(p.ExecutionHistory), p.ExecutionCount, exec.Aborts)
+	}
+	if p.RemainingAmount != "0.2" || exec.Reserved {
+		t.Errorf("remaining %s (reserved %v), want the abort to leave the plan untouched", p.RemainingAmount, exec.Reserved)
+	}
+
+	// A quote within the limit trades, and a later abort starts a new record
+	server.SetQuoteHandler(quoteAt(60000, 59000))
+	e.checkAndExecutePlan("p", nil)
+	if _, exec := lastExecution(t, e); exec.Status != ExecutionPending || exec.Aborts != 0 {
+		t.Fatalf("execution = %s with %d aborts, want a pending trade", exec.Status, exec.Aborts)
+	}
+	server.SetQuoteHandler(quoteAt(60000, 54000))
+	e.checkAndExecutePlan("p", nil)
+	if p, exec := lastExecution(t, e); len(p.ExecutionHistory) != 3 || exec.Aborts != 1 {
+		t.Errorf("%d executions, last with %d aborts; want a fresh abort record", len(p.ExecutionHistory), exec.Aborts)
+	}
+}
+
+func TestExecutorQuotesCarryIntegratorMetadata(t *testing.T) {
+	tests := []struct {
+		name         string
+		referral     string
+		appFee       config.AppFeeConfig
+		wantReferral string
+		wantFees     []oneclick.AppFee
+	}{
+		{name: "none configured"},
+		{name: "referral", referral: "my-wallet", wantReferral: "my-wallet"},
+		{
+			name:         "referral and app fee",
+			referral:     "my-wallet",
+			appFee:       config.AppFeeConfig{Recipient: "fees.near", FeeBps: 25},
+			wantReferral: "my-wallet",
+			wantFees:     []oneclick.AppFee{{Recipient: "fees.near", Fee: 25}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, server := newMockExecutor(t)
+			e.config.Referral, e.config.AppFee = tt.referral, tt.appFee
+			e.checkAndExecutePlan("p", nil)
+			if _, exec := lastExecution(t, e); exec.Status != ExecutionPending {
+				t.Fatalf("execution = %s, want pending", exec.Status)
+			}
+
+			var deposit *oneclick.QuoteRequest
+			for _, req := range server.QuoteRequests() {
+				if !req.Dry {
+					deposit = &req
+				}
+			}
+			if deposit == nil {
+				t.Fatal("no deposit quote requested")
+			}
+			if deposit.GetReferral() != tt.wantReferral {
+				t.Errorf("referral = %q, want %q", deposit.GetReferral(), tt.wantReferral)
+			}
+			if fmt.Sprint(deposit.AppFees) != fmt.Sprint(tt.wantFees) {
+				t.Errorf("app fees = %+v, want %+v", deposit.AppFees, tt.wantFees)
+			}
+		})
+	}
+}
+
+func TestWarmupDelayStaggersStartup(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		wantBatches []int // Warmup batch of each plan, in start order
+	}{
+		{name: "default primes one plan at a time", wantBatches: []int{0, 1, 2, 3}},
+		{name: "one at a time", concurrency: 1, wantBatches: []int{0, 1, 2, 3}},
+		{name: "two at a time", concurrency: 2, wantBatches: []int{0, 0, 1, 1}},
+		{name: "all at once", concurrency: 4, wantBatches: []int{0, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Executor{config: &config.Config{WarmupConcurrency: tt.concurrency}}
+			for i, batch := range tt.wantBatches {
+				// Each batch starts WarmupStagger after the last, plus up to WarmupJitter
+				earliest := time.Duration(batch) * WarmupStagger
+				if delay := e.warmupDelay(i); delay < earliest || delay >= earliest+WarmupJitter {
+					t.Errorf("plan %d: delay %v, want within %v of %v", i, delay, WarmupJitter, earliest)
+				}
+			}
+		})
+	}
+}
+
+func TestExecutorRecordsRefunds(t *testing.T) {
+	e, server := newMockExecutor(t)
+	e.checkAndExecutePlan("p", nil)
+	_, exec := lastExecution(t, e)
+
+	server.QueueStatus(exec.DepositAddress, "REFUNDED")
+	if !e.checkSwapStatus("p", exec.ID, exec.DepositAddress) {
+		t.Fatal("refunded swap not reported as settled")
+	}
+
+	// Without a depositor for the source chain the refund is recorded but not verified on-chain
+	_, exec = lastExecution(t, e)
+	if exec.RefundTxHash != "mock-refund-tx-"+exec.DepositAddress || exec.RefundedAmount != "1" || exec.RefundConfirmed {
+		t.Errorf("refund tx %q of %q (confirmed %v), want the reported refund, unconfirmed",
+			exec.RefundTxHash, exec.RefundedAmount, exec.RefundConfirmed)
+	}
+}
+
+func TestExecutorStopsMidCycle(t *testing.T) {
+	tests := []struct {
+		name         string
+		stopOnDry    bool // Stop while the trigger's dry-run price is being fetched rather than the deposit quote
+		wantDeposits int  // Deposit quotes requested
+		wantFailed   bool // Whether a failed execution is left behind
+	}{
+		{name: "deleted during the price check", stopOnDry: true},
+		{name: "deleted while quoting the deposit", wantDeposits: 1, wantFailed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, server := newMockExecutor(t)
+			stop := make(chan struct{})
+			quote := quoteAt(60000, 60000)
+			server.SetQuoteHandler(func(req oneclick.QuoteRequest) (*oneclick.QuoteResponse, int) {
+				// The daemon closes the plan's stop channel when it sees the plan was deleted
+				if req.Dry == tt.stopOnDry && !stopRequested(stop) {
+					close(stop)
+				}
+				return quote(req)
+			})
+
+			e.checkAndExecutePlan("p", stop)
+
+			deposits := 0
+			for _, req := range server.QuoteRequests() {
+				if !req.Dry {
+					deposits++
+				}
+			}
+			if deposits != tt.wantDeposits || len(server.SubmittedDeposits()) != 0 {
+				t.Errorf("%d deposit quotes, %d deposits submitted; want %d quotes and no deposit",
+					deposits, len(server.SubmittedDeposits()), tt.wantDeposits)
+			}
+			p, _ := e.manager.GetPlan("p")
+			if !tt.wantFailed {
+				if len(p.ExecutionHistory) != 0 {
+					t.Errorf("executions = %+v, want none", p.ExecutionHistory)
+				}
+				return
+			}
+			_, exec := lastExecution(t, e)
+			if exec.Status != ExecutionFailed || exec.ErrorMessage != "plan stopped before deposit" || exec.Reserved {
+				t.Errorf("execution = %s (%q, reserved %v), want failed and released", exec.Status, exec.ErrorMessage, exec.Reserved)
+			}
+			if p.RemainingAmount != "0.20000000" {
+				t.Errorf("remaining %s, want the whole plan", p.RemainingAmount)
+			}
+		})
+	}
+}
+
+func TestExecutorQuotesUseConfigDefaults(t *testing.T) {
+	tests := []struct {
+		name         string
+		adjust       func(p *TradingPlan)
+		wantSlippage float32
+		wantDeadline time.Duration
+	}{
+		{name: "config defaults", adjust: func(p *TradingPlan) {}, wantSlippage: 250, wantDeadline: 30 * time.Minute},
+		{
+			name:         "plan overrides",
+			adjust:       func(p *TradingPlan) { p.SlippageBps, p.QuoteDeadline = 50, "10m" },
+			wantSlippage: 50,
+			wantDeadline: 10 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, server := newMockExecutor(t)
+			// The daemon applies default_slippage and default_deadline to its client
+			e.apiClient.SetQuoteDefaults(250, 30*time.Minute)
+			p, _ := e.manager.storage.Get("p")
+			tt.adjust(p)
+			if err := e.manager.storage.Update(p); err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+			e.checkAndExecutePlan("p", nil)
+			requests := server.QuoteRequests()
+			if len(requests) == 0 {
+				t.Fatal("no quote requested")
+			}
+			deposit := requests[len(requests)-1]
+			if deposit.Dry || deposit.SlippageTolerance != tt.wantSlippage {
+				t.Errorf("deposit quote slippage = %v bps, want %v", deposit.SlippageTolerance, tt.wantSlippage)
+			}
+			if window := deposit.Deadline.Sub(start); window < tt.wantDeadline || window > tt.wantDeadline+time.Minute {
+				t.Errorf("deposit quote deadline %s after the trade started, want %s", window, tt.wantDeadline)
+			}
+		})
+	}
+}
+
+func TestExecutorHoldsTradesAwaitingVerification(t *testing.T) {
+	e, server := newMockExecutor(t)
+	e.config.MaxUnverifiedExecutions = 1
+
+	e.checkAndExecutePlan("p", nil)
+	_, exec := lastExecution(t, e)
+	if err := e.manager.UpdateExecutionStatus("p", exec.ID, ExecutionDeposited, "deposit-tx", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// The deposit hasn't been verified yet, so the plan holds its next trade
+	e.checkAndExecutePlan("p", nil)
+	if p, _ := lastExecution(t, e); len(p.ExecutionHistory) != 1 {
+		t.Fatalf("executions = %d, want the trade held at the cap", len(p.ExecutionHistory))
+	}
+
+	// Verification frees the slot
+	server.QueueStatus(exec.DepositAddress, "SUCCESS")
+	if !e.checkSwapStatus("p", exec.ID, exec.DepositAddress) {
+		t.Fatal("successful swap not reported as settled")
+	}
+	e.checkAndExecutePlan("p", nil)
+	if p, _ := lastExecution(t, e); len(p.ExecutionHistory) != 2 {
+		t.Errorf("executions = %d, want trading to resume after verification", len(p.ExecutionHistory))
+	}
+}
+
+func TestExecutorKillSwitch(t *testing.T) {
+	tests := []struct {
+		name        string
+		cancelBelow string
+		cancelAbove string
+		wantCancel  bool
+	}{
+		{name: "price at the cancel-below price", cancelBelow: "60000", wantCancel: true},
+		{name: "price above the cancel-above price", cancelAbove: "55000", wantCancel: true},
+		{name: "kill switch not reached", cancelBelow: "50000", cancelAbove: "65000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, server := newMockExecutor(t) // Trades at 60000, inside the plan's trigger
+			p, _ := e.manager.storage.Get("p")
+			p.CancelBelow, p.CancelAbove = tt.cancelBelow, tt.cancelAbove
+			if err := e.manager.storage.Update(p); err != nil {
+				t.Fatal(err)
+			}
+
+			e.checkAndExecutePlan("p", nil)
+
+			p, err := e.manager.GetPlan("p")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.wantCancel {
+				if p.Status != StatusActive || len(p.ExecutionHistory) != 1 {
+					t.Errorf("plan %s with %d executions, want it active after one trade", p.Status, len(p.ExecutionHistory))
+				}
+				return
+			}
+			if p.Status != StatusCancelled || !strings.Contains(p.CancelReason, "kill switch") {
+				t.Errorf("plan %s (%q), want it cancelled by the kill switch", p.Status, p.CancelReason)
+			}
+			if len(p.ExecutionHistory) != 0 || len(server.SubmittedDeposits()) != 0 {
+				t.Errorf("cancelled plan traded: %d executions", len(p.ExecutionHistory))
+			}
+			for _, req := range server.QuoteRequests() {
+				if !req.Dry {
+					t.Error("cancelled plan requested a deposit quote")
+				}
+			}
+		})
+	}
+}
+
+func TestExecutorFillsLadderTranchesOnce(t *testing.T) {
+	e, server := newMockExecutor(t)
+	ladder := []LadderLevel{{Price: "60000", Fraction: 0.25}, {Price: "70000", Fraction: 0.25}, {Price: "80000", Fraction: 0.5}}
+	if _, err := e.manager.CreatePlan("l", "BTC", "USDC", "btc", "near", "1", "", "1", "", PriceAbove,
+		"me.near", testRefundAddr, "", CreatePlanOptions{Ladder: ladder, Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.manager.StartPlan("l", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// The price climbs through every level, pausing at each so a level that fired could refire
+	steps := []struct {
+		price      float64
+		wantAmount string // Tranche traded at this price; empty expects no trade
+	}{
+		{price: 55000},
+		{price: 62000, wantAmount: "0.25000000"},
+		{price: 65000},
+		{price: 75000, wantAmount: "0.25000000"},
+		{price: 75000},
+		{price: 90000, wantAmount: "0.50000000"},
+		{price: 90000},
+	}
+
+	trades := 0
+	for _, step := range steps {
+		server.SetQuoteHandler(quoteAt(step.price, step.price))
+		e.checkAndExecutePlan("l", nil)
+
+		p, err := e.manager.GetPlan("l")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if step.wantAmount == "" {
+			if len(p.ExecutionHistory) != trades {
+				t.Errorf("at %.0f: %d executions, want no new tranche", step.price, len(p.ExecutionHistory))
+			}
+			continue
+		}
+		trades++
+		if len(p.ExecutionHistory) != trades {
+			t.Fatalf("at %.0f: %d executions, want %d", step.price, len(p.ExecutionHistory), trades)
+		}
+		exec := p.ExecutionHistory[trades-1]
+		if exec.Amount != step.wantAmount {
+			t.Errorf("at %.0f: traded %s, want %s", step.price, exec.Amount, step.wantAmount)
+		}
+
+		// A tranche awaiting its deposit doesn't fire again either
+		e.checkAndExecutePlan("l", nil)
+		if p, _ := e.manager.GetPlan("l"); len(p.ExecutionHistory) != trades {
+			t.Fatalf("at %.0f: pending tranche fired again", step.price)
+		}
+		server.QueueStatus(exec.DepositAddress, "SUCCESS")
+		if !e.checkSwapStatus("l", exec.ID, exec.DepositAddress) {
+			t.Fatalf("at %.0f: swap not settled", step.price)
+		}
+	}
+
+	p, err := e.manager.GetPlan("l")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, level := range p.Ladder {
+		if !level.Filled {
+			t.Errorf("level %s not marked filled", level.Price)
+		}
+	}
+	if p.Status != StatusCompleted || p.RemainingAmount != "0" {
+		t.Errorf("plan %s with %s remaining, want the ladder to complete it", p.Status, p.RemainingAmount)
+	}
+}
+
+// useFakeBitcoinCLI points the executor's bitcoin auto-deposit at a bitcoin-cli stand-in with a
+// funded wallet that logs every command it runs, returning the command log and the audit log
+func useFakeBitcoinCLI(t *testing.T, e *Executor) (calls, auditLog string) {
+	t.Helper()
+	dir := t.TempDir()
+	cli := filepath.Join(dir, "bitcoin-cli")
+	calls, auditLog = filepath.Join(dir, "calls.log"), filepath.Join(dir, "deposits.log")
+	script := `#!/bin/sh
+echo "$*" >> '` + calls + `'
+for arg in "$@"; do
+	case "$arg" in
+	getblockchaininfo) echo '{}'; exit 0 ;;
+	getbalance) echo 10; exit 0 ;;
+	sendtoaddress) echo deadbeef; exit 0 ;;
+	esac
+done
+exit 1
+`
+	if err := os.WriteFile(cli, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	e.config.AutoDeposit = config.AutoDepositConfig{Enabled: true, AuditLogPath: auditLog,
+		Bitcoin: config.BitcoinConfig{Enabled: true, CLIPath: cli}}
+	return calls, auditLog
+}
+
+func TestExecutorDepositsTheClampedAmount(t *testing.T) {
+	e, _ := newMockExecutor(t)
+	calls, _ := useFakeBitcoinCLI(t, e)
+
+	// Only 0.05 of the plan is left, less than its 0.1 per trade
+	p, _ := e.manager.storage.Get("p")
+	p.TotalExecuted, p.RemainingAmount = "0.15", "0.05"
+	if err := e.manager.storage.Update(p); err != nil {
+		t.Fatal(err)
+	}
+
+	e.checkAndExecutePlan("p", nil)
+
+	_, exec := lastExecution(t, e)
+	if exec.Status != ExecutionDeposited || exec.Amount != "0.05000000" || exec.TxHash != "deadbeef" {
+		t.Fatalf("execution %s of %s (tx %q), want the clamped 0.05 deposited", exec.Status, exec.Amount, exec.TxHash)
+	}
+	sent, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(sent), "sendtoaddress address="+exec.DepositAddress+" amount=0.05000000") {
+		t.Errorf("bitcoin-cli calls:\n%s\nwant a send of the clamped 0.05", sent)
+	}
+}
+
+func TestExecutorAuditsEachDeposit(t *testing.T) {
+	e, _ := newMockExecutor(t) // 0.1 per trade, 0.2 per day
+	_, auditLog := useFakeBitcoinCLI(t, e)
+	e.config.AutoDeposit.Solana = config.SolanaConfig{PrivateKey: "solana-secret-key"}
+
+	start := time.Now().UTC()
+	e.checkAndExecutePlan("p", nil)
+	e.checkAndExecutePlan("p", nil)
+	e.checkAndExecutePlan("p", nil) // Over the daily limit, so nothing is sent
+
+	data, err := os.ReadFile(auditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "solana-secret-key") {
+		t.Errorf("audit log leaks a private key:\n%s", data)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	p, _ := e.manager.GetPlan("p")
+	if len(lines) != 2 || len(p.ExecutionHistory) != 2 {
+		t.Fatalf("%d audit records for %d executions, want one for each of 2 deposits:\n%s", len(lines), len(p.ExecutionHistory), data)
+	}
+	for i, line := range lines {
+		var record deposit.AuditRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("record %d: %v", i+1, err)
+		}
+		exec := p.ExecutionHistory[i]
+		want := deposit.AuditRecord{Timestamp: record.Timestamp, Source: "plan", Chain: "btc", Token: "BTC", Amount: exec.Amount,
+			ToAddress: exec.DepositAddress, TxID: "deadbeef", PlanName: "p", ExecutionID: exec.ID}
+		if record != want || record.Timestamp.Before(start) {
+			t.Errorf("record %d = %+v, want %+v after %v", i+1, record, want, start)
+		}
+	}
+}
+
+func TestExecutorSmoothsPriceSpikes(t *testing.T) {
+	e, server := newMockExecutor(t) // Triggers below 70000
+	p, _ := e.manager.storage.Get("p")
+	p.PriceSmoothing = 5
+	if err := e.manager.storage.Update(p); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		price     float64
+		wantTrade bool
+	}{
+		{price: 75000}, {price: 75000}, {price: 75000}, {price: 75000},
+		{price: 60000}, // A one-check spike averages to 72000
+		{price: 75000}, {price: 75000}, {price: 75000}, {price: 75000}, {price: 75000},
+		{price: 60000},                  // 72000
+		{price: 60000, wantTrade: true}, // A sustained move averages to 69000
+	}
+
+	trades := 0
+	for i, step := range steps {
+		server.SetQuoteHandler(quoteAt(step.price, step.price))
+		e.checkAndExecutePlan("p", nil)
+		if step.wantTrade {
+			trades++
+		}
+		p, err := e.manager.GetPlan("p")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p.ExecutionHistory) != trades {
+			t.Fatalf("check %d at %.0f: %d trades, want %d", i+1, step.price, len(p.ExecutionHistory), trades)
+		}
+	}
+
+	// The samples are saved with the plan, and only as many as the window needs
+	p, _ = e.manager.GetPlan("p")
+	if len(p.PriceSamples) != 5 {
+		t.Errorf("%d price samples saved, want 5", len(p.PriceSamples))
+	}
+}
+
+func TestReconcilePlanBeforeResuming(t *testing.T) {
+	e, server := newMockExecutor(t)
+	p, _ := e.manager.storage.Get("p")
+	p.TotalAmount, p.RemainingAmount, p.AmountPerDay = "1", "1", "1"
+	if err := e.manager.storage.Update(p); err != nil {
+		t.Fatal(err)
+	}
+
+	// Three trades are left unsettled when the plan is stopped
+	for trade := 0; trade < 3; trade++ {
+		e.checkAndExecutePlan("p", nil)
+	}
+	if err := e.manager.StopPlan("p"); err != nil {
+		t.Fatal(err)
+	}
+	p, _ = e.manager.GetPlan("p")
+	statuses := []string{"SUCCESS", "FAILED", "PROCESSING"}
+	for i, exec := range p.ExecutionHistory {
+		server.QueueStatus(exec.DepositAddress, statuses[i])
+	}
+
+	quotes := server.RequestCount("/v0/quote")
+	result, err := e.ReconcilePlan("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Checked != 3 || result.Settled != 2 {
+		t.Errorf("reconciled %d with %d settled, want 3 with 2 settled", result.Checked, result.Settled)
+	}
+	if n := server.RequestCount("/v0/quote") - quotes; n != 0 {
+		t.Errorf("%d quotes requested while reconciling, want none", n)
+	}
+
+	// The failed trade's amount is back in the plan before it can trade again
+	p, _ = e.manager.GetPlan("p")
+	want := []ExecutionStatus{ExecutionCompleted, ExecutionFailed, ExecutionPending}
+	for i, exec := range p.ExecutionHistory {
+		if exec.Status != want[i] {
+			t.Errorf("execution %d is %s, want %s", i+1, exec.Status, want[i])
+		}
+	}
+	if p.Status != StatusPaused || p.RemainingAmount != "0.80000000" {
+		t.Errorf("plan %s with %s remaining, want it still stopped with 0.80000000", p.Status, p.RemainingAmount)
+	}
+}
+
+func TestVerificationSchedule(t *testing.T) {
+	tests := []struct {
+		name                  string
+		estimate              time.Duration
+		wantDelay, wantWindow time.Duration
+	}{
+		{name: "no estimate", estimate: 0, wantDelay: SwapPollInterval, wantWindow: MinVerificationWindow},
+		{name: "faster than a poll", estimate: 10 * time.Second, wantDelay: SwapPollInterval, wantWindow: MinVerificationWindow},
+		{name: "slow route", estimate: 20 * time.Minute, wantDelay: 20 * time.Minute, wantWindow: 80 * time.Minute},
+		{name: "past the delay cap", estimate: 2 * time.Hour, wantDelay: MaxInitialPollDelay, wantWindow: 8 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, window := verificationSchedule(tt.estimate)
+			if delay != tt.wantDelay || window != tt.wantWindow {
+				t.Errorf("verificationSchedule(%s) = %s, %s; want %s, %s", tt.estimate, delay, window, tt.wantDelay, tt.wantWindow)
+			}
+		})
+	}
+}
+
+func TestExecutorDefersPollingSlowSwaps(t *testing.T) {
+	e, server := newMockExecutor(t)
+	e.checkAndExecutePlan("p", nil)
+	_, exec := lastExecution(t, e)
+	if exec.TimeEstimate != 10 {
+		t.Fatalf("time estimate = %v, want the quote's 10 seconds", exec.TimeEstimate)
+	}
+
+	// A route quoted at ten minutes isn't polled two minutes in, but is once the estimate has passed
+	p, _ := e.manager.storage.Get("p")
+	p.ExecutionHistory[0].TimeEstimate = 600
+	p.ExecutionHistory[0].Timestamp = time.Now().Add(-2 * time.Minute)
+	if err := e.manager.storage.Update(p); err != nil {
+		t.Fatal(err)
+	}
+	polls := server.RequestCount("/v0/status")
+	e.verifyPendingSwaps()
+	if n := server.RequestCount("/v0/status") - polls; n != 0 {
+		t.Errorf("%d status polls before the estimate passed, want none", n)
+	}
+
+	p, _ = e.manager.storage.Get("p")
+	p.ExecutionHistory[0].Timestamp = time.Now().Add(-11 * time.Minute)
+	if err := e.manager.storage.Update(p); err != nil {
+		t.Fatal(err)
+	}
+	server.QueueStatus(exec.DepositAddress, "SUCCESS")
+	e.verifyPendingSwaps()
+	if _, exec := lastExecution(t, e); exec.Status != ExecutionCompleted {
+		t.Errorf("execution is %s, want it polled and completed once the estimate passed", exec.Status)
+	}
+}
+
+func TestExecutorPausesOnDestinationFailures(t *testing.T) {
+	e, server := newMockExecutor(t)
+	e.config.MaxDestinationFailures = 2
+	p, _ := e.manager.storage.Get("p")
+	p.TotalAmount, p.RemainingAmount, p.AmountPerDay = "1", "1", "1"
+	if err := e.manager.storage.Update(p); err != nil {
+		t.Fatal(err)
+	}
+
+	// A success between refunds resets the run, so only the last two refunds in a row pause the plan
+	outcomes := []string{"REFUNDED", "SUCCESS", "REFUNDED", "REFUNDED"}
+	for i, outcome := range outcomes {
+		if p, _ := e.manager.GetPlan("p"); p.Status != StatusActive {
+			t.Fatalf("plan %s before trade %d, want it still trading", p.Status, i+1)
+		}
+		e.checkAndExecutePlan("p", nil)
+		_, exec := lastExecution(t, e)
+		server.QueueStatus(exec.DepositAddress, outcome)
+		e.checkSwapStatus("p", exec.ID, exec.DepositAddress)
+	}
+
+	p, _ = e.manager.GetPlan("p")
+	if p.Status != StatusPaused || p.PauseReason != destinationPauseReason || p.DestinationFailures != 2 {
+		t.Errorf("plan %s (%q) after %d destination failures, want paused for %q",
+			p.Status, p.PauseReason, p.DestinationFailures, destinationPauseReason)
+	}
+
+	// Resuming starts a fresh run
+	if err := e.manager.StartPlan("p", false); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := e.manager.GetPlan("p"); p.DestinationFailures != 0 || p.PauseReason != "" {
+		t.Errorf("resumed plan has %d failures, reason %q; want both cleared", p.DestinationFailures, p.PauseReason)
+	}
+}
+
+func TestExecutorCompletesDustRemainders(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining string // Worth 60000 USDC per BTC against a 10 USDC threshold
+		wantTrade bool
+	}{
+		{name: "dust remainder completes the plan", remaining: "0.0001"},
+		{name: "remainder worth a trade", remaining: "0.001", wantTrade: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, server := newMockExecutor(t)
+			p, _ := e.manager.storage.Get("p")
+			p.DustThreshold = "10"
+			p.RemainingAmount = tt.remaining
+			if err := e.manager.storage.Update(p); err != nil {
+				t.Fatal(err)
+			}
+
+			e.checkAndExecutePlan("p", nil)
+
+			p, _ = e.manager.GetPlan("p")
+			if tt.wantTrade {
+				if len(p.ExecutionHistory) != 1 || p.CompletionReason != "" {
+					t.Errorf("%d executions, completion reason %q; want the remainder traded", len(p.ExecutionHistory), p.CompletionReason)
+				}
+				return
+			}
+			if len(p.ExecutionHistory) != 0 || len(server.SubmittedDeposits()) != 0 {
+				t.Errorf("%d executions recorded for a dust remainder, want none", len(p.ExecutionHistory))
+			}
+			if p.Status != StatusCompleted || !strings.HasPrefix(p.CompletionReason, "dust remaining: 0.0001 BTC is worth 6 USDC") {
+				t.Errorf("plan %s (%q), want it completed with the dust remaining", p.Status, p.CompletionReason)
+			}
+			if p.RemainingAmount != tt.remaining {
+				t.Errorf("remaining = %s, want the dust %s left on record", p.RemainingAmount, tt.remaining)
+			}
+		})
+	}
+}
+
+func TestStartResumesInFlightSwaps(t *testing.T) {
+	e, _ := newMockExecutor(t)
+
+	type started struct {
+		id, address string
+		estimate    time.Duration
+	}
+	var mu sync.Mutex
+	var verifiers []started
+	e.startVerifier = func(planName, executionID, depositAddress string, estimate time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		verifiers = append(verifiers, started{id: executionID, address: depositAddress, estimate: estimate})
+	}
+
+	// A paused plan left with swaps in every state by a daemon that died, one of them older
+	// than the periodic sweep looks
+	p, err := e.manager.storage.Get("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Status = StatusPaused
+	now := time.Now()
+	p.ExecutionHistory = []Execution{
+		{ID: "old-pending", Status: ExecutionPending, DepositAddress: "deposit-1", TimeEstimate: 600, Timestamp: now.Add(-48 * time.Hour)},
+		{ID: "deposited", Status: ExecutionDeposited, DepositAddress: "deposit-2", TimeEstimate: 600, Timestamp: now.Add(-100 * time.Second)},
+		{ID: "completed", Status: ExecutionCompleted, DepositAddress: "deposit-3", Timestamp: now},
+		{ID: "failed", Status: ExecutionFailed, DepositAddress: "deposit-4", Timestamp: now},
+		{ID: "never-quoted", Status: ExecutionPending, Timestamp: now},
+	}
+	if err := e.manager.storage.Update(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Start(); err != nil {
+		t.Fatal(err)
+	}
+	e.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(verifiers) != 2 {
+		t.Fatalf("started verifiers %+v, want one each for old-pending and deposited", verifiers)
+	}
+	byID := map[string]started{}
+	for _, v := range verifiers {
+		byID[v.id] = v
+	}
+	// Time spent while the daemon was down counts toward the estimate
+	if v, ok := byID["old-pending"]; !ok || v.address != "deposit-1" || v.estimate > 0 {
+		t.Errorf("old pending swap verifier = %+v, want deposit-1 checked on the first poll", v)
+	}
+	if v, ok := byID["deposited"]; !ok || v.address != "deposit-2" || v.estimate <= 0 || v.estimate > 500*time.Second {
+		t.Errorf("deposited swap verifier = %+v, want deposit-2 with about 500s of its estimate left", v)
+	}
+}
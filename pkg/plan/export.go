@@ -0,0 +1,161 @@
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// ExportVersion is the version of the document written by ExportPlans
+const ExportVersion = 1
+
+// ExportNotice is stored in every export so a backup isn't mistaken for harmless data
+const ExportNotice = "contains recipient, refund and withdrawal addresses; store it privately"
+
+// PlanExport is a portable backup of plans, including their execution history
+type PlanExport struct {
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Notice     string         `json:"notice"`
+	Plans      []*TradingPlan `json:"plans"`
+}
+
+// ExportPlans collects plans for backup, sorted by name. names limits the export to those
+// plans and status to plans with that status; either may be empty to include everything.
+func (m *Manager) ExportPlans(names []string, status PlanStatus) (*PlanExport, error) {
+	var plans []*TradingPlan
+	if len(names) > 0 {
+		for _, name := range names {
+			p, err := m.storage.Get(name)
+			if err != nil {
+				return nil, err
+			}
+			plans = append(plans, p)
+		}
+	} else {
+		plans = m.storage.List()
+	}
+
+	export := &PlanExport{
+		Version:    ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Notice:     ExportNotice,
+		Plans:      make([]*TradingPlan, 0, len(plans)),
+	}
+	for _, p := range plans {
+		if status == "" || p.Status == status {
+			export.Plans = append(export.Plans, p)
+		}
+	}
+	sort.Slice(export.Plans, func(i, j int) bool {
+		return export.Plans[i].Name < export.Plans[j].Name
+	})
+
+	return export, nil
+}
+
+// LoadPlanExport reads a document written by `plan export`
+func LoadPlanExport(path string) (*PlanExport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+
+	var export PlanExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid export %s: %w", path, err)
+	}
+	if export.Version != ExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d in %s (expected %d)", export.Version, path, ExportVersion)
+	}
+
+	return &export, nil
+}
+
+// ImportResult is what ImportPlans did with one plan
+type ImportResult struct {
+	Plan      string `json:"plan"`
+	Imported  bool   `json:"imported"`
+	Overwrote bool   `json:"overwrote,omitempty"` // An existing plan of the same name was replaced
+	Detail    string `json:"detail,omitempty"`    // Why a plan was skipped, or what changed on import
+}
+
+// ImportPlans stores the plans in export, in order. Plans whose name is taken are skipped
+// unless overwrite is set; an active plan is never overwritten. Active plans are imported
+// paused so restoring a backup never starts trading on its own.
+func (m *Manager) ImportPlans(export *PlanExport, overwrite bool) []ImportResult {
+	results := make([]ImportResult, 0, len(export.Plans))
+	seen := make(map[string]bool)
+	for _, p := range export.Plans {
+		if p == nil {
+			continue
+		}
+		if seen[p.Name] {
+			results = append(results, ImportResult{Plan: p.Name, Detail: "duplicate name in the export"})
+			continue
+		}
+		seen[p.Name] = true
+		results = append(results, m.importPlan(p, overwrite))
+	}
+	return results
+}
+
+// importPlan validates and stores one imported plan
+func (m *Manager) importPlan(p *TradingPlan, overwrite bool) ImportResult {
+	result := ImportResult{Plan: p.Name}
+	if err := ValidatePlanName(p.Name); err != nil {
+		result.Detail = err.Error()
+		return result
+	}
+	if err := p.Validate(); err != nil {
+		result.Detail = fmt.Sprintf("invalid plan: %v", err)
+		return result
+	}
+
+	defer m.lockPlan(p.Name)()
+
+	existing, err := m.storage.Get(p.Name)
+	switch {
+	case errors.Is(err, ErrPlanNotFound):
+		if count := m.storage.Count(); m.maxPlans > 0 && count >= m.maxPlans {
+			result.Detail = fmt.Sprintf("plan limit reached: %d plans stored (max_plans is %d)", count, m.maxPlans)
+			return result
+		}
+	case err != nil:
+		result.Detail = err.Error()
+		return result
+	case !overwrite:
+		result.Detail = "a plan with this name already exists (use --overwrite to replace it)"
+		return result
+	case existing.Status == StatusActive:
+		result.Detail = "the existing plan is active; stop it before overwriting"
+		return result
+	default:
+		result.Overwrote = true
+	}
+
+	if p.Status == StatusActive {
+		p.Status = StatusPaused
+		result.Detail = "imported paused; start it with `plan start`"
+	}
+	if p.ExecutionHistory == nil {
+		p.ExecutionHistory = []Execution{}
+	}
+
+	if result.Overwrote {
+		err = m.storage.Update(p)
+	} else {
+		err = m.storage.Create(p)
+	}
+	if err != nil {
+		result.Overwrote = false
+		result.Detail = err.Error()
+		return result
+	}
+
+	result.Imported = true
+	return result
+}
@@ -0,0 +1,60 @@
+package plan
+
+import (
+	"time"
+)
+
+// DefaultFollowInterval is how often storage is polled when following a plan's history
+const DefaultFollowInterval = 2 * time.Second
+
+// FollowExecutions polls storage and calls emit, in history order, for each execution that
+// is added to the plan after the call starts. It returns when stop is closed, the plan
+// completes or is cancelled (after emitting its final executions), or the plan is deleted,
+// in which case the error wraps ErrPlanNotFound. The returned plan is the last one read.
+func (m *Manager) FollowExecutions(name string, interval time.Duration, stop <-chan struct{}, emit func(Execution)) (*TradingPlan, error) {
+	if interval <= 0 {
+		interval = DefaultFollowInterval
+	}
+
+	plan, err := m.storage.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(plan.ExecutionHistory))
+	for _, exec := range plan.ExecutionHistory {
+		seen[exec.ID] = true
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if plan.Status == StatusCompleted || plan.Status == StatusCancelled {
+			return plan, nil
+		}
+
+		select {
+		case <-stop:
+			return plan, nil
+		case <-ticker.C:
+		}
+
+		if err := m.storage.Reload(); err != nil {
+			return plan, err
+		}
+
+		plan, err = m.storage.Get(name)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, exec := range plan.ExecutionHistory {
+			if seen[exec.ID] {
+				continue
+			}
+			seen[exec.ID] = true
+			emit(exec)
+		}
+	}
+}
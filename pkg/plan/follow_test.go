@@ -0,0 +1,104 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollowExecutions(t *testing.T) {
+	tests := []struct {
+		name      string
+		finish    func(m *Manager) error // How the daemon ends the plan after recording the new executions
+		waitToEnd bool                   // End the plan only once the follower printed the new executions
+		wantErr   error
+		wantFinal PlanStatus
+	}{
+		{
+			name:      "plan completes",
+			finish:    func(m *Manager) error { return m.CompletePlanWithReason("p", "dust remaining") },
+			wantFinal: StatusCompleted,
+		},
+		{
+			name:      "plan is deleted",
+			waitToEnd: true,
+			finish: func(m *Manager) error {
+				if err := m.PausePlan("p", ""); err != nil {
+					return err
+				}
+				return m.DeletePlan("p")
+			},
+			wantErr: ErrPlanNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "plans.json")
+			follower, err := NewManager(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := follower.CreatePlan("p", "BTC", "USDC", "btc", "near", "1", "0.1", "1", "70000", PriceBelow,
+				"me.near", testRefundAddr, "", CreatePlanOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if err := follower.StartPlan("p", false); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := follower.AddExecution("p", Execution{Amount: "0.1", DepositAddress: "before", Status: ExecutionPending}); err != nil {
+				t.Fatal(err)
+			}
+
+			// The daemon records executions through its own manager on the same file
+			daemon, err := NewManager(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			done, caughtUp := make(chan error, 1), make(chan struct{})
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				for i := 0; i < 3; i++ {
+					if _, err := daemon.AddExecution("p", Execution{Amount: "0.1", DepositAddress: fmt.Sprintf("after-%d", i), Status: ExecutionPending}); err != nil {
+						done <- err
+						return
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+				if tt.waitToEnd {
+					select {
+					case <-caughtUp:
+					case <-time.After(5 * time.Second):
+					}
+				}
+				done <- tt.finish(daemon)
+			}()
+
+			var emitted []string
+			stop := make(chan struct{})
+			timeout := time.AfterFunc(5*time.Second, func() { close(stop) })
+			defer timeout.Stop()
+			final, err := follower.FollowExecutions("p", 5*time.Millisecond, stop, func(exec Execution) {
+				emitted = append(emitted, exec.DepositAddress)
+				if len(emitted) == 3 {
+					close(caughtUp)
+				}
+			})
+			if daemonErr := <-done; daemonErr != nil {
+				t.Fatal(daemonErr)
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (final == nil || final.Status != tt.wantFinal) {
+				t.Errorf("final plan = %+v, want %s", final, tt.wantFinal)
+			}
+			if fmt.Sprint(emitted) != "[after-0 after-1 after-2]" {
+				t.Errorf("emitted %v, want the three new executions in order", emitted)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"near-swap/pkg/types"
+)
+
+// PriceImpactLimit returns the most a full trade's price may be worse than the probe price:
+// the plan's own limit if it has one, otherwise configured. 0 means the impact isn't checked.
+func (tp *TradingPlan) PriceImpactLimit(configured float64) float64 {
+	if tp.MaxPriceImpact > 0 {
+		return tp.MaxPriceImpact
+	}
+	return configured
+}
+
+// fullTradeAmount returns the size of the trade the plan would make at price: the per-trade
+// amount (in dest tokens for dest-sized plans) or the remaining tranche of the crossed ladder level
+func (tp *TradingPlan) fullTradeAmount(price float64) (string, error) {
+	switch {
+	case tp.IsDestSized():
+		return tp.AmountPerTradeDest, nil
+	case tp.HasLadder():
+		index := tp.NextLadderLevel(price)
+		if index < 0 {
+			return "", fmt.Errorf("no ladder level crossed at %.8f", price)
+		}
+		return fmt.Sprintf("%.8f", tp.LadderRemaining(tp.Ladder[index])), nil
+	default:
+		return tp.AmountPerTrade, nil
+	}
+}
+
+// MeasurePriceImpact quotes a full-size trade and sets info.PriceImpact to how much worse, in
+// percent, its price is than the small probe quote info was priced with. Shallow pools show
+// up as a large impact; a negative impact means the probe paid proportionally more in fees.
+func (p *Pricer) MeasurePriceImpact(ctx context.Context, plan *TradingPlan, info *PriceInfo) error {
+	if info.PriceFloat <= 0 {
+		return fmt.Errorf("no probe price to compare against")
+	}
+
+	amount, err := plan.fullTradeAmount(info.triggerValue())
+	if err != nil {
+		return err
+	}
+	if _, err := strconv.ParseFloat(amount, 64); err != nil {
+		return fmt.Errorf("invalid trade amount '%s': %w", amount, err)
+	}
+
+	quote, err := p.client.GetQuoteContext(ctx, &types.SwapRequest{
+		Amount:        amount,
+		SourceToken:   plan.SourceToken,
+		DestToken:     plan.DestToken,
+		SourceChain:   plan.SourceChain,
+		DestChain:     plan.DestChain,
+		RecipientAddr: plan.RecipientAddr,
+		RefundAddr:    plan.RefundAddr,
+		ExactOutput:   plan.IsDestSized(),
+		SlippageBps:   plan.SlippageBps,
+		Deadline:      plan.quoteDeadline(),
+		Dry:           true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get full-size quote: %w", err)
+	}
+
+	quoteDetails := quote.GetQuote()
+	fullPrice, err := quotePrice(&quoteDetails)
+	if err != nil {
+		return err
+	}
+
+	info.FullTradePrice = fullPrice
+	info.PriceImpact = (info.PriceFloat - fullPrice) / info.PriceFloat * 100
+	return nil
+}
+
+// GetPriceWithImpact fetches the probe price like GetPriceContext and also quotes a full-size
+// trade to measure its price impact
+func (p *Pricer) GetPriceWithImpact(ctx context.Context, plan *TradingPlan) (*PriceInfo, error) {
+	info, err := p.GetPriceContext(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.MeasurePriceImpact(ctx, plan, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// checkPriceImpact measures a triggered plan's price impact when it has a limit, and returns
+// why the trade should be skipped, or an empty string if it can go ahead
+func (e *Executor) checkPriceImpact(pricer *Pricer, plan *TradingPlan, info *PriceInfo) (string, error) {
+	limit := plan.PriceImpactLimit(e.config.MaxPriceImpact)
+	if limit <= 0 {
+		return "", nil
+	}
+
+	if err := pricer.MeasurePriceImpact(context.Background(), plan, info); err != nil {
+		return "", err
+	}
+	if info.PriceImpact <= limit {
+		return "", nil
+	}
+	return fmt.Sprintf("a full trade would price at %.8f %s/%s, %.2f%% worse than the probe price %s (max %.2f%%)",
+		info.FullTradePrice, plan.DestToken, plan.SourceToken, info.PriceImpact, info.Price, limit), nil
+}
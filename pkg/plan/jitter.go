@@ -0,0 +1,44 @@
+package plan
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// MaxAmountJitter is the largest per-trade amount jitter a plan may use, in percent
+const MaxAmountJitter = 25.0
+
+// validateJitter checks that a jitter percentage is within range
+func validateJitter(percent float64) error {
+	if percent < 0 || percent > MaxAmountJitter {
+		return fmt.Errorf("amount jitter must be between 0 and %.0f%%", MaxAmountJitter)
+	}
+	return nil
+}
+
+// HasJitter returns true if the plan randomizes its per-trade amount
+func (tp *TradingPlan) HasJitter() bool {
+	return tp.AmountJitter > 0
+}
+
+// jitteredAmount returns the per-trade amount scaled by a random factor within ±AmountJitter%
+func (tp *TradingPlan) jitteredAmount(amountPerTrade float64) float64 {
+	return applyJitter(amountPerTrade, tp.AmountJitter, rand.Float64())
+}
+
+// applyJitter scales amount by a factor in [1-percent%, 1+percent%], using r in [0, 1) to pick it
+func applyJitter(amount, percent, r float64) float64 {
+	return amount * (1 + percent/100*(2*r-1))
+}
+
+// settleJitter keeps jittered trades converging on the plan total. amount has already been
+// clamped to sourceLimit; if the trade would leave less of the total than a jittered trade can
+// undershoot by, the rest is folded into this trade (when the daily limit allows) so the plan
+// never finishes on a dust-sized execution.
+func settleJitter(amount, amountPerTrade, percent, sourceLimit, remainingTotal float64) float64 {
+	sliver := amountPerTrade * percent / 100
+	if remainingTotal-amount < sliver && remainingTotal <= sourceLimit {
+		return remainingTotal
+	}
+	return amount
+}
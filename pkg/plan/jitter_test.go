@@ -0,0 +1,74 @@
+package plan
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValidateJitter(t *testing.T) {
+	tests := []struct {
+		percent float64
+		wantErr bool
+	}{
+		{percent: 0},
+		{percent: 10},
+		{percent: MaxAmountJitter},
+		{percent: -1, wantErr: true},
+		{percent: MaxAmountJitter + 0.1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := validateJitter(tt.percent); (err != nil) != tt.wantErr {
+			t.Errorf("validateJitter(%v) = %v, want error: %v", tt.percent, err, tt.wantErr)
+		}
+	}
+}
+
+func TestApplyJitter(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  float64
+		percent float64
+		r       float64
+		want    float64
+	}{
+		{name: "no jitter", amount: 0.1, percent: 0, r: 0.9, want: 0.1},
+		{name: "lowest draw", amount: 0.1, percent: 10, r: 0, want: 0.09},
+		{name: "middle draw", amount: 0.1, percent: 10, r: 0.5, want: 0.1},
+		{name: "high draw", amount: 0.1, percent: 10, r: 0.75, want: 0.105},
+		{name: "widest range", amount: 2, percent: MaxAmountJitter, r: 0, want: 1.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyJitter(tt.amount, tt.percent, tt.r); math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("applyJitter(%v, %v, %v) = %v, want %v", tt.amount, tt.percent, tt.r, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettleJitter(t *testing.T) {
+	tests := []struct {
+		name           string
+		amount         float64
+		sourceLimit    float64
+		remainingTotal float64
+		want           float64
+	}{
+		{name: "plenty left", amount: 0.1, sourceLimit: 0.5, remainingTotal: 1, want: 0.1},
+		{name: "leaves more than a sliver", amount: 0.1, sourceLimit: 0.5, remainingTotal: 0.12, want: 0.1},
+		{name: "folds in the rest", amount: 0.1, sourceLimit: 0.5, remainingTotal: 0.105, want: 0.105},
+		{name: "takes the whole remainder", amount: 0.08, sourceLimit: 0.5, remainingTotal: 0.08, want: 0.08},
+		{name: "daily limit too small to fold", amount: 0.1, sourceLimit: 0.1, remainingTotal: 0.105, want: 0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A 10% jitter on 0.1 per trade can undershoot by 0.01
+			if got := settleJitter(tt.amount, 0.1, 10, tt.sourceLimit, tt.remainingTotal); got != tt.want {
+				t.Errorf("settleJitter = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
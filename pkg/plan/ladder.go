@@ -0,0 +1,201 @@
+package plan
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ladderTolerance absorbs floating point error when comparing tranche amounts and fractions
+const ladderTolerance = 0.00000001
+
+// LadderLevel is one price level of a laddered plan. When the price crosses it, the plan
+// trades Fraction of its total amount, once.
+type LadderLevel struct {
+	Price    string  `json:"price"`              // Price at which this tranche fires
+	Fraction float64 `json:"fraction"`           // Share of the total amount traded at this level (0-1]
+	Executed string  `json:"executed,omitempty"` // Source amount deposited for this level so far
+	Filled   bool    `json:"filled,omitempty"`   // Tranche fully traded; never fires again
+}
+
+// ParseLadder parses a ladder spec such as "above 150000:25%,160000:25%,175000:50%".
+// Fractions may be percentages or decimals ("0.25"). The returned levels are ordered in
+// the direction the price must move to cross them.
+func ParseLadder(spec string) (PriceCondition, []LadderLevel, error) {
+	spec = strings.TrimSpace(spec)
+	direction, rest, found := strings.Cut(spec, " ")
+	if !found {
+		return "", nil, fmt.Errorf("ladder must be in format '<above|below> <price>:<fraction>,...' (e.g., 'above 150000:25%%,160000:75%%')")
+	}
+
+	var condition PriceCondition
+	switch strings.ToLower(direction) {
+	case "above", ">":
+		condition = PriceAbove
+	case "below", "<":
+		condition = PriceBelow
+	default:
+		return "", nil, fmt.Errorf("invalid ladder direction '%s', must be 'above' or 'below'", direction)
+	}
+
+	var levels []LadderLevel
+	for _, entry := range strings.Split(rest, ",") {
+		price, fractionStr, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found {
+			return "", nil, fmt.Errorf("invalid ladder level '%s', expected <price>:<fraction>", strings.TrimSpace(entry))
+		}
+
+		fraction, err := parseFraction(fractionStr)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid fraction for ladder level %s: %w", price, err)
+		}
+
+		levels = append(levels, LadderLevel{Price: strings.TrimSpace(price), Fraction: fraction})
+	}
+
+	if err := validateLadder(levels); err != nil {
+		return "", nil, err
+	}
+
+	sortLadder(condition, levels)
+	return condition, levels, nil
+}
+
+// parseFraction parses "25%" or "0.25" into a fraction in (0, 1]
+func parseFraction(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	percent := strings.HasSuffix(s, "%")
+	value, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, err
+	}
+	if percent {
+		value /= 100
+	}
+	if value <= 0 || value > 1 {
+		return 0, fmt.Errorf("must be between 0 and 100%%")
+	}
+	return value, nil
+}
+
+// validateLadder checks level prices and that fractions don't add up to more than the whole
+func validateLadder(levels []LadderLevel) error {
+	if len(levels) == 0 {
+		return fmt.Errorf("ladder needs at least one level")
+	}
+
+	seen := make(map[float64]bool, len(levels))
+	sum := 0.0
+	for _, level := range levels {
+		if err := validateAmount(level.Price); err != nil {
+			return fmt.Errorf("invalid ladder price '%s': %w", level.Price, err)
+		}
+		price, _ := strconv.ParseFloat(level.Price, 64)
+		if seen[price] {
+			return fmt.Errorf("duplicate ladder price %s", level.Price)
+		}
+		seen[price] = true
+
+		if level.Fraction <= 0 || level.Fraction > 1 {
+			return fmt.Errorf("ladder fraction for %s must be between 0 and 100%%", level.Price)
+		}
+		sum += level.Fraction
+	}
+
+	if sum > 1+ladderTolerance {
+		return fmt.Errorf("ladder fractions add up to %.2f%%, more than 100%%", sum*100)
+	}
+	return nil
+}
+
+// sortLadder orders levels in the direction the price must move to cross them:
+// ascending for "above" ladders, descending for "below" ladders
+func sortLadder(condition PriceCondition, levels []LadderLevel) {
+	sort.Slice(levels, func(i, j int) bool {
+		pi, _ := strconv.ParseFloat(levels[i].Price, 64)
+		pj, _ := strconv.ParseFloat(levels[j].Price, 64)
+		if condition == PriceBelow {
+			return pi > pj
+		}
+		return pi < pj
+	})
+}
+
+// HasLadder returns true if the plan trades in tranches at multiple price levels
+func (tp *TradingPlan) HasLadder() bool {
+	return len(tp.Ladder) > 0
+}
+
+// LadderRemaining returns the source amount still to trade at a ladder level
+func (tp *TradingPlan) LadderRemaining(level LadderLevel) float64 {
+	total, _ := strconv.ParseFloat(tp.TotalAmount, 64)
+	executed, _ := strconv.ParseFloat(level.Executed, 64)
+	remaining := total*level.Fraction - executed
+	if remaining < ladderTolerance {
+		return 0
+	}
+	return remaining
+}
+
+// NextLadderLevel returns the index of the first unfilled level the price has crossed,
+// or -1 when no tranche should fire
+func (tp *TradingPlan) NextLadderLevel(price float64) int {
+	for i, level := range tp.Ladder {
+		if level.Filled {
+			continue
+		}
+		levelPrice, err := strconv.ParseFloat(level.Price, 64)
+		if err != nil {
+			continue
+		}
+		if (tp.PriceCondition == PriceAbove && price >= levelPrice) ||
+			(tp.PriceCondition == PriceBelow && price <= levelPrice) {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextTranche returns the source amount still to trade at the first unfilled ladder level,
+// or "0" when every level is filled
+func (tp *TradingPlan) nextTranche() string {
+	for _, level := range tp.Ladder {
+		if !level.Filled {
+			return fmt.Sprintf("%.8f", tp.LadderRemaining(level))
+		}
+	}
+	return "0"
+}
+
+// recordLadderFill adds a deposited amount to the ladder level at price and marks the
+// level filled once its tranche is fully traded
+func (tp *TradingPlan) recordLadderFill(price string, amount *big.Rat) {
+	for i := range tp.Ladder {
+		if tp.Ladder[i].Price != price {
+			continue
+		}
+		executed, err := parseDecimal(tp.Ladder[i].Executed)
+		if err != nil {
+			executed = new(big.Rat)
+		}
+		tp.Ladder[i].Executed = formatDecimal(executed.Add(executed, amount))
+		tp.Ladder[i].Filled = tp.LadderRemaining(tp.Ladder[i]) == 0
+		return
+	}
+}
+
+// FormatLadderLevel describes a ladder level for display
+func (tp *TradingPlan) FormatLadderLevel(level LadderLevel) string {
+	total, _ := strconv.ParseFloat(tp.TotalAmount, 64)
+	state := "open"
+	if level.Filled {
+		state = "filled"
+	} else if level.Executed != "" {
+		state = fmt.Sprintf("%s %s traded", trimDecimal(level.Executed), tp.SourceToken)
+	}
+	return fmt.Sprintf("%s %s: %s%% (%s %s) [%s]", tp.PriceCondition, level.Price,
+		strconv.FormatFloat(level.Fraction*100, 'f', -1, 64),
+		trimDecimal(fmt.Sprintf("%.8f", total*level.Fraction)), tp.SourceToken, state)
+}
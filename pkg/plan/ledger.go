@@ -0,0 +1,274 @@
+package plan
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LedgerColumns is the header of the tax ledger CSV
+var LedgerColumns = []string{
+	"date", "execution_id",
+	"disposed_asset", "disposed_amount",
+	"acquired_asset", "acquired_amount",
+	"price", "fee", "cost_basis", "gain",
+	"deposit_tx", "destination_tx",
+}
+
+// LedgerRow is one disposal in a tax ledger: a completed execution that swapped the
+// plan's source token for its dest token
+type LedgerRow struct {
+	Date           time.Time `json:"date"`
+	ExecutionID    string    `json:"execution_id"`
+	DisposedAsset  string    `json:"disposed_asset"`
+	DisposedAmount string    `json:"disposed_amount"`
+	AcquiredAsset  string    `json:"acquired_asset"`
+	AcquiredAmount string    `json:"acquired_amount"` // Net of swap fees, which are taken out of the rate
+	Price          string    `json:"price"`           // Acquired per disposed unit
+	Fee            string    `json:"fee,omitempty"`   // Separately charged fee, when known
+	CostBasis      string    `json:"cost_basis,omitempty"`
+	Gain           string    `json:"gain,omitempty"`
+	DepositTx      string    `json:"deposit_tx,omitempty"`
+	DestinationTx  string    `json:"destination_tx,omitempty"`
+}
+
+// LedgerManifest summarizes a ledger and fixes its content with a hash
+type LedgerManifest struct {
+	Plan           string `json:"plan"`
+	Rows           int    `json:"rows"`
+	DisposedAsset  string `json:"disposed_asset"`
+	DisposedTotal  string `json:"disposed_total"`
+	AcquiredAsset  string `json:"acquired_asset"`
+	AcquiredTotal  string `json:"acquired_total"`
+	CostBasisTotal string `json:"cost_basis_total,omitempty"`
+	GainTotal      string `json:"gain_total,omitempty"`
+	SHA256         string `json:"sha256"` // Hash of the ledger CSV, header included
+}
+
+// Ledger is a tax-oriented export of a plan's completed executions
+type Ledger struct {
+	Manifest LedgerManifest `json:"manifest"`
+	Rows     []LedgerRow    `json:"rows"`
+}
+
+// BasisLot is a holding of the source token acquired at a known cost. Price is in units of the
+// plan's dest token per source token. A nil Amount covers everything not matched by earlier lots.
+type BasisLot struct {
+	Amount *big.Rat
+	Price  *big.Rat
+}
+
+// ParseBasisLots parses a cost basis spec: either a single price applied to every disposal
+// ("30000") or FIFO lots of amount@price ("0.5@30000,1.5@42000")
+func ParseBasisLots(spec string) ([]BasisLot, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	if !strings.Contains(spec, "@") {
+		price, err := parsePositiveDecimal(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid basis price '%s': %w", spec, err)
+		}
+		return []BasisLot{{Price: price}}, nil
+	}
+
+	var lots []BasisLot
+	for _, entry := range strings.Split(spec, ",") {
+		amountStr, priceStr, found := strings.Cut(strings.TrimSpace(entry), "@")
+		if !found {
+			return nil, fmt.Errorf("invalid basis lot '%s', expected <amount>@<price>", strings.TrimSpace(entry))
+		}
+		amount, err := parsePositiveDecimal(amountStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid basis lot amount '%s': %w", amountStr, err)
+		}
+		price, err := parsePositiveDecimal(priceStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid basis lot price '%s': %w", priceStr, err)
+		}
+		lots = append(lots, BasisLot{Amount: amount, Price: price})
+	}
+	return lots, nil
+}
+
+// parsePositiveDecimal parses a decimal that must be greater than zero
+func parsePositiveDecimal(s string) (*big.Rat, error) {
+	r, err := parseDecimal(s)
+	if err != nil {
+		return nil, err
+	}
+	if r.Sign() <= 0 {
+		return nil, fmt.Errorf("must be greater than 0")
+	}
+	return r, nil
+}
+
+// BuildLedger builds a tax ledger from the plan's completed executions in date order. When lots
+// are given, each disposal is matched against them first-in first-out to compute its cost basis
+// and gain; it is an error if the lots don't cover everything disposed.
+func BuildLedger(tp *TradingPlan, lots []BasisLot) (*Ledger, error) {
+	executions := make([]Execution, 0, len(tp.ExecutionHistory))
+	for _, exec := range tp.ExecutionHistory {
+		if exec.Status == ExecutionCompleted {
+			executions = append(executions, exec)
+		}
+	}
+	sort.SliceStable(executions, func(i, j int) bool {
+		return ledgerDate(executions[i]).Before(ledgerDate(executions[j]))
+	})
+
+	// Work on copies so callers can reuse their lots
+	remaining := make([]BasisLot, len(lots))
+	for i, lot := range lots {
+		remaining[i] = lot
+		if lot.Amount != nil {
+			remaining[i].Amount = new(big.Rat).Set(lot.Amount)
+		}
+	}
+
+	ledger := &Ledger{Rows: make([]LedgerRow, 0, len(executions))}
+	disposedTotal, acquiredTotal := new(big.Rat), new(big.Rat)
+	basisTotal, gainTotal := new(big.Rat), new(big.Rat)
+
+	for _, exec := range executions {
+		disposed, err := parseDecimal(exec.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("execution %s: %w", exec.ID, err)
+		}
+		acquiredStr := exec.ActualOutput
+		if acquiredStr == "" {
+			acquiredStr = exec.EstimatedOutput
+		}
+		acquired, err := parseDecimal(acquiredStr)
+		if err != nil {
+			return nil, fmt.Errorf("execution %s: %w", exec.ID, err)
+		}
+
+		row := LedgerRow{
+			Date:           ledgerDate(exec).UTC(),
+			ExecutionID:    exec.ID,
+			DisposedAsset:  tp.SourceToken,
+			DisposedAmount: trimDecimal(formatDecimal(disposed)),
+			AcquiredAsset:  tp.DestToken,
+			AcquiredAmount: trimDecimal(formatDecimal(acquired)),
+			DepositTx:      exec.TxHash,
+			DestinationTx:  exec.DestinationTxHash,
+		}
+		if disposed.Sign() > 0 {
+			row.Price = trimDecimal(formatDecimal(new(big.Rat).Quo(acquired, disposed)))
+		}
+
+		if len(remaining) > 0 {
+			basis, err := consumeLots(remaining, disposed)
+			if err != nil {
+				return nil, fmt.Errorf("execution %s: %w", exec.ID, err)
+			}
+			gain := new(big.Rat).Sub(acquired, basis)
+			row.CostBasis = trimDecimal(formatDecimal(basis))
+			row.Gain = trimDecimal(formatDecimal(gain))
+			basisTotal.Add(basisTotal, basis)
+			gainTotal.Add(gainTotal, gain)
+		}
+
+		disposedTotal.Add(disposedTotal, disposed)
+		acquiredTotal.Add(acquiredTotal, acquired)
+		ledger.Rows = append(ledger.Rows, row)
+	}
+
+	ledger.Manifest = LedgerManifest{
+		Plan:          tp.Name,
+		Rows:          len(ledger.Rows),
+		DisposedAsset: tp.SourceToken,
+		DisposedTotal: trimDecimal(formatDecimal(disposedTotal)),
+		AcquiredAsset: tp.DestToken,
+		AcquiredTotal: trimDecimal(formatDecimal(acquiredTotal)),
+	}
+	if len(lots) > 0 {
+		ledger.Manifest.CostBasisTotal = trimDecimal(formatDecimal(basisTotal))
+		ledger.Manifest.GainTotal = trimDecimal(formatDecimal(gainTotal))
+	}
+
+	var buf bytes.Buffer
+	if err := ledger.WriteCSV(&buf); err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	ledger.Manifest.SHA256 = hex.EncodeToString(sum[:])
+
+	return ledger, nil
+}
+
+// consumeLots takes amount out of the lots first-in first-out and returns its cost
+func consumeLots(lots []BasisLot, amount *big.Rat) (*big.Rat, error) {
+	cost := new(big.Rat)
+	left := new(big.Rat).Set(amount)
+
+	for i := range lots {
+		if left.Sign() == 0 {
+			break
+		}
+		lot := &lots[i]
+
+		// An open-ended lot takes whatever is left
+		if lot.Amount == nil {
+			cost.Add(cost, new(big.Rat).Mul(left, lot.Price))
+			left.SetInt64(0)
+			break
+		}
+		if lot.Amount.Sign() == 0 {
+			continue
+		}
+
+		take := new(big.Rat).Set(left)
+		if lot.Amount.Cmp(take) < 0 {
+			take.Set(lot.Amount)
+		}
+		cost.Add(cost, new(big.Rat).Mul(take, lot.Price))
+		lot.Amount.Sub(lot.Amount, take)
+		left.Sub(left, take)
+	}
+
+	if left.Sign() > 0 {
+		return nil, fmt.Errorf("cost basis lots don't cover %s more disposed", trimDecimal(formatDecimal(left)))
+	}
+	return cost, nil
+}
+
+// ledgerDate is when an execution's disposal happened: its completion time when known
+func ledgerDate(exec Execution) time.Time {
+	if exec.CompletionTime != nil {
+		return *exec.CompletionTime
+	}
+	return exec.Timestamp
+}
+
+// WriteCSV writes the ledger rows as CSV with a header row
+func (l *Ledger) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(LedgerColumns); err != nil {
+		return err
+	}
+	for _, row := range l.Rows {
+		record := []string{
+			row.Date.Format(time.RFC3339), row.ExecutionID,
+			row.DisposedAsset, row.DisposedAmount,
+			row.AcquiredAsset, row.AcquiredAmount,
+			row.Price, row.Fee, row.CostBasis, row.Gain,
+			row.DepositTx, row.DestinationTx,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,64 @@
+package plan
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestBuildLedger(t *testing.T) {
+	at := func(day, hour int) time.Time { return time.Date(2026, 1, day, hour, 0, 0, 0, time.UTC) }
+	completedAt := at(1, 10)
+	p := &TradingPlan{Name: "tax", SourceToken: "BTC", DestToken: "USDC", ExecutionHistory: []Execution{
+		{ID: "exec-a", Timestamp: at(2, 9), Amount: "0.4", ActualOutput: "20000", Status: ExecutionCompleted,
+			TxHash: "dep-a", DestinationTxHash: "dest-a"},
+		{ID: "exec-b", Timestamp: at(1, 9), CompletionTime: &completedAt, Amount: "0.3", ActualOutput: "15000",
+			EstimatedOutput: "14000", Status: ExecutionCompleted, TxHash: "dep-b", DestinationTxHash: "dest-b"},
+		{ID: "exec-c", Timestamp: at(2, 12), Amount: "0.2", Status: ExecutionFailed},
+		{ID: "exec-d", Timestamp: at(3, 9), Amount: "0.5", EstimatedOutput: "26000", Status: ExecutionCompleted},
+	}}
+	lots, err := ParseBasisLots("0.5@30000, 1.5@42000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ledger, err := BuildLedger(p, lots)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Completed executions in date order, matched against the lots first-in first-out
+	wantCSV := "date,execution_id,disposed_asset,disposed_amount,acquired_asset,acquired_amount,price,fee,cost_basis,gain,deposit_tx,destination_tx\n" +
+		"2026-01-01T10:00:00Z,exec-b,BTC,0.3,USDC,15000,50000,,9000,6000,dep-b,dest-b\n" +
+		"2026-01-02T09:00:00Z,exec-a,BTC,0.4,USDC,20000,50000,,14400,5600,dep-a,dest-a\n" +
+		"2026-01-03T09:00:00Z,exec-d,BTC,0.5,USDC,26000,52000,,21000,5000,,\n"
+	var buf bytes.Buffer
+	if err := ledger.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != wantCSV {
+		t.Errorf("ledger =\n%s\nwant\n%s", buf.String(), wantCSV)
+	}
+
+	want := LedgerManifest{Plan: "tax", Rows: 3, DisposedAsset: "BTC", DisposedTotal: "1.2", AcquiredAsset: "USDC",
+		AcquiredTotal: "61000", CostBasisTotal: "44400", GainTotal: "16600",
+		SHA256: "f9ef330f6ed96f663fd23dfdbf16bd5e39cb17545b0de4d87edec59045d1502a"} // sha256 of wantCSV
+	if ledger.Manifest != want {
+		t.Errorf("manifest = %+v\nwant %+v", ledger.Manifest, want)
+	}
+
+	// The lots are left untouched, so the same inputs build the same ledger
+	again, err := BuildLedger(p, lots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Manifest.SHA256 != ledger.Manifest.SHA256 {
+		t.Errorf("rebuilt ledger hash = %s, want %s", again.Manifest.SHA256, ledger.Manifest.SHA256)
+	}
+
+	// Lots must cover everything disposed
+	short, _ := ParseBasisLots("1@30000")
+	if _, err := BuildLedger(p, short); err == nil {
+		t.Error("lots short of the disposals built a ledger")
+	}
+}
@@ -1,21 +1,35 @@
 package plan
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
+	"sync"
 	"time"
+)
 
-	"github.com/google/uuid"
+// Errors returned (wrapped) when a plan's status doesn't allow starting it
+var (
+	ErrPlanActive    = errors.New("is already active")
+	ErrPlanCompleted = errors.New("has already completed all trades")
 )
 
 // Manager provides high-level operations for trading plans
 type Manager struct {
-	storage *Storage
+	storage  Storage
+	maxPlans int      // Plans storage may hold before CreatePlan refuses new ones (0 is unlimited)
+	locks    sync.Map // Per-plan mutexes serializing read-modify-write updates
 }
 
-// NewManager creates a new plan manager
+// NewManager creates a new plan manager backed by JSON storage
 func NewManager(storagePath string) (*Manager, error) {
-	storage, err := NewStorage(storagePath)
+	return NewManagerWithBackend(BackendJSON, storagePath)
+}
+
+// NewManagerWithBackend creates a new plan manager using the given storage backend
+func NewManagerWithBackend(backend, storagePath string) (*Manager, error) {
+	storage, err := OpenStorage(backend, storagePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create storage: %w", err)
 	}
@@ -25,6 +39,35 @@ func NewManager(storagePath string) (*Manager, error) {
 	}, nil
 }
 
+// SetLimits guards against runaway plan storage: CreatePlan refuses new plans once maxPlans
+// are stored, and saves warn when the storage file grows past warnMB megabytes. 0 disables either.
+func (m *Manager) SetLimits(maxPlans, warnMB int) {
+	m.maxPlans = maxPlans
+	m.storage.SetWarnSize(int64(warnMB) << 20)
+}
+
+// CreatePlanOptions holds optional settings for plan creation
+type CreatePlanOptions struct {
+	Force              bool          // Create the plan even if it overlaps an active plan
+	AmountPerTradeDest string        // Size each trade by the dest amount to acquire (amountPerTrade must be empty)
+	CancelBelow        string        // Kill switch price: cancel the plan at or below it (optional)
+	CancelAbove        string        // Kill switch price: cancel the plan at or above it (optional)
+	Ladder             []LadderLevel // Trade tranches at these levels; the trigger price becomes the first level
+	AmountJitter       float64       // Randomize each trade by up to ±this percent (optional)
+	WithdrawTo         string        // Forward each completed trade's output to this cold address (optional)
+	PriceSmoothing     int           // Trigger on the average of the last N price checks (optional)
+	SlippageBps        int           // Quote slippage tolerance in basis points (optional, 0 uses default_slippage)
+	QuoteDeadline      time.Duration // How long each trade's quote accepts the deposit (optional, 0 uses default_deadline)
+	NormalizeName      bool          // Rewrite an invalid name with NormalizePlanName instead of rejecting it
+	MaxQuoteDivergence float64       // Max % the deposit quote may be worse than the trigger price (optional, 0 uses max_quote_divergence)
+	APITokenEnv        string        // Environment variable holding the plan's own 1Click JWT (optional)
+	DustThreshold      string        // Complete the plan once the remainder is worth less than this in dest tokens (optional)
+	MaxPriceImpact     float64       // Skip trades whose full-size quote is this % worse than the probe price (optional, 0 uses max_price_impact)
+	VerifyFirst        bool          // Run a tiny verification swap before the first full trade (optional)
+	VerifyAmount       string        // Source amount of the verification swap (optional for plans sized by amount per trade)
+	DryRun             bool          // Validate and return the plan without saving it
+}
+
 // CreatePlan creates a new trading plan with validation
 func (m *Manager) CreatePlan(
 	name string,
@@ -35,32 +78,121 @@ func (m *Manager) CreatePlan(
 	priceCondition PriceCondition,
 	recipientAddr, refundAddr string,
 	description string,
+	opts CreatePlanOptions,
 ) (*TradingPlan, error) {
+	if opts.NormalizeName {
+		name = NormalizePlanName(name)
+	}
+	if err := ValidatePlanName(name); err != nil {
+		return nil, err
+	}
+
 	// Check if plan already exists
 	if m.storage.Exists(name) {
-		return nil, fmt.Errorf("plan '%s' already exists", name)
+		return nil, fmt.Errorf("plan '%s' %w", name, ErrPlanExists)
+	}
+	if count := m.storage.Count(); m.maxPlans > 0 && count >= m.maxPlans {
+		return nil, fmt.Errorf("plan limit reached: %d plans stored (max_plans is %d); delete finished plans or raise max_plans", count, m.maxPlans)
+	}
+
+	// Laddered plans trigger at their first level and size trades by tranche
+	if len(opts.Ladder) > 0 {
+		if err := validateLadder(opts.Ladder); err != nil {
+			return nil, err
+		}
+		if priceCondition != PriceAbove && priceCondition != PriceBelow {
+			return nil, fmt.Errorf("laddered plans must trigger 'above' or 'below'")
+		}
+		if opts.AmountPerTradeDest != "" {
+			return nil, fmt.Errorf("laddered plans cannot size trades by dest amount")
+		}
+		sortLadder(priceCondition, opts.Ladder)
+		triggerPrice = opts.Ladder[0].Price
 	}
 
 	// Validate amounts
 	if err := validateAmount(totalAmount); err != nil {
 		return nil, fmt.Errorf("invalid total amount: %w", err)
 	}
-	if err := validateAmount(amountPerTrade); err != nil {
+	destSized := opts.AmountPerTradeDest != ""
+	if destSized {
+		if amountPerTrade != "" {
+			return nil, fmt.Errorf("amount per trade and dest amount per trade are mutually exclusive")
+		}
+		if err := validateAmount(opts.AmountPerTradeDest); err != nil {
+			return nil, fmt.Errorf("invalid dest amount per trade: %w", err)
+		}
+	} else if len(opts.Ladder) > 0 {
+		if amountPerTrade != "" {
+			return nil, fmt.Errorf("laddered plans size trades by level; omit the amount per trade")
+		}
+	} else if err := validateAmount(amountPerTrade); err != nil {
 		return nil, fmt.Errorf("invalid amount per trade: %w", err)
 	}
 	if err := validateAmount(amountPerDay); err != nil {
 		return nil, fmt.Errorf("invalid amount per day: %w", err)
 	}
-	if err := validateAmount(triggerPrice); err != nil {
+	// A trailing stop's trigger is a percentage below the peak rather than a price
+	var trailPercent float64
+	if priceCondition == PriceTrailingStop {
+		percent, err := parseTrailPercent(triggerPrice)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trailing stop: %w", err)
+		}
+		trailPercent = percent
+		triggerPrice = ""
+	} else if err := validateAmount(triggerPrice); err != nil {
 		return nil, fmt.Errorf("invalid trigger price: %w", err)
 	}
+	if opts.CancelBelow != "" {
+		if err := validateAmount(opts.CancelBelow); err != nil {
+			return nil, fmt.Errorf("invalid cancel-below price: %w", err)
+		}
+	}
+	if opts.CancelAbove != "" {
+		if err := validateAmount(opts.CancelAbove); err != nil {
+			return nil, fmt.Errorf("invalid cancel-above price: %w", err)
+		}
+	}
+
+	if opts.DustThreshold != "" {
+		if err := validateAmount(opts.DustThreshold); err != nil {
+			return nil, fmt.Errorf("invalid dust threshold: %w", err)
+		}
+	}
+
+	if err := validateJitter(opts.AmountJitter); err != nil {
+		return nil, err
+	}
+	if err := validatePriceSmoothing(opts.PriceSmoothing); err != nil {
+		return nil, err
+	}
+	if opts.WithdrawTo != "" && opts.WithdrawTo == recipientAddr {
+		return nil, fmt.Errorf("withdraw address must differ from the recipient address")
+	}
+
+	// Plans sized by amount per trade can default the verification swap from it
+	verifyAmount := opts.VerifyAmount
+	if opts.VerifyFirst && verifyAmount == "" {
+		if destSized || len(opts.Ladder) > 0 {
+			return nil, fmt.Errorf("plans sized by dest amount or ladder need an explicit verify amount")
+		}
+		amount, err := defaultVerifyAmount(sourceChain, amountPerTrade)
+		if err != nil {
+			return nil, err
+		}
+		verifyAmount = amount
+	} else if !opts.VerifyFirst && verifyAmount != "" {
+		return nil, fmt.Errorf("a verify amount requires verify-first")
+	}
 
 	// Verify that amountPerTrade <= amountPerDay <= totalAmount
 	totalFloat, _ := strconv.ParseFloat(totalAmount, 64)
 	perTradeFloat, _ := strconv.ParseFloat(amountPerTrade, 64)
 	perDayFloat, _ := strconv.ParseFloat(amountPerDay, 64)
 
-	if perTradeFloat > perDayFloat {
+	// Dest-sized trades are in different units, so only the source limits can be compared
+	if !destSized && perTradeFloat > perDayFloat {
 		return nil, fmt.Errorf("amount per trade cannot be greater than amount per day")
 	}
 	if perDayFloat > totalFloat {
@@ -70,28 +202,44 @@ func (m *Manager) CreatePlan(
 	now := time.Now()
 
 	plan := &TradingPlan{
-		Name:              name,
-		Description:       description,
-		Created:           now,
-		LastUpdated:       now,
-		SourceToken:       sourceToken,
-		DestToken:         destToken,
-		SourceChain:       sourceChain,
-		DestChain:         destChain,
-		TotalAmount:       totalAmount,
-		AmountPerTrade:    amountPerTrade,
-		AmountPerDay:      amountPerDay,
-		TriggerPrice:      triggerPrice,
-		PriceCondition:    priceCondition,
-		RecipientAddr:     recipientAddr,
-		RefundAddr:        refundAddr,
-		Status:            StatusPaused, // Start in paused state
-		TotalExecuted:     "0",
-		RemainingAmount:   totalAmount,
-		ExecutionHistory:  []Execution{},
-		ExecutionCount:    0,
-		LastExecutionDate: "",
-		TodayExecuted:     "0",
+		Name:               name,
+		Description:        description,
+		Created:            now,
+		LastUpdated:        now,
+		SourceToken:        sourceToken,
+		DestToken:          destToken,
+		SourceChain:        sourceChain,
+		DestChain:          destChain,
+		TotalAmount:        totalAmount,
+		AmountPerTrade:     amountPerTrade,
+		AmountPerTradeDest: opts.AmountPerTradeDest,
+		AmountPerDay:       amountPerDay,
+		TriggerPrice:       triggerPrice,
+		PriceCondition:     priceCondition,
+		TrailPercent:       trailPercent,
+		CancelBelow:        opts.CancelBelow,
+		CancelAbove:        opts.CancelAbove,
+		Ladder:             opts.Ladder,
+		AmountJitter:       opts.AmountJitter,
+		PriceSmoothing:     opts.PriceSmoothing,
+		SlippageBps:        opts.SlippageBps,
+		QuoteDeadline:      formatQuoteDeadline(opts.QuoteDeadline),
+		MaxQuoteDivergence: opts.MaxQuoteDivergence,
+		MaxPriceImpact:     opts.MaxPriceImpact,
+		DustThreshold:      opts.DustThreshold,
+		VerifyFirst:        opts.VerifyFirst,
+		VerifyAmount:       verifyAmount,
+		RecipientAddr:      recipientAddr,
+		RefundAddr:         refundAddr,
+		WithdrawTo:         opts.WithdrawTo,
+		APITokenEnv:        opts.APITokenEnv,
+		Status:             StatusPaused, // Start in paused state
+		TotalExecuted:      "0",
+		RemainingAmount:    totalAmount,
+		ExecutionHistory:   []Execution{},
+		ExecutionCount:     0,
+		LastExecutionDate:  "",
+		TodayExecuted:      "0",
 	}
 
 	// Validate the plan
@@ -99,6 +247,17 @@ func (m *Manager) CreatePlan(
 		return nil, err
 	}
 
+	// Refuse to create a plan that would double-trade alongside an active one
+	if !opts.Force {
+		if err := m.CheckConflicts(plan); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.DryRun {
+		return plan, nil
+	}
+
 	// Save to storage
 	if err := m.storage.Create(plan); err != nil {
 		return nil, err
@@ -130,6 +289,8 @@ func (m *Manager) UpdatePlan(plan *TradingPlan) error {
 
 // DeletePlan removes a plan
 func (m *Manager) DeletePlan(name string) error {
+	defer m.lockPlan(name)()
+
 	// Don't allow deletion of active plans
 	plan, err := m.storage.Get(name)
 	if err != nil {
@@ -143,22 +304,35 @@ func (m *Manager) DeletePlan(name string) error {
 	return m.storage.Delete(name)
 }
 
-// StartPlan activates a plan for execution
-func (m *Manager) StartPlan(name string) error {
+// StartPlan activates a plan for execution.
+// Unless force is set, it refuses to start a plan that overlaps another active plan.
+func (m *Manager) StartPlan(name string, force bool) error {
+	defer m.lockPlan(name)()
+
 	plan, err := m.storage.Get(name)
 	if err != nil {
 		return err
 	}
 
 	if plan.Status == StatusActive {
-		return fmt.Errorf("plan '%s' is already active", name)
+		return fmt.Errorf("plan '%s' %w", name, ErrPlanActive)
 	}
 
 	if plan.Status == StatusCompleted {
-		return fmt.Errorf("plan '%s' has already completed all trades", name)
+		return fmt.Errorf("plan '%s' %w", name, ErrPlanCompleted)
+	}
+
+	if !force {
+		if err := m.CheckConflicts(plan); err != nil {
+			return err
+		}
 	}
 
 	plan.Status = StatusActive
+	plan.PauseReason = ""
+	plan.CancelReason = ""
+	plan.DestinationFailures = 0
+	plan.PeakPrice = 0 // A trailing stop tracks the peak from when the plan is started
 	plan.LastUpdated = time.Now()
 
 	return m.storage.Update(plan)
@@ -166,6 +340,8 @@ func (m *Manager) StartPlan(name string) error {
 
 // StopPlan pauses a running plan
 func (m *Manager) StopPlan(name string) error {
+	defer m.lockPlan(name)()
+
 	plan, err := m.storage.Get(name)
 	if err != nil {
 		return err
@@ -181,8 +357,52 @@ func (m *Manager) StopPlan(name string) error {
 	return m.storage.Update(plan)
 }
 
+// PausePlan pauses an active plan automatically, recording the reason so it can be
+// resumed once the condition clears
+func (m *Manager) PausePlan(name, reason string) error {
+	defer m.lockPlan(name)()
+
+	plan, err := m.storage.Get(name)
+	if err != nil {
+		return err
+	}
+
+	if plan.Status != StatusActive {
+		return fmt.Errorf("plan '%s' is not active", name)
+	}
+
+	plan.Status = StatusPaused
+	plan.PauseReason = reason
+	plan.LastUpdated = time.Now()
+
+	return m.storage.Update(plan)
+}
+
+// ResumePlan reactivates a plan that was automatically paused for the given reason.
+// Plans paused manually or for a different reason are left alone.
+func (m *Manager) ResumePlan(name, reason string) error {
+	defer m.lockPlan(name)()
+
+	plan, err := m.storage.Get(name)
+	if err != nil {
+		return err
+	}
+
+	if plan.Status != StatusPaused || plan.PauseReason != reason {
+		return fmt.Errorf("plan '%s' was not paused for: %s", name, reason)
+	}
+
+	plan.Status = StatusActive
+	plan.PauseReason = ""
+	plan.LastUpdated = time.Now()
+
+	return m.storage.Update(plan)
+}
+
 // CancelPlan marks a plan as cancelled
 func (m *Manager) CancelPlan(name string) error {
+	defer m.lockPlan(name)()
+
 	plan, err := m.storage.Get(name)
 	if err != nil {
 		return err
@@ -194,15 +414,56 @@ func (m *Manager) CancelPlan(name string) error {
 	return m.storage.Update(plan)
 }
 
-// AddExecution records a new execution for a plan and returns the execution ID
+// CancelPlanWithReason marks a plan as cancelled and records why, for automatic cancellations
+func (m *Manager) CancelPlanWithReason(name, reason string) error {
+	defer m.lockPlan(name)()
+
+	plan, err := m.storage.Get(name)
+	if err != nil {
+		return err
+	}
+
+	plan.Status = StatusCancelled
+	plan.CancelReason = reason
+	plan.LastUpdated = time.Now()
+
+	return m.storage.Update(plan)
+}
+
+// CompletePlanWithReason marks a plan as completed before its remaining amount was traded
+// and records why
+func (m *Manager) CompletePlanWithReason(name, reason string) error {
+	defer m.lockPlan(name)()
+
+	plan, err := m.storage.Get(name)
+	if err != nil {
+		return err
+	}
+
+	plan.Status = StatusCompleted
+	plan.CompletionReason = reason
+	plan.LastUpdated = time.Now()
+
+	return m.storage.Update(plan)
+}
+
+// AddExecution records a new execution for a plan and returns the execution ID. Recording
+// an execution that is already in the history (same deposit address, amount and day) returns
+// the existing ID without adding a duplicate.
 func (m *Manager) AddExecution(name string, execution Execution) (string, error) {
+	defer m.lockPlan(name)()
+
 	plan, err := m.storage.Get(name)
 	if err != nil {
 		return "", err
 	}
 
+	execution.ID = executionID(name, execution, time.Now())
+	if plan.findExecution(execution.ID) != nil {
+		return execution.ID, nil
+	}
+
 	// Add execution to history
-	execution.ID = uuid.New().String()
 	execution.Timestamp = time.Now()
 	executionID := execution.ID
 	plan.ExecutionHistory = append(plan.ExecutionHistory, execution)
@@ -219,25 +480,21 @@ func (m *Manager) AddExecution(name string, execution Execution) (string, error)
 
 	// Update amounts if execution is successful
 	if execution.Status == ExecutionCompleted || execution.Status == ExecutionDeposited {
-		executionAmount, _ := strconv.ParseFloat(execution.Amount, 64)
-
-		// Update total executed
-		totalExecuted, _ := strconv.ParseFloat(plan.TotalExecuted, 64)
-		totalExecuted += executionAmount
-		plan.TotalExecuted = fmt.Sprintf("%.8f", totalExecuted)
-
-		// Update remaining amount
-		remaining, _ := strconv.ParseFloat(plan.RemainingAmount, 64)
-		remaining -= executionAmount
-		plan.RemainingAmount = fmt.Sprintf("%.8f", remaining)
+		executionAmount, err := parseDecimal(execution.Amount)
+		if err != nil {
+			return "", fmt.Errorf("invalid execution amount: %w", err)
+		}
+		remaining, err := plan.addProgress(executionAmount, true)
+		if err != nil {
+			return "", err
+		}
 
-		// Update today's executed amount
-		todayExecuted, _ := strconv.ParseFloat(plan.TodayExecuted, 64)
-		todayExecuted += executionAmount
-		plan.TodayExecuted = fmt.Sprintf("%.8f", todayExecuted)
+		if execution.LadderPrice != "" {
+			plan.recordLadderFill(execution.LadderPrice, executionAmount)
+		}
 
-		// Check if plan is completed
-		if remaining <= 0.00000001 { // Small tolerance for floating point
+		// Check if plan is completed; less than the smallest stored unit counts as nothing left
+		if remaining.Cmp(smallestAmount) < 0 {
 			plan.Status = StatusCompleted
 			plan.RemainingAmount = "0"
 		}
@@ -248,8 +505,48 @@ func (m *Manager) AddExecution(name string, execution Execution) (string, error)
 	return executionID, m.storage.Update(plan)
 }
 
+// RecordDivergenceAbort records a trade aborted because its deposit quote diverged too far from
+// the trigger price. While the plan keeps aborting, the latest quote is folded into the previous
+// abort's record instead of adding an execution on every price check.
+func (m *Manager) RecordDivergenceAbort(name string, execution Execution) (string, error) {
+	if id, folded, err := m.foldDivergenceAbort(name, execution); err != nil || folded {
+		return id, err
+	}
+
+	execution.Status = ExecutionFailed
+	execution.Aborts = 1
+	return m.AddExecution(name, execution)
+}
+
+// foldDivergenceAbort updates the plan's latest execution with execution's quote if it is also a
+// divergence abort, and reports whether it did
+func (m *Manager) foldDivergenceAbort(name string, execution Execution) (string, bool, error) {
+	defer m.lockPlan(name)()
+
+	plan, err := m.storage.Get(name)
+	if err != nil {
+		return "", false, err
+	}
+	n := len(plan.ExecutionHistory)
+	if n == 0 || plan.ExecutionHistory[n-1].Aborts == 0 {
+		return "", false, nil
+	}
+
+	last := &plan.ExecutionHistory[n-1]
+	last.TriggerPrice = execution.TriggerPrice
+	last.ActualPrice = execution.ActualPrice
+	last.QuoteDivergence = execution.QuoteDivergence
+	last.ErrorMessage = execution.ErrorMessage
+	last.Aborts++
+	plan.LastUpdated = time.Now()
+
+	return last.ID, true, m.storage.Update(plan)
+}
+
 // UpdateExecutionStatus updates the status of a specific execution
 func (m *Manager) UpdateExecutionStatus(planName, executionID string, status ExecutionStatus, txHash string, errorMsg string) error {
+	defer m.lockPlan(planName)()
+
 	plan, err := m.storage.Get(planName)
 	if err != nil {
 		return err
@@ -259,7 +556,30 @@ func (m *Manager) UpdateExecutionStatus(planName, executionID string, status Exe
 	found := false
 	for i := range plan.ExecutionHistory {
 		if plan.ExecutionHistory[i].ID == executionID {
+			// A cancelled execution keeps its status; only a deposit that raced the cancel is kept
+			if plan.ExecutionHistory[i].Status == ExecutionCancelled {
+				if err := plan.recordCancelledDeposit(&plan.ExecutionHistory[i], txHash); err != nil {
+					return err
+				}
+				plan.LastUpdated = time.Now()
+				if err := m.storage.Update(plan); err != nil {
+					return err
+				}
+				return fmt.Errorf("execution '%s': %w", executionID, ErrExecutionCancelled)
+			}
+
+			// Count a ladder tranche once, when it's reserved or its deposit first goes through
+			exec := plan.ExecutionHistory[i]
+			wasCounted := exec.Reserved || exec.Status == ExecutionDeposited || exec.Status == ExecutionCompleted
+			nowCounted := status == ExecutionDeposited || status == ExecutionCompleted
+			if exec.LadderPrice != "" && !wasCounted && nowCounted {
+				if amount, err := parseDecimal(exec.Amount); err == nil {
+					plan.recordLadderFill(exec.LadderPrice, amount)
+				}
+			}
+
 			plan.ExecutionHistory[i].Status = status
+			plan.settleReservation(&plan.ExecutionHistory[i])
 			if txHash != "" {
 				plan.ExecutionHistory[i].TxHash = txHash
 			}
@@ -280,6 +600,68 @@ func (m *Manager) UpdateExecutionStatus(planName, executionID string, status Exe
 	return m.storage.Update(plan)
 }
 
+// RecordRefund stores refund details on an execution
+func (m *Manager) RecordRefund(planName, executionID, refundTxHash, refundedAmount string, confirmed bool) error {
+	defer m.lockPlan(planName)()
+
+	plan, err := m.storage.Get(planName)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i := range plan.ExecutionHistory {
+		if plan.ExecutionHistory[i].ID == executionID {
+			if refundTxHash != "" {
+				plan.ExecutionHistory[i].RefundTxHash = refundTxHash
+			}
+			if refundedAmount != "" {
+				plan.ExecutionHistory[i].RefundedAmount = refundedAmount
+			}
+			plan.ExecutionHistory[i].RefundConfirmed = confirmed
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("execution '%s' not found in plan '%s'", executionID, planName)
+	}
+
+	plan.LastUpdated = time.Now()
+
+	return m.storage.Update(plan)
+}
+
+// RecordWithdrawal stores the outcome of forwarding an execution's output to the cold address
+func (m *Manager) RecordWithdrawal(planName, executionID, txHash, amount, errorMsg string) error {
+	defer m.lockPlan(planName)()
+
+	plan, err := m.storage.Get(planName)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i := range plan.ExecutionHistory {
+		if plan.ExecutionHistory[i].ID == executionID {
+			plan.ExecutionHistory[i].WithdrawalTxHash = txHash
+			plan.ExecutionHistory[i].WithdrawnAmount = amount
+			plan.ExecutionHistory[i].WithdrawalError = errorMsg
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("execution '%s' not found in plan '%s'", executionID, planName)
+	}
+
+	plan.LastUpdated = time.Now()
+
+	return m.storage.Update(plan)
+}
+
 // GetExecutionHistory returns the execution history for a plan
 func (m *Manager) GetExecutionHistory(name string) ([]Execution, error) {
 	plan, err := m.storage.Get(name)
@@ -290,6 +672,11 @@ func (m *Manager) GetExecutionHistory(name string) ([]Execution, error) {
 	return plan.ExecutionHistory, nil
 }
 
+// GetExecutionsPage returns one page of a plan's executions, newest first, and the total count
+func (m *Manager) GetExecutionsPage(name string, offset, limit int) ([]Execution, int, error) {
+	return m.storage.GetExecutionsPage(name, offset, limit)
+}
+
 // validateAmount checks if an amount string is valid
 func validateAmount(amount string) error {
 	if amount == "" {
@@ -314,12 +701,15 @@ func (m *Manager) GetActivePlans() []*TradingPlan {
 }
 
 // GetStorage returns the storage instance (useful for executor)
-func (m *Manager) GetStorage() *Storage {
+func (m *Manager) GetStorage() Storage {
 	return m.storage
 }
 
-// UpdateExecutionWithSwapStatus updates an execution with swap status details
+// UpdateExecutionWithSwapStatus updates an execution with swap status details. A swap that
+// failed or was refunded gives back the amount its deposit counted toward the plan.
 func (m *Manager) UpdateExecutionWithSwapStatus(planName, executionID string, swapStatus, actualOutput, destTxHash string) error {
+	defer m.lockPlan(planName)()
+
 	plan, err := m.storage.Get(planName)
 	if err != nil {
 		return err
@@ -329,6 +719,9 @@ func (m *Manager) UpdateExecutionWithSwapStatus(planName, executionID string, sw
 	found := false
 	for i := range plan.ExecutionHistory {
 		if plan.ExecutionHistory[i].ID == executionID {
+			if plan.ExecutionHistory[i].Status == ExecutionCancelled {
+				return fmt.Errorf("execution '%s': %w", executionID, ErrExecutionCancelled)
+			}
 			plan.ExecutionHistory[i].SwapStatus = swapStatus
 
 			if actualOutput != "" {
@@ -339,14 +732,32 @@ func (m *Manager) UpdateExecutionWithSwapStatus(planName, executionID string, sw
 				plan.ExecutionHistory[i].DestinationTxHash = destTxHash
 			}
 
-			// If status is completed/success, mark execution as completed and set completion time
+			// If status is completed/success, mark execution as completed and set completion time.
+			// Each settlement is counted once toward the plan's consecutive destination failures.
+			settled := plan.ExecutionHistory[i].Status == ExecutionCompleted || plan.ExecutionHistory[i].Status == ExecutionFailed
 			if swapStatus == "SUCCESS" || swapStatus == "COMPLETED" {
 				plan.ExecutionHistory[i].Status = ExecutionCompleted
 				now := time.Now()
 				plan.ExecutionHistory[i].CompletionTime = &now
+				if !settled {
+					plan.DestinationFailures = 0
+				}
+				if plan.ExecutionHistory[i].Verification && plan.VerifiedAt == nil {
+					plan.VerifiedAt = &now
+				}
 			} else if swapStatus == "FAILED" || swapStatus == "REFUNDED" {
+				// A deposit already counted toward the plan never became a trade; give it back
+				if exec := &plan.ExecutionHistory[i]; !exec.Reserved && (exec.Status == ExecutionDeposited || exec.Status == ExecutionCompleted) {
+					if err := plan.releaseCredit(exec, failedSwapPauseReason); err != nil {
+						return err
+					}
+				}
 				plan.ExecutionHistory[i].Status = ExecutionFailed
+				if !settled {
+					plan.DestinationFailures++
+				}
 			}
+			plan.settleReservation(&plan.ExecutionHistory[i])
 
 			found = true
 			break
@@ -361,3 +772,89 @@ func (m *Manager) UpdateExecutionWithSwapStatus(planName, executionID string, sw
 
 	return m.storage.Update(plan)
 }
+
+// RecomputeProgress recalculates a plan's aggregate progress (TotalExecuted, RemainingAmount,
+// TodayExecuted, ExecutionCount) from its execution history using exact decimal math,
+// and re-derives the plan status from the result
+func (m *Manager) RecomputeProgress(name string) (*TradingPlan, error) {
+	defer m.lockPlan(name)()
+
+	plan, err := m.storage.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := parseDecimal(plan.TotalAmount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid total amount: %w", err)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	executed := new(big.Rat)
+	todayExecuted := new(big.Rat)
+	lastExecutionDate := ""
+	pendingReservations := false
+
+	for i := range plan.Ladder {
+		plan.Ladder[i].Executed = ""
+		plan.Ladder[i].Filled = false
+	}
+
+	for _, exec := range plan.ExecutionHistory {
+		reserved := exec.Status == ExecutionPending && exec.Reserved
+		if exec.Status != ExecutionCompleted && exec.Status != ExecutionDeposited && !reserved && !exec.cancelledAfterDeposit() {
+			continue
+		}
+		pendingReservations = pendingReservations || reserved
+
+		amount, err := parseDecimal(exec.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("execution '%s' has invalid amount: %w", exec.ID, err)
+		}
+
+		executed.Add(executed, amount)
+
+		if exec.LadderPrice != "" {
+			plan.recordLadderFill(exec.LadderPrice, amount)
+		}
+
+		execDate := exec.Timestamp.Format("2006-01-02")
+		if execDate > lastExecutionDate {
+			lastExecutionDate = execDate
+		}
+		if execDate == today {
+			todayExecuted.Add(todayExecuted, amount)
+		}
+	}
+
+	remaining := new(big.Rat).Sub(total, executed)
+	if remaining.Sign() < 0 {
+		remaining.SetInt64(0)
+	}
+
+	plan.TotalExecuted = formatDecimal(executed)
+	plan.RemainingAmount = formatDecimal(remaining)
+	plan.TodayExecuted = formatDecimal(todayExecuted)
+	plan.LastExecutionDate = lastExecutionDate
+	plan.ExecutionCount = len(plan.ExecutionHistory)
+
+	// Re-derive status from the corrected progress; a plan isn't done while a reserved trade is
+	// pending, and a cancelled plan stays cancelled
+	if remaining.Sign() == 0 && !pendingReservations {
+		if plan.Status == StatusActive || plan.Status == StatusPaused {
+			plan.Status = StatusCompleted
+		}
+		plan.RemainingAmount = "0"
+	} else if remaining.Sign() != 0 && plan.Status == StatusCompleted && plan.CompletionReason == "" {
+		// Plan was marked completed by drifted accounting; leave it paused for review
+		plan.Status = StatusPaused
+	}
+
+	plan.LastUpdated = time.Now()
+
+	if err := m.storage.Update(plan); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
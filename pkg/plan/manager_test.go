@@ -0,0 +1,113 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecomputeProgress(t *testing.T) {
+	completed := Execution{Amount: "0.1", Status: ExecutionCompleted}
+	failed := Execution{Amount: "0.1", Status: ExecutionFailed}
+	reserved := Execution{Amount: "0.1", Status: ExecutionPending, Reserved: true}
+
+	tests := []struct {
+		name          string
+		status        PlanStatus
+		reason        string // Completion reason
+		cancelReason  string
+		remaining     string // Drifted remaining amount before recomputing
+		history       []Execution
+		wantStatus    PlanStatus
+		wantExecuted  string
+		wantRemaining string
+	}{
+		{name: "drifted totals are corrected", status: StatusActive, remaining: "0.05", history: []Execution{completed, failed},
+			wantStatus: StatusActive, wantExecuted: "0.10000000", wantRemaining: "0.10000000"},
+		{name: "everything traded completes the plan", status: StatusActive, remaining: "0.00000001", history: []Execution{completed, completed},
+			wantStatus: StatusCompleted, wantExecuted: "0.20000000", wantRemaining: "0"},
+		{name: "paused plan that traded everything completes", status: StatusPaused, remaining: "0.1", history: []Execution{completed, completed},
+			wantStatus: StatusCompleted, wantExecuted: "0.20000000", wantRemaining: "0"},
+		{name: "pending reservation keeps the plan open", status: StatusActive, remaining: "0.1", history: []Execution{completed, reserved},
+			wantStatus: StatusActive, wantExecuted: "0.20000000", wantRemaining: "0.00000000"},
+		{name: "completed by drift is reopened paused", status: StatusCompleted, remaining: "0", history: []Execution{completed},
+			wantStatus: StatusPaused, wantExecuted: "0.10000000", wantRemaining: "0.10000000"},
+		{name: "completed for a reason stays completed", status: StatusCompleted, reason: "dust remaining", remaining: "0", history: []Execution{completed},
+			wantStatus: StatusCompleted, wantExecuted: "0.10000000", wantRemaining: "0.10000000"},
+		{name: "cancelled plan that traded everything stays cancelled", status: StatusCancelled, cancelReason: "kill switch", remaining: "0.1",
+			history: []Execution{completed, completed}, wantStatus: StatusCancelled, wantExecuted: "0.20000000", wantRemaining: "0"},
+		{name: "cancelled plan with some left stays cancelled", status: StatusCancelled, cancelReason: "kill switch", remaining: "0",
+			history: []Execution{completed}, wantStatus: StatusCancelled, wantExecuted: "0.10000000", wantRemaining: "0.10000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t, "0.2", "0.1", "0.2")
+			p, _ := manager.storage.Get("p")
+			p.Status, p.CompletionReason, p.CancelReason = tt.status, tt.reason, tt.cancelReason
+			p.RemainingAmount, p.TotalExecuted = tt.remaining, "0.13"
+			p.ExecutionHistory = nil
+			for i, exec := range tt.history {
+				exec.ID = fmt.Sprintf("exec-%d", i)
+				exec.Timestamp = time.Now()
+				p.ExecutionHistory = append(p.ExecutionHistory, exec)
+			}
+			if err := manager.storage.Update(p); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := manager.RecomputeProgress("p")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.wantStatus || got.TotalExecuted != tt.wantExecuted || got.RemainingAmount != tt.wantRemaining {
+				t.Errorf("plan %s, executed %s, remaining %s; want %s, %s, %s",
+					got.Status, got.TotalExecuted, got.RemainingAmount, tt.wantStatus, tt.wantExecuted, tt.wantRemaining)
+			}
+			if got.CompletionReason != tt.reason || got.CancelReason != tt.cancelReason {
+				t.Errorf("reasons = %q / %q, want %q / %q", got.CompletionReason, got.CancelReason, tt.reason, tt.cancelReason)
+			}
+			if got.ExecutionCount != len(tt.history) {
+				t.Errorf("execution count = %d, want %d", got.ExecutionCount, len(tt.history))
+			}
+			if stored, _ := manager.GetPlan("p"); stored.Status != got.Status || stored.RemainingAmount != got.RemainingAmount {
+				t.Errorf("recomputed plan wasn't stored")
+			}
+		})
+	}
+}
+
+func TestCreatePlanEnforcesMaxPlans(t *testing.T) {
+	manager := newTestManager(t, "0.2", "0.1", "0.2")
+	manager.SetLimits(2, 0)
+
+	create := func(name string) error {
+		_, err := manager.CreatePlan(name, "BTC", "USDC", "btc", "near", "0.2", "0.1", "0.2", "70000", PriceBelow,
+			"me.near", testRefundAddr, "", CreatePlanOptions{Force: true})
+		return err
+	}
+
+	if err := create("q"); err != nil {
+		t.Fatalf("second plan: %v", err)
+	}
+	err := create("r")
+	if err == nil || !strings.Contains(err.Error(), "plan limit reached: 2 plans stored (max_plans is 2)") {
+		t.Fatalf("third plan error = %v, want the plan limit", err)
+	}
+	if _, err := manager.GetPlan("r"); err == nil {
+		t.Error("plan over the limit was stored")
+	}
+
+	// Deleting a plan frees a slot, and 0 lifts the limit
+	if err := manager.DeletePlan("q"); err != nil {
+		t.Fatal(err)
+	}
+	if err := create("r"); err != nil {
+		t.Errorf("plan after freeing a slot: %v", err)
+	}
+	manager.SetLimits(0, 0)
+	if err := create("s"); err != nil {
+		t.Errorf("plan with no limit: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// executorMetrics counts what the executor has done since it was created
+type executorMetrics struct {
+	priceChecks     atomic.Int64 // Price checks that reached a price
+	tradesAttempted atomic.Int64 // Triggered trades the executor tried to make
+	tradesCompleted atomic.Int64 // Swaps verified as completed
+	tradesFailed    atomic.Int64 // Trades that failed before the deposit, or swaps that failed after it
+	lastAPISuccess  atomic.Int64 // Unix nanoseconds of the last successful 1Click API call (0 for none yet)
+}
+
+// apiSucceeded records a successful 1Click API call
+func (m *executorMetrics) apiSucceeded() {
+	m.lastAPISuccess.Store(time.Now().UnixNano())
+}
+
+// WriteMetrics writes the executor's metrics in the Prometheus text exposition format
+func (e *Executor) WriteMetrics(w io.Writer) error {
+	var b strings.Builder
+
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("near_swap_up", "gauge", "Whether the executor is running (1) or stopped (0).")
+	up := 0
+	if e.IsRunning() {
+		up = 1
+	}
+	fmt.Fprintf(&b, "near_swap_up %d\n", up)
+
+	metric("near_swap_active_plans", "gauge", "Plans the executor is monitoring.")
+	fmt.Fprintf(&b, "near_swap_active_plans %d\n", len(e.GetRunningPlans()))
+
+	metric("near_swap_price_checks_total", "counter", "Price checks that returned a price.")
+	fmt.Fprintf(&b, "near_swap_price_checks_total %d\n", e.metrics.priceChecks.Load())
+
+	metric("near_swap_executions_attempted_total", "counter", "Trades started after a plan's trigger was met.")
+	fmt.Fprintf(&b, "near_swap_executions_attempted_total %d\n", e.metrics.tradesAttempted.Load())
+
+	metric("near_swap_executions_completed_total", "counter", "Swaps verified as completed.")
+	fmt.Fprintf(&b, "near_swap_executions_completed_total %d\n", e.metrics.tradesCompleted.Load())
+
+	metric("near_swap_executions_failed_total", "counter", "Trades that failed before depositing, plus swaps that failed or were refunded.")
+	fmt.Fprintf(&b, "near_swap_executions_failed_total %d\n", e.metrics.tradesFailed.Load())
+
+	// Last prices are only known for plans checked since the daemon started
+	prices := e.activity.lastPrices()
+	if len(prices) > 0 {
+		metric("near_swap_plan_last_price", "gauge", "Latest price checked for a plan, in destination tokens per source token.")
+		names := make([]string, 0, len(prices))
+		for name := range prices {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&b, "near_swap_plan_last_price{plan=\"%s\"} %s\n", escapeLabel(name),
+				strconv.FormatFloat(prices[name], 'g', -1, 64))
+		}
+	}
+
+	if last := e.metrics.lastAPISuccess.Load(); last > 0 {
+		metric("near_swap_api_last_success_seconds", "gauge", "Seconds since the last successful 1Click API call.")
+		fmt.Fprintf(&b, "near_swap_api_last_success_seconds %.3f\n", time.Since(time.Unix(0, last)).Seconds())
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// lastPrices returns the latest price checked for each plan that has one
+func (t *activityTracker) lastPrices() map[string]float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prices := make(map[string]float64)
+	for name, activity := range t.plans {
+		if activity.lastPriceAt.IsZero() {
+			continue
+		}
+		if price, err := strconv.ParseFloat(activity.lastPrice, 64); err == nil {
+			prices[name] = price
+		}
+	}
+	return prices
+}
+
+// escapeLabel escapes a Prometheus label value
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// MetricsHandler serves /healthz, which answers 200 while the executor runs and 503 once it
+// has stopped, and /metrics in the Prometheus text format
+func (e *Executor) MetricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if !e.IsRunning() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, "stopped\n")
+			return
+		}
+		io.WriteString(w, "ok\n")
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		e.WriteMetrics(w)
+	})
+	return mux
+}
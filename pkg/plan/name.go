@@ -0,0 +1,44 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxPlanNameLength is the longest plan name CreatePlan accepts
+const MaxPlanNameLength = 64
+
+// planNamePattern restricts plan names to letters, digits, '.', '_' and '-', starting with a
+// letter or digit, so they are safe as identifiers, CLI arguments and file names
+var planNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// invalidNameChars matches runs of characters NormalizePlanName replaces with '-'
+var invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ValidatePlanName checks that a plan name is non-empty, at most MaxPlanNameLength characters
+// and uses only the allowed charset
+func ValidatePlanName(name string) error {
+	if name == "" {
+		return fmt.Errorf("plan name is required")
+	}
+	if len(name) > MaxPlanNameLength {
+		return fmt.Errorf("plan name is %d characters long; the limit is %d", len(name), MaxPlanNameLength)
+	}
+	if !planNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid plan name '%s': use letters, digits, '.', '_' and '-', starting with a letter or digit", name)
+	}
+	return nil
+}
+
+// NormalizePlanName turns an arbitrary name into a valid one: runs of disallowed characters
+// (spaces, slashes, ...) become '-', leading punctuation is dropped and the result is cut to
+// MaxPlanNameLength. It returns an empty string if nothing usable is left.
+func NormalizePlanName(name string) string {
+	name = invalidNameChars.ReplaceAllString(strings.TrimSpace(name), "-")
+	name = strings.TrimLeft(name, "._-")
+	if len(name) > MaxPlanNameLength {
+		name = name[:MaxPlanNameLength]
+	}
+	return strings.TrimRight(name, "-")
+}
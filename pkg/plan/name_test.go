@@ -0,0 +1,89 @@
+package plan
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidatePlanName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr string // Empty expects the name accepted
+	}{
+		{name: "btc-dca"},
+		{name: "Weekly_BTC.v2"},
+		{name: "7"},
+		{name: strings.Repeat("a", MaxPlanNameLength)},
+		{name: "", wantErr: "plan name is required"},
+		{name: strings.Repeat("a", MaxPlanNameLength+1), wantErr: "the limit is 64"},
+		{name: "btc dca", wantErr: "invalid plan name"},
+		{name: "../plans", wantErr: "invalid plan name"},
+		{name: "a/b", wantErr: "invalid plan name"},
+		{name: "-flag", wantErr: "invalid plan name"},
+		{name: "émoji", wantErr: "invalid plan name"},
+	}
+
+	for _, tt := range tests {
+		err := ValidatePlanName(tt.name)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("ValidatePlanName(%q) = %v, want it accepted", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ValidatePlanName(%q) = %v, want an error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNormalizePlanName(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{name: "btc-dca", want: "btc-dca"},
+		{name: "  weekly btc / usdc ", want: "weekly-btc-usdc"},
+		{name: "../plans", want: "plans"},
+		{name: strings.Repeat("ab", MaxPlanNameLength), want: strings.Repeat("ab", MaxPlanNameLength/2)},
+		{name: "///", want: ""},
+	}
+
+	for _, tt := range tests {
+		got := NormalizePlanName(tt.name)
+		if got != tt.want {
+			t.Errorf("NormalizePlanName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if got != "" {
+			if err := ValidatePlanName(got); err != nil {
+				t.Errorf("normalized %q is still invalid: %v", tt.name, err)
+			}
+		}
+	}
+}
+
+func TestCreatePlanValidatesName(t *testing.T) {
+	manager, err := NewManager(filepath.Join(t.TempDir(), "plans.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := func(name string, normalize bool) (*TradingPlan, error) {
+		return manager.CreatePlan(name, "BTC", "USDC", "btc", "near", "0.2", "0.1", "0.2", "70000", PriceBelow,
+			"me.near", testRefundAddr, "", CreatePlanOptions{Force: true, NormalizeName: normalize})
+	}
+
+	if _, err := create("my plan", false); err == nil || !strings.Contains(err.Error(), "invalid plan name") {
+		t.Errorf("error = %v, want an invalid plan name", err)
+	}
+	if manager.storage.Count() != 0 {
+		t.Error("plan with an invalid name was stored")
+	}
+
+	p, err := create("my plan", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "my-plan" {
+		t.Errorf("normalized name = %q, want my-plan", p.Name)
+	}
+}
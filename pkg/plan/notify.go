@@ -0,0 +1,267 @@
+package plan
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"near-swap/config"
+	"near-swap/pkg/logging"
+)
+
+// Execution events posted to the notifications webhook
+const (
+	EventTriggerMet    = "trigger_met"    // A plan's trigger fired and an execution was recorded
+	EventDepositSent   = "deposit_sent"   // The auto-deposit for an execution was broadcast
+	EventDepositFailed = "deposit_failed" // The auto-deposit for an execution could not be sent
+	EventSwapCompleted = "swap_completed" // The swap settled and the output was delivered
+	EventSwapFailed    = "swap_failed"    // The swap failed or was refunded after the deposit
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body, keyed with the configured
+// secret, as "sha256=<hex>". It is only sent when a secret is configured.
+const SignatureHeader = "X-Near-Swap-Signature"
+
+// Notification is the JSON payload posted to the webhook for each event
+type Notification struct {
+	Event          string    `json:"event"`
+	Timestamp      time.Time `json:"timestamp"`
+	Plan           string    `json:"plan"`
+	ExecutionID    string    `json:"execution_id,omitempty"`
+	Status         string    `json:"status,omitempty"`
+	SourceToken    string    `json:"source_token"`
+	DestToken      string    `json:"dest_token"`
+	SourceChain    string    `json:"source_chain"`
+	DestChain      string    `json:"dest_chain"`
+	Amount         string    `json:"amount,omitempty"`
+	TriggerPrice   string    `json:"trigger_price,omitempty"`
+	DepositAddress string    `json:"deposit_address,omitempty"`
+	DepositTxHash  string    `json:"deposit_tx_hash,omitempty"`
+	Output         string    `json:"output,omitempty"`
+	DestTxHash     string    `json:"dest_tx_hash,omitempty"`
+	RefundTxHash   string    `json:"refund_tx_hash,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	Test           bool      `json:"test,omitempty"` // Sample sent by `notify test`, not a real execution
+}
+
+// newNotification starts a payload for an event on plan
+func newNotification(event string, plan *TradingPlan) *Notification {
+	return &Notification{
+		Event:       event,
+		Timestamp:   time.Now().UTC(),
+		Plan:        plan.Name,
+		SourceToken: plan.SourceToken,
+		DestToken:   plan.DestToken,
+		SourceChain: plan.SourceChain,
+		DestChain:   plan.DestChain,
+	}
+}
+
+// withExecution fills the payload from an execution record
+func (n *Notification) withExecution(exec *Execution) *Notification {
+	n.ExecutionID = exec.ID
+	n.Status = string(exec.Status)
+	n.Amount = exec.Amount
+	n.TriggerPrice = exec.TriggerPrice
+	n.DepositAddress = exec.DepositAddress
+	n.DepositTxHash = exec.TxHash
+	n.Output = exec.ActualOutput
+	n.DestTxHash = exec.DestinationTxHash
+	n.RefundTxHash = exec.RefundTxHash
+	n.Error = exec.ErrorMessage
+	return n
+}
+
+// notificationSink is one destination notifications are delivered to
+type notificationSink interface {
+	Name() string
+	Deliver(n *Notification) error
+}
+
+// webhookSink posts notifications as JSON to an HTTP endpoint
+type webhookSink struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// Name identifies the sink in delivery reports
+func (w *webhookSink) Name() string {
+	return "webhook " + w.url
+}
+
+// Deliver posts n to the webhook, signing the body when a secret is configured
+func (w *webhookSink) Deliver(n *Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "near-swap")
+	if w.secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+signPayload(w.secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// notifier sends execution events to every configured sink. Delivery is best-effort: each
+// event is sent once in the background and failures are only logged, so a slow or broken
+// sink never holds up trading.
+type notifier struct {
+	sinks []notificationSink
+	log   *slog.Logger
+}
+
+// newNotifier returns a notifier for cfg, or nil when no sink is configured
+func newNotifier(cfg config.NotificationsConfig, log *slog.Logger) *notifier {
+	var sinks []notificationSink
+	if cfg.WebhookURL != "" {
+		sinks = append(sinks, &webhookSink{
+			url:    cfg.WebhookURL,
+			secret: cfg.Secret,
+			client: &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
+		})
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+	return &notifier{sinks: sinks, log: log}
+}
+
+// notify sends n to every sink in the background. It is a no-op on a nil notifier.
+func (nt *notifier) notify(n *Notification) {
+	if nt == nil {
+		return
+	}
+	for _, sink := range nt.sinks {
+		go func(sink notificationSink) {
+			if err := sink.Deliver(n); err != nil {
+				nt.log.Warn("Notification not delivered",
+					"plan", n.Plan, "execution_id", n.ExecutionID, "event", n.Event, "sink", sink.Name(), "error", err)
+			}
+		}(sink)
+	}
+}
+
+// SinkResult is the outcome of delivering a notification to one sink
+type SinkResult struct {
+	Sink  string `json:"sink"`
+	Error string `json:"error,omitempty"`
+}
+
+// deliverAll sends n to every sink, waiting for each, and reports how each delivery went
+func (nt *notifier) deliverAll(n *Notification) []SinkResult {
+	results := make([]SinkResult, len(nt.sinks))
+	var wg sync.WaitGroup
+	for i, sink := range nt.sinks {
+		wg.Add(1)
+		go func(i int, sink notificationSink) {
+			defer wg.Done()
+			results[i] = SinkResult{Sink: sink.Name()}
+			if err := sink.Deliver(n); err != nil {
+				results[i].Error = err.Error()
+			}
+		}(i, sink)
+	}
+	wg.Wait()
+	return results
+}
+
+// TestNotificationKinds maps the sample kinds `notify test` offers to the events they imitate
+var TestNotificationKinds = map[string]string{
+	"trade":    EventTriggerMet,
+	"complete": EventSwapCompleted,
+	"error":    EventSwapFailed,
+}
+
+// SendTestNotification delivers a sample notification of kind (see TestNotificationKinds) to
+// every sink configured in cfg, waiting for each, and reports how each delivery went
+func SendTestNotification(cfg config.NotificationsConfig, kind string) ([]SinkResult, error) {
+	event, ok := TestNotificationKinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown notification kind '%s': use trade, complete or error", kind)
+	}
+	nt := newNotifier(cfg, logging.Component(logging.Default(), "notifier"))
+	if nt == nil {
+		return nil, fmt.Errorf("no notification sinks configured; set notifications.webhook_url")
+	}
+
+	n := newNotification(event, &TradingPlan{
+		Name:        "example-plan",
+		SourceToken: "BTC",
+		DestToken:   "USDC",
+		SourceChain: "btc",
+		DestChain:   "near",
+	})
+	n.Test = true
+	n.ExecutionID = "test"
+	n.Amount = "0.01000000"
+	n.TriggerPrice = "60000.00000000"
+	n.DepositAddress = "bc1qexampledepositaddress"
+	switch event {
+	case EventTriggerMet:
+		n.Status = string(ExecutionPending)
+	case EventSwapCompleted:
+		n.Status = string(ExecutionCompleted)
+		n.DepositTxHash = "example-deposit-tx"
+		n.Output = "600.000000"
+		n.DestTxHash = "example-destination-tx"
+	case EventSwapFailed:
+		n.Status = string(ExecutionFailed)
+		n.DepositTxHash = "example-deposit-tx"
+		n.Error = "sample failure from notify test"
+	}
+
+	return nt.deliverAll(n), nil
+}
+
+// signPayload returns the hex HMAC-SHA256 of body keyed with secret
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// notifyExecution posts event for one of a plan's executions, looking up its latest record
+func (e *Executor) notifyExecution(event, planName, executionID string) {
+	if e.notifier == nil {
+		return
+	}
+	plan, err := e.manager.GetPlan(planName)
+	if err != nil {
+		return
+	}
+	n := newNotification(event, plan)
+	for i := range plan.ExecutionHistory {
+		if plan.ExecutionHistory[i].ID == executionID {
+			n.withExecution(&plan.ExecutionHistory[i])
+			break
+		}
+	}
+	n.ExecutionID = executionID
+	e.notifier.notify(n)
+}
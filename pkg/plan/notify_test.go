@@ -0,0 +1,111 @@
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"near-swap/config"
+)
+
+// recordingSink keeps every notification delivered to it, failing with err if set
+type recordingSink struct {
+	name string
+	err  error
+
+	mu        sync.Mutex
+	delivered []*Notification
+}
+
+func (r *recordingSink) Name() string { return r.name }
+
+func (r *recordingSink) Deliver(n *Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delivered = append(r.delivered, n)
+	return r.err
+}
+
+func TestDeliverAllReportsEachSink(t *testing.T) {
+	ok := &recordingSink{name: "ok"}
+	broken := &recordingSink{name: "broken", err: errors.New("connection refused")}
+	nt := &notifier{sinks: []notificationSink{ok, broken}}
+
+	n := &Notification{Event: EventSwapCompleted, Plan: "p", Test: true}
+	results := nt.deliverAll(n)
+
+	want := []SinkResult{{Sink: "ok"}, {Sink: "broken", Error: "connection refused"}}
+	if len(results) != len(want) || results[0] != want[0] || results[1] != want[1] {
+		t.Errorf("results = %+v, want %+v", results, want)
+	}
+	for _, sink := range []*recordingSink{ok, broken} {
+		if len(sink.delivered) != 1 || sink.delivered[0] != n {
+			t.Errorf("sink %s got %d notifications, want the sample once", sink.name, len(sink.delivered))
+		}
+	}
+}
+
+func TestSendTestNotification(t *testing.T) {
+	var received []Notification
+	var signatures []string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var n Notification
+		if err := json.Unmarshal(body, &n); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		received = append(received, n)
+		signatures = append(signatures, r.Header.Get(SignatureHeader))
+		if n.Event == EventSwapFailed {
+			http.Error(w, "rejected", http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(webhook.Close)
+	cfg := config.NotificationsConfig{WebhookURL: webhook.URL, Secret: "s3cret", Timeout: 5}
+
+	tests := []struct {
+		kind      string
+		wantEvent string
+		wantErr   string // Delivery error reported for the webhook
+	}{
+		{kind: "trade", wantEvent: EventTriggerMet},
+		{kind: "complete", wantEvent: EventSwapCompleted},
+		{kind: "error", wantEvent: EventSwapFailed, wantErr: "webhook responded with 500 Internal Server Error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			received, signatures = nil, nil
+			results, err := SendTestNotification(cfg, tt.kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 1 || results[0].Sink != "webhook "+webhook.URL || results[0].Error != tt.wantErr {
+				t.Errorf("results = %+v, want the webhook reported with error %q", results, tt.wantErr)
+			}
+			if len(received) != 1 {
+				t.Fatalf("webhook received %d notifications, want 1", len(received))
+			}
+			n := received[0]
+			if n.Event != tt.wantEvent || !n.Test || n.Plan != "example-plan" {
+				t.Errorf("payload = %+v, want a %s sample marked as a test", n, tt.wantEvent)
+			}
+			if !strings.HasPrefix(signatures[0], "sha256=") {
+				t.Errorf("signature header = %q, want the payload signed", signatures[0])
+			}
+		})
+	}
+
+	if _, err := SendTestNotification(cfg, "bogus"); err == nil || !strings.Contains(err.Error(), "unknown notification kind") {
+		t.Errorf("unknown kind: error = %v", err)
+	}
+	if _, err := SendTestNotification(config.NotificationsConfig{}, "trade"); err == nil || !strings.Contains(err.Error(), "no notification sinks configured") {
+		t.Errorf("no sinks: error = %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package plan
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// observedAmounts sums the source amounts of a plan's observed executions, in total and today
+func (tp *TradingPlan) observedAmounts() (total, today float64) {
+	todayDate := time.Now().Format("2006-01-02")
+	for _, exec := range tp.ExecutionHistory {
+		if exec.Status != ExecutionObserved {
+			continue
+		}
+		amount, _ := strconv.ParseFloat(exec.Amount, 64)
+		total += amount
+		if exec.Timestamp.Format("2006-01-02") == todayDate {
+			today += amount
+		}
+	}
+	return total, today
+}
+
+// observeTrade records the trade a plan would have made at priceInfo, without quoting a deposit
+// address or sending anything. Observed trades count against a virtual daily and total limit so
+// a trigger that stays met is recorded at the pace the plan would really trade.
+func (e *Executor) observeTrade(plan *TradingPlan, priceInfo *PriceInfo) error {
+	observedTotal, observedToday := plan.observedAmounts()
+	perDay, _ := strconv.ParseFloat(plan.AmountPerDay, 64)
+	remaining, _ := strconv.ParseFloat(plan.RemainingAmount, 64)
+
+	limit := perDay - observedToday
+	if left := remaining - observedTotal; left < limit {
+		limit = left
+	}
+	if limit <= 0.00000001 {
+		return nil
+	}
+
+	// Size the trade the way executeTrade would, from the price that triggered it
+	ladderPrice := ""
+	var amount float64
+	switch {
+	case plan.HasLadder():
+		index := plan.NextLadderLevel(priceInfo.triggerValue())
+		if index < 0 {
+			return nil
+		}
+		ladderPrice = plan.Ladder[index].Price
+		amount = plan.LadderRemaining(plan.Ladder[index])
+	case plan.IsDestSized():
+		destAmount, _ := strconv.ParseFloat(plan.AmountPerTradeDest, 64)
+		if priceInfo.PriceFloat <= 0 {
+			return fmt.Errorf("cannot size a dest-sized trade at price %s", priceInfo.Price)
+		}
+		amount = destAmount / priceInfo.PriceFloat
+	default:
+		amount, _ = strconv.ParseFloat(plan.AmountPerTrade, 64)
+	}
+	if limit < amount {
+		amount = limit
+	}
+
+	execution := Execution{
+		Amount:          fmt.Sprintf("%.8f", amount),
+		TriggerPrice:    priceInfo.Price,
+		ActualPrice:     priceInfo.Price,
+		Status:          ExecutionObserved,
+		EstimatedOutput: fmt.Sprintf("%.8f", amount*priceInfo.PriceFloat),
+		LadderPrice:     ladderPrice,
+	}
+	if _, err := e.manager.AddExecution(plan.Name, execution); err != nil {
+		return fmt.Errorf("failed to record observed execution: %w", err)
+	}
+
+	e.observeLog.Info("Plan would have traded",
+		"plan", plan.Name, "event", "trade_observed", "amount", execution.Amount, "source_token", plan.SourceToken,
+		"estimated_output", execution.EstimatedOutput, "dest_token", plan.DestToken, "price", priceInfo.Price)
+	return nil
+}
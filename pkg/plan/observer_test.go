@@ -0,0 +1,48 @@
+package plan
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestObserverModeRecordsTradesWithoutDepositing(t *testing.T) {
+	e, server := newMockExecutor(t) // 0.1 per trade, 0.2 per day, at 60000
+	calls, auditLog := useFakeBitcoinCLI(t, e)
+	e.config.ObserverMode = true
+
+	// The trigger stays met, but observed trades keep to the plan's daily limit
+	for i := 0; i < 3; i++ {
+		e.checkAndExecutePlan("p", nil)
+	}
+
+	p, err := e.manager.GetPlan("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.ExecutionHistory) != 2 {
+		t.Fatalf("%d executions recorded, want 2 would-be trades", len(p.ExecutionHistory))
+	}
+	for i, exec := range p.ExecutionHistory {
+		if exec.Status != ExecutionObserved || exec.Amount != "0.10000000" || exec.EstimatedOutput != "6000.00000000" ||
+			exec.DepositAddress != "" || exec.TxHash != "" {
+			t.Errorf("execution %d = %+v, want an observed 0.1 trade for 6000 with no deposit", i+1, exec)
+		}
+	}
+
+	// Nothing was quoted for real, sent or counted
+	for _, req := range server.QuoteRequests() {
+		if !req.Dry {
+			t.Errorf("observer requested a deposit address: %+v", req)
+		}
+	}
+	if sent, _ := os.ReadFile(calls); strings.Contains(string(sent), "sendtoaddress") {
+		t.Errorf("observer sent a deposit:\n%s", sent)
+	}
+	if _, err := os.Stat(auditLog); !os.IsNotExist(err) {
+		t.Errorf("observer wrote a deposit audit log (stat error %v)", err)
+	}
+	if p.TotalExecuted != "0" || p.RemainingAmount != "0.2" {
+		t.Errorf("executed %s, remaining %s; observed trades shouldn't count as progress", p.TotalExecuted, p.RemainingAmount)
+	}
+}
@@ -0,0 +1,66 @@
+package plan
+
+import (
+	"fmt"
+	"sort"
+)
+
+// BulkPauseReason is the pause reason recorded by PauseAll, so ResumeAll reactivates only
+// the plans it paused and leaves manually stopped or automatically paused ones alone
+const BulkPauseReason = "paused by plan pause-all"
+
+// BulkResult is what PauseAll or ResumeAll did to one plan
+type BulkResult struct {
+	Plan    string     `json:"plan"`
+	Changed bool       `json:"changed"`
+	Status  PlanStatus `json:"status"`           // Status after the call
+	Detail  string     `json:"detail,omitempty"` // Why a plan was left alone
+	Error   string     `json:"error,omitempty"`  // Why changing the plan failed
+}
+
+// PauseAll pauses every active plan with BulkPauseReason. Results are sorted by plan name.
+func (m *Manager) PauseAll() []BulkResult {
+	results := make([]BulkResult, 0)
+	for _, p := range m.GetActivePlans() {
+		result := BulkResult{Plan: p.Name, Status: StatusPaused, Changed: true}
+		if err := m.PausePlan(p.Name, BulkPauseReason); err != nil {
+			result = BulkResult{Plan: p.Name, Status: p.Status, Error: err.Error()}
+		}
+		results = append(results, result)
+	}
+
+	sortBulkResults(results)
+	return results
+}
+
+// ResumeAll reactivates the plans PauseAll paused. Other paused plans are reported but stay
+// paused. Results are sorted by plan name.
+func (m *Manager) ResumeAll() []BulkResult {
+	results := make([]BulkResult, 0)
+	for _, p := range m.ListPlansByStatus(StatusPaused) {
+		if p.PauseReason != BulkPauseReason {
+			detail := "stopped manually"
+			if p.PauseReason != "" {
+				detail = fmt.Sprintf("paused: %s", p.PauseReason)
+			}
+			results = append(results, BulkResult{Plan: p.Name, Status: StatusPaused, Detail: detail})
+			continue
+		}
+
+		result := BulkResult{Plan: p.Name, Status: StatusActive, Changed: true}
+		if err := m.ResumePlan(p.Name, BulkPauseReason); err != nil {
+			result = BulkResult{Plan: p.Name, Status: StatusPaused, Error: err.Error()}
+		}
+		results = append(results, result)
+	}
+
+	sortBulkResults(results)
+	return results
+}
+
+// sortBulkResults orders results by plan name
+func sortBulkResults(results []BulkResult) {
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Plan < results[j].Plan
+	})
+}
@@ -0,0 +1,116 @@
+package plan
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"near-swap/pkg/parser"
+)
+
+// Performance summarizes what a plan's completed executions have traded over its lifetime.
+// Prices are in units of the dest token per source token; amounts and prices are exact decimals.
+type Performance struct {
+	Completed      int    `json:"completed"`
+	Sold           string `json:"sold"`                          // Source token swapped
+	Received       string `json:"received"`                      // Dest token received, net of swap fees
+	AveragePrice   string `json:"average_price,omitempty"`       // Received per source token sold
+	TriggerValue   string `json:"trigger_value,omitempty"`       // What the sold amount was worth at the trigger prices
+	VersusTrigger  string `json:"versus_trigger,omitempty"`      // Received minus TriggerValue
+	CostBasis      string `json:"cost_basis,omitempty"`          // Cost of the sold amount, when a basis is given
+	RealizedPnL    string `json:"realized_pnl,omitempty"`        // Received minus CostBasis
+	RealizedReturn string `json:"realized_return_pct,omitempty"` // RealizedPnL as a percentage of CostBasis
+}
+
+// CalculatePerformance sums the plan's completed executions. Executions whose output hasn't been
+// reported are counted at their estimated output, as in the tax ledger. When lots are given, the
+// sold amount is matched against them first-in first-out to compute a cost basis and realized
+// P&L; it is an error if the lots don't cover everything sold.
+func CalculatePerformance(tp *TradingPlan, lots []BasisLot) (*Performance, error) {
+	executions := make([]Execution, 0, len(tp.ExecutionHistory))
+	for _, exec := range tp.ExecutionHistory {
+		if exec.Status == ExecutionCompleted {
+			executions = append(executions, exec)
+		}
+	}
+	sort.SliceStable(executions, func(i, j int) bool {
+		return ledgerDate(executions[i]).Before(ledgerDate(executions[j]))
+	})
+
+	// Work on copies so callers can reuse their lots
+	remaining := make([]BasisLot, len(lots))
+	for i, lot := range lots {
+		remaining[i] = lot
+		if lot.Amount != nil {
+			remaining[i].Amount = new(big.Rat).Set(lot.Amount)
+		}
+	}
+
+	sold, received := new(big.Rat), new(big.Rat)
+	triggerValue := new(big.Rat)
+	triggerPriced := true
+	basis := new(big.Rat)
+
+	for _, exec := range executions {
+		amount, err := parseDecimal(exec.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("execution %s: %w", exec.ID, err)
+		}
+		outputStr := exec.ActualOutput
+		if outputStr == "" {
+			outputStr = exec.EstimatedOutput
+		}
+		output := new(big.Rat)
+		if outputStr != "" {
+			normalized, err := parser.NormalizeFormattedAmount(outputStr)
+			if err != nil {
+				return nil, fmt.Errorf("execution %s: %w", exec.ID, err)
+			}
+			if output, err = parseDecimal(normalized); err != nil {
+				return nil, fmt.Errorf("execution %s: %w", exec.ID, err)
+			}
+		}
+
+		sold.Add(sold, amount)
+		received.Add(received, output)
+
+		// One execution recorded without a trigger price leaves out the trigger comparison
+		if trigger, err := parseDecimal(exec.TriggerPrice); err == nil && trigger.Sign() > 0 {
+			triggerValue.Add(triggerValue, new(big.Rat).Mul(amount, trigger))
+		} else {
+			triggerPriced = false
+		}
+
+		if len(remaining) > 0 {
+			cost, err := consumeLots(remaining, amount)
+			if err != nil {
+				return nil, fmt.Errorf("execution %s: %w", exec.ID, err)
+			}
+			basis.Add(basis, cost)
+		}
+	}
+
+	perf := &Performance{
+		Completed: len(executions),
+		Sold:      trimDecimal(formatDecimal(sold)),
+		Received:  trimDecimal(formatDecimal(received)),
+	}
+	if sold.Sign() > 0 {
+		perf.AveragePrice = trimDecimal(formatDecimal(new(big.Rat).Quo(received, sold)))
+	}
+	if triggerPriced && triggerValue.Sign() > 0 {
+		perf.TriggerValue = trimDecimal(formatDecimal(triggerValue))
+		perf.VersusTrigger = trimDecimal(formatDecimal(new(big.Rat).Sub(received, triggerValue)))
+	}
+	if len(lots) > 0 {
+		pnl := new(big.Rat).Sub(received, basis)
+		perf.CostBasis = trimDecimal(formatDecimal(basis))
+		perf.RealizedPnL = trimDecimal(formatDecimal(pnl))
+		if basis.Sign() > 0 {
+			pct := new(big.Rat).Mul(new(big.Rat).Quo(pnl, basis), big.NewRat(100, 1))
+			perf.RealizedReturn = pct.FloatString(2)
+		}
+	}
+
+	return perf, nil
+}
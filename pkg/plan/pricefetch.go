@@ -0,0 +1,169 @@
+package plan
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Price fetching for overviews of many plans
+const (
+	DefaultPriceFetchConcurrency = 4                // Quotes FetchPrices requests at once by default
+	priceCacheTTL                = 15 * time.Second // How long a fetched price is reused
+)
+
+// cachedPrice is a price fetched for a pair and when it was fetched
+type cachedPrice struct {
+	info    *PriceInfo
+	fetched time.Time
+}
+
+// PriceResult is the outcome of fetching one plan's current price
+type PriceResult struct {
+	Price *PriceInfo
+	Err   error
+}
+
+// priceKey identifies the quote a plan's price comes from; plans with the same key share it
+func priceKey(plan *TradingPlan) string {
+	return strings.Join([]string{
+		strings.ToUpper(plan.SourceToken), strings.ToLower(plan.SourceChain),
+		strings.ToUpper(plan.DestToken), strings.ToLower(plan.DestChain),
+		strconv.FormatBool(plan.IsDestSized()),
+	}, "|")
+}
+
+// FetchPrices fetches the current price of each plan, keyed by plan name, with at most
+// concurrency quotes in flight. Plans trading the same pair share one quote, and prices
+// fetched in the last priceCacheTTL are reused. Cancelling ctx stops outstanding fetches;
+// plans that weren't priced get ctx's error.
+func (p *Pricer) FetchPrices(ctx context.Context, plans []*TradingPlan, concurrency int) map[string]PriceResult {
+	return p.fetchPrices(ctx, plans, concurrency, p.GetPriceContext)
+}
+
+// fetchPrices implements FetchPrices with fetch doing the quoting
+func (p *Pricer) fetchPrices(ctx context.Context, plans []*TradingPlan, concurrency int,
+	fetch func(context.Context, *TradingPlan) (*PriceInfo, error)) map[string]PriceResult {
+	if concurrency <= 0 {
+		concurrency = DefaultPriceFetchConcurrency
+	}
+
+	// One representative plan per pair
+	pairs := make(map[string]*TradingPlan)
+	for _, plan := range plans {
+		if _, ok := pairs[priceKey(plan)]; !ok {
+			pairs[priceKey(plan)] = plan
+		}
+	}
+
+	byPair := make(map[string]PriceResult, len(pairs))
+	var mu sync.Mutex
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+	for key, plan := range pairs {
+		if info, ok := p.cachedPrice(key); ok {
+			byPair[key] = PriceResult{Price: info}
+			continue
+		}
+		if gctx.Err() != nil {
+			break
+		}
+
+		g.Go(func() error {
+			if err := gctx.Err(); err != nil {
+				return nil
+			}
+			info, err := fetch(gctx, plan)
+			if err == nil {
+				p.cachePrice(key, info)
+			}
+
+			mu.Lock()
+			byPair[key] = PriceResult{Price: info, Err: err}
+			mu.Unlock()
+			return nil
+		})
+	}
+	g.Wait()
+
+	results := make(map[string]PriceResult, len(plans))
+	for _, plan := range plans {
+		result, ok := byPair[priceKey(plan)]
+		if !ok {
+			result = PriceResult{Err: ctx.Err()}
+		}
+		results[plan.Name] = result
+	}
+	return results
+}
+
+// cachedPrice returns the price fetched for a pair within priceCacheTTL, if any
+func (p *Pricer) cachedPrice(key string) (*PriceInfo, bool) {
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+
+	entry, ok := p.cache[key]
+	if !ok || time.Since(entry.fetched) > priceCacheTTL {
+		return nil, false
+	}
+	return entry.info, true
+}
+
+// cachePrice remembers a freshly fetched price for a pair
+func (p *Pricer) cachePrice(key string, info *PriceInfo) {
+	p.cacheMu.Lock()
+	defer p.cacheMu.Unlock()
+
+	if p.cache == nil {
+		p.cache = make(map[string]cachedPrice)
+	}
+	p.cache[key] = cachedPrice{info: info, fetched: time.Now()}
+}
+
+// TriggerDistance returns how far, in percent of price, the price has to move before the
+// plan triggers: 0 once the trigger is met. ok is false when the plan has no usable trigger.
+// Ladders measure to their next unfilled level; trailing stops to the stop under the peak.
+func (tp *TradingPlan) TriggerDistance(price float64) (distance float64, ok bool) {
+	if price <= 0 {
+		return 0, false
+	}
+
+	condition := tp.PriceCondition
+	target := tp.TriggerPrice
+	if tp.IsTrailingStop() {
+		condition = PriceBelow
+		target = strconv.FormatFloat(tp.TrailingStopPrice(tp.TrailingPeak(price)), 'f', -1, 64)
+	} else if tp.HasLadder() {
+		target = ""
+		for _, level := range tp.Ladder {
+			if !level.Filled {
+				target = level.Price
+				break
+			}
+		}
+	}
+
+	targetPrice, err := strconv.ParseFloat(target, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	switch condition {
+	case PriceAbove:
+		distance = (targetPrice - price) / price * 100
+	case PriceBelow:
+		distance = (price - targetPrice) / price * 100
+	case PriceAt:
+		// Matches the 0.5% tolerance of the "at" condition
+		distance = math.Abs(targetPrice-price)/price*100 - 0.5
+	default:
+		return 0, false
+	}
+	return math.Max(distance, 0), true
+}
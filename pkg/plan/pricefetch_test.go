@@ -0,0 +1,107 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pairPlans returns n plans, each trading a different pair
+func pairPlans(n int) []*TradingPlan {
+	plans := make([]*TradingPlan, n)
+	for i := range plans {
+		plans[i] = &TradingPlan{Name: fmt.Sprintf("p%d", i), SourceToken: fmt.Sprintf("T%d", i), SourceChain: "near",
+			DestToken: "USDC", DestChain: "near"}
+	}
+	return plans
+}
+
+func TestFetchPricesBoundsConcurrency(t *testing.T) {
+	plans := append(pairPlans(10), &TradingPlan{Name: "same-pair", SourceToken: "T0", SourceChain: "near", DestToken: "USDC", DestChain: "near"})
+
+	var mu sync.Mutex
+	inFlight, maxInFlight, calls := 0, 0, 0
+	fetch := func(ctx context.Context, plan *TradingPlan) (*PriceInfo, error) {
+		mu.Lock()
+		inFlight++
+		calls++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return &PriceInfo{PriceFloat: 1, SourceToken: plan.SourceToken}, nil
+	}
+
+	p := &Pricer{}
+	results := p.fetchPrices(context.Background(), plans, 3, fetch)
+	if maxInFlight > 3 {
+		t.Errorf("%d fetches in flight at once, want at most 3", maxInFlight)
+	}
+	if calls != 10 {
+		t.Errorf("%d fetches for 10 pairs, want plans trading the same pair to share one", calls)
+	}
+	for _, plan := range plans {
+		if r := results[plan.Name]; r.Err != nil || r.Price == nil || r.Price.SourceToken != plan.SourceToken {
+			t.Errorf("%s: price %+v, error %v", plan.Name, r.Price, r.Err)
+		}
+	}
+
+	// Fresh prices are served from the cache
+	p.fetchPrices(context.Background(), plans, 3, fetch)
+	if calls != 10 {
+		t.Errorf("%d fetches after a repeat call, want the cached prices reused", calls)
+	}
+}
+
+func TestFetchPricesCancellation(t *testing.T) {
+	plans := pairPlans(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	started := make(chan struct{})
+	fetch := func(ctx context.Context, plan *TradingPlan) (*PriceInfo, error) {
+		calls.Add(1)
+		started <- struct{}{}
+		<-ctx.Done() // Hangs until cancelled
+		return nil, ctx.Err()
+	}
+
+	// Cancel once the first batch is hanging, as Ctrl+C would
+	go func() {
+		for i := 0; i < 3; i++ {
+			<-started
+		}
+		cancel()
+		for range started {
+		}
+	}()
+
+	done := make(chan map[string]PriceResult)
+	go func() { done <- (&Pricer{}).fetchPrices(ctx, plans, 3, fetch) }()
+
+	var results map[string]PriceResult
+	select {
+	case results = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fetchPrices still running after cancellation")
+	}
+	close(started)
+
+	if n := calls.Load(); n != 3 {
+		t.Errorf("%d fetches started, want none after the first 3 were cancelled", n)
+	}
+	for _, plan := range plans {
+		if r := results[plan.Name]; !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("%s: error = %v, want context.Canceled", plan.Name, r.Err)
+		}
+	}
+}
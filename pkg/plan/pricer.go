@@ -1,24 +1,53 @@
 package plan
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math"
 	"strconv"
+	"strings"
+	"sync"
 
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
 	"near-swap/pkg/client"
+	"near-swap/pkg/logging"
+	"near-swap/pkg/parser"
 	"near-swap/pkg/types"
 )
 
 // Pricer handles price fetching for trading plans
 type Pricer struct {
-	client *client.OneClickClient
+	client    *client.OneClickClient
+	providers []PriceProvider // Price sources, tried in order until one returns a price
+
+	cacheMu sync.Mutex
+	cache   map[string]cachedPrice // Recent FetchPrices results by pair
+
+	healthMu sync.Mutex
+	health   map[string]int // Consecutive failures by price source
+
+	log *slog.Logger
 }
 
-// NewPricer creates a new pricer instance
-func NewPricer(apiClient *client.OneClickClient) *Pricer {
-	return &Pricer{
+// NewPricer creates a new pricer instance that prices plans from sources, in order. Without
+// sources (or with only unknown ones) it prices from 1Click quotes.
+func NewPricer(apiClient *client.OneClickClient, sources ...string) *Pricer {
+	p := &Pricer{
 		client: apiClient,
+		health: make(map[string]int),
+		log:    logging.Component(logging.Default(), "pricer"),
+	}
+	for _, source := range sources {
+		if provider := newPriceProvider(source, apiClient); provider != nil {
+			p.providers = append(p.providers, provider)
+		}
 	}
+	if len(p.providers) == 0 {
+		p.providers = []PriceProvider{quotePriceProvider{client: apiClient}}
+	}
+	return p
 }
 
 // PriceInfo contains price information for a token pair
@@ -29,12 +58,79 @@ type PriceInfo struct {
 	DestToken      string
 	SourceChain    string
 	DestChain      string
+	TriggerValue   float64 // Price the trigger is evaluated against: PriceFloat, or its moving average for smoothed plans
+	FullTradePrice float64 // Price quoted for a full-size trade (set by MeasurePriceImpact)
+	PriceImpact    float64 // % the full-size price is worse than PriceFloat (set by MeasurePriceImpact)
+	Source         string  // Price source that supplied Price
+}
+
+// triggerValue returns the price the plan's trigger and ladder are evaluated against
+func (pi *PriceInfo) triggerValue() float64 {
+	if pi.TriggerValue != 0 {
+		return pi.TriggerValue
+	}
+	return pi.PriceFloat
 }
 
 // GetPrice fetches the current price for a token pair using a small test amount
 func (p *Pricer) GetPrice(plan *TradingPlan) (*PriceInfo, error) {
-	// Use a small test amount (0.1 of amountPerTrade) to get the price
-	testAmountFloat, err := strconv.ParseFloat(plan.AmountPerTrade, 64)
+	return p.GetPriceContext(context.Background(), plan)
+}
+
+// GetPriceContext fetches the current price like GetPrice, giving up when ctx is cancelled.
+// Each price source is tried in order until one returns a price.
+func (p *Pricer) GetPriceContext(ctx context.Context, plan *TradingPlan) (*PriceInfo, error) {
+	var errs []error
+	for i, provider := range p.providers {
+		info, err := provider.Price(ctx, plan)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			if len(p.providers) > 1 {
+				failures := p.recordSourceFailure(provider.Name())
+				p.log.Warn("Price source failed",
+					"plan", plan.Name, "price_source", provider.Name(), "failures", failures, "error", err)
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		if failures := p.recordSourceSuccess(provider.Name()); failures > 0 {
+			p.log.Info("Price source recovered",
+				"price_source", provider.Name(), "failures", failures)
+		}
+		if i > 0 {
+			p.log.Info("Plan priced by fallback source",
+				"plan", plan.Name, "price_source", provider.Name(), "price", info.Price)
+		}
+		info.Source = provider.Name()
+		return info, nil
+	}
+
+	// A single source's error is returned as is
+	if len(errs) == 1 {
+		return nil, errors.Unwrap(errs[0])
+	}
+	args := make([]any, len(errs))
+	for i, err := range errs {
+		args[i] = err
+	}
+	return nil, fmt.Errorf("all price sources failed: "+strings.TrimSuffix(strings.Repeat("%w; ", len(errs)), "; "), args...)
+}
+
+// Price prices a plan from a dry-run quote for a small test amount
+func (qp quotePriceProvider) Price(ctx context.Context, plan *TradingPlan) (*PriceInfo, error) {
+	// Use a small test amount (0.1 of amountPerTrade) to get the price.
+	// Dest-sized plans probe with a fraction of the dest amount via an EXACT_OUTPUT quote,
+	// laddered plans with a fraction of their next tranche.
+	perTrade := plan.AmountPerTrade
+	if plan.IsDestSized() {
+		perTrade = plan.AmountPerTradeDest
+	} else if plan.HasLadder() {
+		perTrade = plan.nextTranche()
+	}
+	testAmountFloat, err := strconv.ParseFloat(perTrade, 64)
 	if err != nil {
 		return nil, fmt.Errorf("invalid amount per trade: %w", err)
 	}
@@ -55,37 +151,23 @@ func (p *Pricer) GetPrice(plan *TradingPlan) (*PriceInfo, error) {
 		DestChain:     plan.DestChain,
 		RecipientAddr: plan.RecipientAddr,
 		RefundAddr:    plan.RefundAddr,
+		ExactOutput:   plan.IsDestSized(),
+		Dry:           true,
 	}
 
 	// Get quote from API (with dry=true to avoid creating actual deposit address)
-	quote, err := p.client.GetQuote(swapReq)
+	quote, err := qp.client.GetQuoteContext(ctx, swapReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get quote: %w", err)
 	}
 
 	// Extract price from quote
 	quoteDetails := quote.GetQuote()
-
-	// Parse input and output amounts
-	amountIn := quoteDetails.GetAmountInFormatted()
-	amountOut := quoteDetails.GetAmountOutFormatted()
-
-	amountInFloat, err := strconv.ParseFloat(amountIn, 64)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse amount in: %w", err)
-	}
-
-	amountOutFloat, err := strconv.ParseFloat(amountOut, 64)
+	price, err := quotePrice(&quoteDetails)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse amount out: %w", err)
+		return nil, err
 	}
 
-	// Calculate price: how many dest tokens for 1 source token
-	if amountInFloat == 0 {
-		return nil, fmt.Errorf("invalid amount in: 0")
-	}
-
-	price := amountOutFloat / amountInFloat
 	priceStr := fmt.Sprintf("%.8f", price)
 
 	return &PriceInfo{
@@ -98,28 +180,103 @@ func (p *Pricer) GetPrice(plan *TradingPlan) (*PriceInfo, error) {
 	}, nil
 }
 
+// quotePrice calculates how many dest tokens a quote gives for 1 source token
+func quotePrice(quoteDetails *oneclick.Quote) (float64, error) {
+	amountInFloat, err := parser.ParseFormattedAmount(quoteDetails.GetAmountInFormatted())
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse amount in: %w", err)
+	}
+
+	amountOutFloat, err := parser.ParseFormattedAmount(quoteDetails.GetAmountOutFormatted())
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse amount out: %w", err)
+	}
+
+	if amountInFloat == 0 {
+		return 0, fmt.Errorf("invalid amount in: 0")
+	}
+
+	return amountOutFloat / amountInFloat, nil
+}
+
+// QuoteDivergence returns how much worse (in percent) the deposit quote price is than the
+// price that triggered the plan. A better deposit price yields a negative value.
+func QuoteDivergence(triggerPrice, depositPrice float64) float64 {
+	if triggerPrice <= 0 {
+		return 0
+	}
+	return (triggerPrice - depositPrice) / triggerPrice * 100
+}
+
+// QuoteDivergenceLimit returns the most a plan's deposit quote may diverge from the trigger
+// price: the plan's own limit if it has one, otherwise configured. 0 means no limit.
+func (tp *TradingPlan) QuoteDivergenceLimit(configured float64) float64 {
+	if tp.MaxQuoteDivergence > 0 {
+		return tp.MaxQuoteDivergence
+	}
+	return configured
+}
+
 // CheckTriggerCondition checks if the current price meets the plan's trigger condition
 func (p *Pricer) CheckTriggerCondition(plan *TradingPlan, currentPrice *PriceInfo) (bool, error) {
+	// Trailing stops fire once the price has fallen far enough below the peak, which
+	// includes the current price in case it is a new high
+	if plan.IsTrailingStop() {
+		peak := plan.TrailingPeak(currentPrice.triggerValue())
+		return currentPrice.triggerValue() <= plan.TrailingStopPrice(peak), nil
+	}
+
 	triggerPrice, err := strconv.ParseFloat(plan.TriggerPrice, 64)
 	if err != nil {
 		return false, fmt.Errorf("invalid trigger price: %w", err)
 	}
 
+	// Laddered plans trigger whenever an unfilled level has been crossed
+	if plan.HasLadder() {
+		return plan.NextLadderLevel(currentPrice.triggerValue()) >= 0, nil
+	}
+
 	switch plan.PriceCondition {
 	case PriceAbove:
-		return currentPrice.PriceFloat >= triggerPrice, nil
+		return currentPrice.triggerValue() >= triggerPrice, nil
 	case PriceBelow:
-		return currentPrice.PriceFloat <= triggerPrice, nil
+		return currentPrice.triggerValue() <= triggerPrice, nil
 	case PriceAt:
 		// Use a 0.5% tolerance for "at" condition
 		tolerance := triggerPrice * 0.005
-		diff := math.Abs(currentPrice.PriceFloat - triggerPrice)
+		diff := math.Abs(currentPrice.triggerValue() - triggerPrice)
 		return diff <= tolerance, nil
 	default:
 		return false, fmt.Errorf("unknown price condition: %s", plan.PriceCondition)
 	}
 }
 
+// CheckKillSwitch returns a reason when the current price has crossed one of the plan's
+// kill switch prices, or an empty string when the plan should keep running
+func (p *Pricer) CheckKillSwitch(plan *TradingPlan, currentPrice *PriceInfo) (string, error) {
+	if plan.CancelBelow != "" {
+		cancelBelow, err := strconv.ParseFloat(plan.CancelBelow, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid cancel-below price: %w", err)
+		}
+		if currentPrice.PriceFloat <= cancelBelow {
+			return fmt.Sprintf("kill switch: price %s fell to or below %s", currentPrice.Price, plan.CancelBelow), nil
+		}
+	}
+
+	if plan.CancelAbove != "" {
+		cancelAbove, err := strconv.ParseFloat(plan.CancelAbove, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid cancel-above price: %w", err)
+		}
+		if currentPrice.PriceFloat >= cancelAbove {
+			return fmt.Sprintf("kill switch: price %s rose to or above %s", currentPrice.Price, plan.CancelAbove), nil
+		}
+	}
+
+	return "", nil
+}
+
 // ShouldExecute determines if a plan should execute a trade based on current price
 func (p *Pricer) ShouldExecute(plan *TradingPlan) (bool, *PriceInfo, error) {
 	// Check if plan can execute
@@ -133,6 +290,15 @@ func (p *Pricer) ShouldExecute(plan *TradingPlan) (bool, *PriceInfo, error) {
 		return false, nil, err
 	}
 
+	// Smoothed plans trigger on the moving average, and not before the window has filled
+	if plan.HasPriceSmoothing() {
+		smoothed, ready := plan.SmoothedPrice(currentPrice.PriceFloat)
+		currentPrice.TriggerValue = smoothed
+		if !ready {
+			return false, currentPrice, nil
+		}
+	}
+
 	// Check trigger condition
 	triggered, err := p.CheckTriggerCondition(plan, currentPrice)
 	if err != nil {
@@ -0,0 +1,87 @@
+package plan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"near-swap/config"
+	"near-swap/pkg/client"
+)
+
+// PriceProvider supplies the current price of a plan's pair
+type PriceProvider interface {
+	Name() string
+	Price(ctx context.Context, plan *TradingPlan) (*PriceInfo, error)
+}
+
+// quotePriceProvider prices a pair from a small dry-run 1Click quote
+type quotePriceProvider struct {
+	client *client.OneClickClient
+}
+
+func (quotePriceProvider) Name() string { return config.PriceSourceQuote }
+
+// tokenListPriceProvider prices a pair from the USD prices in the 1Click token list. It needs no
+// quote, so it keeps working when quoting fails, but ignores fees and the trade's size.
+type tokenListPriceProvider struct {
+	client *client.OneClickClient
+}
+
+func (tokenListPriceProvider) Name() string { return config.PriceSourceTokenList }
+
+// Price prices a plan as the ratio of its source and destination tokens' USD prices
+func (tp tokenListPriceProvider) Price(ctx context.Context, plan *TradingPlan) (*PriceInfo, error) {
+	sourceToken, err := tp.client.FindTokenOnChain(plan.SourceToken, plan.SourceChain)
+	if err != nil {
+		return nil, err
+	}
+	destToken, err := tp.client.FindTokenOnChain(plan.DestToken, plan.DestChain)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceUSD, destUSD := float64(sourceToken.GetPrice()), float64(destToken.GetPrice())
+	if sourceUSD <= 0 || destUSD <= 0 {
+		return nil, fmt.Errorf("token list has no USD price for %s or %s", plan.SourceToken, plan.DestToken)
+	}
+
+	price := sourceUSD / destUSD
+	return &PriceInfo{
+		Price:       fmt.Sprintf("%.8f", price),
+		PriceFloat:  price,
+		SourceToken: plan.SourceToken,
+		DestToken:   plan.DestToken,
+		SourceChain: plan.SourceChain,
+		DestChain:   plan.DestChain,
+	}, nil
+}
+
+// newPriceProvider returns the price source named name, or nil if there is none
+func newPriceProvider(name string, apiClient *client.OneClickClient) PriceProvider {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case config.PriceSourceQuote:
+		return quotePriceProvider{client: apiClient}
+	case config.PriceSourceTokenList:
+		return tokenListPriceProvider{client: apiClient}
+	default:
+		return nil
+	}
+}
+
+// recordSourceFailure counts a failed price lookup and returns the source's consecutive failures
+func (p *Pricer) recordSourceFailure(name string) int {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	p.health[name]++
+	return p.health[name]
+}
+
+// recordSourceSuccess resets a source's failures and returns how many there were
+func (p *Pricer) recordSourceSuccess(name string) int {
+	p.healthMu.Lock()
+	defer p.healthMu.Unlock()
+	failures := p.health[name]
+	delete(p.health, name)
+	return failures
+}
@@ -0,0 +1,97 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakePriceProvider returns a fixed price or error, counting calls
+type fakePriceProvider struct {
+	name  string
+	price float64
+	err   error
+	calls *int
+}
+
+func (f fakePriceProvider) Name() string { return f.name }
+
+func (f fakePriceProvider) Price(_ context.Context, plan *TradingPlan) (*PriceInfo, error) {
+	*f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &PriceInfo{Price: fmt.Sprintf("%.8f", f.price), PriceFloat: f.price,
+		SourceToken: plan.SourceToken, DestToken: plan.DestToken}, nil
+}
+
+func TestExecutorFallsBackToSecondaryPriceSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		secondary float64
+		wantTrade bool
+	}{
+		{name: "secondary price meets the trigger", secondary: 65000, wantTrade: true},
+		{name: "secondary price misses the trigger", secondary: 75000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, _ := newMockExecutor(t)
+			var primaryCalls, secondaryCalls, unusedCalls int
+			e.pricer.providers = []PriceProvider{
+				fakePriceProvider{name: "primary", err: errors.New("rate limited"), calls: &primaryCalls},
+				fakePriceProvider{name: "secondary", price: tt.secondary, calls: &secondaryCalls},
+				fakePriceProvider{name: "unused", price: 1, calls: &unusedCalls},
+			}
+
+			e.checkAndExecutePlan("p", nil)
+
+			if primaryCalls != 1 || secondaryCalls != 1 || unusedCalls != 0 {
+				t.Errorf("sources called %d, %d, %d times; want the chain to stop at the secondary",
+					primaryCalls, secondaryCalls, unusedCalls)
+			}
+			if e.pricer.health["primary"] != 1 || e.pricer.health["secondary"] != 0 {
+				t.Errorf("source failures = %v, want only the primary's counted", e.pricer.health)
+			}
+
+			p, _ := e.manager.GetPlan("p")
+			if !tt.wantTrade {
+				if len(p.ExecutionHistory) != 0 {
+					t.Errorf("%d executions, want none at the secondary's price", len(p.ExecutionHistory))
+				}
+				return
+			}
+			if len(p.ExecutionHistory) != 1 || p.ExecutionHistory[0].TriggerPrice != "65000.00000000" {
+				t.Fatalf("executions = %+v, want one triggered at the secondary's 65000", p.ExecutionHistory)
+			}
+		})
+	}
+}
+
+func TestPriceSourcesAllFailing(t *testing.T) {
+	var calls int
+	p := NewPricer(nil)
+	p.providers = []PriceProvider{
+		fakePriceProvider{name: "quote", err: errors.New("quote rejected"), calls: &calls},
+		fakePriceProvider{name: "tokens", err: errors.New("no USD price"), calls: &calls},
+	}
+	plan := &TradingPlan{Name: "p", SourceToken: "BTC", DestToken: "USDC"}
+
+	_, err := p.GetPrice(plan)
+	if err == nil || !strings.Contains(err.Error(), "all price sources failed: quote: quote rejected; tokens: no USD price") {
+		t.Errorf("error = %v, want every source's failure", err)
+	}
+
+	// A recovered source's failure count is reset
+	p.providers[0] = fakePriceProvider{name: "quote", price: 60000, calls: &calls}
+	info, err := p.GetPrice(plan)
+	if err != nil || info.Source != "quote" {
+		t.Fatalf("price %+v, error %v; want it from the recovered quote source", info, err)
+	}
+	if p.health["quote"] != 0 || p.health["tokens"] != 1 {
+		t.Errorf("source failures = %v, want the recovered source reset", p.health)
+	}
+}
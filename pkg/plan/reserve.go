@@ -0,0 +1,234 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+)
+
+// ErrLimitReached is returned (wrapped) when an execution doesn't fit what is left of a
+// plan's daily or total amount
+var ErrLimitReached = errors.New("plan limit reached")
+
+// reservationWindow is how long a pending execution that was never funded keeps its amount
+// reserved when its quote deadline is unknown. It matches how long verifyPendingSwaps polls.
+const reservationWindow = 24 * time.Hour
+
+// reservationExpiredMessage is recorded on an execution whose quote expired without a deposit
+const reservationExpiredMessage = "quote expired without a deposit"
+
+// failedSwapPauseReason is recorded on a completed plan reopened by a swap that failed or was
+// refunded after its deposit was counted
+const failedSwapPauseReason = "a swap failed or was refunded after the plan completed"
+
+// lockPlan serializes read-modify-write updates of one plan and returns the unlock function
+func (m *Manager) lockPlan(name string) func() {
+	lock, _ := m.locks.LoadOrStore(name, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// addProgress adds amount to the plan's executed totals and takes it off the remaining
+// amount (a negative amount gives it back), with exact decimal math so many small
+// executions add up to the total without drift. countToday also applies it to today's total.
+// It returns the new remaining amount.
+func (tp *TradingPlan) addProgress(amount *big.Rat, countToday bool) (*big.Rat, error) {
+	totalExecuted, err := parseDecimal(tp.TotalExecuted)
+	if err != nil {
+		return nil, fmt.Errorf("invalid total executed: %w", err)
+	}
+	remaining, err := parseDecimal(tp.RemainingAmount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remaining amount: %w", err)
+	}
+	todayExecuted, err := parseDecimal(tp.TodayExecuted)
+	if err != nil {
+		return nil, fmt.Errorf("invalid today executed: %w", err)
+	}
+
+	totalExecuted.Add(totalExecuted, amount)
+	remaining.Sub(remaining, amount)
+	if totalExecuted.Sign() < 0 {
+		totalExecuted.SetInt64(0)
+	}
+	tp.TotalExecuted = formatDecimal(totalExecuted)
+	tp.RemainingAmount = formatDecimal(remaining)
+	if countToday {
+		todayExecuted.Add(todayExecuted, amount)
+		if todayExecuted.Sign() < 0 {
+			todayExecuted.SetInt64(0)
+		}
+		tp.TodayExecuted = formatDecimal(todayExecuted)
+	}
+
+	return remaining, nil
+}
+
+// ReserveExecution records a pending execution and counts its amount against the plan's daily
+// and total limits and its ladder level right away, under the plan's lock, so two trades started at the same time
+// can't both pass the limit check and overshoot it. It fails with ErrLimitReached when the
+// amount doesn't fit what is left. The reservation is kept once the deposit goes through and
+// given back if the execution fails or its quote expires unfunded (see ExpireReservations). Reserving an execution that is already recorded returns
+// its ID without reserving the amount again.
+func (m *Manager) ReserveExecution(name string, execution Execution) (string, error) {
+	defer m.lockPlan(name)()
+
+	plan, err := m.storage.Get(name)
+	if err != nil {
+		return "", err
+	}
+
+	execution.ID = executionID(name, execution, time.Now())
+	if plan.findExecution(execution.ID) != nil {
+		return execution.ID, nil
+	}
+	if plan.Status != StatusActive {
+		return "", fmt.Errorf("plan '%s' is not active", name)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	if plan.LastExecutionDate != today {
+		plan.LastExecutionDate = today
+		plan.TodayExecuted = "0"
+	}
+
+	amount, err := parseDecimal(execution.Amount)
+	if err != nil {
+		return "", fmt.Errorf("invalid execution amount: %w", err)
+	}
+	dailyLeft, err := parseDecimal(plan.GetRemainingDailyAmount())
+	if err != nil {
+		return "", fmt.Errorf("invalid daily amount: %w", err)
+	}
+	totalLeft, err := parseDecimal(plan.RemainingAmount)
+	if err != nil {
+		return "", fmt.Errorf("invalid remaining amount: %w", err)
+	}
+
+	if amount.Cmp(dailyLeft) > 0 {
+		return "", fmt.Errorf("%w: %s %s exceeds the %s %s left today", ErrLimitReached,
+			trimDecimal(execution.Amount), plan.SourceToken, trimDecimal(formatDecimal(dailyLeft)), plan.SourceToken)
+	}
+	if amount.Cmp(totalLeft) > 0 {
+		return "", fmt.Errorf("%w: %s %s exceeds the %s %s left in the plan", ErrLimitReached,
+			trimDecimal(execution.Amount), plan.SourceToken, trimDecimal(plan.RemainingAmount), plan.SourceToken)
+	}
+
+	if _, err := plan.addProgress(amount, true); err != nil {
+		return "", err
+	}
+	if execution.LadderPrice != "" {
+		plan.recordLadderFill(execution.LadderPrice, amount)
+	}
+
+	execution.Timestamp = time.Now()
+	execution.Status = ExecutionPending
+	execution.Reserved = true
+	plan.ExecutionHistory = append(plan.ExecutionHistory, execution)
+	plan.ExecutionCount++
+	plan.LastUpdated = time.Now()
+
+	return execution.ID, m.storage.Update(plan)
+}
+
+// reservationExpired reports whether a reserved execution can no longer be funded: its quote
+// deadline has passed, or it has aged out of the reservation window. An execution with a
+// deposit sent, or one the API reports beyond PENDING_DEPOSIT, is settled by its swap status.
+func (exec *Execution) reservationExpired(now time.Time) bool {
+	if !exec.Reserved || exec.Status != ExecutionPending || exec.TxHash != "" {
+		return false
+	}
+	if exec.SwapStatus != "" && exec.SwapStatus != "PENDING_DEPOSIT" {
+		return false
+	}
+	if exec.QuoteDeadline != nil && now.After(*exec.QuoteDeadline) {
+		return true
+	}
+	return now.Sub(exec.Timestamp) >= reservationWindow
+}
+
+// ExpireReservations fails the plan's pending executions whose quotes expired without a
+// deposit and gives their reserved amounts back to the daily and total limits. It returns
+// the number of executions expired.
+func (m *Manager) ExpireReservations(name string, now time.Time) (int, error) {
+	defer m.lockPlan(name)()
+
+	plan, err := m.storage.Get(name)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for i := range plan.ExecutionHistory {
+		exec := &plan.ExecutionHistory[i]
+		if !exec.reservationExpired(now) {
+			continue
+		}
+		exec.Status = ExecutionFailed
+		exec.ErrorMessage = reservationExpiredMessage
+		plan.settleReservation(exec)
+		expired++
+	}
+	if expired == 0 {
+		return 0, nil
+	}
+
+	plan.LastUpdated = now
+	return expired, m.storage.Update(plan)
+}
+
+// settleReservation resolves a reserved execution's hold on the plan's limits once its status
+// has changed: a deposited or completed execution keeps the amount counted (completing the plan
+// if nothing is left), any other outcome gives it back. Still-pending executions stay reserved.
+func (tp *TradingPlan) settleReservation(exec *Execution) {
+	if !exec.Reserved || exec.Status == ExecutionPending {
+		return
+	}
+	exec.Reserved = false
+
+	amount, err := parseDecimal(exec.Amount)
+	if err != nil {
+		return
+	}
+
+	if exec.Status == ExecutionDeposited || exec.Status == ExecutionCompleted {
+		remaining, err := parseDecimal(tp.RemainingAmount)
+		if err == nil && remaining.Cmp(smallestAmount) < 0 {
+			tp.Status = StatusCompleted
+			tp.RemainingAmount = "0"
+		}
+		return
+	}
+
+	reservedToday := exec.Timestamp.Format("2006-01-02") == tp.LastExecutionDate
+	tp.addProgress(amount.Neg(amount), reservedToday)
+	if exec.LadderPrice != "" {
+		tp.recordLadderFill(exec.LadderPrice, amount)
+	}
+}
+
+// releaseCredit gives a counted execution's amount back to the plan's daily and total limits
+// and to its ladder level, for a deposit that was dropped or a swap that failed or was refunded.
+// A plan the execution had completed is reopened paused with pauseReason for review.
+func (tp *TradingPlan) releaseCredit(exec *Execution, pauseReason string) error {
+	amount, err := parseDecimal(exec.Amount)
+	if err != nil {
+		return fmt.Errorf("invalid execution amount: %w", err)
+	}
+
+	countedToday := exec.Timestamp.Format("2006-01-02") == tp.LastExecutionDate
+	if _, err := tp.addProgress(new(big.Rat).Neg(amount), countedToday); err != nil {
+		return err
+	}
+	if exec.LadderPrice != "" {
+		tp.recordLadderFill(exec.LadderPrice, new(big.Rat).Neg(amount))
+	}
+	if tp.Status == StatusCompleted && tp.CompletionReason == "" {
+		tp.Status = StatusPaused
+		tp.PauseReason = pauseReason
+	}
+	return nil
+}
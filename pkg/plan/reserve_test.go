@@ -0,0 +1,452 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestManager returns a manager over temp storage holding an active BTC -> USDC plan "p"
+func newTestManager(t *testing.T, total, perTrade, perDay string) *Manager {
+	t.Helper()
+	manager, err := NewManager(filepath.Join(t.TempDir(), "plans.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.CreatePlan("p", "BTC", "USDC", "btc", "near", total, perTrade, perDay, "70000", PriceBelow,
+		"me.near", testRefundAddr, "", CreatePlanOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.StartPlan("p", false); err != nil {
+		t.Fatal(err)
+	}
+	return manager
+}
+
+func TestAddProgress(t *testing.T) {
+	tests := []struct {
+		name          string
+		plan          TradingPlan
+		amount        string
+		countToday    bool
+		wantExecuted  string
+		wantRemaining string
+		wantToday     string
+		wantErr       bool
+	}{
+		{
+			name:          "adds to today",
+			plan:          TradingPlan{TotalExecuted: "0.1", RemainingAmount: "0.9", TodayExecuted: "0.1"},
+			amount:        "0.2",
+			countToday:    true,
+			wantExecuted:  "0.30000000",
+			wantRemaining: "0.70000000",
+			wantToday:     "0.30000000",
+		},
+		{
+			name:          "leaves today alone",
+			plan:          TradingPlan{TotalExecuted: "0.1", RemainingAmount: "0.9", TodayExecuted: "0.1"},
+			amount:        "0.2",
+			wantExecuted:  "0.30000000",
+			wantRemaining: "0.70000000",
+			wantToday:     "0.1",
+		},
+		{
+			name:          "negative amount gives it back",
+			plan:          TradingPlan{TotalExecuted: "0.3", RemainingAmount: "0.7", TodayExecuted: "0.3"},
+			amount:        "-0.2",
+			countToday:    true,
+			wantExecuted:  "0.10000000",
+			wantRemaining: "0.90000000",
+			wantToday:     "0.10000000",
+		},
+		{
+			name:          "executed totals never go negative",
+			plan:          TradingPlan{TotalExecuted: "0.1", RemainingAmount: "0.9", TodayExecuted: "0.1"},
+			amount:        "-0.2",
+			countToday:    true,
+			wantExecuted:  "0.00000000",
+			wantRemaining: "1.10000000",
+			wantToday:     "0.00000000",
+		},
+		{
+			name:          "empty amounts count as zero",
+			plan:          TradingPlan{RemainingAmount: "1"},
+			amount:        "0.00000001",
+			countToday:    true,
+			wantExecuted:  "0.00000001",
+			wantRemaining: "0.99999999",
+			wantToday:     "0.00000001",
+		},
+		{name: "invalid total executed", plan: TradingPlan{TotalExecuted: "lots", RemainingAmount: "1"}, amount: "0.1", wantErr: true},
+		{name: "invalid remaining amount", plan: TradingPlan{TotalExecuted: "0", RemainingAmount: "1,5"}, amount: "0.1", wantErr: true},
+		{name: "invalid today executed", plan: TradingPlan{RemainingAmount: "1", TodayExecuted: "?"}, amount: "0.1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount, _ := new(big.Rat).SetString(tt.amount)
+			p := tt.plan
+			remaining, err := p.addProgress(amount, tt.countToday)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if p.TotalExecuted != tt.plan.TotalExecuted || p.RemainingAmount != tt.plan.RemainingAmount {
+					t.Errorf("plan changed on error: executed %s, remaining %s", p.TotalExecuted, p.RemainingAmount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.TotalExecuted != tt.wantExecuted || p.RemainingAmount != tt.wantRemaining || p.TodayExecuted != tt.wantToday {
+				t.Errorf("executed %s, remaining %s, today %s; want %s, %s, %s",
+					p.TotalExecuted, p.RemainingAmount, p.TodayExecuted, tt.wantExecuted, tt.wantRemaining, tt.wantToday)
+			}
+			if formatDecimal(remaining) != tt.wantRemaining {
+				t.Errorf("returned remaining %s, want %s", formatDecimal(remaining), tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestAddExecutionAddsUpExactly(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        string
+		perTrade     string
+		trades       int
+		wantExecuted string
+	}{
+		{name: "tenths", total: "1", perTrade: "0.1", trades: 10, wantExecuted: "1.00000000"},
+		{name: "tenths that drift as floats", total: "0.9", perTrade: "0.3", trades: 3, wantExecuted: "0.90000000"},
+		{name: "a thousand tenths", total: "100", perTrade: "0.1", trades: 1000, wantExecuted: "100.00000000"},
+		{name: "smallest units", total: "0.00000007", perTrade: "0.00000001", trades: 7, wantExecuted: "0.00000007"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t, tt.total, tt.perTrade, tt.total)
+			for i := 0; i < tt.trades; i++ {
+				exec := Execution{Amount: tt.perTrade, DepositAddress: fmt.Sprintf("deposit-%d", i), Status: ExecutionCompleted}
+				if _, err := manager.AddExecution("p", exec); err != nil {
+					t.Fatal(err)
+				}
+				p, _ := manager.GetPlan("p")
+				if done := i == tt.trades-1; p.IsCompleted() != done {
+					t.Fatalf("after trade %d: completed = %v, remaining %s", i+1, p.IsCompleted(), p.RemainingAmount)
+				}
+			}
+
+			// 0.1 has no exact binary form; summed as floats, 1000 of them come to 99.9999999999986
+			p, _ := manager.GetPlan("p")
+			if p.TotalExecuted != tt.wantExecuted || p.RemainingAmount != "0" {
+				t.Errorf("executed %s, remaining %s; want %s, 0", p.TotalExecuted, p.RemainingAmount, tt.wantExecuted)
+			}
+		})
+	}
+}
+
+func TestReserveExecution(t *testing.T) {
+	tests := []struct {
+		name          string
+		earlier       []string // Amounts reserved on an earlier day
+		today         []string // Amounts already reserved today
+		amount        string
+		paused        bool
+		wantLimit     string // Which limit the reservation runs into
+		wantErr       bool
+		wantToday     string
+		wantRemaining string
+	}{
+		{name: "fits", amount: "0.1", wantToday: "0.10000000", wantRemaining: "0.90000000"},
+		{name: "fills the day", today: []string{"0.1"}, amount: "0.1", wantToday: "0.20000000", wantRemaining: "0.80000000"},
+		{name: "one unit over the day", today: []string{"0.1"}, amount: "0.10000001", wantLimit: "left today"},
+		{name: "rest of the total", earlier: []string{"0.2", "0.2", "0.2", "0.2", "0.15"}, amount: "0.05", wantToday: "0.05000000", wantRemaining: "0.00000000"},
+		{name: "one unit over the total", earlier: []string{"0.2", "0.2", "0.2", "0.2", "0.15"}, amount: "0.05000001", wantLimit: "left in the plan"},
+		{name: "day already used up", today: []string{"0.2"}, amount: "0.01", wantLimit: "left today"},
+		{name: "earlier days don't count today", earlier: []string{"0.2", "0.2"}, amount: "0.2", wantToday: "0.20000000", wantRemaining: "0.40000000"},
+		{name: "over the total", earlier: []string{"0.2", "0.2", "0.2", "0.2", "0.15"}, amount: "0.1", wantLimit: "left in the plan"},
+		{name: "plan not active", amount: "0.1", paused: true, wantErr: true},
+		{name: "invalid amount", amount: "0.1.1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t, "1", "0.1", "0.2")
+			for i, amount := range append(append([]string(nil), tt.earlier...), tt.today...) {
+				if _, err := manager.ReserveExecution("p", Execution{Amount: amount, DepositAddress: fmt.Sprintf("earlier-%d", i)}); err != nil {
+					t.Fatal(err)
+				}
+				if i < len(tt.earlier) {
+					p, _ := manager.storage.Get("p")
+					p.LastExecutionDate = "2000-01-01"
+					if err := manager.storage.Update(p); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if tt.paused {
+				if err := manager.PausePlan("p", "testing"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			before, _ := manager.GetPlan("p")
+
+			id, err := manager.ReserveExecution("p", Execution{Amount: tt.amount, DepositAddress: "deposit"})
+			after, _ := manager.GetPlan("p")
+			if tt.wantLimit != "" || tt.wantErr {
+				if err == nil {
+					t.Fatalf("reserved %s as %s, want an error", tt.amount, id)
+				}
+				if limited := errors.Is(err, ErrLimitReached); limited != (tt.wantLimit != "") || !strings.Contains(err.Error(), tt.wantLimit) {
+					t.Errorf("error = %v, want the %q limit", err, tt.wantLimit)
+				}
+				if after.RemainingAmount != before.RemainingAmount || len(after.ExecutionHistory) != len(before.ExecutionHistory) {
+					t.Errorf("failed reservation changed the plan")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if after.TodayExecuted != tt.wantToday || after.RemainingAmount != tt.wantRemaining {
+				t.Errorf("today %s, remaining %s; want %s, %s", after.TodayExecuted, after.RemainingAmount, tt.wantToday, tt.wantRemaining)
+			}
+			exec := after.findExecution(id)
+			if exec == nil || exec.Status != ExecutionPending || !exec.Reserved {
+				t.Fatalf("execution = %+v, want a pending reservation", exec)
+			}
+
+			// Recording the same execution again doesn't reserve it twice
+			again, err := manager.ReserveExecution("p", Execution{Amount: tt.amount, DepositAddress: "deposit"})
+			if err != nil || again != id {
+				t.Fatalf("second reservation = %s, %v; want %s", again, err, id)
+			}
+			if p, _ := manager.GetPlan("p"); p.RemainingAmount != tt.wantRemaining || len(p.ExecutionHistory) != len(after.ExecutionHistory) {
+				t.Errorf("second reservation counted again: remaining %s", p.RemainingAmount)
+			}
+		})
+	}
+}
+
+func TestReserveExecutionConcurrently(t *testing.T) {
+	manager := newTestManager(t, "1", "0.1", "1")
+
+	const traders = 40
+	var wg sync.WaitGroup
+	errs := make(chan error, traders)
+	for i := 0; i < traders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := manager.ReserveExecution("p", Execution{Amount: "0.1", DepositAddress: fmt.Sprintf("deposit-%d", i)})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	reserved := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			reserved++
+		case !errors.Is(err, ErrLimitReached):
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	p, _ := manager.GetPlan("p")
+	if reserved != 10 || len(p.ExecutionHistory) != 10 {
+		t.Errorf("reserved %d trades (%d recorded), want 10", reserved, len(p.ExecutionHistory))
+	}
+	if p.TotalExecuted != "1.00000000" || p.RemainingAmount != "0.00000000" || p.TodayExecuted != "1.00000000" {
+		t.Errorf("executed %s, remaining %s, today %s; want the whole plan and no more",
+			p.TotalExecuted, p.RemainingAmount, p.TodayExecuted)
+	}
+}
+
+func TestExpireReservations(t *testing.T) {
+	hour := time.Hour
+
+	tests := []struct {
+		name        string
+		deadline    *time.Duration // Quote deadline from now; nil when the quote didn't say
+		after       time.Duration  // How long after the reservation expiry runs
+		adjust      func(exec *Execution)
+		wantExpired bool
+	}{
+		{name: "deadline passed", deadline: &hour, after: 2 * time.Hour, wantExpired: true},
+		{name: "deadline ahead", deadline: &hour, after: 30 * time.Minute},
+		{name: "no deadline, aged out", after: reservationWindow, wantExpired: true},
+		{name: "no deadline, still recent", after: time.Hour},
+		{name: "still waiting for the deposit", deadline: &hour, after: 2 * time.Hour,
+			adjust: func(exec *Execution) { exec.SwapStatus = "PENDING_DEPOSIT" }, wantExpired: true},
+		{name: "deposit sent", deadline: &hour, after: 2 * time.Hour,
+			adjust: func(exec *Execution) { exec.TxHash = "tx" }},
+		{name: "API saw the deposit", deadline: &hour, after: 2 * time.Hour,
+			adjust: func(exec *Execution) { exec.SwapStatus = "PROCESSING" }},
+		{name: "not a reservation", after: reservationWindow,
+			adjust: func(exec *Execution) { exec.Reserved = false }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newTestManager(t, "1", "0.1", "0.2")
+			exec := Execution{Amount: "0.1", DepositAddress: "deposit"}
+			if tt.deadline != nil {
+				deadline := time.Now().Add(*tt.deadline)
+				exec.QuoteDeadline = &deadline
+			}
+			id, err := manager.ReserveExecution("p", exec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.adjust != nil {
+				p, _ := manager.storage.Get("p")
+				tt.adjust(p.findExecution(id))
+				if err := manager.storage.Update(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expired, err := manager.ExpireReservations("p", time.Now().Add(tt.after))
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, _ := manager.GetPlan("p")
+			got := p.findExecution(id)
+			if tt.wantExpired {
+				if expired != 1 || got.Status != ExecutionFailed || got.Reserved || got.ErrorMessage != reservationExpiredMessage {
+					t.Fatalf("expired %d, execution %s (reserved %v, %q); want it failed and released", expired, got.Status, got.Reserved, got.ErrorMessage)
+				}
+				if p.RemainingAmount != "1.00000000" || p.TodayExecuted != "0.00000000" {
+					t.Errorf("remaining %s, today %s; want the amount given back", p.RemainingAmount, p.TodayExecuted)
+				}
+				return
+			}
+			if expired != 0 || got.Status != ExecutionPending || p.RemainingAmount != "0.90000000" {
+				t.Errorf("expired %d, execution %s, remaining %s; want it left alone", expired, got.Status, p.RemainingAmount)
+			}
+		})
+	}
+}
+
+func TestSettleReservation(t *testing.T) {
+	today := time.Now()
+	yesterday := today.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name          string
+		status        ExecutionStatus
+		reserved      bool
+		at            time.Time
+		remaining     string
+		wantReserved  bool
+		wantRemaining string
+		wantToday     string
+		wantPlan      PlanStatus
+	}{
+		{name: "still pending", status: ExecutionPending, reserved: true, at: today, remaining: "0.5",
+			wantReserved: true, wantRemaining: "0.5", wantToday: "0.2", wantPlan: StatusActive},
+		{name: "not reserved", status: ExecutionFailed, at: today, remaining: "0.5",
+			wantRemaining: "0.5", wantToday: "0.2", wantPlan: StatusActive},
+		{name: "deposited keeps the amount", status: ExecutionDeposited, reserved: true, at: today, remaining: "0.5",
+			wantRemaining: "0.5", wantToday: "0.2", wantPlan: StatusActive},
+		{name: "completed with nothing left completes the plan", status: ExecutionCompleted, reserved: true, at: today, remaining: "0.000000001",
+			wantRemaining: "0", wantToday: "0.2", wantPlan: StatusCompleted},
+		{name: "failed gives the amount back", status: ExecutionFailed, reserved: true, at: today, remaining: "0.5",
+			wantRemaining: "0.60000000", wantToday: "0.10000000", wantPlan: StatusActive},
+		{name: "cancelled gives the amount back", status: ExecutionCancelled, reserved: true, at: today, remaining: "0.5",
+			wantRemaining: "0.60000000", wantToday: "0.10000000", wantPlan: StatusActive},
+		{name: "failed from an earlier day leaves today alone", status: ExecutionFailed, reserved: true, at: yesterday, remaining: "0.5",
+			wantRemaining: "0.60000000", wantToday: "0.2", wantPlan: StatusActive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := TradingPlan{Status: StatusActive, TotalExecuted: "0.5", RemainingAmount: tt.remaining, TodayExecuted: "0.2",
+				LastExecutionDate: today.Format("2006-01-02")}
+			exec := Execution{Amount: "0.1", Status: tt.status, Reserved: tt.reserved, Timestamp: tt.at}
+
+			p.settleReservation(&exec)
+			if exec.Reserved != tt.wantReserved {
+				t.Errorf("reserved = %v, want %v", exec.Reserved, tt.wantReserved)
+			}
+			if p.RemainingAmount != tt.wantRemaining || p.TodayExecuted != tt.wantToday || p.Status != tt.wantPlan {
+				t.Errorf("remaining %s, today %s, plan %s; want %s, %s, %s",
+					p.RemainingAmount, p.TodayExecuted, p.Status, tt.wantRemaining, tt.wantToday, tt.wantPlan)
+			}
+		})
+	}
+}
+
+func TestReleaseCredit(t *testing.T) {
+	today := time.Now()
+
+	tests := []struct {
+		name          string
+		status        PlanStatus
+		reason        string // Plan's completion reason
+		at            time.Time
+		amount        string
+		wantErr       bool
+		wantStatus    PlanStatus
+		wantRemaining string
+		wantToday     string
+		wantLadder    string
+	}{
+		{name: "active plan", status: StatusActive, at: today, amount: "0.1",
+			wantStatus: StatusActive, wantRemaining: "0.60000000", wantToday: "0.10000000", wantLadder: "0.40000000"},
+		{name: "earlier day leaves today alone", status: StatusActive, at: today.AddDate(0, 0, -2), amount: "0.1",
+			wantStatus: StatusActive, wantRemaining: "0.60000000", wantToday: "0.2", wantLadder: "0.40000000"},
+		{name: "reopens a completed plan paused", status: StatusCompleted, at: today, amount: "0.1",
+			wantStatus: StatusPaused, wantRemaining: "0.60000000", wantToday: "0.10000000", wantLadder: "0.40000000"},
+		{name: "plan completed for a reason stays completed", status: StatusCompleted, reason: "dust", at: today, amount: "0.1",
+			wantStatus: StatusCompleted, wantRemaining: "0.60000000", wantToday: "0.10000000", wantLadder: "0.40000000"},
+		{name: "invalid amount", status: StatusActive, at: today, amount: "a tenth", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := TradingPlan{Status: tt.status, CompletionReason: tt.reason, TotalAmount: "1", TotalExecuted: "0.5",
+				RemainingAmount: "0.5", TodayExecuted: "0.2", LastExecutionDate: today.Format("2006-01-02"),
+				Ladder: []LadderLevel{{Price: "100", Fraction: 0.5, Executed: "0.5", Filled: true}}}
+			exec := Execution{Amount: tt.amount, LadderPrice: "100", Timestamp: tt.at}
+
+			err := p.releaseCredit(&exec, "swap failed")
+			if tt.wantErr {
+				if err == nil || p.RemainingAmount != "0.5" {
+					t.Fatalf("error = %v, remaining %s; want an error and no change", err, p.RemainingAmount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if p.Status != tt.wantStatus || p.RemainingAmount != tt.wantRemaining || p.TodayExecuted != tt.wantToday {
+				t.Errorf("plan %s, remaining %s, today %s; want %s, %s, %s",
+					p.Status, p.RemainingAmount, p.TodayExecuted, tt.wantStatus, tt.wantRemaining, tt.wantToday)
+			}
+			wantReason := ""
+			if tt.wantStatus == StatusPaused {
+				wantReason = "swap failed"
+			}
+			if p.PauseReason != wantReason {
+				t.Errorf("pause reason = %q, want %q", p.PauseReason, wantReason)
+			}
+			if level := p.Ladder[0]; level.Executed != tt.wantLadder || level.Filled {
+				t.Errorf("ladder level executed %s (filled %v), want %s and open", level.Executed, level.Filled, tt.wantLadder)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package plan
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxPriceSmoothing is the largest moving-average window a plan may use, in price checks
+const MaxPriceSmoothing = 100
+
+// validatePriceSmoothing checks that a smoothing window is within range
+func validatePriceSmoothing(window int) error {
+	if window < 0 || window > MaxPriceSmoothing {
+		return fmt.Errorf("price smoothing must be between 0 and %d price checks", MaxPriceSmoothing)
+	}
+	return nil
+}
+
+// HasPriceSmoothing returns true if the plan triggers on a moving average of its price checks
+func (tp *TradingPlan) HasPriceSmoothing() bool {
+	return tp.PriceSmoothing > 1
+}
+
+// SmoothedPrice averages current with the plan's most recent recorded samples over the
+// smoothing window. ready is false until the window has filled, so a plan that was just
+// started can't trigger on its first (possibly spiking) price check.
+func (tp *TradingPlan) SmoothedPrice(current float64) (price float64, ready bool) {
+	if !tp.HasPriceSmoothing() {
+		return current, true
+	}
+
+	samples := tp.PriceSamples
+	if len(samples) > tp.PriceSmoothing-1 {
+		samples = samples[len(samples)-(tp.PriceSmoothing-1):]
+	}
+
+	sum := current
+	for _, sample := range samples {
+		sum += sample
+	}
+	return sum / float64(len(samples)+1), len(samples)+1 >= tp.PriceSmoothing
+}
+
+// RecordPriceSample appends a price check to a smoothed plan's rolling samples, keeping
+// only as many as its window needs. Plans without smoothing are left untouched.
+func (m *Manager) RecordPriceSample(planName string, price float64) error {
+	defer m.lockPlan(planName)()
+
+	plan, err := m.storage.Get(planName)
+	if err != nil {
+		return err
+	}
+	if !plan.HasPriceSmoothing() {
+		return nil
+	}
+
+	plan.PriceSamples = append(plan.PriceSamples, price)
+	if len(plan.PriceSamples) > plan.PriceSmoothing {
+		plan.PriceSamples = plan.PriceSamples[len(plan.PriceSamples)-plan.PriceSmoothing:]
+	}
+	plan.LastUpdated = time.Now()
+
+	return m.storage.Update(plan)
+}
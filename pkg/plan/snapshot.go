@@ -0,0 +1,234 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"near-swap/pkg/parser"
+)
+
+// MinSnapshotInterval is the shortest allowed interval between stats snapshots
+const MinSnapshotInterval = 5 * time.Second
+
+// StatsSnapshot is an aggregate view of all plans written periodically by the daemon
+type StatsSnapshot struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	Totals      SnapshotTotals `json:"totals"`
+	Plans       []PlanSnapshot `json:"plans"`
+}
+
+// SnapshotTotals aggregates counts across all plans
+type SnapshotTotals struct {
+	Plans       int `json:"plans"`
+	ActivePlans int `json:"active_plans"`
+	Executions  int `json:"executions"`
+	Completed   int `json:"completed"`
+	Pending     int `json:"pending"`
+	Failed      int `json:"failed"`
+}
+
+// PlanSnapshot holds progress and recent activity for a single plan
+type PlanSnapshot struct {
+	Name            string     `json:"name"`
+	Status          PlanStatus `json:"status"`
+	SourceToken     string     `json:"source_token"`
+	DestToken       string     `json:"dest_token"`
+	TotalAmount     string     `json:"total_amount"`
+	TotalExecuted   string     `json:"total_executed"`
+	RemainingAmount string     `json:"remaining_amount"`
+	TodayExecuted   string     `json:"today_executed"`
+	ProgressPercent float64    `json:"progress_percent"`
+	TotalReceived   string     `json:"total_received"`
+	Executions      int        `json:"executions"`
+	Completed       int        `json:"completed"`
+	Pending         int        `json:"pending"`
+	Failed          int        `json:"failed"`
+	Observed        int        `json:"observed,omitempty"`
+	Cancelled       int        `json:"cancelled,omitempty"`
+	LastPrice       string     `json:"last_price,omitempty"`
+	LastPriceAt     *time.Time `json:"last_price_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+	LastErrorAt     *time.Time `json:"last_error_at,omitempty"`
+}
+
+// planActivity is the executor's in-memory record of a plan's latest price check and error
+type planActivity struct {
+	lastPrice   string
+	lastPriceAt time.Time
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// activityTracker records per-plan activity for snapshots
+type activityTracker struct {
+	mu    sync.Mutex
+	plans map[string]*planActivity
+}
+
+func newActivityTracker() *activityTracker {
+	return &activityTracker{plans: make(map[string]*planActivity)}
+}
+
+func (t *activityTracker) get(name string) *planActivity {
+	activity, exists := t.plans[name]
+	if !exists {
+		activity = &planActivity{}
+		t.plans[name] = activity
+	}
+	return activity
+}
+
+// recordPrice stores the latest price seen for a plan
+func (t *activityTracker) recordPrice(name, price string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	activity := t.get(name)
+	activity.lastPrice = price
+	activity.lastPriceAt = time.Now()
+}
+
+// recordError stores the latest error seen for a plan
+func (t *activityTracker) recordError(name string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	activity := t.get(name)
+	activity.lastError = err.Error()
+	activity.lastErrorAt = time.Now()
+}
+
+// lookup returns a copy of a plan's activity
+func (t *activityTracker) lookup(name string) planActivity {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if activity, exists := t.plans[name]; exists {
+		return *activity
+	}
+	return planActivity{}
+}
+
+// BuildSnapshot aggregates stats for every plan the manager knows about
+func (e *Executor) BuildSnapshot() *StatsSnapshot {
+	snapshot := &StatsSnapshot{
+		GeneratedAt: time.Now().UTC(),
+		Plans:       []PlanSnapshot{},
+	}
+
+	for _, p := range e.manager.ListPlans() {
+		ps := PlanSnapshot{
+			Name:            p.Name,
+			Status:          p.Status,
+			SourceToken:     p.SourceToken,
+			DestToken:       p.DestToken,
+			TotalAmount:     p.TotalAmount,
+			TotalExecuted:   p.TotalExecuted,
+			RemainingAmount: p.RemainingAmount,
+			TodayExecuted:   p.TodayExecuted,
+			Executions:      len(p.ExecutionHistory),
+		}
+
+		total, _ := strconv.ParseFloat(p.TotalAmount, 64)
+		executed, _ := strconv.ParseFloat(p.TotalExecuted, 64)
+		if total > 0 {
+			ps.ProgressPercent = executed / total * 100
+		}
+
+		received := 0.0
+		for _, exec := range p.ExecutionHistory {
+			switch exec.Status {
+			case ExecutionCompleted:
+				ps.Completed++
+			case ExecutionFailed:
+				ps.Failed++
+			case ExecutionObserved:
+				ps.Observed++
+			case ExecutionCancelled:
+				ps.Cancelled++
+			default:
+				ps.Pending++
+			}
+			if exec.ActualOutput != "" {
+				if amount, err := parser.ParseFormattedAmount(exec.ActualOutput); err == nil {
+					received += amount
+				}
+			}
+		}
+		ps.TotalReceived = fmt.Sprintf("%.8f", received)
+
+		activity := e.activity.lookup(p.Name)
+		if !activity.lastPriceAt.IsZero() {
+			at := activity.lastPriceAt
+			ps.LastPrice = activity.lastPrice
+			ps.LastPriceAt = &at
+		}
+		if !activity.lastErrorAt.IsZero() {
+			at := activity.lastErrorAt
+			ps.LastError = activity.lastError
+			ps.LastErrorAt = &at
+		}
+
+		snapshot.Totals.Plans++
+		if p.IsActive() {
+			snapshot.Totals.ActivePlans++
+		}
+		snapshot.Totals.Executions += ps.Executions
+		snapshot.Totals.Completed += ps.Completed
+		snapshot.Totals.Pending += ps.Pending
+		snapshot.Totals.Failed += ps.Failed
+
+		snapshot.Plans = append(snapshot.Plans, ps)
+	}
+
+	return snapshot
+}
+
+// WriteSnapshot atomically writes a stats snapshot to path
+func WriteSnapshot(path string, snapshot *StatsSnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Write to temporary file first, then rename for atomic write
+	tempFile := path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	if err := os.Rename(tempFile, path); err != nil {
+		return fmt.Errorf("failed to rename temp file: %w", err)
+	}
+
+	return nil
+}
+
+// monitorSnapshots periodically writes the stats snapshot until the executor stops
+func (e *Executor) monitorSnapshots(path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	write := func() {
+		if err := WriteSnapshot(path, e.BuildSnapshot()); err != nil {
+			e.log.Error("Failed to write stats snapshot", "error", err)
+		}
+	}
+
+	write()
+	for {
+		select {
+		case <-e.stopChan:
+			write()
+			return
+		case <-ticker.C:
+			write()
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteSnapshot(t *testing.T) {
+	e, server := newMockExecutor(t)
+
+	// One settled trade, one still in flight and a failed price check
+	e.checkAndExecutePlan("p", nil)
+	_, exec := lastExecution(t, e)
+	server.QueueStatus(exec.DepositAddress, "SUCCESS")
+	e.checkSwapStatus("p", exec.ID, exec.DepositAddress)
+	e.checkAndExecutePlan("p", nil)
+	e.activity.recordError("p", errors.New("price source down"))
+
+	path := filepath.Join(t.TempDir(), "stats", "snapshot.json")
+	if err := WriteSnapshot(path, e.BuildSnapshot()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot StatsSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("snapshot isn't JSON: %v", err)
+	}
+
+	want := SnapshotTotals{Plans: 1, ActivePlans: 1, Executions: 2, Completed: 1, Pending: 1}
+	if snapshot.Totals != want {
+		t.Errorf("totals = %+v, want %+v", snapshot.Totals, want)
+	}
+	if len(snapshot.Plans) != 1 {
+		t.Fatalf("plans = %+v, want p", snapshot.Plans)
+	}
+	ps := snapshot.Plans[0]
+	if ps.Name != "p" || ps.TotalExecuted != "0.20000000" || ps.ProgressPercent != 100 || ps.TotalReceived != "1.00000000" {
+		t.Errorf("plan = %s: executed %s (%.0f%%), received %s; want p: 0.20000000 (100%%), 1.00000000",
+			ps.Name, ps.TotalExecuted, ps.ProgressPercent, ps.TotalReceived)
+	}
+	if ps.LastPrice != "60000.00000000" || ps.LastPriceAt == nil || ps.LastError != "price source down" || ps.LastErrorAt == nil {
+		t.Errorf("last price %q, last error %q; want the recorded activity", ps.LastPrice, ps.LastError)
+	}
+}
@@ -2,21 +2,88 @@ package plan
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 )
 
 const (
 	DefaultStorageFileName = ".near-swap-plans.json"
 )
 
+// Storage backends selectable with plan_storage_backend
+const (
+	BackendJSON   = "json"   // All plans in a single JSON file (default)
+	BackendSQLite = "sqlite" // Plans and executions in SQLite tables
+)
+
+// ErrPlanNotFound is returned (wrapped) when a plan does not exist
+var ErrPlanNotFound = errors.New("not found")
+
+// ErrPlanExists is returned (wrapped) when creating a plan whose name is taken
+var ErrPlanExists = errors.New("already exists")
+
 // Storage handles persistence of trading plans
-type Storage struct {
+type Storage interface {
+	Create(plan *TradingPlan) error
+	Get(name string) (*TradingPlan, error)
+	Update(plan *TradingPlan) error
+	Delete(name string) error
+	List() []*TradingPlan
+	ListByStatus(status PlanStatus) []*TradingPlan
+	GetExecutionsPage(name string, offset, limit int) ([]Execution, int, error)
+	Exists(name string) bool
+	Count() int
+	Reload() error           // Picks up changes made by other processes
+	SetWarnSize(bytes int64) // Warn once the storage file grows past bytes (0 disables)
+	GetFilePath() string
+}
+
+// OpenStorage opens plan storage with the given backend ("" means JSON). An empty path uses
+// the backend's default file in the home directory.
+func OpenStorage(backend, path string) (Storage, error) {
+	switch backend {
+	case "", BackendJSON:
+		return NewStorage(path)
+	case BackendSQLite:
+		return NewSQLiteStorage(path)
+	default:
+		return nil, fmt.Errorf("unknown plan storage backend '%s'", backend)
+	}
+}
+
+// sizeWarning prints a one-time warning when a storage file grows past a size
+type sizeWarning struct {
+	warnSize int64       // Storage file size in bytes above which a save warns (0 disables)
+	warned   atomic.Bool // The size warning is printed once per process
+}
+
+// SetWarnSize sets the storage file size in bytes above which saves print a warning; 0 disables it
+func (w *sizeWarning) SetWarnSize(bytes int64) {
+	w.warnSize = bytes
+}
+
+// checkSize warns, once, when a save has grown the storage file at path past the warning size
+func (w *sizeWarning) checkSize(path string, size int64) {
+	if w.warnSize <= 0 || size <= w.warnSize || w.warned.Swap(true) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: plan storage %s is %.1f MB, above the %.1f MB warning threshold; "+
+		"consider deleting finished plans\n", path, float64(size)/(1<<20), float64(w.warnSize)/(1<<20))
+}
+
+// JSONStorage keeps all plans in memory and persists them to a single JSON file
+type JSONStorage struct {
 	filePath string
 	mu       sync.RWMutex
 	plans    map[string]*TradingPlan
+
+	sizeWarning
+	stampTracker // Detects writes to the file by other processes
 }
 
 // PlanStorage represents the JSON structure for storage
@@ -24,8 +91,8 @@ type PlanStorage struct {
 	Plans map[string]*TradingPlan `json:"plans"`
 }
 
-// NewStorage creates a new storage instance
-func NewStorage(filePath string) (*Storage, error) {
+// NewStorage creates a new JSON storage instance
+func NewStorage(filePath string) (*JSONStorage, error) {
 	if filePath == "" {
 		// Default to home directory
 		home, err := os.UserHomeDir()
@@ -34,8 +101,11 @@ func NewStorage(filePath string) (*Storage, error) {
 		}
 		filePath = filepath.Join(home, DefaultStorageFileName)
 	}
+	if err := checkStoragePath(filePath, DefaultStorageFileName); err != nil {
+		return nil, err
+	}
 
-	storage := &Storage{
+	storage := &JSONStorage{
 		filePath: filePath,
 		plans:    make(map[string]*TradingPlan),
 	}
@@ -51,12 +121,22 @@ func NewStorage(filePath string) (*Storage, error) {
 	return storage, nil
 }
 
-// load reads plans from the storage file
-func (s *Storage) load() error {
+// load reads plans from the storage file, merging them with those already in memory
+func (s *JSONStorage) load() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	data, err := os.ReadFile(s.filePath)
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(file)
 	if err != nil {
 		return err
 	}
@@ -66,16 +146,31 @@ func (s *Storage) load() error {
 		return fmt.Errorf("failed to unmarshal plans: %w", err)
 	}
 
+	if planStorage.Plans == nil {
+		planStorage.Plans = make(map[string]*TradingPlan)
+	}
+	mergeLoaded(s.plans, planStorage.Plans)
 	s.plans = planStorage.Plans
-	if s.plans == nil {
+	s.setStamp(stampOf(info))
+
+	return nil
+}
+
+// Reload re-reads plans from the storage file, picking up changes made by other processes
+func (s *JSONStorage) Reload() error {
+	if err := s.load(); err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to load plans: %w", err)
+		}
+		s.mu.Lock()
 		s.plans = make(map[string]*TradingPlan)
+		s.mu.Unlock()
 	}
-
 	return nil
 }
 
 // save writes plans to the storage file
-func (s *Storage) save() error {
+func (s *JSONStorage) save() error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -104,16 +199,23 @@ func (s *Storage) save() error {
 		return fmt.Errorf("failed to rename temp file: %w", err)
 	}
 
+	if info, err := os.Stat(s.filePath); err == nil {
+		s.setStamp(stampOf(info))
+	}
+	s.checkSize(s.filePath, int64(len(data)))
+
 	return nil
 }
 
 // Create adds a new plan to storage
-func (s *Storage) Create(plan *TradingPlan) error {
+func (s *JSONStorage) Create(plan *TradingPlan) error {
+	s.refresh()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if _, exists := s.plans[plan.Name]; exists {
-		return fmt.Errorf("plan '%s' already exists", plan.Name)
+		return fmt.Errorf("plan '%s' %w", plan.Name, ErrPlanExists)
 	}
 
 	s.plans[plan.Name] = plan
@@ -123,29 +225,38 @@ func (s *Storage) Create(plan *TradingPlan) error {
 	err := s.save()
 	s.mu.Lock()
 
+	// Don't keep a plan in memory that never reached the file
+	if err != nil && s.plans[plan.Name] == plan {
+		delete(s.plans, plan.Name)
+	}
+
 	return err
 }
 
 // Get retrieves a plan by name
-func (s *Storage) Get(name string) (*TradingPlan, error) {
+func (s *JSONStorage) Get(name string) (*TradingPlan, error) {
+	s.refresh()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	plan, exists := s.plans[name]
 	if !exists {
-		return nil, fmt.Errorf("plan '%s' not found", name)
+		return nil, fmt.Errorf("plan '%s' %w", name, ErrPlanNotFound)
 	}
 
 	return plan, nil
 }
 
 // Update modifies an existing plan
-func (s *Storage) Update(plan *TradingPlan) error {
+func (s *JSONStorage) Update(plan *TradingPlan) error {
+	s.refresh()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if _, exists := s.plans[plan.Name]; !exists {
-		return fmt.Errorf("plan '%s' not found", plan.Name)
+		return fmt.Errorf("plan '%s' %w", plan.Name, ErrPlanNotFound)
 	}
 
 	s.plans[plan.Name] = plan
@@ -159,12 +270,14 @@ func (s *Storage) Update(plan *TradingPlan) error {
 }
 
 // Delete removes a plan from storage
-func (s *Storage) Delete(name string) error {
+func (s *JSONStorage) Delete(name string) error {
+	s.refresh()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if _, exists := s.plans[name]; !exists {
-		return fmt.Errorf("plan '%s' not found", name)
+		return fmt.Errorf("plan '%s' %w", name, ErrPlanNotFound)
 	}
 
 	delete(s.plans, name)
@@ -178,7 +291,9 @@ func (s *Storage) Delete(name string) error {
 }
 
 // List returns all plans
-func (s *Storage) List() []*TradingPlan {
+func (s *JSONStorage) List() []*TradingPlan {
+	s.refresh()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -191,7 +306,9 @@ func (s *Storage) List() []*TradingPlan {
 }
 
 // ListByStatus returns plans filtered by status
-func (s *Storage) ListByStatus(status PlanStatus) []*TradingPlan {
+func (s *JSONStorage) ListByStatus(status PlanStatus) []*TradingPlan {
+	s.refresh()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -205,8 +322,45 @@ func (s *Storage) ListByStatus(status PlanStatus) []*TradingPlan {
 	return plans
 }
 
+// GetExecutionsPage returns up to limit executions of a plan, newest first, skipping the
+// first offset, along with the total number of executions. The JSON backend keeps history
+// in memory and just slices it; backends that archive history should page at the source.
+func (s *JSONStorage) GetExecutionsPage(name string, offset, limit int) ([]Execution, int, error) {
+	s.refresh()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	plan, exists := s.plans[name]
+	if !exists {
+		return nil, 0, fmt.Errorf("plan '%s' %w", name, ErrPlanNotFound)
+	}
+
+	total := len(plan.ExecutionHistory)
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || offset >= total {
+		return []Execution{}, total, nil
+	}
+
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+
+	page := make([]Execution, 0, end-offset)
+	for i := total - 1 - offset; i >= total-end; i-- {
+		page = append(page, plan.ExecutionHistory[i])
+	}
+
+	return page, total, nil
+}
+
 // Exists checks if a plan with the given name exists
-func (s *Storage) Exists(name string) bool {
+func (s *JSONStorage) Exists(name string) bool {
+	s.refresh()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -215,7 +369,9 @@ func (s *Storage) Exists(name string) bool {
 }
 
 // Count returns the total number of plans
-func (s *Storage) Count() int {
+func (s *JSONStorage) Count() int {
+	s.refresh()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -223,6 +379,6 @@ func (s *Storage) Count() int {
 }
 
 // GetFilePath returns the storage file path
-func (s *Storage) GetFilePath() string {
+func (s *JSONStorage) GetFilePath() string {
 	return s.filePath
 }
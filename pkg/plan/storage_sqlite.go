@@ -0,0 +1,375 @@
+package plan
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	DefaultSQLiteFileName = ".near-swap-plans.db"
+)
+
+// sqliteSchema keeps each plan's settings and progress as a JSON document, so new plan fields
+// need no migration, and its executions as rows of their own, so an update only rewrites the
+// executions that changed
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS plans (
+	name   TEXT PRIMARY KEY,
+	status TEXT NOT NULL,
+	data   TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS plans_status ON plans (status);
+CREATE TABLE IF NOT EXISTS executions (
+	plan_name TEXT NOT NULL REFERENCES plans (name) ON DELETE CASCADE,
+	seq       INTEGER NOT NULL,
+	id        TEXT NOT NULL,
+	status    TEXT NOT NULL,
+	data      TEXT NOT NULL,
+	PRIMARY KEY (plan_name, seq)
+);
+`
+
+// SQLiteStorage persists plans in a SQLite database, one row per plan and per execution
+type SQLiteStorage struct {
+	filePath string
+	db       *sql.DB
+
+	sizeWarning
+}
+
+// NewSQLiteStorage opens (creating if needed) a SQLite plan database
+func NewSQLiteStorage(filePath string) (*SQLiteStorage, error) {
+	if filePath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		filePath = filepath.Join(home, DefaultSQLiteFileName)
+	}
+
+	if err := checkStoragePath(filePath, DefaultSQLiteFileName); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+filePath+"?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plan database: %w", err)
+	}
+	// A single connection serializes writes from this process; other processes wait on the busy timeout
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create plan tables: %w", err)
+	}
+	if err := os.Chmod(filePath, 0600); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to restrict plan database permissions: %w", err)
+	}
+
+	return &SQLiteStorage{
+		filePath: filePath,
+		db:       db,
+	}, nil
+}
+
+// encodePlan returns a plan's JSON document without its execution history
+func encodePlan(plan *TradingPlan) (string, error) {
+	doc := *plan
+	doc.ExecutionHistory = nil
+	data, err := json.Marshal(&doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	return string(data), nil
+}
+
+// writeExecutions brings a plan's execution rows in line with its history, rewriting only
+// the rows that changed
+func writeExecutions(tx *sql.Tx, plan *TradingPlan) error {
+	stored := make(map[int]string)
+	rows, err := tx.Query(`SELECT seq, data FROM executions WHERE plan_name = ?`, plan.Name)
+	if err != nil {
+		return fmt.Errorf("failed to read executions: %w", err)
+	}
+	for rows.Next() {
+		var seq int
+		var data string
+		if err := rows.Scan(&seq, &data); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read executions: %w", err)
+		}
+		stored[seq] = data
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read executions: %w", err)
+	}
+
+	for seq, exec := range plan.ExecutionHistory {
+		data, err := json.Marshal(exec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal execution: %w", err)
+		}
+		if stored[seq] == string(data) {
+			continue
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO executions (plan_name, seq, id, status, data) VALUES (?, ?, ?, ?, ?)`,
+			plan.Name, seq, exec.ID, string(exec.Status), string(data)); err != nil {
+			return fmt.Errorf("failed to write execution: %w", err)
+		}
+	}
+
+	if len(stored) > len(plan.ExecutionHistory) {
+		if _, err := tx.Exec(`DELETE FROM executions WHERE plan_name = ? AND seq >= ?`,
+			plan.Name, len(plan.ExecutionHistory)); err != nil {
+			return fmt.Errorf("failed to delete executions: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// write runs fn in a transaction and checks the database size once it is committed
+func (s *SQLiteStorage) write(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit plans: %w", err)
+	}
+
+	if info, err := os.Stat(s.filePath); err == nil {
+		s.checkSize(s.filePath, info.Size())
+	}
+	return nil
+}
+
+// Create adds a new plan to storage
+func (s *SQLiteStorage) Create(plan *TradingPlan) error {
+	data, err := encodePlan(plan)
+	if err != nil {
+		return err
+	}
+
+	return s.write(func(tx *sql.Tx) error {
+		var exists int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM plans WHERE name = ?`, plan.Name).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check plan: %w", err)
+		}
+		if exists > 0 {
+			return fmt.Errorf("plan '%s' %w", plan.Name, ErrPlanExists)
+		}
+
+		if _, err := tx.Exec(`INSERT INTO plans (name, status, data) VALUES (?, ?, ?)`,
+			plan.Name, string(plan.Status), data); err != nil {
+			return fmt.Errorf("failed to write plan: %w", err)
+		}
+		return writeExecutions(tx, plan)
+	})
+}
+
+// Get retrieves a plan by name
+func (s *SQLiteStorage) Get(name string) (*TradingPlan, error) {
+	plans, err := s.query(`WHERE name = ?`, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("plan '%s' %w", name, ErrPlanNotFound)
+	}
+	return plans[0], nil
+}
+
+// Update modifies an existing plan
+func (s *SQLiteStorage) Update(plan *TradingPlan) error {
+	data, err := encodePlan(plan)
+	if err != nil {
+		return err
+	}
+
+	return s.write(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`UPDATE plans SET status = ?, data = ? WHERE name = ?`,
+			string(plan.Status), data, plan.Name)
+		if err != nil {
+			return fmt.Errorf("failed to write plan: %w", err)
+		}
+		if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+			return fmt.Errorf("plan '%s' %w", plan.Name, ErrPlanNotFound)
+		}
+		return writeExecutions(tx, plan)
+	})
+}
+
+// Delete removes a plan and its executions from storage
+func (s *SQLiteStorage) Delete(name string) error {
+	return s.write(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`DELETE FROM plans WHERE name = ?`, name)
+		if err != nil {
+			return fmt.Errorf("failed to delete plan: %w", err)
+		}
+		if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+			return fmt.Errorf("plan '%s' %w", name, ErrPlanNotFound)
+		}
+		return nil
+	})
+}
+
+// query loads the plans matching a WHERE clause along with their execution history
+func (s *SQLiteStorage) query(where string, args ...interface{}) ([]*TradingPlan, error) {
+	rows, err := s.db.Query(`SELECT name, data FROM plans `+where+` ORDER BY name`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plans: %w", err)
+	}
+
+	var plans []*TradingPlan
+	byName := make(map[string]*TradingPlan)
+	for rows.Next() {
+		var name, data string
+		if err := rows.Scan(&name, &data); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read plans: %w", err)
+		}
+		plan := &TradingPlan{}
+		if err := json.Unmarshal([]byte(data), plan); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to unmarshal plan '%s': %w", name, err)
+		}
+		plan.ExecutionHistory = []Execution{}
+		plans = append(plans, plan)
+		byName[name] = plan
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read plans: %w", err)
+	}
+	if len(plans) == 0 {
+		return plans, nil
+	}
+
+	execRows, err := s.db.Query(`SELECT plan_name, data FROM executions WHERE plan_name IN (SELECT name FROM plans `+where+`) ORDER BY plan_name, seq`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read executions: %w", err)
+	}
+	defer execRows.Close()
+
+	for execRows.Next() {
+		var name, data string
+		if err := execRows.Scan(&name, &data); err != nil {
+			return nil, fmt.Errorf("failed to read executions: %w", err)
+		}
+		plan, ok := byName[name]
+		if !ok {
+			continue
+		}
+		var exec Execution
+		if err := json.Unmarshal([]byte(data), &exec); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal execution of plan '%s': %w", name, err)
+		}
+		plan.ExecutionHistory = append(plan.ExecutionHistory, exec)
+	}
+
+	return plans, execRows.Err()
+}
+
+// List returns all plans
+func (s *SQLiteStorage) List() []*TradingPlan {
+	plans, err := s.query(``)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to list plans: %v\n", err)
+		return []*TradingPlan{}
+	}
+	return plans
+}
+
+// ListByStatus returns plans filtered by status
+func (s *SQLiteStorage) ListByStatus(status PlanStatus) []*TradingPlan {
+	plans, err := s.query(`WHERE status = ?`, string(status))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to list plans: %v\n", err)
+		return []*TradingPlan{}
+	}
+	return plans
+}
+
+// GetExecutionsPage returns up to limit executions of a plan, newest first, skipping the
+// first offset, along with the total number of executions. Only the page is read.
+func (s *SQLiteStorage) GetExecutionsPage(name string, offset, limit int) ([]Execution, int, error) {
+	if !s.Exists(name) {
+		return nil, 0, fmt.Errorf("plan '%s' %w", name, ErrPlanNotFound)
+	}
+
+	var total int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM executions WHERE plan_name = ?`, name).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count executions: %w", err)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || offset >= total {
+		return []Execution{}, total, nil
+	}
+
+	rows, err := s.db.Query(`SELECT data FROM executions WHERE plan_name = ? ORDER BY seq DESC LIMIT ? OFFSET ?`,
+		name, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read executions: %w", err)
+	}
+	defer rows.Close()
+
+	page := make([]Execution, 0, limit)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, 0, fmt.Errorf("failed to read executions: %w", err)
+		}
+		var exec Execution
+		if err := json.Unmarshal([]byte(data), &exec); err != nil {
+			return nil, 0, fmt.Errorf("failed to unmarshal execution: %w", err)
+		}
+		page = append(page, exec)
+	}
+
+	return page, total, rows.Err()
+}
+
+// Exists checks if a plan with the given name exists
+func (s *SQLiteStorage) Exists(name string) bool {
+	var found string
+	err := s.db.QueryRow(`SELECT name FROM plans WHERE name = ?`, name).Scan(&found)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		fmt.Fprintf(os.Stderr, "Warning: failed to look up plan '%s': %v\n", name, err)
+	}
+	return err == nil
+}
+
+// Count returns the total number of plans
+func (s *SQLiteStorage) Count() int {
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM plans`).Scan(&count); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to count plans: %v\n", err)
+	}
+	return count
+}
+
+// Reload is a no-op: every read goes to the database, so changes from other processes are
+// always visible
+func (s *SQLiteStorage) Reload() error {
+	return nil
+}
+
+// GetFilePath returns the database path
+func (s *SQLiteStorage) GetFilePath() string {
+	return s.filePath
+}
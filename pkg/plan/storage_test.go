@@ -0,0 +1,122 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetExecutionsPage(t *testing.T) {
+	const records = 1234
+
+	for _, backend := range []string{BackendJSON, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			storage, err := OpenStorage(backend, filepath.Join(t.TempDir(), "plans"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			plan := &TradingPlan{Name: "p", Status: StatusActive, LastUpdated: time.Now()}
+			for i := 0; i < records; i++ {
+				plan.ExecutionHistory = append(plan.ExecutionHistory,
+					Execution{ID: fmt.Sprintf("exec-%d", i), Amount: "0.1", Status: ExecutionCompleted, Timestamp: time.Now()})
+			}
+			if err := storage.Create(plan); err != nil {
+				t.Fatal(err)
+			}
+
+			// Paging through the whole history visits every execution once, newest first
+			next := records - 1
+			for offset := 0; offset < records; offset += 100 {
+				page, total, err := storage.GetExecutionsPage("p", offset, 100)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if total != records {
+					t.Fatalf("total = %d, want %d", total, records)
+				}
+				wantLen := min(100, records-offset)
+				if len(page) != wantLen {
+					t.Fatalf("page at %d has %d executions, want %d", offset, len(page), wantLen)
+				}
+				for _, exec := range page {
+					if want := fmt.Sprintf("exec-%d", next); exec.ID != want {
+						t.Fatalf("page at %d: got %s, want %s", offset, exec.ID, want)
+					}
+					next--
+				}
+			}
+			if next != -1 {
+				t.Errorf("%d executions never paged", next+1)
+			}
+
+			tests := []struct {
+				name          string
+				offset, limit int
+				wantIDs       []string
+			}{
+				{name: "past the end", offset: records, limit: 10},
+				{name: "zero limit", offset: 0, limit: 0},
+				{name: "negative offset starts at the newest", offset: -5, limit: 2, wantIDs: []string{"exec-1233", "exec-1232"}},
+				{name: "last page is short", offset: records - 2, limit: 10, wantIDs: []string{"exec-1", "exec-0"}},
+			}
+			for _, tt := range tests {
+				page, total, err := storage.GetExecutionsPage("p", tt.offset, tt.limit)
+				if err != nil || total != records {
+					t.Errorf("%s: total %d, error %v; want %d", tt.name, total, err, records)
+					continue
+				}
+				var ids []string
+				for _, exec := range page {
+					ids = append(ids, exec.ID)
+				}
+				if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+					t.Errorf("%s: page = %v, want %v", tt.name, ids, tt.wantIDs)
+				}
+			}
+
+			if _, _, err := storage.GetExecutionsPage("missing", 0, 10); !errors.Is(err, ErrPlanNotFound) {
+				t.Errorf("missing plan: error = %v, want ErrPlanNotFound", err)
+			}
+		})
+	}
+}
+
+func TestCreateRollsBackOnSaveFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plans.json")
+	storage, err := NewStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Create(&TradingPlan{Name: "kept", Status: StatusActive}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory in the way of the temp file makes the save fail
+	if err := os.Mkdir(path+".tmp", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Create(&TradingPlan{Name: "lost", Status: StatusActive}); err == nil {
+		t.Fatal("Create succeeded without saving")
+	}
+	if storage.Exists("lost") || storage.Count() != 1 {
+		t.Errorf("unsaved plan left in memory (%d plans)", storage.Count())
+	}
+	reopened, err := NewStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Exists("lost") || !reopened.Exists("kept") {
+		t.Error("file should hold only the plan saved before the failure")
+	}
+
+	// Once saving works again the name is free
+	if err := os.Remove(path + ".tmp"); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.Create(&TradingPlan{Name: "lost", Status: StatusActive}); err != nil {
+		t.Errorf("retrying Create: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package plan
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileStamp identifies a version of the storage file by its modification time and size
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// stampOf returns the stamp of a file
+func stampOf(info os.FileInfo) fileStamp {
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// stampTracker remembers the stamp of the storage file as this process last read or wrote it
+type stampTracker struct {
+	stampMu sync.Mutex
+	stamp   fileStamp
+	broken  fileStamp // Last version that failed to reload, so it is only reported once
+}
+
+// setStamp records the stamp of the file as this process last saw it
+func (t *stampTracker) setStamp(stamp fileStamp) {
+	t.stampMu.Lock()
+	t.stamp = stamp
+	t.stampMu.Unlock()
+}
+
+// reportBroken reports whether a version of the file that failed to reload is new
+func (t *stampTracker) reportBroken(stamp fileStamp) bool {
+	t.stampMu.Lock()
+	defer t.stampMu.Unlock()
+	if t.broken == stamp {
+		return false
+	}
+	t.broken = stamp
+	return true
+}
+
+// changedSince reports whether stamp differs from the one last recorded
+func (t *stampTracker) changedSince(stamp fileStamp) bool {
+	t.stampMu.Lock()
+	defer t.stampMu.Unlock()
+	return !t.stamp.modTime.Equal(stamp.modTime) || t.stamp.size != stamp.size
+}
+
+// refresh reloads the storage file if something other than this process has written it since
+// it was last loaded or saved, e.g. a hand edit or another near-swap command. A file that
+// can't be parsed (say, half-way through an edit) is reported once and retried on the next call.
+func (s *JSONStorage) refresh() {
+	info, err := os.Stat(s.filePath)
+	if err != nil || !s.changedSince(stampOf(info)) {
+		return
+	}
+
+	if err := s.load(); err != nil && !os.IsNotExist(err) && s.reportBroken(stampOf(info)) {
+		fmt.Fprintf(os.Stderr, "Warning: plan file %s changed but could not be reloaded: %v\n", s.filePath, err)
+	}
+}
+
+// mergeLoaded replaces the in-memory plans with those loaded from the file. Parameters always
+// come from the file. Runtime state (status, progress, executions, price tracking) is kept from
+// memory for plans updated more recently here than in the file, so a file saved from an older
+// copy can't roll back trades recorded since, nor reactivate a plan the daemon has paused,
+// completed or cancelled.
+func mergeLoaded(current, loaded map[string]*TradingPlan) {
+	for name, plan := range loaded {
+		if existing, ok := current[name]; ok && existing.LastUpdated.After(plan.LastUpdated) {
+			plan.keepRuntimeState(existing)
+		}
+	}
+}
+
+// keepRuntimeState copies the state the executor maintains from other onto the plan
+func (tp *TradingPlan) keepRuntimeState(other *TradingPlan) {
+	tp.LastUpdated = other.LastUpdated
+	tp.Status = other.Status
+	tp.PauseReason = other.PauseReason
+	tp.CancelReason = other.CancelReason
+	tp.CompletionReason = other.CompletionReason
+	tp.PeakPrice = other.PeakPrice
+	tp.PriceSamples = other.PriceSamples
+	tp.DestinationFailures = other.DestinationFailures
+	tp.VerifiedAt = other.VerifiedAt
+	tp.TotalExecuted = other.TotalExecuted
+	tp.RemainingAmount = other.RemainingAmount
+	tp.ExecutionHistory = other.ExecutionHistory
+	tp.ExecutionCount = other.ExecutionCount
+	tp.LastExecutionDate = other.LastExecutionDate
+	tp.TodayExecuted = other.TodayExecuted
+
+	// Ladder fills carry over as long as the levels themselves weren't redefined
+	if len(tp.Ladder) == len(other.Ladder) {
+		for i := range tp.Ladder {
+			if tp.Ladder[i].Price == other.Ladder[i].Price {
+				tp.Ladder[i].Executed = other.Ladder[i].Executed
+				tp.Ladder[i].Filled = other.Ladder[i].Filled
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package plan
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeLoaded(t *testing.T) {
+	now := time.Now()
+	older := now.Add(-time.Minute)
+
+	tests := []struct {
+		name          string
+		memory        TradingPlan
+		file          TradingPlan
+		wantStatus    PlanStatus
+		wantReason    string
+		wantExecuted  string
+		wantTrigger   string
+		wantUpdatedAt time.Time
+	}{
+		{
+			name:          "stale file keeps a plan the daemon paused",
+			memory:        TradingPlan{Status: StatusPaused, PauseReason: destinationPauseReason, TotalExecuted: "2", TriggerPrice: "100", LastUpdated: now},
+			file:          TradingPlan{Status: StatusActive, TotalExecuted: "1", TriggerPrice: "120", LastUpdated: older},
+			wantStatus:    StatusPaused,
+			wantReason:    destinationPauseReason,
+			wantExecuted:  "2",
+			wantTrigger:   "120",
+			wantUpdatedAt: now,
+		},
+		{
+			name:          "stale file keeps a plan the daemon completed",
+			memory:        TradingPlan{Status: StatusCompleted, TotalExecuted: "10", TriggerPrice: "100", LastUpdated: now},
+			file:          TradingPlan{Status: StatusActive, TotalExecuted: "9", TriggerPrice: "100", LastUpdated: older},
+			wantStatus:    StatusCompleted,
+			wantExecuted:  "10",
+			wantTrigger:   "100",
+			wantUpdatedAt: now,
+		},
+		{
+			name:          "newer file changes status",
+			memory:        TradingPlan{Status: StatusActive, TotalExecuted: "1", TriggerPrice: "100", LastUpdated: older},
+			file:          TradingPlan{Status: StatusPaused, TotalExecuted: "1", TriggerPrice: "100", LastUpdated: now},
+			wantStatus:    StatusPaused,
+			wantExecuted:  "1",
+			wantTrigger:   "100",
+			wantUpdatedAt: now,
+		},
+		{
+			name:          "newer file resumes a paused plan",
+			memory:        TradingPlan{Status: StatusPaused, PauseReason: verificationPauseReason, TotalExecuted: "0", LastUpdated: older},
+			file:          TradingPlan{Status: StatusActive, TotalExecuted: "0", LastUpdated: now},
+			wantStatus:    StatusActive,
+			wantExecuted:  "0",
+			wantUpdatedAt: now,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory, file := tt.memory, tt.file
+			loaded := map[string]*TradingPlan{"p": &file}
+			mergeLoaded(map[string]*TradingPlan{"p": &memory}, loaded)
+
+			got := loaded["p"]
+			if got.Status != tt.wantStatus || got.PauseReason != tt.wantReason {
+				t.Errorf("status = %s (%q), want %s (%q)", got.Status, got.PauseReason, tt.wantStatus, tt.wantReason)
+			}
+			if got.TotalExecuted != tt.wantExecuted {
+				t.Errorf("total executed = %s, want %s", got.TotalExecuted, tt.wantExecuted)
+			}
+			if got.TriggerPrice != tt.wantTrigger {
+				t.Errorf("trigger price = %s, want %s", got.TriggerPrice, tt.wantTrigger)
+			}
+			if !got.LastUpdated.Equal(tt.wantUpdatedAt) {
+				t.Errorf("last updated = %v, want %v", got.LastUpdated, tt.wantUpdatedAt)
+			}
+		})
+	}
+}
+
+func TestMergeLoadedNewPlan(t *testing.T) {
+	file := &TradingPlan{Name: "new", Status: StatusActive}
+	loaded := map[string]*TradingPlan{"new": file}
+	mergeLoaded(map[string]*TradingPlan{}, loaded)
+	if loaded["new"] != file || file.Status != StatusActive {
+		t.Fatalf("plan only in the file should load unchanged, got %+v", loaded["new"])
+	}
+}
@@ -0,0 +1,52 @@
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// checkStoragePath makes sure plans can be saved at path before anything is loaded from it, so a
+// bad plan_storage_path fails at startup with advice instead of on the first save. It creates
+// the parent directory if needed.
+func checkStoragePath(path, defaultFileName string) error {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return fmt.Errorf("plan storage path %s is a directory; set plan_storage_path to a file inside it (e.g. %s)",
+			path, filepath.Join(path, defaultFileName))
+	}
+
+	dir := filepath.Dir(path)
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		return fmt.Errorf("plan storage path %s is inside %s, which is a file, not a directory; check plan_storage_path", path, dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("cannot create directory %s for plan storage: %w; create it or point plan_storage_path elsewhere", dir, err)
+	}
+
+	// Saves write a temporary file next to the storage file and rename it into place
+	probe, err := os.CreateTemp(dir, ".near-swap-write-check-*")
+	if err != nil {
+		return fmt.Errorf("plan storage directory %s is not writable: %w; fix its permissions or point plan_storage_path elsewhere", dir, pathCause(err))
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err == nil {
+		file.Close()
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("plan storage file %s is not writable: %w; fix its permissions or point plan_storage_path elsewhere", path, pathCause(err))
+	}
+
+	return nil
+}
+
+// pathCause strips the operation and path from a file error, which the messages above already name
+func pathCause(err error) error {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenStorageChecksPath(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	readOnly := filepath.Join(dir, "read-only")
+	if err := os.Mkdir(readOnly, 0555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(readOnly, 0755) })
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string // Empty expects the path accepted
+		asUser  bool   // Root writes anywhere, so permission checks only fail for other users
+	}{
+		{name: "new file in a new directory", path: filepath.Join(dir, "new", "plans.json")},
+		{name: "directory", path: dir, wantErr: "is a directory; set plan_storage_path to a file inside it (e.g. " + filepath.Join(dir, DefaultStorageFileName) + ")"},
+		{name: "inside a file", path: filepath.Join(file, "plans.json"), wantErr: "which is a file, not a directory"},
+		{name: "unwritable directory", path: filepath.Join(readOnly, "plans.json"), asUser: true,
+			wantErr: "plan storage directory " + readOnly + " is not writable: permission denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.asUser && os.Geteuid() == 0 {
+				t.Skip("permissions don't apply to root")
+			}
+			_, err := NewStorage(tt.path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package plan
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IsTrailingStop returns true if the plan trades once the price falls a percentage below
+// the highest price seen since it was started
+func (tp *TradingPlan) IsTrailingStop() bool {
+	return tp.PriceCondition == PriceTrailingStop
+}
+
+// parseTrailPercent parses a trailing stop distance such as "5%" or "5"
+func parseTrailPercent(input string) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(input), "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid trailing percentage '%s'", input)
+	}
+	if err := validateTrailPercent(value); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// validateTrailPercent checks that a trailing stop distance is a usable percentage
+func validateTrailPercent(percent float64) error {
+	if percent <= 0 || percent >= 100 {
+		return fmt.Errorf("trailing percentage must be greater than 0 and less than 100")
+	}
+	return nil
+}
+
+// TrailingPeak returns the plan's peak price including current, which may be a new high
+func (tp *TradingPlan) TrailingPeak(current float64) float64 {
+	if current > tp.PeakPrice {
+		return current
+	}
+	return tp.PeakPrice
+}
+
+// TrailingStopPrice returns the price at or below which a trailing stop fires for a peak
+func (tp *TradingPlan) TrailingStopPrice(peak float64) float64 {
+	return peak * (1 - tp.TrailPercent/100)
+}
+
+// RecordPeakPrice raises a trailing stop plan's peak price when price is a new high.
+// Other plans, and prices at or below the peak, are left untouched.
+func (m *Manager) RecordPeakPrice(planName string, price float64) error {
+	defer m.lockPlan(planName)()
+
+	plan, err := m.storage.Get(planName)
+	if err != nil {
+		return err
+	}
+	if !plan.IsTrailingStop() || price <= plan.PeakPrice {
+		return nil
+	}
+
+	plan.PeakPrice = price
+	plan.LastUpdated = time.Now()
+
+	return m.storage.Update(plan)
+}
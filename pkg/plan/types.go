@@ -2,8 +2,11 @@ package plan
 
 import (
 	"fmt"
+	"math/big"
 	"strconv"
 	"time"
+
+	"near-swap/pkg/client"
 )
 
 // PriceCondition defines when a trade should be triggered
@@ -13,6 +16,8 @@ const (
 	PriceAbove PriceCondition = "above" // Trigger when price goes above target
 	PriceBelow PriceCondition = "below" // Trigger when price goes below target
 	PriceAt    PriceCondition = "at"    // Trigger when price equals target (with tolerance)
+
+	PriceTrailingStop PriceCondition = "trailing" // Trigger when price falls TrailPercent below the highest price seen since the plan started
 )
 
 // PlanStatus defines the current state of a trading plan
@@ -33,6 +38,8 @@ const (
 	ExecutionDeposited ExecutionStatus = "deposited"  // Deposit sent
 	ExecutionCompleted ExecutionStatus = "completed"  // Swap completed
 	ExecutionFailed    ExecutionStatus = "failed"     // Execution failed
+	ExecutionObserved  ExecutionStatus = "observed"   // Would have traded; recorded in observer mode without a deposit
+	ExecutionCancelled ExecutionStatus = "cancelled"  // Cancelled by the user while in flight; no longer verified
 )
 
 // TradingPlan represents a user's automated trading strategy
@@ -50,16 +57,41 @@ type TradingPlan struct {
 	DestChain      string  `json:"dest_chain"`       // Destination blockchain
 	TotalAmount    string  `json:"total_amount"`     // Total amount to trade
 	AmountPerTrade string  `json:"amount_per_trade"` // Amount per execution
+	AmountPerTradeDest string `json:"amount_per_trade_dest,omitempty"` // Dest amount to acquire per execution (replaces AmountPerTrade)
 	AmountPerDay   string  `json:"amount_per_day"`   // Maximum amount to trade per day
 	TriggerPrice   string  `json:"trigger_price"`    // Price target
 	PriceCondition PriceCondition `json:"price_condition"` // When to trigger
+	TrailPercent   float64 `json:"trail_percent,omitempty"` // Trailing stop: % below the peak price that triggers (replaces TriggerPrice)
+	PeakPrice      float64 `json:"peak_price,omitempty"`    // Trailing stop: highest price seen since the plan was started
+	CancelBelow    string  `json:"cancel_below,omitempty"` // Kill switch: cancel the plan if the price falls to or below this
+	CancelAbove    string  `json:"cancel_above,omitempty"` // Kill switch: cancel the plan if the price rises to or above this
+	Ladder         []LadderLevel `json:"ladder,omitempty"` // Tranches traded at successive price levels (replaces per-trade sizing)
+	AmountJitter   float64 `json:"amount_jitter,omitempty"` // Randomize each trade by up to ±this percent of AmountPerTrade
+	PriceSmoothing int     `json:"price_smoothing,omitempty"` // Trigger on the average of the last N price checks (0 or 1 uses the spot price)
+	PriceSamples   []float64 `json:"price_samples,omitempty"` // Most recent price checks, kept for smoothing
+	SlippageBps    int     `json:"slippage_bps,omitempty"` // Quote slippage tolerance in basis points (0 uses default_slippage)
+	QuoteDeadline  string  `json:"quote_deadline,omitempty"` // How long each trade's quote accepts the deposit, as a Go duration ("" uses default_deadline)
+	MaxQuoteDivergence float64 `json:"max_quote_divergence,omitempty"` // Max % the deposit quote may be worse than the trigger price (0 uses max_quote_divergence)
+	DustThreshold  string  `json:"dust_threshold,omitempty"` // Complete the plan once the remaining amount is worth less than this in dest tokens
+	MaxPriceImpact float64 `json:"max_price_impact,omitempty"` // Max % a full trade may price worse than the small probe quote (0 uses max_price_impact)
+	VerifyFirst    bool    `json:"verify_first,omitempty"`  // Run a tiny verification swap end-to-end before the first full trade
+	VerifyAmount   string  `json:"verify_amount,omitempty"` // Source amount the verification swap trades
 
 	// Addresses
 	RecipientAddr string `json:"recipient_addr"` // Where to receive tokens
 	RefundAddr    string `json:"refund_addr"`    // Where to refund if swap fails
+	WithdrawTo    string `json:"withdraw_to,omitempty"` // Cold address received funds are forwarded to (requires auto_withdraw)
+
+	// Credentials
+	APITokenEnv string `json:"api_token_env,omitempty"` // Environment variable holding this plan's 1Click JWT (empty uses the global token)
 
 	// Execution tracking
 	Status           PlanStatus   `json:"status"`
+	PauseReason      string       `json:"pause_reason,omitempty"`     // Why the plan was paused automatically (empty for manual pauses)
+	CancelReason     string       `json:"cancel_reason,omitempty"`    // Why the plan was cancelled automatically (e.g. kill switch)
+	CompletionReason string       `json:"completion_reason,omitempty"` // Why the plan completed with an amount left over (e.g. dust remaining)
+	DestinationFailures int       `json:"destination_failures,omitempty"` // Consecutive swaps that failed or were refunded after the deposit went through
+	VerifiedAt       *time.Time   `json:"verified_at,omitempty"`      // When the verification swap completed (VerifyFirst plans)
 	TotalExecuted    string       `json:"total_executed"`     // Amount already executed
 	RemainingAmount  string       `json:"remaining_amount"`   // Amount left to execute
 	ExecutionHistory []Execution  `json:"execution_history"`  // History of executions
@@ -86,6 +118,22 @@ type Execution struct {
 	DestinationTxHash string          `json:"destination_tx_hash,omitempty"` // Withdrawal transaction hash
 	CompletionTime    *time.Time      `json:"completion_time,omitempty"` // When swap completed
 	SwapStatus        string          `json:"swap_status,omitempty"` // Latest status from API
+	RefundTxHash      string          `json:"refund_tx_hash,omitempty"` // Refund transaction hash (origin chain)
+	RefundedAmount    string          `json:"refunded_amount,omitempty"` // Amount refunded to the refund address
+	RefundConfirmed   bool            `json:"refund_confirmed,omitempty"` // Refund tx verified on-chain
+	QuoteDivergence   string          `json:"quote_divergence,omitempty"` // % the deposit quote was worse than the trigger price
+	Aborts            int             `json:"aborts,omitempty"` // Consecutive trades aborted for quote divergence, folded into this record
+	LadderPrice       string          `json:"ladder_price,omitempty"` // Ladder level this execution traded
+	WithdrawalTxHash  string          `json:"withdrawal_tx_hash,omitempty"` // Transfer of the output to the plan's cold address
+	WithdrawnAmount   string          `json:"withdrawn_amount,omitempty"` // Amount forwarded to the cold address
+	WithdrawalError   string          `json:"withdrawal_error,omitempty"` // Why the auto-withdrawal failed
+	TimeEstimate      float64         `json:"time_estimate_sec,omitempty"` // Quote's estimate of how long the swap takes to settle, in seconds
+	Reserved          bool            `json:"reserved,omitempty"` // Pending amount already counted against the plan's limits
+	CancelReason      string          `json:"cancel_reason,omitempty"` // Why the user cancelled the execution
+	CancelledAt       *time.Time      `json:"cancelled_at,omitempty"` // When the execution was cancelled
+	Verification      bool            `json:"verification,omitempty"` // Tiny swap proving the route before the plan's first full trade
+	QuoteDeadline     *time.Time      `json:"quote_deadline,omitempty"` // When the quote stops accepting the deposit
+	DepositBlockhash  string          `json:"deposit_blockhash,omitempty"` // Recent blockhash a Solana deposit was signed with
 }
 
 // Validate checks if the trading plan has valid parameters
@@ -105,27 +153,105 @@ func (tp *TradingPlan) Validate() error {
 	if tp.DestChain == "" {
 		return fmt.Errorf("destination chain is required")
 	}
+	if tp.tradesSameToken() {
+		return fmt.Errorf("source and destination are both %s on %s", tp.SourceToken, tp.SourceChain)
+	}
 	if tp.TotalAmount == "" || tp.TotalAmount == "0" {
 		return fmt.Errorf("total amount must be greater than 0")
 	}
-	if tp.AmountPerTrade == "" || tp.AmountPerTrade == "0" {
+	if tp.IsDestSized() {
+		if tp.AmountPerTrade != "" {
+			return fmt.Errorf("amount per trade and dest amount per trade are mutually exclusive")
+		}
+	} else if !tp.HasLadder() && (tp.AmountPerTrade == "" || tp.AmountPerTrade == "0") {
 		return fmt.Errorf("amount per trade must be greater than 0")
 	}
 	if tp.AmountPerDay == "" || tp.AmountPerDay == "0" {
 		return fmt.Errorf("amount per day must be greater than 0")
 	}
-	if tp.TriggerPrice == "" || tp.TriggerPrice == "0" {
+	if tp.IsTrailingStop() {
+		if err := validateTrailPercent(tp.TrailPercent); err != nil {
+			return err
+		}
+	} else if tp.TriggerPrice == "" || tp.TriggerPrice == "0" {
 		return fmt.Errorf("trigger price must be greater than 0")
 	}
-	if tp.PriceCondition != PriceAbove && tp.PriceCondition != PriceBelow && tp.PriceCondition != PriceAt {
-		return fmt.Errorf("price condition must be 'above', 'below', or 'at'")
+	if tp.PriceCondition != PriceAbove && tp.PriceCondition != PriceBelow && tp.PriceCondition != PriceAt && !tp.IsTrailingStop() {
+		return fmt.Errorf("price condition must be 'above', 'below', 'at', or 'trailing'")
 	}
 	if tp.RecipientAddr == "" {
 		return fmt.Errorf("recipient address is required")
 	}
+	if tp.HasLadder() {
+		if tp.PriceCondition != PriceAbove && tp.PriceCondition != PriceBelow {
+			return fmt.Errorf("laddered plans must trigger 'above' or 'below'")
+		}
+		if tp.IsDestSized() {
+			return fmt.Errorf("laddered plans cannot size trades by dest amount")
+		}
+		if err := validateLadder(tp.Ladder); err != nil {
+			return err
+		}
+	}
+	if tp.AmountJitter != 0 {
+		if err := validateJitter(tp.AmountJitter); err != nil {
+			return err
+		}
+		if tp.HasLadder() || tp.IsDestSized() {
+			return fmt.Errorf("amount jitter only applies to plans sized by amount per trade")
+		}
+	}
+	if err := validatePriceSmoothing(tp.PriceSmoothing); err != nil {
+		return err
+	}
+	if tp.SlippageBps != 0 {
+		if err := client.ValidateSlippage(tp.SlippageBps); err != nil {
+			return err
+		}
+	}
+	if _, err := parseQuoteDeadline(tp.QuoteDeadline); err != nil {
+		return err
+	}
+	if err := validateAPITokenEnv(tp.APITokenEnv); err != nil {
+		return err
+	}
+	if tp.MaxQuoteDivergence < 0 || tp.MaxQuoteDivergence > 100 {
+		return fmt.Errorf("max quote divergence must be between 0 and 100%%")
+	}
+	if tp.MaxPriceImpact < 0 || tp.MaxPriceImpact > 100 {
+		return fmt.Errorf("max price impact must be between 0 and 100%%")
+	}
+	if tp.VerifyFirst {
+		if err := tp.validateVerifyAmount(); err != nil {
+			return err
+		}
+	}
+	if tp.CancelBelow != "" && tp.CancelAbove != "" {
+		below, _ := strconv.ParseFloat(tp.CancelBelow, 64)
+		above, _ := strconv.ParseFloat(tp.CancelAbove, 64)
+		if below >= above {
+			return fmt.Errorf("cancel-below price must be lower than cancel-above price")
+		}
+	}
 	return nil
 }
 
+// IsDestSized returns true if trades are sized by the amount of dest token to acquire
+func (tp *TradingPlan) IsDestSized() bool {
+	return tp.AmountPerTradeDest != ""
+}
+
+// UnverifiedCount returns how many executions have deposited but not yet been verified
+func (tp *TradingPlan) UnverifiedCount() int {
+	count := 0
+	for _, exec := range tp.ExecutionHistory {
+		if exec.Status == ExecutionDeposited {
+			count++
+		}
+	}
+	return count
+}
+
 // IsActive returns true if the plan is currently active
 func (tp *TradingPlan) IsActive() bool {
 	return tp.Status == StatusActive
@@ -150,9 +276,12 @@ type PlanSummary struct {
 	RemainingAmount string     `json:"remaining_amount"`
 	TriggerPrice    string     `json:"trigger_price"`
 	PriceCondition  PriceCondition `json:"price_condition"`
+	TrailPercent    float64    `json:"trail_percent,omitempty"`
 	Status          PlanStatus `json:"status"`
 	ExecutionCount  int        `json:"execution_count"`
 	Created         time.Time  `json:"created"`
+	CurrentPrice    string     `json:"current_price,omitempty"`        // Filled in by plan list --prices
+	TriggerDistance *float64   `json:"trigger_distance_pct,omitempty"` // % the price must move to trigger; filled in by plan list --prices
 }
 
 // ToSummary converts a TradingPlan to a PlanSummary
@@ -165,6 +294,7 @@ func (tp *TradingPlan) ToSummary() *PlanSummary {
 		RemainingAmount: tp.RemainingAmount,
 		TriggerPrice:    tp.TriggerPrice,
 		PriceCondition:  tp.PriceCondition,
+		TrailPercent:    tp.TrailPercent,
 		Status:          tp.Status,
 		ExecutionCount:  tp.ExecutionCount,
 		Created:         tp.Created,
@@ -186,10 +316,16 @@ func (tp *TradingPlan) CanExecuteToday() bool {
 	}
 
 	// Check if we've reached the daily limit
-	todayExecuted, _ := strconv.ParseFloat(tp.TodayExecuted, 64)
-	dailyLimit, _ := strconv.ParseFloat(tp.AmountPerDay, 64)
+	todayExecuted, err := parseDecimal(tp.TodayExecuted)
+	if err != nil {
+		return false
+	}
+	dailyLimit, err := parseDecimal(tp.AmountPerDay)
+	if err != nil {
+		return false
+	}
 
-	return todayExecuted < dailyLimit
+	return todayExecuted.Cmp(dailyLimit) < 0
 }
 
 // GetRemainingDailyAmount returns how much can still be executed today
@@ -202,13 +338,19 @@ func (tp *TradingPlan) GetRemainingDailyAmount() string {
 	}
 
 	// Calculate remaining for today
-	todayExecuted, _ := strconv.ParseFloat(tp.TodayExecuted, 64)
-	dailyLimit, _ := strconv.ParseFloat(tp.AmountPerDay, 64)
-	remaining := dailyLimit - todayExecuted
+	todayExecuted, err := parseDecimal(tp.TodayExecuted)
+	if err != nil {
+		return "0"
+	}
+	dailyLimit, err := parseDecimal(tp.AmountPerDay)
+	if err != nil {
+		return "0"
+	}
+	remaining := new(big.Rat).Sub(dailyLimit, todayExecuted)
 
-	if remaining < 0 {
+	if remaining.Sign() < 0 {
 		return "0"
 	}
 
-	return fmt.Sprintf("%.8f", remaining)
+	return formatDecimal(remaining)
 }
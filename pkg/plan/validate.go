@@ -0,0 +1,353 @@
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
+	"near-swap/pkg/client"
+	"near-swap/pkg/deposit"
+	"near-swap/pkg/types"
+)
+
+// ImplausibleTriggerDistance is how far, in percent of the current price, a trigger may be
+// before validation warns that it may be in the wrong units or inverted
+const ImplausibleTriggerDistance = 50.0
+
+// Severity says whether a validation finding stops a plan from working
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The plan can't trade as defined
+	SeverityWarning Severity = "warning" // The plan can trade, but probably not as intended
+)
+
+// Finding is one problem found while validating a plan
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Field    string   `json:"field,omitempty"` // JSON name of the plan field at fault
+	Message  string   `json:"message"`
+}
+
+// HasErrors reports whether any finding is an error
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// findings collects validation results
+type findings []Finding
+
+func (fs *findings) fail(field, format string, args ...interface{}) {
+	*fs = append(*fs, Finding{Severity: SeverityError, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (fs *findings) warn(field, format string, args ...interface{}) {
+	*fs = append(*fs, Finding{Severity: SeverityWarning, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkAmount records an error if a required amount is not a positive decimal
+func (fs *findings) checkAmount(field, amount string) {
+	if err := validateAmount(amount); err != nil {
+		fs.fail(field, "%v", err)
+	}
+}
+
+// tradesSameToken reports whether a plan's source and destination are the same token on the
+// same chain, which can't be swapped. Aliases of one asset are caught by the market check.
+func (tp *TradingPlan) tradesSameToken() bool {
+	return tp.SourceToken != "" && strings.EqualFold(tp.SourceToken, tp.DestToken) &&
+		deposit.CanonicalChain(tp.SourceChain) == deposit.CanonicalChain(tp.DestChain)
+}
+
+// ValidatePlan runs every structural check on a plan and returns all problems found, rather
+// than stopping at the first like Validate: required fields, amounts and their ordering,
+// trigger and kill switch consistency, and option ranges.
+func ValidatePlan(tp *TradingPlan) []Finding {
+	fs := findings{}
+
+	if err := ValidatePlanName(tp.Name); err != nil {
+		fs.fail("name", "%v", err)
+	}
+	for _, required := range []struct{ field, label, value string }{
+		{"source_token", "source token", tp.SourceToken},
+		{"dest_token", "destination token", tp.DestToken},
+		{"source_chain", "source chain", tp.SourceChain},
+		{"dest_chain", "destination chain", tp.DestChain},
+		{"recipient_addr", "recipient address", tp.RecipientAddr},
+	} {
+		if required.value == "" {
+			fs.fail(required.field, "%s is required", required.label)
+		}
+	}
+	if tp.tradesSameToken() {
+		fs.fail("dest_token", "source and destination are both %s on %s", tp.SourceToken, tp.SourceChain)
+	}
+
+	// Amounts
+	fs.checkAmount("total_amount", tp.TotalAmount)
+	fs.checkAmount("amount_per_day", tp.AmountPerDay)
+	switch {
+	case tp.IsDestSized():
+		fs.checkAmount("amount_per_trade_dest", tp.AmountPerTradeDest)
+		if tp.AmountPerTrade != "" {
+			fs.fail("amount_per_trade", "amount per trade and dest amount per trade are mutually exclusive")
+		}
+	case tp.HasLadder():
+		if err := validateLadder(tp.Ladder); err != nil {
+			fs.fail("ladder", "%v", err)
+		}
+	default:
+		fs.checkAmount("amount_per_trade", tp.AmountPerTrade)
+	}
+	if tp.CancelBelow != "" {
+		fs.checkAmount("cancel_below", tp.CancelBelow)
+	}
+	if tp.CancelAbove != "" {
+		fs.checkAmount("cancel_above", tp.CancelAbove)
+	}
+	if tp.DustThreshold != "" {
+		fs.checkAmount("dust_threshold", tp.DustThreshold)
+	}
+
+	// Ordering: per trade <= per day <= total
+	total, _ := strconv.ParseFloat(tp.TotalAmount, 64)
+	perTrade, _ := strconv.ParseFloat(tp.AmountPerTrade, 64)
+	perDay, _ := strconv.ParseFloat(tp.AmountPerDay, 64)
+	if !tp.IsDestSized() && perTrade > perDay && perDay > 0 {
+		fs.fail("amount_per_trade", "amount per trade (%s) cannot be greater than amount per day (%s)", tp.AmountPerTrade, tp.AmountPerDay)
+	}
+	if perDay > total && total > 0 {
+		fs.fail("amount_per_day", "amount per day (%s) cannot be greater than total amount (%s)", tp.AmountPerDay, tp.TotalAmount)
+	}
+	if !tp.IsDestSized() && !tp.HasLadder() && perTrade > 0 && total > 0 {
+		if warning := tp.TradeSplitWarning(); warning != "" {
+			fs.warn("amount_per_trade", "%s", warning)
+		}
+	}
+
+	// Trigger
+	switch tp.PriceCondition {
+	case PriceAbove, PriceBelow, PriceAt:
+		if tp.HasLadder() && tp.PriceCondition == PriceAt {
+			fs.fail("price_condition", "laddered plans must trigger 'above' or 'below'")
+		} else if !tp.HasLadder() {
+			fs.checkAmount("trigger_price", tp.TriggerPrice)
+		}
+	case PriceTrailingStop:
+		if err := validateTrailPercent(tp.TrailPercent); err != nil {
+			fs.fail("trail_percent", "%v", err)
+		}
+	default:
+		fs.fail("price_condition", "price condition must be 'above', 'below', 'at', or 'trailing'")
+	}
+
+	// Kill switch prices must not fire before the trigger can
+	trigger, _ := strconv.ParseFloat(tp.TriggerPrice, 64)
+	cancelBelow, _ := strconv.ParseFloat(tp.CancelBelow, 64)
+	cancelAbove, _ := strconv.ParseFloat(tp.CancelAbove, 64)
+	if cancelBelow > 0 && cancelAbove > 0 && cancelBelow >= cancelAbove {
+		fs.fail("cancel_below", "cancel-below price must be lower than cancel-above price")
+	}
+	if trigger > 0 && tp.PriceCondition == PriceBelow && cancelBelow >= trigger {
+		fs.fail("cancel_below", "cancel-below price %s is not below the trigger %s; the plan would be cancelled before it trades", tp.CancelBelow, tp.TriggerPrice)
+	}
+	if trigger > 0 && tp.PriceCondition == PriceAbove && cancelAbove > 0 && cancelAbove <= trigger {
+		fs.fail("cancel_above", "cancel-above price %s is not above the trigger %s; the plan would be cancelled before it trades", tp.CancelAbove, tp.TriggerPrice)
+	}
+
+	// Options
+	if err := validateJitter(tp.AmountJitter); err != nil {
+		fs.fail("amount_jitter", "%v", err)
+	} else if tp.HasJitter() && (tp.HasLadder() || tp.IsDestSized()) {
+		fs.fail("amount_jitter", "amount jitter only applies to plans sized by amount per trade")
+	}
+	if err := validatePriceSmoothing(tp.PriceSmoothing); err != nil {
+		fs.fail("price_smoothing", "%v", err)
+	}
+	if tp.SlippageBps != 0 {
+		if err := client.ValidateSlippage(tp.SlippageBps); err != nil {
+			fs.fail("slippage_bps", "%v", err)
+		}
+	}
+	if _, err := parseQuoteDeadline(tp.QuoteDeadline); err != nil {
+		fs.fail("quote_deadline", "%v", err)
+	}
+	if tp.MaxQuoteDivergence < 0 || tp.MaxQuoteDivergence > 100 {
+		fs.fail("max_quote_divergence", "max quote divergence must be between 0 and 100%%")
+	}
+	if tp.MaxPriceImpact < 0 || tp.MaxPriceImpact > 100 {
+		fs.fail("max_price_impact", "max price impact must be between 0 and 100%%")
+	}
+	if err := validateAPITokenEnv(tp.APITokenEnv); err != nil {
+		fs.fail("api_token_env", "%v", err)
+	}
+	if tp.WithdrawTo != "" && tp.WithdrawTo == tp.RecipientAddr {
+		fs.fail("withdraw_to", "withdraw address must differ from the recipient address")
+	}
+	if tp.VerifyFirst {
+		if err := tp.validateVerifyAmount(); err != nil {
+			fs.fail("verify_amount", "%v", err)
+		} else if minimum, ok := deposit.MinimumSend(tp.SourceChain); ok {
+			if value, _ := strconv.ParseFloat(tp.VerifyAmount, 64); value < minimum {
+				fs.fail("verify_amount", "a verification swap of %s %s is below the %s minimum send of %s", tp.VerifyAmount,
+					tp.SourceToken, tp.SourceChain, strconv.FormatFloat(minimum, 'f', -1, 64))
+			}
+		}
+	}
+
+	// Anything Validate knows about that the checks above missed
+	if !HasErrors(fs) {
+		if err := tp.Validate(); err != nil {
+			fs.fail("", "%v", err)
+		}
+	}
+
+	return fs
+}
+
+// ValidatePlanAgainstMarket runs ValidatePlan and, if the plan is structurally sound, checks
+// it against the 1Click API: that both tokens exist on their chains, that a trade of the
+// plan's size can be quoted and clears the source chain's minimum send, and that the
+// trigger and kill switch prices are plausible next to the current price.
+func ValidatePlanAgainstMarket(ctx context.Context, tp *TradingPlan, apiClient *client.OneClickClient) []Finding {
+	fs := findings(ValidatePlan(tp))
+	if HasErrors(fs) || apiClient == nil {
+		return fs
+	}
+
+	// Pair existence
+	tokens, err := apiClient.GetSupportedTokens()
+	if err != nil {
+		fs.warn("", "could not check the market: %v", err)
+		return fs
+	}
+	sourceFound := hasToken(tokens, tp.SourceToken, tp.SourceChain)
+	destFound := hasToken(tokens, tp.DestToken, tp.DestChain)
+	if !sourceFound {
+		fs.fail("source_token", "token %s is not supported on %s", tp.SourceToken, tp.SourceChain)
+	}
+	if !destFound {
+		fs.fail("dest_token", "token %s is not supported on %s", tp.DestToken, tp.DestChain)
+	}
+	if !sourceFound || !destFound {
+		return fs
+	}
+
+	// Minimum send on the source chain
+	amount, field := tp.probeAmount()
+	if minimum, ok := deposit.MinimumSend(tp.SourceChain); ok && !tp.IsDestSized() {
+		if value, _ := strconv.ParseFloat(amount, 64); value < minimum {
+			fs.fail(field, "a trade of %s %s is below the %s minimum send of %s", amount, tp.SourceToken,
+				tp.SourceChain, strconv.FormatFloat(minimum, 'f', -1, 64))
+		}
+	}
+
+	// A dry quote of one trade proves the route exists and the size clears the pair minimum
+	quote, err := apiClient.GetQuoteContext(ctx, &types.SwapRequest{
+		Amount:        amount,
+		SourceToken:   tp.SourceToken,
+		DestToken:     tp.DestToken,
+		SourceChain:   tp.SourceChain,
+		DestChain:     tp.DestChain,
+		RecipientAddr: tp.RecipientAddr,
+		RefundAddr:    tp.RefundAddr,
+		ExactOutput:   tp.IsDestSized(),
+		SlippageBps:   tp.SlippageBps,
+		Deadline:      tp.quoteDeadline(),
+		Dry:           true,
+	})
+	var routeErr *client.UnsupportedRouteError
+	var sameAsset *client.SameAssetError
+	switch {
+	case errors.As(err, &routeErr):
+		fs.fail("dest_chain", "%v", routeErr)
+		return fs
+	case errors.As(err, &sameAsset):
+		fs.fail("dest_token", "%v", sameAsset)
+		return fs
+	case err != nil:
+		fs.fail(field, "a trade of %s could not be quoted: %v", amount, err)
+		return fs
+	}
+
+	details := quote.GetQuote()
+	price, err := quotePrice(&details)
+	if err != nil {
+		fs.warn("", "could not read the current price from the quote: %v", err)
+		return fs
+	}
+	fs.checkPrices(tp, price)
+
+	return fs
+}
+
+// checkPrices warns about triggers and kill switches that would fire right away or are so
+// far from the current price that they were likely entered in the wrong units
+func (fs *findings) checkPrices(tp *TradingPlan, price float64) {
+	pair := fmt.Sprintf("%s/%s", tp.DestToken, tp.SourceToken)
+	current := strconv.FormatFloat(price, 'f', -1, 64)
+
+	if !tp.IsTrailingStop() {
+		target := tp.TriggerPrice
+		field := "trigger_price"
+		if tp.HasLadder() {
+			target, field = tp.Ladder[0].Price, "ladder"
+		}
+		if distance, ok := tp.TriggerDistance(price); ok && distance == 0 && tp.PriceCondition != PriceAt {
+			fs.warn(field, "trigger %s %s is already met at the current price of %s %s; the plan trades as soon as it starts",
+				tp.PriceCondition, target, current, pair)
+		}
+		if value, err := strconv.ParseFloat(target, 64); err == nil {
+			if gap := math.Abs(value-price) / price * 100; gap > ImplausibleTriggerDistance {
+				fs.warn(field, "trigger %s is %.0f%% away from the current price of %s %s; prices are in %s",
+					target, gap, current, pair, pair)
+			}
+		}
+	}
+
+	if value, err := strconv.ParseFloat(tp.CancelBelow, 64); err == nil && price <= value {
+		fs.warn("cancel_below", "the current price of %s %s is already at or below cancel-below %s; the plan would be cancelled immediately",
+			current, pair, tp.CancelBelow)
+	}
+	if value, err := strconv.ParseFloat(tp.CancelAbove, 64); err == nil && price >= value {
+		fs.warn("cancel_above", "the current price of %s %s is already at or above cancel-above %s; the plan would be cancelled immediately",
+			current, pair, tp.CancelAbove)
+	}
+}
+
+// probeAmount returns the size of one trade to test-quote, and the field it comes from:
+// the per-trade amount, the dest amount for dest-sized plans, or the smallest ladder tranche
+func (tp *TradingPlan) probeAmount() (string, string) {
+	switch {
+	case tp.IsDestSized():
+		return tp.AmountPerTradeDest, "amount_per_trade_dest"
+	case tp.HasLadder():
+		total, _ := strconv.ParseFloat(tp.TotalAmount, 64)
+		smallest := math.Inf(1)
+		for _, level := range tp.Ladder {
+			smallest = math.Min(smallest, total*level.Fraction)
+		}
+		return trimDecimal(fmt.Sprintf("%.8f", smallest)), "ladder"
+	default:
+		return tp.AmountPerTrade, "amount_per_trade"
+	}
+}
+
+// hasToken reports whether tokens lists symbol on chain
+func hasToken(tokens []oneclick.TokenResponse, symbol, chain string) bool {
+	for _, token := range tokens {
+		if strings.EqualFold(token.GetSymbol(), symbol) && strings.EqualFold(token.GetBlockchain(), chain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package plan
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"near-swap/pkg/mockserver"
+)
+
+// validPlan returns a structurally sound BTC -> USDC plan
+func validPlan() *TradingPlan {
+	return &TradingPlan{
+		Name: "p", SourceToken: "BTC", DestToken: "USDC", SourceChain: "btc", DestChain: "near",
+		TotalAmount: "1", AmountPerTrade: "0.1", AmountPerDay: "0.2", TriggerPrice: "55000", PriceCondition: PriceBelow,
+		RecipientAddr: "me.near", RefundAddr: testRefundAddr,
+	}
+}
+
+// findingKeys reduces findings to "severity field" pairs for comparison
+func findingKeys(fs []Finding) []string {
+	keys := make([]string, 0, len(fs))
+	for _, f := range fs {
+		keys = append(keys, string(f.Severity)+" "+f.Field)
+	}
+	return keys
+}
+
+func TestValidatePlanReportsEveryProblem(t *testing.T) {
+	if fs := ValidatePlan(validPlan()); len(fs) != 0 {
+		t.Fatalf("valid plan has findings: %+v", fs)
+	}
+
+	bad := validPlan()
+	bad.Name = "my plan"
+	bad.RecipientAddr = ""
+	bad.AmountPerTrade = "0.5" // More than per day
+	bad.CancelBelow = "60000"  // Not below the trigger
+	bad.SlippageBps = 9999
+	bad.AmountJitter = 150
+
+	want := []string{
+		"error name",
+		"error recipient_addr",
+		"error amount_per_trade",
+		"error cancel_below",
+		"error amount_jitter",
+		"error slippage_bps",
+	}
+	fs := ValidatePlan(bad)
+	if got := findingKeys(fs); !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %q, want %q\n%+v", got, want, fs)
+	}
+	if !HasErrors(fs) {
+		t.Error("HasErrors = false for a plan with errors")
+	}
+}
+
+func TestValidatePlanRejectsSameToken(t *testing.T) {
+	tests := []struct {
+		name                 string
+		destToken, destChain string
+		wantErr              bool
+	}{
+		{name: "same token and chain", destToken: "BTC", destChain: "btc", wantErr: true},
+		{name: "chain alias and symbol case", destToken: "btc", destChain: "Bitcoin", wantErr: true},
+		{name: "same symbol on another chain", destToken: "BTC", destChain: "near"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validPlan()
+			p.DestToken, p.DestChain = tt.destToken, tt.destChain
+
+			err := p.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error: %v", err, tt.wantErr)
+			}
+			var found bool
+			for _, f := range ValidatePlan(p) {
+				found = found || f.Field == "dest_token"
+			}
+			if found != tt.wantErr {
+				t.Errorf("ValidatePlan flagged dest_token: %v, want %v", found, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePlanAgainstMarket(t *testing.T) {
+	server := mockserver.New()
+	t.Cleanup(server.Close)
+	btc := server.AddToken("BTC", "btc", 8, 60000)
+	usdc := server.AddToken("USDC", "near", 6, 1)
+	server.SetRate(btc, usdc, 60000)
+	apiClient := server.Client("t")
+
+	tests := []struct {
+		name   string
+		modify func(*TradingPlan)
+		want   []string
+	}{
+		{name: "sound plan", modify: func(*TradingPlan) {}, want: []string{}},
+		{name: "unknown token", modify: func(p *TradingPlan) { p.DestToken = "USDT" }, want: []string{"error dest_token"}},
+		{name: "same asset", modify: func(p *TradingPlan) { p.DestToken, p.DestChain = "BTC", "btc" }, want: []string{"error dest_token"}},
+		{name: "dust trades", modify: func(p *TradingPlan) { p.AmountPerTrade = "0.000001" }, want: []string{"error amount_per_trade"}},
+		{name: "trigger met and kill switch crossed", modify: func(p *TradingPlan) {
+			p.TriggerPrice = "65000"
+			p.CancelAbove = "59000"
+			p.PriceCondition = PriceBelow
+			p.CancelBelow = ""
+		}, want: []string{"warning trigger_price", "warning cancel_above"}},
+		{name: "trigger in the wrong units", modify: func(p *TradingPlan) { p.TriggerPrice = "0.00002" },
+			want: []string{"warning trigger_price"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := validPlan()
+			tt.modify(p)
+			fs := ValidatePlanAgainstMarket(context.Background(), p, apiClient)
+			if got := findingKeys(fs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findings = %q, want %q\n%+v", got, tt.want, fs)
+			}
+		})
+	}
+}
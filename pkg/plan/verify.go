@@ -0,0 +1,86 @@
+package plan
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"near-swap/pkg/deposit"
+)
+
+// verificationPauseReason is the pause reason recorded for plans paused because their
+// verification swap failed or was refunded
+const verificationPauseReason = "verification swap failed or was refunded"
+
+// verifyAmountPercent is the share of amount_per_trade a verification swap trades by default
+const verifyAmountPercent = 1.0
+
+// defaultVerifyAmount returns the verification swap size for a plan trading amountPerTrade:
+// verifyAmountPercent of it, raised to the source chain's minimum send
+func defaultVerifyAmount(sourceChain, amountPerTrade string) (string, error) {
+	perTrade, err := strconv.ParseFloat(amountPerTrade, 64)
+	if err != nil || perTrade <= 0 {
+		return "", fmt.Errorf("invalid amount per trade '%s'", amountPerTrade)
+	}
+	amount := perTrade * verifyAmountPercent / 100
+	if minimum, ok := deposit.MinimumSend(sourceChain); ok {
+		amount = math.Max(amount, minimum)
+	}
+	return trimDecimal(fmt.Sprintf("%.8f", amount)), nil
+}
+
+// validateVerifyAmount checks a verification swap is smaller than the plan's trades
+func (tp *TradingPlan) validateVerifyAmount() error {
+	if err := validateAmount(tp.VerifyAmount); err != nil {
+		return fmt.Errorf("invalid verify amount: %w", err)
+	}
+	if tp.IsDestSized() || tp.HasLadder() {
+		return nil
+	}
+	amount, _ := strconv.ParseFloat(tp.VerifyAmount, 64)
+	perTrade, _ := strconv.ParseFloat(tp.AmountPerTrade, 64)
+	if amount >= perTrade {
+		return fmt.Errorf("verify amount must be smaller than the amount per trade")
+	}
+	return nil
+}
+
+// NeedsVerification returns true if the plan must complete a verification swap before its
+// first full trade
+func (tp *TradingPlan) NeedsVerification() bool {
+	return tp.VerifyFirst && tp.VerifiedAt == nil
+}
+
+// verificationInFlight returns the verification execution still awaiting its swap, if any
+func (tp *TradingPlan) verificationInFlight() *Execution {
+	for i := range tp.ExecutionHistory {
+		exec := &tp.ExecutionHistory[i]
+		if exec.Verification && (exec.Status == ExecutionPending || exec.Status == ExecutionDeposited) {
+			return exec
+		}
+	}
+	return nil
+}
+
+// haltOnFailedVerification pauses a plan whose verification swap failed or was refunded, so
+// it never commits a full trade to a route that just proved broken
+func (e *Executor) haltOnFailedVerification(planName, executionID string) {
+	plan, err := e.manager.GetPlan(planName)
+	if err != nil || plan.Status != StatusActive || !plan.NeedsVerification() {
+		return
+	}
+	if exec := plan.findExecution(executionID); exec == nil || !exec.Verification {
+		return
+	}
+
+	if err := e.manager.PausePlan(planName, verificationPauseReason); err != nil {
+		e.verifyLog.Error("Error pausing plan", "plan", planName, "error", err)
+		return
+	}
+	e.StopPlan(planName)
+
+	e.verifyLog.Warn("Paused plan",
+		"plan", planName, "execution_id", executionID, "event", "plan_paused", "reason", verificationPauseReason,
+		"source_token", plan.SourceToken, "dest_token", plan.DestToken, "dest_chain", plan.DestChain)
+	e.verifyLog.Info("Check the route and addresses, then retry with: near-swap plan start", "plan", planName)
+}
@@ -0,0 +1,89 @@
+package plan
+
+import (
+	"testing"
+
+	"near-swap/pkg/mockserver"
+)
+
+// newVerifyFirstExecutor returns a mock executor whose plan must verify its route with a
+// 0.001 BTC swap before trading 0.1 BTC at a time
+func newVerifyFirstExecutor(t *testing.T) (*Executor, *mockserver.Server) {
+	t.Helper()
+	e, server := newMockExecutor(t)
+	p, err := e.manager.storage.Get("p")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.VerifyFirst = true
+	p.VerifyAmount = "0.001"
+	if err := e.manager.storage.Update(p); err != nil {
+		t.Fatal(err)
+	}
+	return e, server
+}
+
+func TestVerificationSwapRunsOnceBeforeTheFirstFullTrade(t *testing.T) {
+	e, server := newVerifyFirstExecutor(t)
+
+	e.checkAndExecutePlan("p", nil)
+	_, verification := lastExecution(t, e)
+	if !verification.Verification || verification.Amount != "0.00100000" {
+		t.Fatalf("first execution = %+v, want a 0.001 verification swap", verification)
+	}
+
+	// The full trade waits while the verification swap is in flight
+	e.checkAndExecutePlan("p", nil)
+	if p, _ := lastExecution(t, e); len(p.ExecutionHistory) != 1 {
+		t.Fatalf("%d executions while the verification swap was unsettled, want 1", len(p.ExecutionHistory))
+	}
+
+	server.QueueStatus(verification.DepositAddress, "SUCCESS")
+	if !e.checkSwapStatus("p", verification.ID, verification.DepositAddress) {
+		t.Fatal("verification swap didn't settle")
+	}
+	if p, _ := lastExecution(t, e); p.VerifiedAt == nil || p.NeedsVerification() {
+		t.Fatal("plan not marked verified after its verification swap completed")
+	}
+
+	// Every trade after that is a full one
+	for i := 0; i < 2; i++ {
+		e.checkAndExecutePlan("p", nil)
+		p, exec := lastExecution(t, e)
+		if len(p.ExecutionHistory) != i+2 || exec.Verification {
+			t.Fatalf("trade %d = %+v (%d executions), want a full trade", i+1, exec, len(p.ExecutionHistory))
+		}
+		if i == 0 && exec.Amount != "0.10000000" {
+			t.Errorf("first full trade = %s, want the 0.1 amount per trade", exec.Amount)
+		}
+		server.QueueStatus(exec.DepositAddress, "SUCCESS")
+		e.checkSwapStatus("p", exec.ID, exec.DepositAddress)
+	}
+	p, _ := lastExecution(t, e)
+	verifications := 0
+	for _, exec := range p.ExecutionHistory {
+		if exec.Verification {
+			verifications++
+		}
+	}
+	if verifications != 1 {
+		t.Errorf("%d verification swaps, want exactly one", verifications)
+	}
+}
+
+func TestFailedVerificationSwapPausesThePlan(t *testing.T) {
+	e, server := newVerifyFirstExecutor(t)
+
+	e.checkAndExecutePlan("p", nil)
+	_, verification := lastExecution(t, e)
+	server.QueueStatus(verification.DepositAddress, "REFUNDED")
+	e.checkSwapStatus("p", verification.ID, verification.DepositAddress)
+
+	p, _ := lastExecution(t, e)
+	if p.Status != StatusPaused || p.PauseReason != verificationPauseReason {
+		t.Errorf("plan %s (%q), want it paused for the failed verification", p.Status, p.PauseReason)
+	}
+	if !p.NeedsVerification() {
+		t.Error("a refunded verification swap marked the plan verified")
+	}
+}
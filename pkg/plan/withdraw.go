@@ -0,0 +1,102 @@
+package plan
+
+import (
+	"fmt"
+	"log/slog"
+	"math/big"
+
+	"near-swap/pkg/deposit"
+)
+
+// withdrawSender sends funds from a configured wallet; deposit.Manager satisfies it
+type withdrawSender interface {
+	SendDeposit(chain, address, amount string) (string, error)
+}
+
+// autoWithdraw forwards a completed execution's output from the recipient wallet to the
+// plan's cold address, when the plan has one and auto_withdraw is enabled
+func (e *Executor) autoWithdraw(planName, executionID string) {
+	plan, err := e.manager.GetPlan(planName)
+	if err != nil || plan.WithdrawTo == "" {
+		return
+	}
+
+	if !e.config.AutoWithdraw.Enabled {
+		e.verifyLog.Info("Plan has a withdraw address but auto_withdraw is disabled",
+			"plan", planName, "execution_id", executionID, "output_address", plan.RecipientAddr)
+		return
+	}
+
+	// Both the daemon's periodic verification and the per-trade verifier can see the
+	// same completion; only one of them may send the withdrawal
+	if _, busy := e.withdrawing.LoadOrStore(executionID, true); busy {
+		return
+	}
+	defer e.withdrawing.Delete(executionID)
+
+	reserve := e.config.AutoWithdraw.FeeReserve[deposit.CanonicalChain(plan.DestChain)]
+	if reserve == 0 {
+		reserve = e.config.AutoWithdraw.FeeReserve[plan.DestChain]
+	}
+
+	sender := e.withdrawer
+	if sender == nil {
+		sender = deposit.NewManager(e.config.AutoDeposit)
+	}
+	withdrawExecution(e.manager, sender, e.verifyLog, plan, executionID, reserve)
+}
+
+// withdrawExecution sends an execution's actual output, less reserve, to the plan's cold
+// address and records the transfer (or why it failed) on the execution
+func withdrawExecution(manager *Manager, sender withdrawSender, log *slog.Logger, plan *TradingPlan, executionID string, reserve float64) {
+	var exec *Execution
+	for i := range plan.ExecutionHistory {
+		if plan.ExecutionHistory[i].ID == executionID {
+			exec = &plan.ExecutionHistory[i]
+			break
+		}
+	}
+	if exec == nil || exec.Status != ExecutionCompleted || exec.WithdrawalTxHash != "" {
+		return
+	}
+
+	fail := func(err error) {
+		log.Error("Auto-withdrawal failed",
+			"plan", plan.Name, "execution_id", executionID, "event", "withdrawal_failed", "error", err)
+		if recordErr := manager.RecordWithdrawal(plan.Name, executionID, "", "", err.Error()); recordErr != nil {
+			log.Error("Error recording withdrawal",
+				"plan", plan.Name, "execution_id", executionID, "error", recordErr)
+		}
+	}
+
+	// Only forward output the swap has actually delivered to the recipient
+	if exec.ActualOutput == "" || exec.DestinationTxHash == "" {
+		fail(fmt.Errorf("swap output or destination transaction not reported yet"))
+		return
+	}
+
+	output, err := parseDecimal(exec.ActualOutput)
+	if err != nil {
+		fail(err)
+		return
+	}
+	amount := new(big.Rat).Sub(output, new(big.Rat).SetFloat64(reserve))
+	if amount.Sign() <= 0 {
+		fail(fmt.Errorf("output %s %s does not cover the fee reserve of %v", exec.ActualOutput, plan.DestToken, reserve))
+		return
+	}
+	amountStr := trimDecimal(formatDecimal(amount))
+
+	txid, err := sender.SendDeposit(plan.DestChain, plan.WithdrawTo, amountStr)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	if err := manager.RecordWithdrawal(plan.Name, executionID, txid, amountStr, ""); err != nil {
+		log.Error("Error recording withdrawal", "plan", plan.Name, "execution_id", executionID, "error", err)
+	}
+	log.Info("Withdrew output",
+		"plan", plan.Name, "execution_id", executionID, "event", "withdrawal_sent", "tx_hash", txid,
+		"amount", amountStr, "token", plan.DestToken, "to", plan.WithdrawTo)
+}
@@ -0,0 +1,78 @@
+package plan
+
+import (
+	"errors"
+	"testing"
+)
+
+// fakeWithdrawer records the withdrawals it is asked to send
+type fakeWithdrawer struct {
+	err  error
+	sent []string // "chain address amount"
+}
+
+func (f *fakeWithdrawer) SendDeposit(chain, address, amount string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.sent = append(f.sent, chain+" "+address+" "+amount)
+	return "withdraw-tx", nil
+}
+
+func TestExecutorWithdrawsCompletedOutput(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		sendErr    error
+		wantSent   string // Empty expects nothing sent
+		wantTx     string
+		wantAmount string
+		wantErr    string
+	}{
+		{name: "forwards the output less the fee reserve", enabled: true,
+			wantSent: "near cold.near 0.75", wantTx: "withdraw-tx", wantAmount: "0.75"},
+		{name: "records a failed withdrawal", enabled: true, sendErr: errors.New("insufficient gas"), wantErr: "insufficient gas"},
+		{name: "disabled in config", enabled: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, server := newMockExecutor(t)
+			withdrawer := &fakeWithdrawer{err: tt.sendErr}
+			e.withdrawer = withdrawer
+			e.config.AutoWithdraw.Enabled = tt.enabled
+			e.config.AutoWithdraw.FeeReserve = map[string]float64{"near": 0.25}
+
+			p, _ := e.manager.storage.Get("p")
+			p.WithdrawTo = "cold.near"
+			if err := e.manager.storage.Update(p); err != nil {
+				t.Fatal(err)
+			}
+
+			// The swap completes with an output of 1 USDC
+			e.checkAndExecutePlan("p", nil)
+			_, exec := lastExecution(t, e)
+			server.QueueStatus(exec.DepositAddress, "SUCCESS")
+			if !e.checkSwapStatus("p", exec.ID, exec.DepositAddress) {
+				t.Fatal("successful swap not reported as settled")
+			}
+
+			_, exec = lastExecution(t, e)
+			if len(withdrawer.sent) > 1 || (tt.wantSent == "") != (len(withdrawer.sent) == 0) ||
+				(tt.wantSent != "" && withdrawer.sent[0] != tt.wantSent) {
+				t.Errorf("withdrawals sent = %q, want %q", withdrawer.sent, tt.wantSent)
+			}
+			if exec.WithdrawalTxHash != tt.wantTx || exec.WithdrawnAmount != tt.wantAmount || exec.WithdrawalError != tt.wantErr {
+				t.Errorf("withdrawal tx %q of %q, error %q; want %q of %q, error %q",
+					exec.WithdrawalTxHash, exec.WithdrawnAmount, exec.WithdrawalError, tt.wantTx, tt.wantAmount, tt.wantErr)
+			}
+
+			// Seeing the completion again doesn't withdraw twice
+			server.QueueStatus(exec.DepositAddress, "SUCCESS")
+			e.checkSwapStatus("p", exec.ID, exec.DepositAddress)
+			if tt.wantSent != "" && len(withdrawer.sent) != 1 {
+				t.Errorf("%d withdrawals sent, want one", len(withdrawer.sent))
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package swap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	oneclick "github.com/defuse-protocol/one-click-sdk-go"
+
+	"near-swap/pkg/deposit"
+	"near-swap/pkg/parser"
+	"near-swap/pkg/types"
+)
+
+// Rolled-up outcome of a two-leg swap
+const (
+	StatusCompleted = "completed" // Both legs succeeded
+	StatusPartial   = "partial"   // First leg delivered the intermediate token; second leg did not complete
+	StatusFailed    = "failed"    // First leg did not complete; nothing was swapped
+)
+
+const (
+	DefaultPollInterval = 10 * time.Second // How often leg status is polled
+	DefaultLegTimeout   = 2 * time.Hour    // How long to wait for a single leg to settle
+)
+
+// Client is the subset of the 1Click client needed to run a swap
+type Client interface {
+	GetQuote(req *types.SwapRequest) (*oneclick.QuoteResponse, error)
+	GetSwapStatus(depositAddress string) (*oneclick.GetExecutionStatusResponse, error)
+}
+
+// Depositor sends deposits from the configured wallets
+type Depositor interface {
+	SendDeposit(chain, address, amount string) (string, error)
+}
+
+// LegResult describes how one leg of a multi-leg swap went
+type LegResult struct {
+	SourceToken    string `json:"source_token"`
+	DestToken      string `json:"dest_token"`
+	DepositAddress string `json:"deposit_address,omitempty"`
+	DepositTx      string `json:"deposit_tx,omitempty"`
+	AmountIn       string `json:"amount_in,omitempty"`
+	AmountOut      string `json:"amount_out,omitempty"` // Actual output once settled, otherwise the quoted estimate
+	Status         string `json:"status,omitempty"`     // Last status reported by the API
+	Error          string `json:"error,omitempty"`
+}
+
+// Result is the rolled-up outcome of a multi-leg swap
+type Result struct {
+	Status string      `json:"status"`
+	Legs   []LegResult `json:"legs"`
+}
+
+// ViaSwap swaps First.SourceToken into Second.DestToken through an intermediate token. The
+// first leg delivers the intermediate token to First.RecipientAddr, which must be the
+// auto-deposit wallet for that chain; once it settles, the second leg is quoted for the
+// amount actually received and funded from that wallet.
+type ViaSwap struct {
+	Client    Client
+	Depositor Depositor
+
+	First  types.SwapRequest // Source -> intermediate
+	Second types.SwapRequest // Intermediate -> dest; Amount is set from the first leg's output
+
+	ManualFirstLeg bool          // The first deposit is sent by the user rather than auto-deposited
+	PollInterval   time.Duration // Defaults to DefaultPollInterval
+	LegTimeout     time.Duration // Defaults to DefaultLegTimeout
+
+	// OnQuote is called with each leg's quote before it is funded (leg is 1 or 2).
+	// Returning an error aborts the swap.
+	OnQuote func(leg int, quote *oneclick.Quote) error
+	// OnStatus is called whenever a leg's reported status changes
+	OnStatus func(leg int, status string)
+}
+
+// Run performs both legs. The returned Result is always non-nil and says how far the swap
+// got; the error explains why it stopped short of StatusCompleted.
+func (v *ViaSwap) Run(ctx context.Context) (*Result, error) {
+	result := &Result{
+		Status: StatusFailed,
+		Legs: []LegResult{
+			{SourceToken: v.First.SourceToken, DestToken: v.First.DestToken},
+			{SourceToken: v.Second.SourceToken, DestToken: v.Second.DestToken},
+		},
+	}
+
+	amountOut, err := v.runLeg(ctx, 1, &v.First, &result.Legs[0], v.ManualFirstLeg)
+	if err != nil {
+		return result, fmt.Errorf("first leg (%s -> %s): %w", v.First.SourceToken, v.First.DestToken, err)
+	}
+
+	// From here on the intermediate token sits in the via wallet
+	result.Status = StatusPartial
+	v.Second.Amount = amountOut
+
+	if _, err := v.runLeg(ctx, 2, &v.Second, &result.Legs[1], false); err != nil {
+		err = fmt.Errorf("second leg (%s -> %s): %w", v.Second.SourceToken, v.Second.DestToken, err)
+		if second := result.Legs[1]; second.DepositTx == "" || second.Status == "REFUNDED" {
+			err = fmt.Errorf("%w; the %s from the first leg remains at %s", err, v.Second.SourceToken, v.First.RecipientAddr)
+		}
+		return result, err
+	}
+
+	result.Status = StatusCompleted
+	return result, nil
+}
+
+// runLeg quotes, funds and waits for one leg, returning the amount it delivered
+func (v *ViaSwap) runLeg(ctx context.Context, leg int, req *types.SwapRequest, lr *LegResult, manual bool) (string, error) {
+	fail := func(err error) (string, error) {
+		lr.Error = err.Error()
+		return "", err
+	}
+
+	quote, err := v.Client.GetQuote(req)
+	if err != nil {
+		return fail(fmt.Errorf("failed to get quote: %w", err))
+	}
+	details := quote.GetQuote()
+	depositAddress := details.GetDepositAddress()
+	lr.DepositAddress = depositAddress
+	lr.AmountIn = req.Amount
+	lr.AmountOut = details.GetAmountOutFormatted()
+
+	if v.OnQuote != nil {
+		if err := v.OnQuote(leg, &details); err != nil {
+			return fail(err)
+		}
+	}
+
+	if !manual {
+		// Never send funds back to one of our own addresses
+		if err := deposit.CheckDepositAddress(req.SourceChain, req.DestChain, depositAddress, req.RecipientAddr, req.RefundAddr); err != nil {
+			return fail(err)
+		}
+		txid, err := v.Depositor.SendDeposit(req.SourceChain, depositAddress, req.Amount)
+		if err != nil {
+			return fail(fmt.Errorf("deposit failed: %w", err))
+		}
+		lr.DepositTx = txid
+	}
+
+	status, err := v.waitForLeg(ctx, leg, depositAddress, lr)
+	if err != nil {
+		return fail(err)
+	}
+
+	swapDetails := status.GetSwapDetails()
+	amountOut, err := parser.NormalizeFormattedAmount(swapDetails.GetAmountOutFormatted())
+	if err != nil {
+		return fail(fmt.Errorf("swap succeeded but reported no usable output amount: %w", err))
+	}
+	lr.AmountOut = amountOut
+	return amountOut, nil
+}
+
+// waitForLeg polls a leg's status until it succeeds, fails or times out
+func (v *ViaSwap) waitForLeg(ctx context.Context, leg int, depositAddress string, lr *LegResult) (*oneclick.GetExecutionStatusResponse, error) {
+	pollInterval := v.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	timeout := v.LegTimeout
+	if timeout <= 0 {
+		timeout = DefaultLegTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		status, err := v.Client.GetSwapStatus(depositAddress)
+		if err == nil {
+			swapStatus := strings.ToUpper(status.GetStatus())
+			if swapStatus != lr.Status {
+				lr.Status = swapStatus
+				if v.OnStatus != nil {
+					v.OnStatus(leg, swapStatus)
+				}
+			}
+
+			switch swapStatus {
+			case "SUCCESS", "COMPLETED":
+				return status, nil
+			case "FAILED", "REFUNDED":
+				return nil, fmt.Errorf("swap %s", strings.ToLower(swapStatus))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				last := lr.Status
+				if last == "" {
+					last = "unconfirmed"
+				}
+				return nil, fmt.Errorf("swap still %s after %s; check it with 'near-swap status %s'",
+					last, timeout, depositAddress)
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
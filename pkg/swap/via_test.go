@@ -0,0 +1,123 @@
+package swap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"near-swap/pkg/mockserver"
+	"near-swap/pkg/types"
+)
+
+// recordingDepositor records the deposits it is asked to send
+type recordingDepositor struct {
+	sent []string
+}
+
+func (d *recordingDepositor) SendDeposit(chain, address, amount string) (string, error) {
+	d.sent = append(d.sent, fmt.Sprintf("%s %s %s", chain, address, amount))
+	return fmt.Sprintf("tx-%d", len(d.sent)), nil
+}
+
+func TestViaSwapAgainstMockServer(t *testing.T) {
+	tests := []struct {
+		name         string
+		firstLeg     []string // Statuses the first leg's deposit address reports, in order
+		secondLeg    []string
+		wantStatus   string
+		wantDeposits int
+		wantErr      string
+		wantStatuses []string // Leg status changes reported through OnStatus
+	}{
+		{
+			name:         "both legs settle",
+			firstLeg:     []string{"PROCESSING", "SUCCESS"},
+			secondLeg:    []string{"SUCCESS"},
+			wantStatus:   StatusCompleted,
+			wantDeposits: 2,
+			wantStatuses: []string{"1 PROCESSING", "1 SUCCESS", "2 SUCCESS"},
+		},
+		{
+			name:         "second leg refunded",
+			firstLeg:     []string{"SUCCESS"},
+			secondLeg:    []string{"PROCESSING", "REFUNDED"},
+			wantStatus:   StatusPartial,
+			wantDeposits: 2,
+			wantErr:      "remains at via.near",
+			wantStatuses: []string{"1 SUCCESS", "2 PROCESSING", "2 REFUNDED"},
+		},
+		{
+			name:         "first leg fails",
+			firstLeg:     []string{"FAILED"},
+			wantStatus:   StatusFailed,
+			wantDeposits: 1,
+			wantErr:      "first leg (BTC -> USDC): swap failed",
+			wantStatuses: []string{"1 FAILED"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockserver.New()
+			defer server.Close()
+			btc := server.AddToken("BTC", "btc", 8, 60000)
+			usdc := server.AddToken("USDC", "near", 6, 1)
+			eth := server.AddToken("ETH", "eth", 18, 3000)
+			server.SetRate(btc, usdc, 60000)
+			server.SetRate(usdc, eth, 0.0005)
+
+			// Quotes hand out deposit addresses in order, one per leg
+			server.QueueStatus("mock-deposit-1", tt.firstLeg...)
+			server.QueueStatus("mock-deposit-2", tt.secondLeg...)
+
+			depositor := &recordingDepositor{}
+			var statuses []string
+			via := &ViaSwap{
+				Client:    server.Client("test-token"),
+				Depositor: depositor,
+				First: types.SwapRequest{
+					Amount: "0.1", SourceToken: "BTC", DestToken: "USDC", SourceChain: "btc", DestChain: "near",
+					RecipientAddr: "via.near", RefundAddr: "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq",
+				},
+				Second: types.SwapRequest{
+					SourceToken: "USDC", DestToken: "ETH", SourceChain: "near", DestChain: "eth",
+					RecipientAddr: "0x1111111111111111111111111111111111111111", RefundAddr: "via.near",
+				},
+				PollInterval: time.Millisecond,
+				LegTimeout:   time.Second,
+				OnStatus: func(leg int, status string) {
+					statuses = append(statuses, fmt.Sprintf("%d %s", leg, status))
+				},
+			}
+
+			result, err := via.Run(context.Background())
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want it to mention %q", err, tt.wantErr)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", result.Status, tt.wantStatus)
+			}
+			if len(depositor.sent) != tt.wantDeposits {
+				t.Errorf("deposits = %v, want %d", depositor.sent, tt.wantDeposits)
+			}
+			if got := strings.Join(statuses, ", "); got != strings.Join(tt.wantStatuses, ", ") {
+				t.Errorf("status changes = %s, want %s", got, strings.Join(tt.wantStatuses, ", "))
+			}
+
+			// The second leg trades what the first actually delivered
+			if tt.wantDeposits == 2 {
+				if via.Second.Amount != result.Legs[0].AmountOut {
+					t.Errorf("second leg amount = %s, want the first leg's output %s", via.Second.Amount, result.Legs[0].AmountOut)
+				}
+				if want := "near mock-deposit-2 " + result.Legs[0].AmountOut; depositor.sent[1] != want {
+					t.Errorf("second deposit = %q, want %q", depositor.sent[1], want)
+				}
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 // SwapRequest represents a user's swap command
 type SwapRequest struct {
 	Amount          string
@@ -9,6 +11,13 @@ type SwapRequest struct {
 	DestChain       string
 	RecipientAddr   string
 	RefundAddr      string
+	Referral        string        // Optional referral identifier (omitted when empty)
+	AppFeeRecipient string        // Optional integrator fee recipient (omitted when empty)
+	AppFeeBps       float32       // Integrator fee in basis points
+	ExactOutput     bool          // Amount is the dest amount to receive (EXACT_OUTPUT) rather than the source amount to spend
+	SlippageBps     int           // Slippage tolerance in basis points (0 uses the client default)
+	Deadline        time.Duration // Quote deadline from now (0 uses the client default)
+	Dry             bool          // Preview the quote without generating a deposit address
 }
 
 // QuoteDisplay holds formatted quote information for display